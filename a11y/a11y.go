@@ -0,0 +1,210 @@
+// Package a11y проверяет доступность содержимого админки: структуру заголовков
+// и альтернативный текст изображений в HTML страниц, контраст цветов оформления.
+// Найденные проблемы - предупреждения самодиагностики (проверка accessibility)
+package a11y
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// BrandingTarget цель проблем оформления в отчете самодиагностики
+const BrandingTarget = "branding"
+
+var (
+	// commentPattern комментарии HTML
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// rawTextPattern скрипты и стили, содержимое которых не является разметкой
+	rawTextPattern = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>`)
+	// tagPattern открывающий или закрывающий тег
+	tagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)\b((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	// attrPattern атрибут тега со значением в кавычках, без кавычек или без значения
+	attrPattern = regexp.MustCompile(`([^\s"'=/<>]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+)
+
+// tag тег разметки
+type tag struct {
+	name    string
+	closing bool
+	attrs   map[string]string
+	start   int // начало тега в разметке
+	end     int // конец тега в разметке
+}
+
+// Page проверяет HTML содержимое страницы: у изображений есть атрибут alt
+// (пустой - для декоративных), заголовки не пустые и идут без пропуска уровней
+func Page(page *types.Page) []types.DiagnosticIssue {
+	if page.Content == "" {
+		return nil
+	}
+
+	var issues []types.DiagnosticIssue
+	for _, message := range AuditHTML(page.Content) {
+		issues = append(issues, types.DiagnosticIssue{
+			Level:   types.DiagnosticWarning,
+			Target:  page.Name,
+			Message: message,
+		})
+	}
+	return issues
+}
+
+// AuditHTML проверяет разметку и возвращает описания найденных проблем
+func AuditHTML(content string) []string {
+	content = commentPattern.ReplaceAllStringFunc(content, blank)
+	content = rawTextPattern.ReplaceAllStringFunc(content, blank)
+	tags := parseTags(content)
+
+	var messages []string
+	previous, h1 := 0, 0
+	for i, t := range tags {
+		if t.closing {
+			continue
+		}
+
+		switch t.name {
+		case "img", "area":
+			if _, ok := t.attrs["alt"]; !ok {
+				messages = append(messages, fmt.Sprintf("Изображение %s без атрибута alt", describe(t)))
+			}
+		case "input":
+			if _, ok := t.attrs["alt"]; !ok && strings.EqualFold(t.attrs["type"], "image") {
+				messages = append(messages, fmt.Sprintf("Кнопка-изображение %s без атрибута alt", describe(t)))
+			}
+		}
+
+		level := headingLevel(t.name)
+		if level == 0 {
+			continue
+		}
+		if level == 1 {
+			if h1++; h1 == 2 {
+				messages = append(messages, "Больше одного заголовка h1")
+			}
+		}
+		switch {
+		case previous == 0 && level > 2:
+			messages = append(messages, fmt.Sprintf("Первый заголовок страницы - %s, ожидается h1 или h2", t.name))
+		case previous > 0 && level > previous+1:
+			messages = append(messages, fmt.Sprintf("Пропущен уровень заголовка: %s после h%d", t.name, previous))
+		}
+		previous = level
+
+		if t.attrs["aria-label"] == "" && strings.TrimSpace(headingText(content, tags, i)) == "" {
+			messages = append(messages, fmt.Sprintf("Пустой заголовок %s", t.name))
+		}
+	}
+	return messages
+}
+
+// Branding проверяет контраст цветов оформления по WCAG 2.1 AA и альтернативный текст логотипа
+func Branding(branding *types.Branding) []types.DiagnosticIssue {
+	if branding == nil {
+		return nil
+	}
+
+	var issues []types.DiagnosticIssue
+	add := func(format string, args ...interface{}) {
+		issues = append(issues, types.DiagnosticIssue{
+			Level:   types.DiagnosticWarning,
+			Target:  BrandingTarget,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if branding.Logo != "" && strings.TrimSpace(branding.LogoAlt) == "" {
+		add("Логотип без альтернативного текста (LogoAlt)")
+	}
+
+	colors := branding.Colors()
+	invalid := make(map[string]bool)
+	for _, color := range []string{colors.PrimaryColor, colors.PrimaryTextColor, colors.BackgroundColor, colors.TextColor} {
+		if _, err := ParseColor(color); color != "" && err != nil && !invalid[color] {
+			invalid[color] = true
+			add("%v", err)
+		}
+	}
+
+	pairs := []struct {
+		name       string
+		foreground string
+		background string
+		min        float64
+	}{
+		{"текст на фоне", colors.TextColor, colors.BackgroundColor, MinTextContrast},
+		{"текст кнопок на основном цвете", colors.PrimaryTextColor, colors.PrimaryColor, MinTextContrast},
+		{"основной цвет на фоне", colors.PrimaryColor, colors.BackgroundColor, MinUIContrast},
+	}
+	for _, pair := range pairs {
+		if pair.foreground == "" || pair.background == "" || invalid[pair.foreground] || invalid[pair.background] {
+			continue
+		}
+		if ratio, _ := ContrastRatio(pair.foreground, pair.background); ratio < pair.min {
+			add("Недостаточный контраст (%s): %s и %s - %.2f:1, требуется не менее %.1f:1",
+				pair.name, pair.foreground, pair.background, ratio, pair.min)
+		}
+	}
+	return issues
+}
+
+// parseTags возвращает теги разметки по порядку
+func parseTags(content string) []tag {
+	var tags []tag
+	for _, match := range tagPattern.FindAllStringSubmatchIndex(content, -1) {
+		t := tag{
+			name:    strings.ToLower(content[match[4]:match[5]]),
+			closing: match[3] > match[2],
+			attrs:   make(map[string]string),
+			start:   match[0],
+			end:     match[1],
+		}
+		for _, attr := range attrPattern.FindAllStringSubmatch(content[match[6]:match[7]], -1) {
+			t.attrs[strings.ToLower(attr[1])] = attr[2] + attr[3] + attr[4]
+		}
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// headingText возвращает текст заголовка tags[open] до закрывающего тега:
+// разметка отбрасывается, изображения учитываются по alt
+func headingText(content string, tags []tag, open int) string {
+	name := tags[open].name
+	var text strings.Builder
+	pos := tags[open].end
+	for _, t := range tags[open+1:] {
+		text.WriteString(content[pos:t.start])
+		pos = t.end
+		if t.closing && t.name == name {
+			return text.String()
+		}
+		if t.name == "img" {
+			text.WriteString(t.attrs["alt"])
+		}
+	}
+	return text.String() + content[pos:]
+}
+
+// headingLevel возвращает уровень заголовка h1-h6 или 0
+func headingLevel(name string) int {
+	if len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6' {
+		return int(name[1] - '0')
+	}
+	return 0
+}
+
+// describe возвращает src изображения для сообщения
+func describe(t tag) string {
+	if src := t.attrs["src"]; src != "" {
+		return src
+	}
+	return "<" + t.name + ">"
+}
+
+// blank заменяет фрагмент пробелами той же длины, сохраняя позиции тегов
+func blank(s string) string {
+	return strings.Repeat(" ", len(s))
+}
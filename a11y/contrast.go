@@ -0,0 +1,92 @@
+package a11y
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Минимальный контраст по WCAG 2.1 AA
+const (
+	MinTextContrast = 4.5 // обычный текст (1.4.3)
+	MinUIContrast   = 3.0 // крупный текст и элементы управления (1.4.11)
+)
+
+// namedColors цвета, которые можно указать по имени
+var namedColors = map[string][3]uint8{
+	"white": {255, 255, 255},
+	"black": {0, 0, 0},
+}
+
+// ContrastRatio возвращает контраст двух цветов по WCAG: от 1 (одинаковые) до 21 (черный на белом).
+// Цвета задаются как #rgb, #rrggbb, rgb(r, g, b), white или black
+func ContrastRatio(foreground, background string) (float64, error) {
+	fg, err := ParseColor(foreground)
+	if err != nil {
+		return 0, err
+	}
+	bg, err := ParseColor(background)
+	if err != nil {
+		return 0, err
+	}
+
+	lighter, darker := luminance(fg), luminance(bg)
+	if darker > lighter {
+		lighter, darker = darker, lighter
+	}
+	return (lighter + 0.05) / (darker + 0.05), nil
+}
+
+// ParseColor разбирает цвет CSS: #rgb, #rrggbb, rgb(r, g, b), white или black
+func ParseColor(color string) ([3]uint8, error) {
+	value := strings.ToLower(strings.TrimSpace(color))
+	if rgb, ok := namedColors[value]; ok {
+		return rgb, nil
+	}
+
+	var rgb [3]uint8
+	switch {
+	case strings.HasPrefix(value, "#") && (len(value) == 4 || len(value) == 7):
+		digits := value[1:]
+		if len(digits) == 3 {
+			digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+		}
+		for i := range rgb {
+			n, err := strconv.ParseUint(digits[2*i:2*i+2], 16, 8)
+			if err != nil {
+				return rgb, fmt.Errorf("некорректный цвет %q", color)
+			}
+			rgb[i] = uint8(n)
+		}
+		return rgb, nil
+	case strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")"):
+		parts := strings.Split(value[len("rgb("):len(value)-1], ",")
+		if len(parts) != 3 {
+			return rgb, fmt.Errorf("некорректный цвет %q", color)
+		}
+		for i, part := range parts {
+			n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+			if err != nil {
+				return rgb, fmt.Errorf("некорректный цвет %q", color)
+			}
+			rgb[i] = uint8(n)
+		}
+		return rgb, nil
+	}
+	return rgb, fmt.Errorf("неподдерживаемый формат цвета %q (#rrggbb, #rgb, rgb(r, g, b))", color)
+}
+
+// luminance возвращает относительную яркость цвета по WCAG
+func luminance(rgb [3]uint8) float64 {
+	var channels [3]float64
+	for i, c := range rgb {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			channels[i] = v / 12.92
+		} else {
+			channels[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*channels[0] + 0.7152*channels[1] + 0.0722*channels[2]
+}
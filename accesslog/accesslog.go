@@ -0,0 +1,124 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// Redacted значение, которым заменяются чувствительные поля
+const Redacted = "[redacted]"
+
+// DefaultSensitiveNames части имен полей, значения которых никогда не записываются
+var DefaultSensitiveNames = []string{"password", "passwd", "secret", "token", "card", "cvv", "cvc", "passport", "snils"}
+
+// Entry строка журнала доступа
+type Entry struct {
+	Time      time.Time              `json:"time"`
+	Method    string                 `json:"method"`
+	Path      string                 `json:"path"`
+	Status    int                    `json:"status"`
+	LatencyMs float64                `json:"latencyMs"`
+	Bytes     int                    `json:"bytes"`
+	RequestID string                 `json:"requestId,omitempty"`
+	RemoteIP  string                 `json:"remoteIp,omitempty"`
+	User      string                 `json:"user,omitempty"`
+	Form      string                 `json:"form,omitempty"`
+	Fields    []string               `json:"fields,omitempty"` // имена отправленных полей
+	Values    map[string]interface{} `json:"values,omitempty"` // значения при Options.LogValues
+}
+
+// Options настройки журнала доступа
+type Options struct {
+	// LogValues добавляет значения отправленных полей, кроме чувствительных.
+	// По умолчанию записываются только имена полей
+	LogValues bool
+
+	// SensitiveNames части имен полей (без учета регистра), значения которых не записываются.
+	// Дополняют DefaultSensitiveNames, поля типа password и поля с Sensitive
+	SensitiveNames []string
+}
+
+// Logger пишет журнал доступа в формате JSON Lines (по записи на строку)
+type Logger struct {
+	mu        sync.Mutex
+	encoder   *json.Encoder
+	logValues bool
+	sensitive []string
+}
+
+// New создает журнал доступа, пишущий в w
+func New(w io.Writer, options Options) *Logger {
+	return &Logger{
+		encoder:   json.NewEncoder(w),
+		logValues: options.LogValues,
+		sensitive: sensitiveNames(options.SensitiveNames),
+	}
+}
+
+// sensitiveNames объединяет DefaultSensitiveNames с дополнительными именами в нижнем регистре
+func sensitiveNames(extra []string) []string {
+	sensitive := make([]string, 0, len(DefaultSensitiveNames)+len(extra))
+	for _, name := range append(append([]string(nil), DefaultSensitiveNames...), extra...) {
+		sensitive = append(sensitive, strings.ToLower(name))
+	}
+	return sensitive
+}
+
+// Write записывает строку журнала
+func (l *Logger) Write(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.encoder.Encode(entry)
+}
+
+// Submission заполняет поля записи по отправленным данным формы:
+// имена полей и, при Options.LogValues, значения с заменой чувствительных на Redacted
+func (l *Logger) Submission(entry *Entry, form *types.Form, data map[string]interface{}) {
+	entry.Form = form.Key()
+	if len(data) == 0 {
+		return
+	}
+
+	entry.Fields = make([]string, 0, len(data))
+	for name := range data {
+		entry.Fields = append(entry.Fields, name)
+	}
+	sort.Strings(entry.Fields)
+
+	if !l.logValues {
+		return
+	}
+
+	entry.Values = make(map[string]interface{}, len(data))
+	for name, value := range data {
+		if isSensitive(form, name, l.sensitive) {
+			entry.Values[name] = Redacted
+			continue
+		}
+		entry.Values[name] = value
+	}
+}
+
+// isSensitive проверяет, является ли поле чувствительным
+func isSensitive(form *types.Form, name string, sensitive []string) bool {
+	for _, field := range form.Fields {
+		if field.Name == name && field.IsSensitive() {
+			return true
+		}
+	}
+
+	lower := strings.ToLower(name)
+	for _, part := range sensitive {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// MaxDebugPayload максимальный размер тела запроса или ответа в отладочной записи
+const MaxDebugPayload = 64 << 10
+
+// DebugEntry отладочная запись с полными телами запроса и ответа формы
+type DebugEntry struct {
+	Time      time.Time   `json:"time"`
+	Form      string      `json:"form"`
+	Method    string      `json:"method"`
+	Path      string      `json:"path"`
+	Status    int         `json:"status"`
+	LatencyMs float64     `json:"latencyMs"`
+	RequestID string      `json:"requestId,omitempty"`
+	User      string      `json:"user,omitempty"`
+	Request   interface{} `json:"request,omitempty"`
+	Response  interface{} `json:"response,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"` // тело запроса или ответа обрезано до MaxDebugPayload
+}
+
+// DebugLogger пишет отладочные записи форм в формате JSON Lines.
+// Значения чувствительных полей заменяются на Redacted на любом уровне вложенности
+type DebugLogger struct {
+	mu        sync.Mutex
+	encoder   *json.Encoder
+	sensitive []string
+}
+
+// NewDebug создает отладочный журнал, пишущий в w. Используется Options.SensitiveNames
+func NewDebug(w io.Writer, options Options) *DebugLogger {
+	return &DebugLogger{
+		encoder:   json.NewEncoder(w),
+		sensitive: sensitiveNames(options.SensitiveNames),
+	}
+}
+
+// Write записывает отладочную запись
+func (l *DebugLogger) Write(entry DebugEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.encoder.Encode(entry)
+}
+
+// Payload разбирает тело запроса или ответа и скрывает чувствительные значения.
+// Тело, не являющееся JSON, записывается строкой
+func (l *DebugLogger) Payload(form *types.Form, body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+	return l.redact(form, value)
+}
+
+// redact рекурсивно заменяет значения чувствительных полей
+func (l *DebugLogger) redact(form *types.Form, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, item := range v {
+			if isSensitive(form, name, l.sensitive) {
+				v[name] = Redacted
+				continue
+			}
+			v[name] = l.redact(form, item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = l.redact(form, item)
+		}
+	}
+	return value
+}
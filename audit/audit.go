@@ -0,0 +1,295 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/privacy"
+)
+
+// Действия, которые записывает админка
+const (
+	ActionFormSubmit        = "form.submit"
+	ActionFormAction        = "form.action"
+	ActionFormBatch         = "form.batch"
+	ActionFormPatch         = "form.patch"
+	ActionTableCellUpdate   = "table.cell_update"
+	ActionCommentCreate     = "comment.create"
+	ActionCommentDelete     = "comment.delete"
+	ActionTokenCreate       = "token.create"
+	ActionTokenRevoke       = "token.revoke"
+	ActionApprovalApprove   = "approval.approve"
+	ActionApprovalReject    = "approval.reject"
+	ActionUndo              = "undo"
+	ActionMaintenanceChange = "maintenance.change"
+	ActionDebugEnable       = "debug.enable"
+	ActionDebugDisable      = "debug.disable"
+	ActionProfilingCapture  = "debug.pprof"
+	ActionRouteCreate       = "route.create"
+	ActionRouteDelete       = "route.delete"
+	ActionPrivacyExport     = "privacy.export"
+	ActionPrivacyErase      = "privacy.erase"
+	ActionBackupCreate      = "backup.create"
+	ActionBackupRestore     = "backup.restore"
+	ActionConfigApply       = "config.apply"
+	ActionSnapshotView      = "snapshot.view"
+	ActionSnapshotReplay    = "snapshot.replay"
+)
+
+// ErrTampered возвращается, если цепочка записей журнала нарушена
+var ErrTampered = errors.New("журнал аудита изменен")
+
+// Entry представляет запись журнала аудита.
+// Hash вычисляется как HMAC от хеша предыдущей записи и содержимого текущей,
+// поэтому изменение или удаление любой записи нарушает цепочку
+type Entry struct {
+	Seq      uint64                 `json:"seq"`
+	Time     time.Time              `json:"time"`
+	Actor    string                 `json:"actor,omitempty"`
+	Action   string                 `json:"action"`
+	Target   string                 `json:"target,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	PrevHash string                 `json:"prevHash"`
+	Hash     string                 `json:"hash"`
+}
+
+// Log журнал аудита с HMAC цепочкой записей
+type Log struct {
+	mu       sync.RWMutex
+	key      []byte
+	entries  []*Entry
+	seq      uint64
+	lastHash string
+
+	forwarders []*Forwarder
+}
+
+// NewLog создает журнал аудита, подписывающий записи ключом key
+func NewLog(key []byte) *Log {
+	return &Log{
+		key: append([]byte(nil), key...),
+	}
+}
+
+// Record добавляет запись в журнал. Автор берется из контекста (auth.UserFromContext).
+// Безопасен для вызова на nil журнале
+func (l *Log) Record(ctx context.Context, action, target string, details map[string]interface{}) *Entry {
+	if l == nil {
+		return nil
+	}
+
+	entry := &Entry{
+		Time:    time.Now().UTC(),
+		Action:  action,
+		Target:  target,
+		Details: details,
+	}
+	if user, ok := auth.UserFromContext(ctx); ok {
+		entry.Actor = user.ID
+		if user.TokenID != "" {
+			entry.Details = make(map[string]interface{}, len(details)+1)
+			for key, value := range details {
+				entry.Details[key] = value
+			}
+			entry.Details["token"] = user.TokenID
+		}
+	}
+
+	l.mu.Lock()
+	l.seq++
+	entry.Seq = l.seq
+	entry.PrevHash = l.lastHash
+	entry.Hash = computeHash(l.key, entry)
+
+	l.entries = append(l.entries, entry)
+	l.lastHash = entry.Hash
+	forwarders := l.forwarders
+	l.mu.Unlock()
+
+	for _, f := range forwarders {
+		f.enqueue(entry)
+	}
+	return entry
+}
+
+// AddForwarder подключает доставку новых записей во внешнюю систему
+func (l *Log) AddForwarder(f *Forwarder) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.forwarders = append(l.forwarders[:len(l.forwarders):len(l.forwarders)], f)
+}
+
+// Entries возвращает копию записей журнала
+func (l *Log) Entries() []*Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append([]*Entry(nil), l.entries...)
+}
+
+// Purge удаляет записи старше before. Оставшаяся часть цепочки остается проверяемой
+func (l *Log) Purge(ctx context.Context, before time.Time, dryRun bool) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Записи упорядочены по времени, удаляем префикс
+	count := 0
+	for count < len(l.entries) && l.entries[count].Time.Before(before) {
+		count++
+	}
+
+	if !dryRun && count > 0 {
+		l.entries = append([]*Entry(nil), l.entries[count:]...)
+	}
+	return count, nil
+}
+
+// ExportSubject возвращает записи, в которых субъект является автором, целью или упоминается в деталях
+func (l *Log) ExportSubject(ctx context.Context, subject string) ([]interface{}, error) {
+	records := make([]interface{}, 0)
+	for _, entry := range l.Entries() {
+		if entryMatches(entry, subject) {
+			records = append(records, entry)
+		}
+	}
+	return records, nil
+}
+
+// EraseSubject обезличивает записи субъекта и заново подписывает цепочку,
+// начиная с первой измененной записи. Само удаление фиксируется отдельной записью
+func (l *Log) EraseSubject(ctx context.Context, subject string) (int, error) {
+	l.mu.Lock()
+
+	count := 0
+	first := -1
+	for i, entry := range l.entries {
+		if !entryMatches(entry, subject) {
+			continue
+		}
+
+		erased := *entry
+		if privacy.Matches(erased.Actor, subject) {
+			erased.Actor = privacy.Erased
+		}
+		if privacy.Matches(erased.Target, subject) {
+			erased.Target = privacy.Erased
+		}
+		if erased.Details != nil {
+			details, _ := privacy.Redact(erased.Details, subject)
+			erased.Details, _ = details.(map[string]interface{})
+		}
+
+		l.entries[i] = &erased
+		count++
+		if first < 0 {
+			first = i
+		}
+	}
+
+	if first >= 0 {
+		l.rechain(first)
+	}
+	l.mu.Unlock()
+
+	if count > 0 {
+		l.Record(ctx, ActionPrivacyErase, "", map[string]interface{}{"records": count})
+	}
+	return count, nil
+}
+
+// rechain пересчитывает подписи записей начиная с индекса from
+func (l *Log) rechain(from int) {
+	prevHash := ""
+	if from > 0 {
+		prevHash = l.entries[from-1].Hash
+	} else if len(l.entries) > 0 {
+		prevHash = l.entries[0].PrevHash
+	}
+
+	// Записи копируются, чтобы не изменять ранее выданные Entries()
+	for i := from; i < len(l.entries); i++ {
+		entry := *l.entries[i]
+		entry.PrevHash = prevHash
+		entry.Hash = computeHash(l.key, &entry)
+		l.entries[i] = &entry
+		prevHash = entry.Hash
+	}
+	l.lastHash = prevHash
+}
+
+// entryMatches проверяет, связана ли запись с субъектом
+func entryMatches(entry *Entry, subject string) bool {
+	return privacy.Matches(entry.Actor, subject) ||
+		privacy.Matches(entry.Target, subject) ||
+		privacy.Contains(entry.Details, subject)
+}
+
+// Restore заменяет записи журнала восстановленными из резервной копии.
+// Записи должны быть подписаны ключом журнала, новые записи продолжают их цепочку
+func (l *Log) Restore(entries []*Entry) error {
+	if err := l.VerifyEntries(entries); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append([]*Entry(nil), entries...)
+	l.seq, l.lastHash = 0, ""
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		l.seq, l.lastHash = last.Seq, last.Hash
+	}
+	return nil
+}
+
+// Verify проверяет цепочку записей журнала
+func (l *Log) Verify() error {
+	return Verify(l.Entries(), l.key)
+}
+
+// VerifyEntries проверяет экспортированные записи ключом журнала
+func (l *Log) VerifyEntries(entries []*Entry) error {
+	return Verify(entries, l.key)
+}
+
+// Verify проверяет цепочку экспортированных записей ключом key.
+// Первая запись может ссылаться на удаленную (например, политикой хранения) предыдущую запись
+func Verify(entries []*Entry, key []byte) error {
+	for i, entry := range entries {
+		if i == 0 && entry.Seq == 1 && entry.PrevHash != "" {
+			return fmt.Errorf("%w: первая запись ссылается на предыдущую", ErrTampered)
+		}
+		if i > 0 {
+			previous := entries[i-1]
+			if entry.Seq != previous.Seq+1 || entry.PrevHash != previous.Hash {
+				return fmt.Errorf("%w: разрыв цепочки на записи %d", ErrTampered, entry.Seq)
+			}
+		}
+
+		if !hmac.Equal([]byte(computeHash(key, entry)), []byte(entry.Hash)) {
+			return fmt.Errorf("%w: некорректная подпись записи %d", ErrTampered, entry.Seq)
+		}
+	}
+	return nil
+}
+
+// computeHash вычисляет подпись записи
+func computeHash(key []byte, entry *Entry) string {
+	unsigned := *entry
+	unsigned.Hash = ""
+	payload, _ := json.Marshal(unsigned)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
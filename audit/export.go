@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Форматы экспорта журнала
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// csvHeader заголовок CSV экспорта
+var csvHeader = []string{"seq", "time", "actor", "action", "target", "details", "prevHash", "hash"}
+
+// Export записывает журнал в формате FormatJSONL или FormatCSV
+func Export(w io.Writer, entries []*Entry, format string) error {
+	switch format {
+	case FormatJSONL, "":
+		return ExportJSONL(w, entries)
+	case FormatCSV:
+		return ExportCSV(w, entries)
+	default:
+		return fmt.Errorf("неподдерживаемый формат экспорта: %s", format)
+	}
+}
+
+// ExportJSONL записывает журнал в формате JSON Lines (одна запись на строку)
+func ExportJSONL(w io.Writer, entries []*Entry) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportCSV записывает журнал в формате CSV. Details сериализуются в JSON
+func ExportCSV(w io.Writer, entries []*Entry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		details := ""
+		if entry.Details != nil {
+			data, err := json.Marshal(entry.Details)
+			if err != nil {
+				return err
+			}
+			details = string(data)
+		}
+
+		record := []string{
+			strconv.FormatUint(entry.Seq, 10),
+			entry.Time.Format(time.RFC3339Nano),
+			entry.Actor,
+			entry.Action,
+			entry.Target,
+			details,
+			entry.PrevHash,
+			entry.Hash,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Read читает экспортированный журнал в формате FormatJSONL или FormatCSV
+func Read(r io.Reader, format string) ([]*Entry, error) {
+	switch format {
+	case FormatJSONL, "":
+		return ReadJSONL(r)
+	case FormatCSV:
+		return ReadCSV(r)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат экспорта: %s", format)
+	}
+}
+
+// ReadJSONL читает журнал в формате JSON Lines
+func ReadJSONL(r io.Reader) ([]*Entry, error) {
+	entries := make([]*Entry, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("некорректная запись журнала: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// ReadCSV читает журнал в формате CSV
+func ReadCSV(r io.Reader) ([]*Entry, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("некорректный CSV: %w", err)
+	}
+
+	entries := make([]*Entry, 0, len(records))
+	for i, record := range records {
+		if i == 0 {
+			continue // заголовок
+		}
+		if len(record) != len(csvHeader) {
+			return nil, fmt.Errorf("строка %d: ожидается %d колонок", i+1, len(csvHeader))
+		}
+
+		seq, err := strconv.ParseUint(record[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: некорректный seq: %w", i+1, err)
+		}
+
+		recordTime, err := time.Parse(time.RFC3339Nano, record[1])
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: некорректное время: %w", i+1, err)
+		}
+
+		entry := &Entry{
+			Seq:      seq,
+			Time:     recordTime,
+			Actor:    record[2],
+			Action:   record[3],
+			Target:   record[4],
+			PrevHash: record[6],
+			Hash:     record[7],
+		}
+		if record[5] != "" {
+			if err := json.Unmarshal([]byte(record[5]), &entry.Details); err != nil {
+				return nil, fmt.Errorf("строка %d: некорректные details: %w", i+1, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
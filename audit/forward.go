@@ -0,0 +1,236 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Sink доставляет записи журнала во внешнюю систему (SIEM, Splunk, ELK).
+// Send получает записи в порядке Seq и должен вернуть ошибку, если пакет не доставлен
+type Sink interface {
+	Send(ctx context.Context, entries []*Entry) error
+}
+
+// permanentError ошибка доставки, которую бессмысленно повторять
+type permanentError struct {
+	err error
+}
+
+// Error возвращает текст ошибки
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent помечает ошибку Sink как неисправимую: пакет отбрасывается без повторов
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent сообщает, что ошибка помечена Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Значения ForwardOptions по умолчанию
+const (
+	DefaultForwardBuffer   = 10000
+	DefaultForwardBatch    = 100
+	DefaultForwardInterval = 5 * time.Second
+	DefaultForwardRetries  = 5
+	DefaultForwardBackoff  = time.Second
+)
+
+// maxForwardBackoff максимальная пауза между повторами
+const maxForwardBackoff = time.Minute
+
+// closeTimeout время на доставку оставшихся записей после остановки
+const closeTimeout = 10 * time.Second
+
+// ForwardOptions настройки буферизации и повторов доставки
+type ForwardOptions struct {
+	BufferSize    int           // записей в очереди; при переполнении новые записи отбрасываются
+	BatchSize     int           // записей в одном вызове Send
+	FlushInterval time.Duration // максимальное ожидание неполного пакета
+	MaxRetries    int           // повторов пакета, после которых он отбрасывается; отрицательное значение - без повторов
+	RetryBackoff  time.Duration // первая пауза между повторами, далее удваивается (до минуты)
+
+	// OnError получает ошибку доставки и число отброшенных записей (0, если пакет будет повторен)
+	OnError func(err error, dropped int)
+}
+
+// withDefaults возвращает настройки с заполненными значениями по умолчанию
+func (o ForwardOptions) withDefaults() ForwardOptions {
+	if o.BufferSize <= 0 {
+		o.BufferSize = DefaultForwardBuffer
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultForwardBatch
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = DefaultForwardInterval
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	} else if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultForwardRetries
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = DefaultForwardBackoff
+	}
+	return o
+}
+
+// ForwarderStats счетчики доставки
+type ForwarderStats struct {
+	Sent    uint64 `json:"sent"`
+	Dropped uint64 `json:"dropped"`
+	Pending int    `json:"pending"`
+}
+
+// Forwarder буферизует записи журнала и доставляет их в Sink пакетами с повторами.
+// Доставка "хотя бы один раз": после сбоя пакет может быть отправлен повторно,
+// получатель может убрать дубликаты по Seq и Hash
+type Forwarder struct {
+	sink    Sink
+	options ForwardOptions
+	queue   chan *Entry
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// NewForwarder создает доставку записей в sink. Записи начинают поступать
+// после Log.AddForwarder, а отправляются после Start
+func NewForwarder(sink Sink, options ForwardOptions) *Forwarder {
+	options = options.withDefaults()
+	return &Forwarder{
+		sink:    sink,
+		options: options,
+		queue:   make(chan *Entry, options.BufferSize),
+	}
+}
+
+// Stats возвращает счетчики доставки
+func (f *Forwarder) Stats() ForwarderStats {
+	return ForwarderStats{
+		Sent:    f.sent.Load(),
+		Dropped: f.dropped.Load(),
+		Pending: len(f.queue),
+	}
+}
+
+// enqueue ставит запись в очередь, не блокируя запись журнала
+func (f *Forwarder) enqueue(entry *Entry) {
+	select {
+	case f.queue <- entry:
+	default:
+		f.dropped.Add(1)
+		f.report(errors.New("очередь доставки аудита переполнена"), 1)
+	}
+}
+
+// Start запускает доставку до отмены ctx. После отмены оставшиеся записи
+// отправляются одной попыткой с ограничением по времени
+func (f *Forwarder) Start(ctx context.Context) {
+	go f.run(ctx)
+}
+
+// run собирает пакеты и отправляет их
+func (f *Forwarder) run(ctx context.Context) {
+	ticker := time.NewTicker(f.options.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Entry, 0, f.options.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			f.drain(batch)
+			return
+		case entry := <-f.queue:
+			batch = append(batch, entry)
+			if len(batch) < f.options.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		if !f.deliver(ctx, batch) && ctx.Err() != nil {
+			f.drain(batch)
+			return
+		}
+		batch = make([]*Entry, 0, f.options.BatchSize)
+	}
+}
+
+// deliver отправляет пакет с повторами. Возвращает false, если пакет не доставлен
+func (f *Forwarder) deliver(ctx context.Context, batch []*Entry) bool {
+	backoff := f.options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := f.sink.Send(ctx, batch)
+		if err == nil {
+			f.sent.Add(uint64(len(batch)))
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		if IsPermanent(err) || attempt >= f.options.MaxRetries {
+			f.dropped.Add(uint64(len(batch)))
+			f.report(err, len(batch))
+			return false
+		}
+		f.report(err, 0)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxForwardBackoff)
+	}
+}
+
+// drain отправляет текущий пакет и очередь после остановки
+func (f *Forwarder) drain(batch []*Entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+
+queued:
+	for {
+		select {
+		case entry := <-f.queue:
+			batch = append(batch, entry)
+		default:
+			break queued
+		}
+	}
+
+	for len(batch) > 0 {
+		n := min(len(batch), f.options.BatchSize)
+		if err := f.sink.Send(ctx, batch[:n]); err != nil {
+			f.dropped.Add(uint64(len(batch)))
+			f.report(err, len(batch))
+			return
+		}
+		f.sent.Add(uint64(n))
+		batch = batch[n:]
+	}
+}
+
+// report передает ошибку в OnError
+func (f *Forwarder) report(err error, dropped int) {
+	if f.options.OnError != nil {
+		f.options.OnError(err, dropped)
+	}
+}
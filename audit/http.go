@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultHTTPContentType тип тела пакета HTTPSink по умолчанию
+const DefaultHTTPContentType = "application/x-ndjson"
+
+// HTTPSink отправляет пакеты записей POST запросом (Splunk HEC, Logstash http input,
+// Elasticsearch ingest). Ответ не 2xx считается ошибкой; ответы 4xx, кроме 408 и 429,
+// не повторяются
+type HTTPSink struct {
+	URL         string
+	Client      *http.Client                              // по умолчанию http.DefaultClient
+	Headers     map[string]string                         // например, Authorization
+	ContentType string                                    // по умолчанию DefaultHTTPContentType
+	Encode      func(w io.Writer, entries []*Entry) error // по умолчанию ExportJSONL
+}
+
+// NewHTTPSink создает отправку пакетов JSON Lines на url
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{URL: url}
+}
+
+// Send отправляет пакет одним запросом
+func (s *HTTPSink) Send(ctx context.Context, entries []*Entry) error {
+	encode := s.Encode
+	if encode == nil {
+		encode = ExportJSONL
+	}
+	var body bytes.Buffer
+	if err := encode(&body, entries); err != nil {
+		return Permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, &body)
+	if err != nil {
+		return Permanent(err)
+	}
+	contentType := s.ContentType
+	if contentType == "" {
+		contentType = DefaultHTTPContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range s.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("отправка аудита: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("отправка аудита: ответ %s", resp.Status)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Значения SyslogSink по умолчанию
+const (
+	DefaultSyslogApp      = "formist"
+	DefaultSyslogFacility = 13 // log audit
+	DefaultSyslogSeverity = 6  // informational
+)
+
+// syslogSDID идентификатор структурированных данных записи (RFC 5424, раздел 6.3.2)
+const syslogSDID = "formist@32473"
+
+// syslogDialTimeout время на подключение к серверу syslog
+const syslogDialTimeout = 10 * time.Second
+
+// SyslogSink отправляет записи журнала на сервер syslog в формате RFC 5424.
+// Поля записи передаются структурированными данными, сама запись - телом сообщения в JSON.
+// По UDP каждая запись отправляется отдельной датаграммой, по TCP сообщения
+// разделяются подсчетом октетов (RFC 6587); с TLS подключение защищено (RFC 5425)
+type SyslogSink struct {
+	Network  string      // "udp", "tcp" или "tls"
+	Address  string      // host:port
+	TLS      *tls.Config // настройки TLS для Network "tls"
+	App      string      // APP-NAME, по умолчанию DefaultSyslogApp
+	Hostname string      // HOSTNAME, по умолчанию имя хоста
+	Facility int         // по умолчанию DefaultSyslogFacility
+	Severity int         // по умолчанию DefaultSyslogSeverity
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink создает отправку в syslog по сети network ("udp", "tcp" или "tls")
+func NewSyslogSink(network, address string) *SyslogSink {
+	return &SyslogSink{
+		Network:  network,
+		Address:  address,
+		Facility: DefaultSyslogFacility,
+		Severity: DefaultSyslogSeverity,
+	}
+}
+
+// Send отправляет записи. При ошибке соединение закрывается и переоткрывается при следующей отправке
+func (s *SyslogSink) Send(ctx context.Context, entries []*Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	deadline, _ := ctx.Deadline() // без срока - нулевое время, запись без ограничения
+	s.conn.SetWriteDeadline(deadline)
+
+	for _, entry := range entries {
+		message, err := s.format(entry)
+		if err != nil {
+			return Permanent(err)
+		}
+		if s.Network != "udp" {
+			message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+		}
+		if _, err := s.conn.Write(message); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("отправка в syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close закрывает соединение с сервером syslog
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// dial подключается к серверу syslog
+func (s *SyslogSink) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+	switch s.Network {
+	case "udp", "tcp":
+		return dialer.DialContext(ctx, s.Network, s.Address)
+	case "tls":
+		config := s.TLS
+		if config == nil {
+			config = &tls.Config{}
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: config}
+		return tlsDialer.DialContext(ctx, "tcp", s.Address)
+	default:
+		return nil, Permanent(fmt.Errorf("неподдерживаемая сеть syslog: %s", s.Network))
+	}
+}
+
+// format формирует сообщение RFC 5424:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME - MSGID [SD] MSG
+func (s *SyslogSink) format(entry *Entry) ([]byte, error) {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	app := s.App
+	if app == "" {
+		app = DefaultSyslogApp
+	}
+	hostname := s.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s - %s [%s seq=\"%d\"",
+		s.Facility*8+s.Severity,
+		entry.Time.UTC().Format(time.RFC3339Nano),
+		syslogName(hostname, 255),
+		syslogName(app, 48),
+		syslogName(entry.Action, 32),
+		syslogSDID,
+		entry.Seq,
+	)
+	for _, param := range [][2]string{{"actor", entry.Actor}, {"target", entry.Target}, {"hash", entry.Hash}} {
+		if param[1] != "" {
+			fmt.Fprintf(&b, " %s=\"%s\"", param[0], syslogEscape(param[1]))
+		}
+	}
+	b.WriteString("] ")
+	b.Write(body)
+	return []byte(b.String()), nil
+}
+
+// syslogName приводит значение заголовка к печатаемым ASCII символам без пробелов
+// и ограничивает длину; пустое значение заменяется на "-"
+func syslogName(value string, limit int) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(name) > limit {
+		name = name[:limit]
+	}
+	if name == "" {
+		return "-"
+	}
+	return name
+}
+
+// syslogEscape экранирует значение параметра структурированных данных
+var syslogEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// PermissionRoutesWrite разрешение на изменение роутов через /api/routes
+const PermissionRoutesWrite = "routes:write"
+
+// PermissionDebugWrite разрешение на включение отладочного журнала форм через /api/debug
+const PermissionDebugWrite = "debug:write"
+
+// PermissionBackup разрешение на выгрузку и восстановление резервных копий через /api/backup
+const PermissionBackup = "backup:manage"
+
+// PermissionPrivacy разрешение на выгрузку и обезличивание данных субъектов через /api/privacy
+const PermissionPrivacy = "privacy:manage"
+
+// PermissionRetention разрешение на просмотр политик хранения и запуск очистки через /api/retention
+const PermissionRetention = "retention:manage"
+
+// PermissionMaintenance разрешение на включение и выключение режима обслуживания через PUT /api/maintenance
+const PermissionMaintenance = "maintenance:write"
+
+// PermissionAudit разрешение на экспорт и проверку журнала аудита через /api/audit
+const PermissionAudit = "audit:read"
+
+// PermissionFederation разрешение на просмотр федерации через /api/federation и запросы к удаленным админкам через /admin/remote
+const PermissionFederation = "federation:access"
+
+// PermissionDiagnostics разрешение на просмотр отчета самодиагностики /admin/diagnostics
+const PermissionDiagnostics = "diagnostics:read"
+
+// PermissionCommentsModerate разрешение на удаление чужих комментариев
+const PermissionCommentsModerate = "comments:moderate"
+
+// PermissionMetrics разрешение на просмотр сводок, метрик и правил оповещений SLO через /api/slo
+const PermissionMetrics = "metrics:read"
+
+// PermissionProfiling разрешение на снятие профилей через /admin/debug/pprof
+const PermissionProfiling = "profiling:read"
+
+// PermissionTokensManage разрешение на просмотр и отзыв чужих персональных токенов
+const PermissionTokensManage = "tokens:manage"
+
+// PermissionSnapshots разрешение на просмотр снимков неудачных запросов через /api/snapshots
+const PermissionSnapshots = "snapshots:read"
+
+// PermissionSnapshotsReplay разрешение на повтор снимков в пробном режиме (дополнительно к snapshots:read)
+const PermissionSnapshotsReplay = "snapshots:replay"
+
+// PermissionSnapshotsWrite разрешение на удаление снимков (дополнительно к snapshots:read)
+const PermissionSnapshotsWrite = "snapshots:write"
+
+// User представляет пользователя админки
+type User struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	Timezone    string   `json:"timezone,omitempty"` // часовой пояс IANA, например Europe/Moscow
+	TokenID     string   `json:"tokenId,omitempty"`  // персональный токен, которым аутентифицирован запрос
+}
+
+// HasPermission проверяет наличие разрешения у пользователя
+func (u *User) HasPermission(permission string) bool {
+	if u == nil {
+		return false
+	}
+	for _, p := range u.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole проверяет наличие роли у пользователя
+func (u *User) HasRole(role string) bool {
+	if u == nil {
+		return false
+	}
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAnyRole проверяет наличие хотя бы одной из ролей.
+// Пустой список ролей означает отсутствие ограничений
+func (u *User) HasAnyRole(roles []string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if u.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// userKey ключ пользователя в контексте
+type userKey struct{}
+
+// WithUser возвращает контекст с пользователем
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext возвращает пользователя из контекста
+func UserFromContext(ctx context.Context) (*User, bool) {
+	user, ok := ctx.Value(userKey{}).(*User)
+	return user, ok && user != nil
+}
+
+// Authenticator определяет пользователя по запросу
+type Authenticator func(req *http.Request) (*User, error)
+
+// Middleware помещает пользователя, определенного authenticator, в контекст запроса.
+// Запросы, для которых пользователь не определен, обрабатываются анонимно
+func Middleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			user, err := authenticator(req)
+			if err == nil && user != nil {
+				req = req.WithContext(WithUser(req.Context(), user))
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
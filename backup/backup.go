@@ -0,0 +1,290 @@
+// Package backup выгружает данные, которыми управляет formist (роуты, настройки,
+// заявки на согласование, журнал аудита), в переносимый архив tar.gz и восстанавливает их
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/workflow"
+)
+
+// Version версия формата архива
+const Version = 1
+
+// Файлы архива
+const (
+	manifestFile    = "manifest.json"
+	routesFile      = "routes.jsonl"
+	settingsFile    = "settings.json"
+	submissionsFile = "submissions.jsonl"
+	auditFile       = "audit.jsonl"
+)
+
+// ErrInvalidArchive возвращается, если архив поврежден или имеет неподдерживаемый формат
+var ErrInvalidArchive = errors.New("некорректный архив резервной копии")
+
+// Sources хранилища, которые попадают в резервную копию. Незаданные пропускаются
+type Sources struct {
+	Storage     storage.Storage
+	Settings    []string // ключи настроек SettingsStorage
+	Submissions workflow.Store
+	Audit       *audit.Log
+}
+
+// Manifest описывает содержимое архива
+type Manifest struct {
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Routes      int       `json:"routes"`
+	Settings    int       `json:"settings"`
+	Submissions int       `json:"submissions"`
+	Audit       int       `json:"audit"`
+}
+
+// archive содержимое резервной копии
+type archive struct {
+	manifest    Manifest
+	routes      []*storage.Route
+	settings    map[string][]byte
+	submissions []*workflow.Submission
+	audit       []*audit.Entry
+}
+
+// Write выгружает данные sources в w
+func Write(ctx context.Context, w io.Writer, sources Sources) (*Manifest, error) {
+	a, err := collect(ctx, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{manifestFile, func(w io.Writer) error { return json.NewEncoder(w).Encode(a.manifest) }},
+		{routesFile, func(w io.Writer) error { return writeLines(w, a.routes) }},
+		{settingsFile, func(w io.Writer) error { return json.NewEncoder(w).Encode(a.settings) }},
+		{submissionsFile, func(w io.Writer) error { return writeLines(w, a.submissions) }},
+		{auditFile, func(w io.Writer) error { return audit.ExportJSONL(w, a.audit) }},
+	}
+
+	for _, file := range files {
+		var buf bytes.Buffer
+		if err := file.write(&buf); err != nil {
+			return nil, err
+		}
+
+		header := &tar.Header{
+			Name:    file.name,
+			Mode:    0o600,
+			Size:    int64(buf.Len()),
+			ModTime: a.manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(buf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return &a.manifest, nil
+}
+
+// Restore восстанавливает данные из архива r в sources. Роуты, настройки и заявки
+// сохраняются поверх существующих, журнал аудита заменяется целиком.
+// Архив полностью проверяется (включая подписи журнала) до записи.
+// При dryRun данные только проверяются
+func Restore(ctx context.Context, r io.Reader, sources Sources, dryRun bool) (*Manifest, error) {
+	a, err := read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if sources.Audit != nil {
+		if err := sources.Audit.VerifyEntries(a.audit); err != nil {
+			return nil, err
+		}
+	}
+	if dryRun {
+		return &a.manifest, nil
+	}
+
+	if sources.Storage != nil && len(a.routes) > 0 {
+		if err := storage.SaveRoutes(ctx, sources.Storage, a.routes); err != nil {
+			return nil, fmt.Errorf("восстановление роутов: %w", err)
+		}
+	}
+
+	if settings, ok := sources.Storage.(storage.SettingsStorage); ok {
+		for key, value := range a.settings {
+			if err := settings.SaveSetting(ctx, key, value); err != nil {
+				return nil, fmt.Errorf("восстановление настройки %s: %w", key, err)
+			}
+		}
+	}
+
+	if sources.Submissions != nil {
+		for _, submission := range a.submissions {
+			if err := sources.Submissions.Save(ctx, submission); err != nil {
+				return nil, fmt.Errorf("восстановление заявки %s: %w", submission.ID, err)
+			}
+		}
+	}
+
+	if sources.Audit != nil {
+		if err := sources.Audit.Restore(a.audit); err != nil {
+			return nil, err
+		}
+	}
+	return &a.manifest, nil
+}
+
+// collect читает данные из хранилищ
+func collect(ctx context.Context, sources Sources) (*archive, error) {
+	a := &archive{
+		manifest: Manifest{Version: Version, CreatedAt: time.Now().UTC()},
+		routes:   make([]*storage.Route, 0),
+		settings: make(map[string][]byte),
+	}
+
+	if sources.Storage != nil {
+		routes, err := sources.Storage.GetRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("чтение роутов: %w", err)
+		}
+		a.routes = routes
+
+		if settings, ok := sources.Storage.(storage.SettingsStorage); ok {
+			for _, key := range sources.Settings {
+				value, err := settings.GetSetting(ctx, key)
+				if errors.Is(err, storage.ErrNotFound) {
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("чтение настройки %s: %w", key, err)
+				}
+				a.settings[key] = value
+			}
+		}
+	}
+
+	if sources.Submissions != nil {
+		submissions, err := sources.Submissions.List(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("чтение заявок: %w", err)
+		}
+		a.submissions = submissions
+	}
+
+	if sources.Audit != nil {
+		a.audit = sources.Audit.Entries()
+	}
+
+	a.manifest.Routes = len(a.routes)
+	a.manifest.Settings = len(a.settings)
+	a.manifest.Submissions = len(a.submissions)
+	a.manifest.Audit = len(a.audit)
+	return a, nil
+}
+
+// read разбирает архив
+func read(r io.Reader) (*archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	a := &archive{}
+	seen := make(map[string]bool)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		switch header.Name {
+		case manifestFile:
+			err = json.NewDecoder(tr).Decode(&a.manifest)
+		case routesFile:
+			a.routes, err = readLines[storage.Route](tr)
+		case settingsFile:
+			err = json.NewDecoder(tr).Decode(&a.settings)
+		case submissionsFile:
+			a.submissions, err = readLines[workflow.Submission](tr)
+		case auditFile:
+			a.audit, err = audit.ReadJSONL(tr)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, header.Name, err)
+		}
+		seen[header.Name] = true
+	}
+
+	if !seen[manifestFile] {
+		return nil, fmt.Errorf("%w: нет %s", ErrInvalidArchive, manifestFile)
+	}
+	if a.manifest.Version != Version {
+		return nil, fmt.Errorf("%w: неподдерживаемая версия %d", ErrInvalidArchive, a.manifest.Version)
+	}
+	return a, nil
+}
+
+// writeLines записывает значения в формате JSON Lines
+func writeLines[T any](w io.Writer, items []T) error {
+	encoder := json.NewEncoder(w)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLines читает значения в формате JSON Lines
+func readLines[T any](r io.Reader) ([]*T, error) {
+	items := make([]*T, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		item := new(T)
+		if err := json.Unmarshal(line, item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
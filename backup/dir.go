@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Имена файлов резервных копий в каталоге
+const (
+	filePrefix = "formist-backup-"
+	fileSuffix = ".tar.gz"
+)
+
+// WriteFile записывает резервную копию в каталог dir и возвращает путь к файлу.
+// Файл появляется в каталоге только после полной записи
+func WriteFile(ctx context.Context, dir string, sources Sources) (string, *Manifest, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", nil, err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".formist-backup-*")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := Write(ctx, tmp, sources)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	name := filepath.Join(dir, filePrefix+manifest.CreatedAt.Format("20060102T150405.000Z")+fileSuffix)
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return "", nil, err
+	}
+	return name, manifest, nil
+}
+
+// Files возвращает резервные копии в каталоге dir от старых к новым
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	// Время в имени файла упорядочивается лексикографически
+	sort.Strings(files)
+	return files, nil
+}
+
+// Prune удаляет резервные копии в каталоге dir, кроме keep последних
+func Prune(dir string, keep int) (int, error) {
+	files, err := Files(dir)
+	if err != nil || len(files) <= keep {
+		return 0, err
+	}
+
+	removed := 0
+	for _, file := range files[:len(files)-keep] {
+		if err := os.Remove(file); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Schedule описывает периодическое резервное копирование в каталог
+type Schedule struct {
+	Dir      string
+	Interval time.Duration
+	Keep     int // сколько последних копий хранить, 0 - все
+}
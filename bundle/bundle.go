@@ -0,0 +1,386 @@
+// Package bundle описывает конфигурацию админки (роуты форм и страниц, настройки storage)
+// переносимым пакетом и импортирует его в два шага: план изменений, затем явное применение
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/koteyye/go-formist/storage"
+)
+
+// Version версия формата пакета
+const Version = 1
+
+// Ошибки импорта
+var (
+	ErrInvalid = errors.New("некорректный пакет конфигурации")
+	ErrStale   = errors.New("конфигурация изменилась после построения плана, постройте план заново")
+)
+
+// Sources хранилища, которыми управляет пакет
+type Sources struct {
+	Storage  storage.Storage
+	Settings []string // ключи настроек SettingsStorage, попадающие в выгрузку
+}
+
+// Bundle желаемое состояние конфигурации. Роуты сопоставляются по имени:
+// роуты хранилища, которых нет в пакете, удаляются. Настройки, не указанные в пакете, не меняются
+type Bundle struct {
+	Version  int                        `json:"version"`
+	Routes   []*storage.Route           `json:"routes"`
+	Settings map[string]json.RawMessage `json:"settings,omitempty"`
+}
+
+// Validate нормализует роуты и проверяет пакет
+func (b *Bundle) Validate() error {
+	if b.Version != Version {
+		return fmt.Errorf("%w: неподдерживаемая версия %d", ErrInvalid, b.Version)
+	}
+
+	names := make(map[string]bool, len(b.Routes))
+	for i, route := range b.Routes {
+		if route == nil {
+			return fmt.Errorf("%w: пустой роут %d", ErrInvalid, i)
+		}
+		route.Normalize()
+		if err := route.Validate(); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalid, route.Name, err)
+		}
+		if names[route.Name] {
+			return fmt.Errorf("%w: роут %s указан дважды", ErrInvalid, route.Name)
+		}
+		names[route.Name] = true
+	}
+
+	for key, value := range b.Settings {
+		if !json.Valid(value) {
+			return fmt.Errorf("%w: настройка %s не является JSON", ErrInvalid, key)
+		}
+	}
+	return nil
+}
+
+// Kind вид изменяемого объекта
+type Kind string
+
+// Виды объектов
+const (
+	KindForm    Kind = storage.RouteTypeForm
+	KindPage    Kind = storage.RouteTypePage
+	KindSetting Kind = "setting"
+)
+
+// Op операция плана
+type Op string
+
+// Операции плана
+const (
+	OpCreate Op = "create"
+	OpUpdate Op = "update"
+	OpDelete Op = "delete"
+)
+
+// Change изменение одного объекта
+type Change struct {
+	Kind   Kind        `json:"kind"`
+	Op     Op          `json:"op"`
+	Name   string      `json:"name"`
+	Fields []string    `json:"fields,omitempty"` // измененные поля при update
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Summary количество изменений по операциям
+type Summary struct {
+	Create int `json:"create"`
+	Update int `json:"update"`
+	Delete int `json:"delete"`
+}
+
+// Plan изменения, которые внесет применение пакета. План применим, пока
+// состояние хранилища совпадает с тем, по которому он построен (State)
+type Plan struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	State       string    `json:"state"` // хеш исходного состояния
+	Changes     []Change  `json:"changes"`
+	Summary     Summary   `json:"summary"`
+	Destructive bool      `json:"destructive"` // план удаляет роуты
+
+	bundle *Bundle
+}
+
+// Empty сообщает, что план ничего не меняет
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// state текущее состояние: роуты по имени и значения настроек из keys
+type state struct {
+	routes   map[string]*storage.Route
+	settings map[string][]byte
+}
+
+// readState читает роуты и настройки keys из хранилища
+func readState(ctx context.Context, sources Sources, keys []string) (*state, error) {
+	current := &state{
+		routes:   make(map[string]*storage.Route),
+		settings: make(map[string][]byte),
+	}
+	if sources.Storage == nil {
+		return current, nil
+	}
+
+	routes, err := sources.Storage.GetRoutes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("чтение роутов: %w", err)
+	}
+	for _, route := range routes {
+		current.routes[route.Name] = route
+	}
+
+	if settings, ok := sources.Storage.(storage.SettingsStorage); ok {
+		for _, key := range keys {
+			value, err := settings.GetSetting(ctx, key)
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("чтение настройки %s: %w", key, err)
+			}
+			current.settings[key] = value
+		}
+	}
+	return current, nil
+}
+
+// hash возвращает хеш состояния без времени изменения роутов
+func (s *state) hash() string {
+	names := make([]string, 0, len(s.routes))
+	for name := range s.routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	encoder := json.NewEncoder(h)
+	for _, name := range names {
+		route := *s.routes[name]
+		route.CreatedAt, route.UpdatedAt = time.Time{}, time.Time{}
+		encoder.Encode(route)
+	}
+
+	keys := make([]string, 0, len(s.settings))
+	for key := range s.settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		encoder.Encode([]interface{}{key, json.RawMessage(s.settings[key])})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Export выгружает текущую конфигурацию пакетом
+func Export(ctx context.Context, sources Sources) (*Bundle, error) {
+	current, err := readState(ctx, sources, sources.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		Version: Version,
+		Routes:  make([]*storage.Route, 0, len(current.routes)),
+	}
+	for _, route := range current.routes {
+		b.Routes = append(b.Routes, route)
+	}
+	sort.Slice(b.Routes, func(i, j int) bool {
+		return b.Routes[i].Name < b.Routes[j].Name
+	})
+
+	if len(current.settings) > 0 {
+		b.Settings = make(map[string]json.RawMessage, len(current.settings))
+		for key, value := range current.settings {
+			b.Settings[key] = value
+		}
+	}
+	return b, nil
+}
+
+// NewPlan проверяет пакет и сравнивает его с текущим состоянием
+func NewPlan(ctx context.Context, sources Sources, b *Bundle) (*Plan, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	current, err := readState(ctx, sources, settingKeys(b))
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		CreatedAt: time.Now().UTC(),
+		State:     current.hash(),
+		Changes:   make([]Change, 0),
+		bundle:    b,
+	}
+
+	desired := make(map[string]bool, len(b.Routes))
+	for _, route := range b.Routes {
+		desired[route.Name] = true
+		existing, ok := current.routes[route.Name]
+		if !ok {
+			plan.add(Change{Kind: Kind(route.Type), Op: OpCreate, Name: route.Name, After: route})
+			continue
+		}
+		if fields := routeDiff(existing, route); len(fields) > 0 {
+			plan.add(Change{Kind: Kind(route.Type), Op: OpUpdate, Name: route.Name, Fields: fields, Before: existing, After: route})
+		}
+	}
+	for name, route := range current.routes {
+		if !desired[name] {
+			plan.add(Change{Kind: Kind(route.Type), Op: OpDelete, Name: name, Before: route})
+			plan.Destructive = true
+		}
+	}
+
+	for _, key := range settingKeys(b) {
+		value := b.Settings[key]
+		existing, ok := current.settings[key]
+		switch {
+		case !ok:
+			plan.add(Change{Kind: KindSetting, Op: OpCreate, Name: key, After: value})
+		case !jsonEqual(existing, value):
+			plan.add(Change{Kind: KindSetting, Op: OpUpdate, Name: key, Before: json.RawMessage(existing), After: value})
+		}
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return plan, nil
+}
+
+// add добавляет изменение в план
+func (p *Plan) add(change Change) {
+	p.Changes = append(p.Changes, change)
+	switch change.Op {
+	case OpCreate:
+		p.Summary.Create++
+	case OpUpdate:
+		p.Summary.Update++
+	case OpDelete:
+		p.Summary.Delete++
+	}
+}
+
+// Apply применяет план, если состояние хранилища не изменилось с момента его построения
+func Apply(ctx context.Context, sources Sources, plan *Plan) error {
+	if plan.bundle == nil {
+		return fmt.Errorf("%w: план не содержит пакета", ErrInvalid)
+	}
+	if sources.Storage == nil {
+		return errors.New("storage не подключен")
+	}
+
+	current, err := readState(ctx, sources, settingKeys(plan.bundle))
+	if err != nil {
+		return err
+	}
+	if current.hash() != plan.State {
+		return ErrStale
+	}
+
+	var save []*storage.Route
+	for _, change := range plan.Changes {
+		if change.Kind == KindSetting {
+			continue
+		}
+		switch change.Op {
+		case OpDelete:
+			if err := sources.Storage.DeleteRoute(ctx, current.routes[change.Name].ID); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("удаление роута %s: %w", change.Name, err)
+			}
+		case OpCreate, OpUpdate:
+			route := *change.After.(*storage.Route)
+			route.ID, route.CreatedAt = "", time.Time{}
+			if existing, ok := current.routes[route.Name]; ok {
+				route.ID, route.CreatedAt = existing.ID, existing.CreatedAt
+			}
+			save = append(save, &route)
+		}
+	}
+	if len(save) > 0 {
+		if err := storage.SaveRoutes(ctx, sources.Storage, save); err != nil {
+			return fmt.Errorf("сохранение роутов: %w", err)
+		}
+	}
+
+	settings, _ := sources.Storage.(storage.SettingsStorage)
+	for _, change := range plan.Changes {
+		if change.Kind != KindSetting {
+			continue
+		}
+		if settings == nil {
+			return errors.New("storage не поддерживает настройки")
+		}
+		if err := settings.SaveSetting(ctx, change.Name, plan.bundle.Settings[change.Name]); err != nil {
+			return fmt.Errorf("сохранение настройки %s: %w", change.Name, err)
+		}
+	}
+	return nil
+}
+
+// routeDiff возвращает поля роута, которые изменит пакет
+func routeDiff(before, after *storage.Route) []string {
+	var fields []string
+	for _, field := range []struct {
+		name          string
+		before, after string
+	}{
+		{"path", before.Path, after.Path},
+		{"title", before.Title, after.Title},
+		{"description", before.Description, after.Description},
+		{"icon", before.Icon, after.Icon},
+		{"type", before.Type, after.Type},
+	} {
+		if field.before != field.after {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
+// settingKeys возвращает ключи настроек пакета по порядку
+func settingKeys(b *Bundle) []string {
+	keys := make([]string, 0, len(b.Settings))
+	for key := range b.Settings {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// jsonEqual сравнивает значения JSON без учета форматирования
+func jsonEqual(a, b []byte) bool {
+	var compactA, compactB bytes.Buffer
+	if json.Compact(&compactA, a) != nil || json.Compact(&compactB, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
@@ -0,0 +1,74 @@
+package formist
+
+import (
+	"context"
+	"time"
+
+	"github.com/koteyye/go-formist/storage"
+)
+
+// ListenChanges подписывается на изменения, сделанные другими экземплярами приложения
+// с общим storage (например, через LISTEN/NOTIFY в PostgreSQL). При изменении настроек
+// перезагружается режим обслуживания, при изменении форм выполняется синхронизация
+// с реестром. onChange, если задан, получает каждое уведомление (например, для сброса
+// собственных кешей). При обрыве соединения подписка восстанавливается с задержкой
+// согласно политике повторов, ошибки передаются в onError. Работает до отмены ctx
+func (a *Admin) ListenChanges(ctx context.Context, onChange func(storage.ChangeEvent), onError func(error)) error {
+	notifier, ok := storage.ChangeNotifierOf(a.storage)
+	if !ok {
+		return storage.ErrChangesNotSupported
+	}
+
+	handle := func(event storage.ChangeEvent) {
+		if err := a.applyChange(ctx, event); err != nil && onError != nil {
+			onError(err)
+		}
+		if onChange != nil {
+			onChange(event)
+		}
+	}
+
+	go func() {
+		attempt := 0
+		for {
+			err := notifier.ListenChanges(ctx, handle)
+			if ctx.Err() != nil {
+				return
+			}
+
+			attempt++
+			if err != nil && onError != nil {
+				onError(err)
+			}
+
+			timer := time.NewTimer(a.retryPolicy.Backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// applyChange обновляет состояние экземпляра по уведомлению об изменении
+func (a *Admin) applyChange(ctx context.Context, event storage.ChangeEvent) error {
+	switch event.Kind {
+	case storage.ChangeKindSetting:
+		if event.Key == maintenanceSettingKey {
+			return a.LoadMaintenance(ctx)
+		}
+	case storage.ChangeKindForms:
+		a.syncMu.Lock()
+		source := a.syncSource
+		a.syncMu.Unlock()
+
+		if source != nil {
+			return a.SyncFormsOnce(ctx, source)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package chaos внедряет задержки и ошибки в ответы админки, чтобы проверить,
+// как UI переносит медленный или отказывающий бэкенд. Не предназначен для продакшена
+package chaos
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// DefaultErrorStatus статус внедренной ошибки по умолчанию
+const DefaultErrorStatus = http.StatusServiceUnavailable
+
+// Header заголовок ответа с примененным воздействием: latency или error
+const Header = "X-Chaos"
+
+// Rule правило внедрения сбоев. Для запроса применяется первое подходящее правило
+type Rule struct {
+	Path        string        // шаблон пути без префикса в формате path.Match, пустой - любой путь
+	Methods     []string      // методы запроса, пустой список - любые
+	Latency     time.Duration // задержка перед обработкой
+	Jitter      time.Duration // случайная добавка к задержке от 0 до Jitter
+	ErrorRate   float64       // доля запросов от 0 до 1, завершаемых ошибкой
+	ErrorStatus int           // статус ошибки, по умолчанию DefaultErrorStatus
+}
+
+// Injector применяет правила внедрения сбоев
+type Injector struct {
+	rules []Rule
+}
+
+// New проверяет правила и создает Injector
+func New(rules ...Rule) (*Injector, error) {
+	checked := make([]Rule, len(rules))
+	for i, rule := range rules {
+		if _, err := path.Match(rule.Path, "/"); err != nil {
+			return nil, fmt.Errorf("правило %d: некорректный шаблон пути %q: %w", i, rule.Path, err)
+		}
+		if rule.ErrorRate < 0 || rule.ErrorRate > 1 {
+			return nil, fmt.Errorf("правило %d: доля ошибок должна быть от 0 до 1", i)
+		}
+		if rule.Latency < 0 || rule.Jitter < 0 {
+			return nil, fmt.Errorf("правило %d: задержка не может быть отрицательной", i)
+		}
+		if rule.ErrorStatus == 0 {
+			rule.ErrorStatus = DefaultErrorStatus
+		}
+		if rule.ErrorStatus < 400 || rule.ErrorStatus > 599 {
+			return nil, fmt.Errorf("правило %d: статус ошибки должен быть 4xx или 5xx", i)
+		}
+		rule.Methods = append([]string(nil), rule.Methods...)
+		checked[i] = rule
+	}
+	return &Injector{rules: checked}, nil
+}
+
+// Rules возвращает копию правил
+func (in *Injector) Rules() []Rule {
+	return append([]Rule(nil), in.rules...)
+}
+
+// Match возвращает первое правило для метода и пути запроса
+func (in *Injector) Match(method, urlPath string) (Rule, bool) {
+	for _, rule := range in.rules {
+		if rule.matches(method, urlPath) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Serve применяет правило к запросу. Возвращает false, если ответ уже отправлен
+// (внедрена ошибка или клиент отключился во время задержки)
+func (in *Injector) Serve(w http.ResponseWriter, req *http.Request, rule Rule) bool {
+	if delay := rule.delay(); delay > 0 {
+		w.Header().Set(Header, "latency")
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return false
+		}
+	}
+
+	if rule.ErrorRate > 0 && rand.Float64() < rule.ErrorRate {
+		w.Header().Set(Header, "error")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(rule.ErrorStatus)
+		json.NewEncoder(w).Encode(types.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Внедренная ошибка: %s", http.StatusText(rule.ErrorStatus)),
+		})
+		return false
+	}
+	return true
+}
+
+// matches проверяет, подходит ли правило запросу
+func (rule Rule) matches(method, urlPath string) bool {
+	if len(rule.Methods) > 0 {
+		found := false
+		for _, m := range rule.Methods {
+			if strings.EqualFold(m, method) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if rule.Path == "" {
+		return true
+	}
+	matched, _ := path.Match(rule.Path, strings.TrimRight(urlPath, "/"))
+	return matched
+}
+
+// delay возвращает задержку с учетом случайной добавки
+func (rule Rule) delay() time.Duration {
+	delay := rule.Latency
+	if rule.Jitter > 0 {
+		delay += rand.N(rule.Jitter + 1)
+	}
+	return delay
+}
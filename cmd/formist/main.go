@@ -0,0 +1,103 @@
+// Команда formist выполняет служебные операции с определениями форм.
+//
+//	formist lint [-strict] form.json...
+//
+// lint проверяет определения форм в JSON (объект формы или массив форм) и завершается
+// с кодом 1, если найдены ошибки (с -strict - и предупреждения)
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/koteyye/go-formist"
+	"github.com/koteyye/go-formist/types"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "lint":
+		os.Exit(runLint(os.Args[2:], os.Stdout, os.Stderr))
+	case "help", "-h", "-help", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "неизвестная команда %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+}
+
+// usage выводит список команд
+func usage() {
+	fmt.Fprintln(os.Stderr, "Использование: formist <команда> [аргументы]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Команды:")
+	fmt.Fprintln(os.Stderr, "  lint [-strict] form.json...  проверка определений форм")
+}
+
+// runLint проверяет файлы с определениями форм и возвращает код завершения
+func runLint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	strict := flags.Bool("strict", false, "считать предупреждения ошибками")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "Использование: formist lint [-strict] form.json...")
+		return 2
+	}
+
+	failed := false
+	for _, path := range flags.Args() {
+		forms, err := readForms(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			return 2
+		}
+
+		for _, form := range forms {
+			for _, issue := range formist.Lint(form) {
+				fmt.Fprintf(stdout, "%s: %s: %s: %s\n", path, issue.Level, issue.Target, issue.Message)
+				if issue.Level == types.DiagnosticError || *strict {
+					failed = true
+				}
+			}
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// readForms читает объект формы или массив форм из файла
+func readForms(path string) ([]*types.Form, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var forms []*types.Form
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(raw, &forms)
+	} else {
+		var form types.Form
+		err = json.Unmarshal(raw, &form)
+		forms = append(forms, &form)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("некорректное определение формы: %w", err)
+	}
+	return forms, nil
+}
@@ -0,0 +1,52 @@
+// Package codec задает кодек JSON для ответов и тел запросов админки.
+// По умолчанию используется encoding/json; на нагруженных инсталляциях его можно
+// заменить более быстрой совместимой реализацией (sonic, go-json)
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Codec кодирует и декодирует JSON. Реализация должна быть совместима с encoding/json:
+// учитывать теги json, Marshaler/Unmarshaler и экранировать HTML
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Standard кодек на основе encoding/json
+type Standard struct{}
+
+// Marshal кодирует v через json.Marshal
+func (Standard) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal декодирует data через json.Unmarshal
+func (Standard) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Encode записывает v в w с переводом строки в конце, как json.Encoder
+func Encode(c Codec, w io.Writer, v interface{}) error {
+	data, err := c.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Decode читает тело r целиком и декодирует его в v. Пустое тело - io.EOF, как у json.Decoder
+func Decode(c Codec, r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+	return c.Unmarshal(data, v)
+}
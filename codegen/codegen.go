@@ -0,0 +1,422 @@
+// Package codegen превращает определение формы (зарегистрированное или собранное
+// в визуальном конструкторе) в код FormBuilder, который можно хранить в репозитории
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/koteyye/go-formist/form"
+	"github.com/koteyye/go-formist/types"
+)
+
+// DefaultPackage имя пакета сгенерированного кода по умолчанию
+const DefaultPackage = "forms"
+
+// Options настраивает генерацию кода
+type Options struct {
+	Package string // имя пакета, по умолчанию DefaultPackage
+}
+
+// Generate возвращает исходный код Go с функцией New<Форма>Form для каждой формы.
+// Обработчики (OnGet, OnPost, действия, таблицы) не входят в определение формы:
+// места, где их нужно задать, отмечены комментариями TODO
+func Generate(opts Options, forms ...*types.Form) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = DefaultPackage
+	}
+	if !isIdentifier(opts.Package) {
+		return nil, fmt.Errorf("некорректное имя пакета %q", opts.Package)
+	}
+
+	g := &generator{imports: map[string]bool{formPackage: true}}
+	names := make(map[string]string, len(forms))
+	for _, f := range forms {
+		if f == nil || f.Name == "" {
+			return nil, fmt.Errorf("не задано имя формы")
+		}
+		name := FuncName(f)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("формы %s и %s дают одинаковое имя функции %s", other, f.Key(), name)
+		}
+		names[name] = f.Key()
+
+		if err := g.form(name, f); err != nil {
+			return nil, fmt.Errorf("форма %s: %w", f.Key(), err)
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Код сгенерирован из определений форм; обработчики задаются вручную (см. TODO)\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", opts.Package)
+	var std, external []string
+	for path := range g.imports {
+		if strings.Contains(path, ".") {
+			external = append(external, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	slices.Sort(std)
+	slices.Sort(external)
+	for _, path := range std {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	if len(std) > 0 {
+		out.WriteString("\n")
+	}
+	for _, path := range external {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(g.body.Bytes())
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка форматирования кода: %w", err)
+	}
+	return source, nil
+}
+
+// FuncName возвращает имя функции формы: billing/users -> NewBillingUsersForm
+func FuncName(f *types.Form) string {
+	var name strings.Builder
+	name.WriteString("New")
+	upper := true
+	for _, r := range f.Key() {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	name.WriteString("Form")
+	return name.String()
+}
+
+// isIdentifier проверяет, что name - идентификатор Go
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// generator собирает тела функций и используемые пакеты
+type generator struct {
+	body    bytes.Buffer
+	imports map[string]bool
+}
+
+// line добавляет строку кода
+func (g *generator) line(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, "\t"+format+"\n", args...)
+}
+
+// form генерирует функцию формы
+func (g *generator) form(name string, f *types.Form) error {
+	fmt.Fprintf(&g.body, "\n// %s возвращает строитель формы %s\nfunc %s() *form.FormBuilder {\n", name, quote(f.Title), name)
+	g.line("fb := form.NewForm(%s, %s)", quote(f.Name), quote(f.Title))
+
+	if err := g.settings(f); err != nil {
+		return err
+	}
+	for _, field := range f.Fields {
+		if err := g.field(field); err != nil {
+			return fmt.Errorf("поле %s: %w", field.Name, err)
+		}
+	}
+	for _, group := range f.Groups {
+		g.line("fb.AddGroup(%s, %s, %s)", quote(group.Name), quote(group.Title), g.must(group.Fields))
+		if group.Description != "" {
+			g.line("fb.Build().Groups[len(fb.Build().Groups)-1].Description = %s", quote(group.Description))
+		}
+	}
+	if err := g.actions(f); err != nil {
+		return err
+	}
+	g.shortcuts(f.Shortcuts)
+
+	g.line("// TODO: fb.OnGet(...), fb.OnPost(...)")
+	g.line("return fb")
+	g.body.WriteString("}\n")
+	return nil
+}
+
+// settings генерирует настройки формы
+func (g *generator) settings(f *types.Form) error {
+	if f.Module != "" {
+		g.line("fb.InModule(%s)", quote(f.Module))
+	}
+	if f.Description != "" {
+		g.line("fb.WithDescription(%s)", quote(f.Description))
+	}
+	if f.Icon != "" {
+		g.line("fb.WithIcon(%s)", quote(f.Icon))
+	}
+	if len(f.Tags) > 0 {
+		g.line("fb.WithTags(%s)", g.variadic(f.Tags))
+	}
+	if f.Meta != nil {
+		meta, err := g.literal(reflect.ValueOf(*f.Meta), false)
+		if err != nil {
+			return err
+		}
+		g.line("fb.WithMeta(%s)", meta)
+	}
+	if f.Locale != "" {
+		g.line("fb.WithLocale(%s)", quote(f.Locale))
+	}
+	if f.Approval != nil {
+		g.line("fb.RequireApproval(%s)", g.variadic(f.Approval.Roles))
+	}
+	if c := f.Concurrency; c != nil {
+		g.line("fb.Concurrency(%d, %d, %s)", c.MaxConcurrent, c.QueueSize, g.must(c.QueueTimeout))
+	}
+	if s := f.SLO; s != nil {
+		g.line("fb.WithSLO(%s, %s, %s)", g.must(s.Availability), g.must(s.Latency), g.must(s.LatencyTarget))
+		if s.Period != 0 {
+			g.line("fb.Build().SLO.Period = %s", g.must(s.Period))
+		}
+	}
+	if f.Coalesce {
+		g.line("fb.CoalesceReads()")
+	}
+	if len(f.Prefill) > 0 {
+		g.line("fb.AllowPrefill(%s)", g.variadic(f.Prefill))
+	}
+	if f.Provenance {
+		g.line("fb.TrackProvenance()")
+	}
+	if f.PartTimeout != 0 {
+		g.line("fb.WithPartTimeout(%s)", g.must(f.PartTimeout))
+	}
+	if f.ConfirmWarnings {
+		g.line("fb.RequireWarningsConfirm()")
+	}
+	return nil
+}
+
+// field генерирует добавление поля: готовым методом строителя, если он дает то же поле,
+// иначе через AddField. Справка, подсказки и ссылки задаются отдельными методами
+func (g *generator) field(field types.Field) error {
+	base := field
+	base.Help, base.Tooltip, base.Examples = "", "", nil
+	base.Link, base.Autocomplete, base.Accessibility, base.Suggest = nil, "", nil, nil
+
+	if call, ok := g.shortcutCall(base); ok {
+		g.line("fb.%s", call)
+	} else {
+		literal, err := g.literal(reflect.ValueOf(base), false)
+		if err != nil {
+			return err
+		}
+		g.line("fb.AddField(%s)", literal)
+		if field.TableConfig != nil {
+			g.line("// TODO: обработчики таблицы %s (TableConfig.OnGet, OnRowUpdate)", field.Name)
+		}
+	}
+
+	name := quote(field.Name)
+	if field.Help != "" {
+		g.line("fb.WithHelp(%s, %s)", name, quote(field.Help))
+	}
+	if field.Tooltip != "" {
+		g.line("fb.WithTooltip(%s, %s)", name, quote(field.Tooltip))
+	}
+	if len(field.Examples) > 0 {
+		g.line("fb.WithExamples(%s, %s)", name, g.variadic(field.Examples))
+	}
+	if field.Autocomplete != "" {
+		g.line("fb.WithAutocomplete(%s, %s)", name, quote(field.Autocomplete))
+	}
+	if field.Link != nil {
+		g.line("fb.WithLink(%s, %s)", name, g.must(*field.Link))
+	}
+	if field.Accessibility != nil {
+		g.line("fb.WithAccessibility(%s, %s)", name, g.must(*field.Accessibility))
+	}
+	if field.Suggest != nil {
+		g.line("// TODO: fb.WithSuggest(%s, ...)", name)
+	}
+	return nil
+}
+
+// shortcutCall возвращает вызов метода строителя, который создает ровно такое же поле
+func (g *generator) shortcutCall(field types.Field) (string, bool) {
+	name, label := quote(field.Name), quote(field.Label)
+
+	var call string
+	fb := form.NewForm("", "")
+	switch field.Type {
+	case types.FieldTypeText:
+		if field.Mask != "" {
+			call = fmt.Sprintf("AddMaskedField(%s, %s, %s)", name, label, g.maskExpr(field.Mask))
+			fb.AddMaskedField(field.Name, field.Label, field.Mask)
+		} else {
+			call = fmt.Sprintf("AddTextField(%s, %s)", name, label)
+			fb.AddTextField(field.Name, field.Label)
+		}
+	case types.FieldTypeEmail:
+		call = fmt.Sprintf("AddEmailField(%s, %s)", name, label)
+		fb.AddEmailField(field.Name, field.Label)
+	case types.FieldTypePassword:
+		call = fmt.Sprintf("AddPasswordField(%s, %s)", name, label)
+		fb.AddPasswordField(field.Name, field.Label)
+	case types.FieldTypeNumber:
+		call = fmt.Sprintf("AddNumberField(%s, %s)", name, label)
+		fb.AddNumberField(field.Name, field.Label)
+	case types.FieldTypeCheckbox:
+		call = fmt.Sprintf("AddCheckboxField(%s, %s)", name, label)
+		fb.AddCheckboxField(field.Name, field.Label)
+	case types.FieldTypeTextarea:
+		call = fmt.Sprintf("AddTextareaField(%s, %s)", name, label)
+		fb.AddTextareaField(field.Name, field.Label)
+	case types.FieldTypeDate:
+		call = fmt.Sprintf("AddDateField(%s, %s)", name, label)
+		fb.AddDateField(field.Name, field.Label)
+	case types.FieldTypeDateTime:
+		call = fmt.Sprintf("AddDateTimeField(%s, %s)", name, label)
+		fb.AddDateTimeField(field.Name, field.Label)
+	case types.FieldTypeFile:
+		call = fmt.Sprintf("AddFileField(%s, %s)", name, label)
+		fb.AddFileField(field.Name, field.Label)
+	case types.FieldTypeSelect:
+		options, err := g.literal(reflect.ValueOf(field.Options), false)
+		if err != nil {
+			return "", false
+		}
+		switch {
+		case field.Dictionary != nil:
+			call = fmt.Sprintf("AddDictionaryField(%s, %s, %s)", name, label, quote(field.Dictionary.Name))
+			fb.AddDictionaryField(field.Name, field.Label, field.Dictionary.Name)
+		case field.Multiple:
+			call = fmt.Sprintf("AddMultiSelectField(%s, %s, %s)", name, label, options)
+			fb.AddMultiSelectField(field.Name, field.Label, field.Options)
+		default:
+			call = fmt.Sprintf("AddSelectField(%s, %s, %s)", name, label, options)
+			fb.AddSelectField(field.Name, field.Label, field.Options)
+		}
+	case types.FieldTypeHidden:
+		value, err := g.literal(reflect.ValueOf(&field.DefaultValue).Elem(), false)
+		if err != nil {
+			return "", false
+		}
+		call = fmt.Sprintf("AddHiddenField(%s, %s)", name, value)
+		fb.AddHiddenField(field.Name, field.DefaultValue)
+	default:
+		return "", false
+	}
+
+	built := fb.Build().Fields
+	return call, len(built) == 1 && reflect.DeepEqual(built[0], field)
+}
+
+// actions генерирует кнопки формы
+func (g *generator) actions(f *types.Form) error {
+	actions := f.Actions
+	if actions == nil {
+		return nil
+	}
+	if actions.SubmitLabel != "" {
+		g.line("fb.WithSubmitLabel(%s)", quote(actions.SubmitLabel))
+	}
+	if actions.CancelLabel != "" {
+		g.line("fb.WithCancelLabel(%s)", quote(actions.CancelLabel))
+	}
+	if actions.ConfirmSubmit != "" {
+		g.line("fb.ConfirmSubmit(%s)", quote(actions.ConfirmSubmit))
+	}
+	for _, action := range actions.Custom {
+		action.Handler = nil
+		literal, err := g.literal(reflect.ValueOf(action), false)
+		if err != nil {
+			return err
+		}
+		g.line("// TODO: Handler действия %s", action.Name)
+		g.line("fb.AddAction(%s)", literal)
+	}
+	if actions.Duplicate != nil {
+		args := append([]string{"nil"}, quoteAll(actions.Duplicate.Exclude)...)
+		g.line("// TODO: загрузка копируемой записи")
+		g.line("fb.WithDuplicate(%s)", strings.Join(args, ", "))
+		if actions.Duplicate.Label != "" {
+			g.line("fb.Build().Actions.Duplicate.Label = %s", quote(actions.Duplicate.Label))
+		}
+	}
+	return nil
+}
+
+// shortcuts генерирует сочетания клавиш, заменяя стандартный набор на WithDefaultShortcuts
+func (g *generator) shortcuts(shortcuts []types.Shortcut) {
+	defaults := types.DefaultShortcuts()
+	if len(shortcuts) >= len(defaults) && slices.Equal(shortcuts[:len(defaults)], defaults) {
+		g.line("fb.WithDefaultShortcuts()")
+		shortcuts = shortcuts[len(defaults):]
+	}
+	for _, shortcut := range shortcuts {
+		g.line("fb.AddShortcut(%s, %s, %s)", quote(shortcut.Keys), g.shortcutAction(shortcut.Action), quote(shortcut.Label))
+	}
+}
+
+// variadic возвращает строки аргументами через запятую
+func (g *generator) variadic(values []string) string {
+	return strings.Join(quoteAll(values), ", ")
+}
+
+// must возвращает литерал значения, которое всегда представимо в коде
+func (g *generator) must(value interface{}) string {
+	literal, err := g.literal(reflect.ValueOf(value), false)
+	if err != nil {
+		panic(err)
+	}
+	return literal
+}
+
+// quote возвращает строковый литерал
+func quote(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+// quoteAll возвращает строковые литералы
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote(value)
+	}
+	return quoted
+}
+
+// maskExpr возвращает константу маски types.Mask*, если маска стандартная
+func (g *generator) maskExpr(mask string) string {
+	if name, ok := maskConstants[mask]; ok {
+		return g.qualify(typesPackage, name)
+	}
+	return quote(mask)
+}
+
+// shortcutAction возвращает константу встроенного действия сочетания клавиш
+func (g *generator) shortcutAction(action string) string {
+	switch action {
+	case types.ShortcutSubmit:
+		return g.qualify(typesPackage, "ShortcutSubmit")
+	case types.ShortcutCancel:
+		return g.qualify(typesPackage, "ShortcutCancel")
+	case types.ShortcutSearch:
+		return g.qualify(typesPackage, "ShortcutSearch")
+	case types.ActionDuplicate:
+		return g.qualify(typesPackage, "ActionDuplicate")
+	}
+	return quote(action)
+}
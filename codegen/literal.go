@@ -0,0 +1,294 @@
+package codegen
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// Пакеты сгенерированного кода
+const (
+	formPackage  = "github.com/koteyye/go-formist/form"
+	typesPackage = "github.com/koteyye/go-formist/types"
+	timePackage  = "time"
+)
+
+// fieldTypeConstants константы типов полей по значению
+var fieldTypeConstants = map[types.FieldType]string{
+	types.FieldTypeText:     "FieldTypeText",
+	types.FieldTypeEmail:    "FieldTypeEmail",
+	types.FieldTypePassword: "FieldTypePassword",
+	types.FieldTypeNumber:   "FieldTypeNumber",
+	types.FieldTypeTextarea: "FieldTypeTextarea",
+	types.FieldTypeSelect:   "FieldTypeSelect",
+	types.FieldTypeRadio:    "FieldTypeRadio",
+	types.FieldTypeCheckbox: "FieldTypeCheckbox",
+	types.FieldTypeDate:     "FieldTypeDate",
+	types.FieldTypeTime:     "FieldTypeTime",
+	types.FieldTypeDateTime: "FieldTypeDateTime",
+	types.FieldTypeFile:     "FieldTypeFile",
+	types.FieldTypeHidden:   "FieldTypeHidden",
+	types.FieldTypeTable:    "FieldTypeTable",
+}
+
+// columnFormatConstants константы форматов колонок по значению
+var columnFormatConstants = map[types.ColumnFormatKind]string{
+	types.ColumnFormatCurrency: "ColumnFormatCurrency",
+	types.ColumnFormatPercent:  "ColumnFormatPercent",
+	types.ColumnFormatBytes:    "ColumnFormatBytes",
+	types.ColumnFormatDateTime: "ColumnFormatDateTime",
+	types.ColumnFormatBadge:    "ColumnFormatBadge",
+	types.ColumnFormatLink:     "ColumnFormatLink",
+}
+
+// maskConstants константы масок ввода по значению
+var maskConstants = map[string]string{
+	types.MaskPhoneRU:      "MaskPhoneRU",
+	types.MaskPostalCodeRU: "MaskPostalCodeRU",
+	types.MaskINNLegal:     "MaskINNLegal",
+	types.MaskINNPerson:    "MaskINNPerson",
+	types.MaskSNILS:        "MaskSNILS",
+}
+
+// durationType тип time.Duration
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// qualify возвращает имя из пакета path и отмечает импорт пакета
+func (g *generator) qualify(path, name string) string {
+	g.imports[path] = true
+	return path[strings.LastIndex(path, "/")+1:] + "." + name
+}
+
+// typeExpr возвращает запись типа в коде
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		return g.qualify(t.PkgPath(), t.Name()), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Pointer:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+	}
+	return "", fmt.Errorf("тип %s не поддерживается", t)
+}
+
+// literal возвращает литерал значения v. elide опускает тип составного литерала
+// внутри списка или словаря, как это делает gofmt -s
+func (g *generator) literal(v reflect.Value, elide bool) (string, error) {
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		return g.literal(v.Elem(), false)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		if t.Elem().Kind() != reflect.Struct {
+			return "", fmt.Errorf("указатель на %s не поддерживается", t.Elem())
+		}
+		literal, err := g.literal(v.Elem(), elide)
+		if err != nil || elide {
+			return literal, err
+		}
+		return "&" + literal, nil
+	case reflect.Struct:
+		return g.structLiteral(v, elide)
+	case reflect.Slice:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elem, err := g.literal(v.Index(i), true)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = elem
+		}
+		return g.composite(t, elide, elems)
+	case reflect.Map:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		elems := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			k, err := g.literal(key, true)
+			if err != nil {
+				return "", err
+			}
+			value, err := g.literal(v.MapIndex(key), true)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, k+": "+value)
+		}
+		sort.Strings(elems)
+		return g.composite(t, elide, elems)
+	}
+	return g.basicLiteral(v)
+}
+
+// composite возвращает составной литерал: короткий в одну строку, иначе по элементу на строку
+func (g *generator) composite(t reflect.Type, elide bool, elems []string) (string, error) {
+	var prefix string
+	if !elide {
+		typ, err := g.typeExpr(t)
+		if err != nil {
+			return "", err
+		}
+		prefix = typ
+	}
+	if short(elems) {
+		return prefix + "{" + strings.Join(elems, ", ") + "}", nil
+	}
+	return prefix + "{\n" + strings.Join(elems, ",\n") + ",\n}", nil
+}
+
+// short сообщает, что элементы литерала помещаются в одну строку
+func short(elems []string) bool {
+	length := 0
+	for _, elem := range elems {
+		if strings.Contains(elem, "\n") {
+			return false
+		}
+		length += len(elem) + 2
+	}
+	return length <= 80
+}
+
+// structLiteral возвращает литерал структуры с заполненными экспортируемыми полями.
+// Функции (обработчики) не входят в определение формы и пропускаются
+func (g *generator) structLiteral(v reflect.Value, elide bool) (string, error) {
+	t := v.Type()
+	var elems []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if !field.IsExported() {
+			if !value.IsZero() {
+				return "", fmt.Errorf("тип %s не поддерживается", t)
+			}
+			continue
+		}
+		if field.Type.Kind() == reflect.Func || value.IsZero() {
+			continue
+		}
+		if (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0 {
+			continue
+		}
+
+		literal, err := g.literal(value, false)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", field.Name, err)
+		}
+		elems = append(elems, field.Name+": "+literal)
+	}
+
+	return g.composite(t, elide, elems)
+}
+
+// basicLiteral возвращает литерал строки, числа или логического значения
+func (g *generator) basicLiteral(v reflect.Value) (string, error) {
+	t := v.Type()
+	switch {
+	case t == durationType:
+		return g.durationExpr(time.Duration(v.Int())), nil
+	case t == reflect.TypeOf(types.FieldType("")):
+		if name, ok := fieldTypeConstants[types.FieldType(v.String())]; ok {
+			return g.qualify(typesPackage, name), nil
+		}
+	case t == reflect.TypeOf(types.ColumnFormatKind("")):
+		if name, ok := columnFormatConstants[types.ColumnFormatKind(v.String())]; ok {
+			return g.qualify(typesPackage, name), nil
+		}
+	}
+
+	var literal string
+	switch t.Kind() {
+	case reflect.String:
+		literal = quote(v.String())
+	case reflect.Bool:
+		literal = strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		literal = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		literal = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return "", fmt.Errorf("значение %v не поддерживается", f)
+		}
+		literal = strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(literal, ".e") {
+			// Без точки константа в interface{} стала бы int
+			literal += ".0"
+		}
+	default:
+		return "", fmt.Errorf("тип %s не поддерживается", t)
+	}
+
+	// Именованные типы и не типы по умолчанию в interface{} сохраняют тип преобразованием
+	if t.Name() != t.Kind().String() || (t.Kind() != reflect.String && t.Kind() != reflect.Bool &&
+		t.Kind() != reflect.Int && t.Kind() != reflect.Float64) {
+		typ, err := g.typeExpr(t)
+		if err != nil {
+			return "", err
+		}
+		return typ + "(" + literal + ")", nil
+	}
+	return literal, nil
+}
+
+// durationExpr возвращает длительность в крупнейших целых единицах: 90 * time.Second
+func (g *generator) durationExpr(d time.Duration) string {
+	units := []struct {
+		name string
+		unit time.Duration
+	}{
+		{"Hour", time.Hour},
+		{"Minute", time.Minute},
+		{"Second", time.Second},
+		{"Millisecond", time.Millisecond},
+		{"Microsecond", time.Microsecond},
+	}
+	for _, u := range units {
+		if d != 0 && d%u.unit == 0 {
+			name := g.qualify(timePackage, u.name)
+			if d == u.unit {
+				return name
+			}
+			return strconv.FormatInt(int64(d/u.unit), 10) + " * " + name
+		}
+	}
+	if d == 0 {
+		return "0"
+	}
+	return g.qualify(timePackage, "Duration") + "(" + strconv.FormatInt(int64(d), 10) + ")"
+}
@@ -0,0 +1,305 @@
+// Package comments реализует обсуждения записей и заявок внутри админки:
+// комментарии привязаны к форме и записи, упоминания @пользователь отправляют уведомления
+package comments
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/id"
+	"github.com/koteyye/go-formist/privacy"
+)
+
+// MaxLength максимальная длина комментария в символах
+const MaxLength = 10000
+
+// Ошибки комментариев
+var (
+	ErrNotFound  = errors.New("комментарий не найден")
+	ErrForbidden = errors.New("удалить комментарий может только автор или модератор")
+	ErrEmpty     = errors.New("комментарий пуст")
+	ErrTooLong   = errors.New("комментарий слишком длинный")
+)
+
+// Comment представляет комментарий к записи формы или заявке на согласование
+type Comment struct {
+	ID        string     `json:"id"`
+	Form      string     `json:"form"`
+	Record    string     `json:"record"` // ID записи или заявки
+	Author    *auth.User `json:"author,omitempty"`
+	Text      string     `json:"text"`
+	Mentions  []string   `json:"mentions,omitempty"` // ID упомянутых пользователей
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Mention уведомление пользователя об упоминании в комментарии
+type Mention struct {
+	UserID  string   `json:"userId"`
+	Comment *Comment `json:"comment"`
+}
+
+// Notifier получает упоминания (отправка писем, сообщений в мессенджер)
+type Notifier func(ctx context.Context, mention Mention)
+
+// Store хранит комментарии
+type Store interface {
+	Save(ctx context.Context, comment *Comment) error
+	Get(ctx context.Context, id string) (*Comment, error)
+	List(ctx context.Context, form, record string) ([]*Comment, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// mentionPattern упоминание @id пользователя (не часть email)
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w[\w.-]*)`)
+
+// ParseMentions возвращает ID пользователей, упомянутых в тексте, без повторов
+func ParseMentions(text string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		userID := strings.TrimRight(match[1], ".-")
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		mentions = append(mentions, userID)
+	}
+	return mentions
+}
+
+// MemoryStore хранит комментарии в памяти
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]*Comment
+}
+
+// NewMemoryStore создает хранилище комментариев в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]*Comment),
+	}
+}
+
+// Save сохраняет копию комментария
+func (s *MemoryStore) Save(ctx context.Context, comment *Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone := *comment
+	s.items[comment.ID] = &clone
+	return nil
+}
+
+// Get возвращает копию комментария по ID
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	clone := *comment
+	return &clone, nil
+}
+
+// List возвращает комментарии записи формы в порядке создания
+func (s *MemoryStore) List(ctx context.Context, form, record string) ([]*Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]*Comment, 0)
+	for _, comment := range s.items {
+		if comment.Form == form && comment.Record == record {
+			clone := *comment
+			items = append(items, &clone)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+// Delete удаляет комментарий
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.items, id)
+	return nil
+}
+
+// ExportSubject возвращает комментарии, автором которых является субъект или в которых он упомянут
+func (s *MemoryStore) ExportSubject(ctx context.Context, subject string) ([]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]interface{}, 0)
+	for _, comment := range s.items {
+		if authoredBy(comment, subject) || mentioned(comment, subject) {
+			clone := *comment
+			records = append(records, &clone)
+		}
+	}
+	return records, nil
+}
+
+// EraseSubject обезличивает комментарии субъекта: у его комментариев удаляются автор и текст,
+// а его упоминания в чужих комментариях заменяются на privacy.Erased
+func (s *MemoryStore) EraseSubject(ctx context.Context, subject string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, comment := range s.items {
+		authored, mentioned := authoredBy(comment, subject), mentioned(comment, subject)
+		if !authored && !mentioned {
+			continue
+		}
+		count++
+
+		if authored {
+			comment.Author = &auth.User{ID: privacy.Erased}
+			comment.Text = privacy.Erased
+		}
+		if mentioned {
+			mentions := make([]string, len(comment.Mentions))
+			for i, userID := range comment.Mentions {
+				if privacy.Matches(userID, subject) {
+					userID = privacy.Erased
+					comment.Text = strings.ReplaceAll(comment.Text, "@"+subject, "@"+privacy.Erased)
+				}
+				mentions[i] = userID
+			}
+			comment.Mentions = mentions
+		}
+	}
+	return count, nil
+}
+
+// authoredBy проверяет, что автор комментария - субъект
+func authoredBy(comment *Comment, subject string) bool {
+	return comment.Author != nil && privacy.Matches(comment.Author.ID, subject)
+}
+
+// mentioned проверяет, что субъект упомянут в комментарии
+func mentioned(comment *Comment, subject string) bool {
+	for _, userID := range comment.Mentions {
+		if privacy.Matches(userID, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// Service управляет комментариями и уведомлениями об упоминаниях
+type Service struct {
+	store    Store
+	notifier Notifier
+	ids      id.Generator
+	mu       sync.Mutex
+}
+
+// New создает сервис комментариев. По умолчанию комментарии хранятся в памяти
+func New(store Store, notifier Notifier) *Service {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Service{
+		store:    store,
+		notifier: notifier,
+		ids:      id.Default(),
+	}
+}
+
+// SetIDGenerator устанавливает генератор идентификаторов комментариев
+func (s *Service) SetIDGenerator(g id.Generator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ids = g
+}
+
+// Store возвращает хранилище комментариев
+func (s *Service) Store() Store {
+	return s.store
+}
+
+// Add создает комментарий от имени пользователя из контекста
+// и уведомляет упомянутых пользователей (кроме автора)
+func (s *Service) Add(ctx context.Context, form, record, text string) (*Comment, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmpty
+	}
+	if utf8.RuneCountInString(text) > MaxLength {
+		return nil, ErrTooLong
+	}
+
+	user, _ := auth.UserFromContext(ctx)
+
+	s.mu.Lock()
+	ids := s.ids
+	s.mu.Unlock()
+
+	comment := &Comment{
+		ID:        ids.NewID(),
+		Form:      form,
+		Record:    record,
+		Author:    user,
+		Text:      text,
+		Mentions:  ParseMentions(text),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.store.Save(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	if s.notifier != nil {
+		for _, userID := range comment.Mentions {
+			if user != nil && user.ID == userID {
+				continue
+			}
+			s.notifier(ctx, Mention{UserID: userID, Comment: comment})
+		}
+	}
+	return comment, nil
+}
+
+// List возвращает комментарии записи формы
+func (s *Service) List(ctx context.Context, form, record string) ([]*Comment, error) {
+	return s.store.List(ctx, form, record)
+}
+
+// Delete удаляет комментарий. Без moderator удалить можно только собственный комментарий
+func (s *Service) Delete(ctx context.Context, id string, moderator bool) (*Comment, error) {
+	comment, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !moderator {
+		user, ok := auth.UserFromContext(ctx)
+		if !ok || comment.Author == nil || comment.Author.ID != user.ID {
+			return nil, ErrForbidden
+		}
+	}
+
+	if err := s.store.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
@@ -0,0 +1,146 @@
+// Package datasource подключает форму к существующему хранилищу записей - внутреннему
+// HTTP API (REST) или таблице БД (SQL) - без ручного написания обработчиков:
+// Source назначается форме (FormBuilder.WithDataSource) или табличному полю
+package datasource
+
+import (
+	"context"
+	"errors"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// ErrNotFound возвращается, если записи с указанным ID нет
+var ErrNotFound = errors.New("запись не найдена")
+
+// Query параметры получения списка записей
+type Query struct {
+	Page    int                    // номер страницы, начиная с 1
+	Limit   int                    // записей на странице
+	Filters map[string]interface{} // фильтры таблицы: значения параметров и types.FilterConditions
+}
+
+// Offset возвращает число пропускаемых записей
+func (q Query) Offset() int {
+	if q.Page < 1 {
+		return 0
+	}
+	return (q.Page - 1) * q.Limit
+}
+
+// List страница записей
+type List struct {
+	Rows  []map[string]interface{}
+	Total int // всего записей с учетом фильтров
+}
+
+// Source источник записей формы
+type Source interface {
+	// Get возвращает запись по ID или ErrNotFound
+	Get(ctx context.Context, id string) (map[string]interface{}, error)
+
+	// List возвращает страницу записей
+	List(ctx context.Context, query Query) (List, error)
+
+	// Create создает запись и возвращает ее
+	Create(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error)
+
+	// Update изменяет переданные поля записи и возвращает ее; остальные поля не меняются
+	Update(ctx context.Context, id string, data map[string]interface{}) (map[string]interface{}, error)
+
+	// Delete удаляет запись или возвращает ErrNotFound
+	Delete(ctx context.Context, id string) error
+}
+
+// ActionDelete имя действия удаления записи
+const ActionDelete = "delete"
+
+// GetHandler возвращает обработчик OnGet: запись из ?id= (types.RecordID)
+// или пустую форму для новой записи
+func GetHandler(src Source) types.GetHandler {
+	return func(ctx context.Context) (interface{}, error) {
+		id := types.RecordID(ctx)
+		if id == "" {
+			return nil, nil
+		}
+		return src.Get(ctx, id)
+	}
+}
+
+// PostHandler возвращает обработчик OnPost: с ?id= изменяет запись, без него - создает.
+// При пробном запуске данные не сохраняются
+func PostHandler(src Source) types.FormHandler {
+	return func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
+		if types.IsDryRun(ctx) {
+			return data, nil
+		}
+		if id := types.RecordID(ctx); id != "" {
+			return src.Update(ctx, id, data)
+		}
+		return src.Create(ctx, data)
+	}
+}
+
+// RecordHandler возвращает загрузку записи для частичного обновления и копирования.
+// Отсутствующая запись возвращается как nil
+func RecordHandler(src Source) types.RecordHandler {
+	return func(ctx context.Context, id string) (map[string]interface{}, error) {
+		record, err := src.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return record, err
+	}
+}
+
+// PatchHandler возвращает сохранение частичного обновления записи
+func PatchHandler(src Source) types.PatchHandler {
+	return func(ctx context.Context, id string, changes map[string]interface{}) (interface{}, error) {
+		return src.Update(ctx, id, changes)
+	}
+}
+
+// TableHandler возвращает обработчик данных табличного поля
+func TableHandler(src Source) types.TableHandler {
+	return func(ctx context.Context, page, limit int, filters map[string]interface{}) (types.TableData, error) {
+		list, err := src.List(ctx, Query{Page: page, Limit: limit, Filters: filters})
+		if err != nil {
+			return types.TableData{}, err
+		}
+		if list.Rows == nil {
+			list.Rows = make([]map[string]interface{}, 0)
+		}
+		return types.TableData{
+			Rows:  list.Rows,
+			Total: list.Total,
+			Page:  page,
+			Limit: limit,
+		}, nil
+	}
+}
+
+// RowUpdateHandler возвращает сохранение ячейки редактируемой таблицы
+func RowUpdateHandler(src Source) types.RowUpdateHandler {
+	return func(ctx context.Context, id, key string, value interface{}) (interface{}, error) {
+		return src.Update(ctx, id, map[string]interface{}{key: value})
+	}
+}
+
+// DeleteAction возвращает действие формы, удаляющее запись из ?id=
+func DeleteAction(src Source, label string) types.Action {
+	return types.Action{
+		Name:    ActionDelete,
+		Label:   label,
+		Confirm: "Удалить запись?",
+		Handler: func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
+			id := types.RecordID(ctx)
+			if id == "" {
+				return nil, errors.New("не указан ID записи")
+			}
+			if types.IsDryRun(ctx) {
+				return nil, nil
+			}
+			return nil, src.Delete(ctx, id)
+		},
+	}
+}
@@ -0,0 +1,184 @@
+package datasource
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mapping сопоставляет поля формы с JSON удаленного API. Путь - имена ключей
+// через точку, число - индекс массива: "profile.email", "phones.0.number"
+type Mapping struct {
+	Fields map[string]string `json:"fields,omitempty"` // поле формы -> путь в записи API; без Fields запись передается как есть
+	ID     string            `json:"id,omitempty"`     // путь ID в записи API, по умолчанию "id"
+	Items  string            `json:"items,omitempty"`  // путь массива записей в ответе списка, по умолчанию "items"
+	Total  string            `json:"total,omitempty"`  // путь общего числа записей в ответе списка, по умолчанию "total"
+}
+
+// Validate проверяет пути сопоставления
+func (m *Mapping) Validate() error {
+	paths := map[string]string{"id": m.ID, "items": m.Items, "total": m.Total}
+	for field, path := range m.Fields {
+		paths["fields."+field] = path
+	}
+	for _, name := range sortedStringKeys(paths) {
+		path := paths[name]
+		if path == "" && strings.HasPrefix(name, "fields.") {
+			return fmt.Errorf("сопоставление %s: пустой путь", name)
+		}
+		keys := splitPath(path)
+		for _, key := range keys {
+			if key == "" {
+				return fmt.Errorf("сопоставление %s: некорректный путь %q", name, path)
+			}
+		}
+		if len(keys) > 0 && isIndex(keys[0]) {
+			return fmt.Errorf("сопоставление %s: путь %q должен начинаться с ключа объекта", name, path)
+		}
+	}
+	return nil
+}
+
+// record преобразует запись API в данные формы
+func (m *Mapping) record(remote map[string]interface{}) map[string]interface{} {
+	if m == nil || len(m.Fields) == 0 {
+		return remote
+	}
+
+	record := make(map[string]interface{}, len(m.Fields)+1)
+	if id, ok := lookupPath(remote, m.idPath()); ok {
+		record[DefaultIDColumn] = id
+	}
+	for field, path := range m.Fields {
+		if value, ok := lookupPath(remote, path); ok {
+			record[field] = value
+		}
+	}
+	return record
+}
+
+// payload преобразует данные формы в тело запроса API. Поля без сопоставления не передаются
+func (m *Mapping) payload(data map[string]interface{}) map[string]interface{} {
+	if m == nil || len(m.Fields) == 0 {
+		return data
+	}
+
+	payload := make(map[string]interface{})
+	for _, field := range sortedKeys(data) {
+		if path, ok := m.Fields[field]; ok {
+			payload = setPath(payload, splitPath(path), data[field]).(map[string]interface{})
+		}
+	}
+	return payload
+}
+
+// param возвращает имя параметра запроса для фильтра по полю формы
+func (m *Mapping) param(field string) string {
+	if m == nil {
+		return field
+	}
+	if path, ok := m.Fields[field]; ok {
+		return path
+	}
+	if field == DefaultIDColumn {
+		return m.idPath()
+	}
+	return field
+}
+
+// idPath возвращает путь ID записи
+func (m *Mapping) idPath() string {
+	if m == nil || m.ID == "" {
+		return DefaultIDColumn
+	}
+	return m.ID
+}
+
+// itemsPath возвращает путь массива записей списка
+func (m *Mapping) itemsPath() string {
+	if m == nil || m.Items == "" {
+		return "items"
+	}
+	return m.Items
+}
+
+// totalPath возвращает путь общего числа записей списка
+func (m *Mapping) totalPath() string {
+	if m == nil || m.Total == "" {
+		return "total"
+	}
+	return m.Total
+}
+
+// splitPath разбирает путь на ключи
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// lookupPath возвращает значение по пути
+func lookupPath(node interface{}, path string) (interface{}, bool) {
+	for _, key := range splitPath(path) {
+		switch current := node.(type) {
+		case map[string]interface{}:
+			value, ok := current[key]
+			if !ok {
+				return nil, false
+			}
+			node = value
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false
+			}
+			node = current[index]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// setPath записывает значение по пути, создавая недостающие объекты и массивы
+func setPath(node interface{}, path []string, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+
+	key := path[0]
+	if isIndex(key) {
+		index, _ := strconv.Atoi(key)
+		array, _ := node.([]interface{})
+		for len(array) <= index {
+			array = append(array, nil)
+		}
+		array[index] = setPath(array[index], path[1:], value)
+		return array
+	}
+
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{})
+	}
+	object[key] = setPath(object[key], path[1:], value)
+	return object
+}
+
+// sortedStringKeys возвращает ключи в стабильном порядке
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isIndex сообщает, что ключ пути - индекс массива
+func isIndex(key string) bool {
+	index, err := strconv.Atoi(key)
+	return err == nil && index >= 0
+}
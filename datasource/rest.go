@@ -0,0 +1,510 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/types"
+)
+
+// TotalCountHeader заголовок ответа списка с общим числом записей
+const TotalCountHeader = "X-Total-Count"
+
+// MaxResponseSize максимальный размер ответа API, который читает REST источник
+const MaxResponseSize = 10 << 20
+
+// maxErrorBody размер начала тела ответа с ошибкой, сохраняемого в APIError
+const maxErrorBody = 1 << 10
+
+// restSuffixes суффиксы параметров запроса для операторов фильтра (соглашение json-server)
+var restSuffixes = map[types.FilterOperator]string{
+	types.FilterNe:       "_ne",
+	types.FilterGt:       "_gt",
+	types.FilterGte:      "_gte",
+	types.FilterLt:       "_lt",
+	types.FilterLte:      "_lte",
+	types.FilterContains: "_like",
+}
+
+// AuthProvider возвращает заголовки авторизации запроса к API,
+// например свежий токен OAuth. Вызывается перед каждой попыткой запроса
+type AuthProvider func(ctx context.Context) (map[string]string, error)
+
+// BearerToken возвращает AuthProvider с заголовком Authorization: Bearer <token>
+func BearerToken(token func(ctx context.Context) (string, error)) AuthProvider {
+	return func(ctx context.Context) (map[string]string, error) {
+		value, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"Authorization": "Bearer " + value}, nil
+	}
+}
+
+// REST источник записей внутреннего HTTP API ресурса:
+//
+//	GET    {BaseURL}?page=&limit=&... - список
+//	GET    {BaseURL}/{id}              - запись
+//	POST   {BaseURL}                   - создание
+//	PATCH  {BaseURL}/{id}              - изменение
+//	DELETE {BaseURL}/{id}              - удаление
+//
+// Список - JSON массив (общее число записей в заголовке X-Total-Count)
+// или объект {"items": [...], "total": N}. Ответ 404 возвращается как ErrNotFound.
+// Источник можно описать в JSON (см. LoadREST): формы для сторонних API задаются конфигурацией
+type REST struct {
+	BaseURL string               `json:"baseUrl"`
+	Headers map[string]string    `json:"headers,omitempty"` // заголовки каждого запроса, например Authorization
+	Mapping *Mapping             `json:"mapping,omitempty"` // сопоставление полей формы и JSON API, по умолчанию один к одному
+	Retry   *storage.RetryPolicy `json:"retry,omitempty"`   // повторы GET и DELETE при сетевых ошибках, 429 и 5xx
+	Timeout time.Duration        `json:"timeout,omitempty"` // время одной попытки, 0 - без ограничения
+
+	Client *http.Client `json:"-"` // по умолчанию http.DefaultClient
+	Auth   AuthProvider `json:"-"` // заголовки авторизации, вычисляемые на каждый запрос
+}
+
+// NewREST создает источник записей ресурса baseURL
+func NewREST(baseURL string) *REST {
+	return &REST{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// LoadREST читает описание источника из JSON файла. Переменные окружения
+// ${NAME} в адресе и заголовках подставляются, поэтому секреты не хранятся в файле:
+//
+//	{"baseUrl": "https://crm.internal/api/v2/contacts",
+//	 "headers": {"Authorization": "Bearer ${CRM_TOKEN}"},
+//	 "mapping": {"fields": {"email": "contact.email"}, "items": "data", "total": "meta.total"},
+//	 "retry": {"maxAttempts": 3, "initialBackoff": "200ms", "multiplier": 2},
+//	 "timeout": "5s"}
+func LoadREST(path string) (*REST, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s REST
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("источник %s: %w", path, err)
+	}
+
+	s.BaseURL = strings.TrimSuffix(os.ExpandEnv(s.BaseURL), "/")
+	for name, value := range s.Headers {
+		s.Headers[name] = os.ExpandEnv(value)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("источник %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// restConfig описание источника без собственного UnmarshalJSON
+type restConfig REST
+
+// retryConfig JSON представление storage.RetryPolicy с длительностями-строками
+type retryConfig struct {
+	MaxAttempts    int          `json:"maxAttempts"`
+	InitialBackoff jsonDuration `json:"initialBackoff"`
+	MaxBackoff     jsonDuration `json:"maxBackoff"`
+	Multiplier     float64      `json:"multiplier"`
+}
+
+// UnmarshalJSON читает описание источника. Длительности timeout и retry задаются
+// строками time.ParseDuration ("200ms", "5s"); число - наносекунды
+func (s *REST) UnmarshalJSON(data []byte) error {
+	config := struct {
+		*restConfig
+		Retry   *retryConfig `json:"retry,omitempty"`
+		Timeout jsonDuration `json:"timeout,omitempty"`
+	}{restConfig: (*restConfig)(s)}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	s.Timeout = time.Duration(config.Timeout)
+	if config.Retry != nil {
+		s.Retry = &storage.RetryPolicy{
+			MaxAttempts:    config.Retry.MaxAttempts,
+			InitialBackoff: time.Duration(config.Retry.InitialBackoff),
+			MaxBackoff:     time.Duration(config.Retry.MaxBackoff),
+			Multiplier:     config.Retry.Multiplier,
+		}
+	}
+	return nil
+}
+
+// jsonDuration длительность в JSON: строка time.ParseDuration или число наносекунд
+type jsonDuration time.Duration
+
+// UnmarshalJSON разбирает длительность
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		parsed, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("некорректная длительность %q", text)
+		}
+		*d = jsonDuration(parsed)
+		return nil
+	}
+
+	var nanoseconds int64
+	if err := json.Unmarshal(data, &nanoseconds); err != nil {
+		return fmt.Errorf("некорректная длительность %s, ожидается строка, например \"200ms\"", data)
+	}
+	*d = jsonDuration(nanoseconds)
+	return nil
+}
+
+// Validate проверяет описание источника
+func (s *REST) Validate() error {
+	target, err := url.Parse(s.BaseURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("некорректный адрес API %q", s.BaseURL)
+	}
+	if s.Retry != nil && (s.Retry.MaxAttempts < 0 || s.Retry.InitialBackoff < 0 || s.Retry.MaxBackoff < 0) {
+		return errors.New("параметры повторов не могут быть отрицательными")
+	}
+	if s.Timeout < 0 {
+		return errors.New("время запроса не может быть отрицательным")
+	}
+	if s.Mapping != nil {
+		return s.Mapping.Validate()
+	}
+	return nil
+}
+
+// WithHeader добавляет заголовок к каждому запросу
+func (s *REST) WithHeader(name, value string) *REST {
+	if s.Headers == nil {
+		s.Headers = make(map[string]string)
+	}
+	s.Headers[name] = value
+	return s
+}
+
+// WithAuth задает заголовки авторизации, вычисляемые на каждый запрос
+func (s *REST) WithAuth(auth AuthProvider) *REST {
+	s.Auth = auth
+	return s
+}
+
+// WithMapping задает сопоставление полей формы (ключи) и путей в JSON записей API (значения)
+func (s *REST) WithMapping(fields map[string]string) *REST {
+	if s.Mapping == nil {
+		s.Mapping = &Mapping{}
+	}
+	s.Mapping.Fields = fields
+	return s
+}
+
+// WithRetry включает повторы GET и DELETE при сетевых ошибках, 429 и 5xx
+func (s *REST) WithRetry(policy storage.RetryPolicy) *REST {
+	s.Retry = &policy
+	return s
+}
+
+// Get возвращает запись по ID
+func (s *REST) Get(ctx context.Context, id string) (map[string]interface{}, error) {
+	var record map[string]interface{}
+	if _, err := s.do(ctx, http.MethodGet, s.recordURL(id), nil, &record); err != nil {
+		return nil, err
+	}
+	return s.Mapping.record(record), nil
+}
+
+// List возвращает страницу записей
+func (s *REST) List(ctx context.Context, query Query) (List, error) {
+	params := url.Values{}
+	if query.Page > 0 {
+		params.Set("page", strconv.Itoa(query.Page))
+	}
+	if query.Limit > 0 {
+		params.Set("limit", strconv.Itoa(query.Limit))
+	}
+	for key, value := range query.Filters {
+		if v, ok := value.(string); ok && key != types.FilterParam {
+			params.Set(s.Mapping.param(key), v)
+		}
+	}
+	for _, condition := range types.FilterConditions(query.Filters) {
+		if err := s.condition(params, condition); err != nil {
+			return List{}, err
+		}
+	}
+
+	target := s.BaseURL
+	if encoded := params.Encode(); encoded != "" {
+		target += "?" + encoded
+	}
+
+	var body interface{}
+	header, err := s.do(ctx, http.MethodGet, target, nil, &body)
+	if err != nil {
+		return List{}, err
+	}
+
+	items, ok := body.([]interface{})
+	if !ok {
+		value, _ := lookupPath(body, s.Mapping.itemsPath())
+		if items, ok = value.([]interface{}); !ok {
+			return List{}, &APIError{
+				Message: "некорректный ответ API источника",
+				Method:  http.MethodGet,
+				URL:     target,
+				Err:     fmt.Errorf("нет массива записей %s", s.Mapping.itemsPath()),
+			}
+		}
+	}
+
+	list := List{Rows: make([]map[string]interface{}, 0, len(items)), Total: len(items)}
+	for _, item := range items {
+		if record, ok := item.(map[string]interface{}); ok {
+			list.Rows = append(list.Rows, s.Mapping.record(record))
+		}
+	}
+	if total, err := strconv.Atoi(header.Get(TotalCountHeader)); err == nil {
+		list.Total = total
+	} else if total, ok := lookupPath(body, s.Mapping.totalPath()); ok {
+		if n, ok := total.(float64); ok {
+			list.Total = int(n)
+		}
+	}
+	return list, nil
+}
+
+// Create создает запись
+func (s *REST) Create(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+	var record map[string]interface{}
+	if _, err := s.do(ctx, http.MethodPost, s.BaseURL, s.Mapping.payload(data), &record); err != nil {
+		return nil, err
+	}
+	return s.Mapping.record(record), nil
+}
+
+// Update изменяет переданные поля записи
+func (s *REST) Update(ctx context.Context, id string, data map[string]interface{}) (map[string]interface{}, error) {
+	var record map[string]interface{}
+	if _, err := s.do(ctx, http.MethodPatch, s.recordURL(id), s.Mapping.payload(data), &record); err != nil {
+		return nil, err
+	}
+	return s.Mapping.record(record), nil
+}
+
+// Delete удаляет запись
+func (s *REST) Delete(ctx context.Context, id string) error {
+	_, err := s.do(ctx, http.MethodDelete, s.recordURL(id), nil, nil)
+	return err
+}
+
+// recordURL возвращает адрес записи
+func (s *REST) recordURL(id string) string {
+	return s.BaseURL + "/" + url.PathEscape(id)
+}
+
+// do выполняет запрос и декодирует JSON ответа в out (пустой ответ допустим).
+// Временные ошибки идемпотентных GET и DELETE повторяются по политике Retry
+func (s *REST) do(ctx context.Context, method, target string, body, out interface{}) (http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	attempts := 1
+	if s.Retry != nil && (method == http.MethodGet || method == http.MethodDelete) {
+		attempts = max(s.Retry.MaxAttempts, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		header, err := s.attempt(ctx, method, target, payload, out)
+		var temporary *temporaryError
+		if err == nil || !errors.As(err, &temporary) || attempt >= attempts {
+			return header, err
+		}
+
+		timer := time.NewTimer(s.Retry.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt выполняет одну попытку запроса
+func (s *REST) attempt(ctx context.Context, method, target string, payload []byte, out interface{}) (http.Header, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range s.Headers {
+		req.Header.Set(name, value)
+	}
+	if s.Auth != nil {
+		headers, err := s.Auth(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("авторизация в API источника: %w", err)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &temporaryError{err: &APIError{Message: "API источника недоступен", Method: method, URL: target, Err: err}}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, &temporaryError{err: &APIError{Message: "API источника недоступен", Method: method, URL: target, Err: err}}
+	}
+	if len(data) > MaxResponseSize {
+		return nil, &APIError{
+			Message: "ответ API источника слишком большой",
+			Method:  method,
+			URL:     target,
+			Status:  resp.StatusCode,
+			Err:     fmt.Errorf("ответ больше %d байт", MaxResponseSize),
+		}
+	}
+	if resp.StatusCode >= 300 {
+		body := strings.TrimSpace(string(data))
+		if len(body) > maxErrorBody {
+			body = strings.ToValidUTF8(body[:maxErrorBody], "")
+		}
+		err := &APIError{
+			Message: fmt.Sprintf("API источника вернул статус %d", resp.StatusCode),
+			Method:  method,
+			URL:     target,
+			Status:  resp.StatusCode,
+			Body:    body,
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, &temporaryError{err: err}
+		}
+		return nil, err
+	}
+	if out != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, &APIError{Message: "некорректный ответ API источника", Method: method, URL: target, Status: resp.StatusCode, Err: err}
+		}
+	}
+	return resp.Header, nil
+}
+
+// condition добавляет условие фильтра в параметры запроса
+func (s *REST) condition(params url.Values, condition types.FilterCondition) error {
+	name := s.Mapping.param(condition.Field)
+	switch condition.Operator {
+	case types.FilterEq:
+		params.Add(name, restValue(condition.Value))
+	case types.FilterIn:
+		values, _ := condition.Value.([]interface{})
+		for _, value := range values {
+			params.Add(name, restValue(value))
+		}
+	default:
+		suffix, ok := restSuffixes[condition.Operator]
+		if !ok {
+			return fmt.Errorf("оператор %s не поддерживается REST источником", condition.Operator)
+		}
+		params.Add(name+suffix, restValue(condition.Value))
+	}
+	return nil
+}
+
+// APIError ошибка запроса к API источника. Error возвращает только Message, так как
+// ошибка обработчика может попасть пользователю формы; адрес, статус и начало тела
+// ответа доступны получателю ошибок через errors.As
+type APIError struct {
+	Message string // сообщение без адреса и тела ответа
+	Method  string
+	URL     string
+	Status  int    // HTTP статус ответа, 0 - ответ не получен
+	Body    string // начало тела ответа с ошибкой
+	Err     error  // исходная ошибка: сеть, разбор ответа
+}
+
+// Error возвращает сообщение для пользователя
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// Detail возвращает подробности ошибки для журнала
+func (e *APIError) Detail() string {
+	detail := e.Method + " " + e.URL
+	if e.Status != 0 {
+		detail += ": " + strconv.Itoa(e.Status)
+	}
+	if e.Body != "" {
+		detail += ": " + e.Body
+	}
+	if e.Err != nil {
+		detail += ": " + e.Err.Error()
+	}
+	return detail
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// temporaryError временная ошибка запроса: сеть, 429 или 5xx
+type temporaryError struct {
+	err error
+}
+
+// Error возвращает текст ошибки
+func (e *temporaryError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *temporaryError) Unwrap() error {
+	return e.err
+}
+
+// restValue форматирует значение условия для параметра запроса
+func restValue(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,312 @@
+package datasource
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/koteyye/go-formist/transaction"
+	"github.com/koteyye/go-formist/types"
+)
+
+// DefaultIDColumn колонка первичного ключа по умолчанию
+const DefaultIDColumn = "id"
+
+// identifierPattern допустимое имя таблицы или колонки (с необязательной схемой)
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQL источник записей таблицы БД. Записываются и фильтруются только колонки Columns,
+// остальные поля данных формы игнорируются. Внутри транзакции отправки
+// (transaction.SQL) запросы выполняются в ней
+type SQL struct {
+	db       *sql.DB
+	table    string
+	idColumn string
+	columns  []string
+	sb       sq.StatementBuilderType
+}
+
+// NewSQL создает источник записей таблицы table с колонками columns.
+// Плейсхолдеры по умолчанию $1, $2 (PostgreSQL), см. WithPlaceholder
+func NewSQL(db *sql.DB, table string, columns ...string) (*SQL, error) {
+	if !identifierPattern.MatchString(table) {
+		return nil, fmt.Errorf("некорректное имя таблицы %q", table)
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("не указаны колонки таблицы")
+	}
+	for _, column := range columns {
+		if !identifierPattern.MatchString(column) {
+			return nil, fmt.Errorf("некорректное имя колонки %q", column)
+		}
+	}
+	s := &SQL{
+		db:       db,
+		table:    table,
+		idColumn: DefaultIDColumn,
+		columns:  columns,
+		sb:       sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+	if !slices.Contains(columns, DefaultIDColumn) {
+		s.columns = append([]string{DefaultIDColumn}, columns...)
+	}
+	return s, nil
+}
+
+// WithIDColumn задает колонку первичного ключа
+func (s *SQL) WithIDColumn(column string) *SQL {
+	if !identifierPattern.MatchString(column) {
+		panic(fmt.Sprintf("некорректное имя колонки %q", column))
+	}
+	s.idColumn = column
+	if !slices.Contains(s.columns, column) {
+		s.columns = append([]string{column}, s.columns...)
+	}
+	return s
+}
+
+// WithPlaceholder задает формат плейсхолдеров, например sq.Question для MySQL и SQLite
+func (s *SQL) WithPlaceholder(format sq.PlaceholderFormat) *SQL {
+	s.sb = sq.StatementBuilder.PlaceholderFormat(format)
+	return s
+}
+
+// Get возвращает запись по ID
+func (s *SQL) Get(ctx context.Context, id string) (map[string]interface{}, error) {
+	query, args, err := s.sb.Select(s.columns...).From(s.table).Where(sq.Eq{s.idColumn: id}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrNotFound
+	}
+	return records[0], nil
+}
+
+// List возвращает страницу записей
+func (s *SQL) List(ctx context.Context, query Query) (List, error) {
+	where, err := s.where(query.Filters)
+	if err != nil {
+		return List{}, err
+	}
+
+	count, args, err := s.sb.Select("COUNT(*)").From(s.table).Where(where).ToSql()
+	if err != nil {
+		return List{}, err
+	}
+	var list List
+	if err := s.runner(ctx).QueryRowContext(ctx, count, args...).Scan(&list.Total); err != nil {
+		return List{}, err
+	}
+
+	builder := s.sb.Select(s.columns...).From(s.table).Where(where).OrderBy(s.idColumn)
+	if query.Limit > 0 {
+		builder = builder.Limit(uint64(query.Limit)).Offset(uint64(query.Offset()))
+	}
+	statement, args, err := builder.ToSql()
+	if err != nil {
+		return List{}, err
+	}
+	list.Rows, err = s.query(ctx, statement, args...)
+	return list, err
+}
+
+// Create создает запись и возвращает ее с присвоенным ID
+func (s *SQL) Create(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+	values := s.values(data)
+	if id, ok := values[s.idColumn]; ok && (id == nil || id == "") {
+		// ID присваивает БД
+		delete(values, s.idColumn)
+	}
+
+	var statement string
+	var args []interface{}
+	if len(values) == 0 {
+		statement = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING %s", s.table, s.idColumn)
+	} else {
+		columns := sortedKeys(values)
+		row := make([]interface{}, len(columns))
+		for i, column := range columns {
+			row[i] = values[column]
+		}
+		var err error
+		statement, args, err = s.sb.Insert(s.table).Columns(columns...).Values(row...).
+			Suffix("RETURNING " + s.idColumn).ToSql()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var id interface{}
+	if err := s.runner(ctx).QueryRowContext(ctx, statement, args...).Scan(&id); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, fmt.Sprint(normalize(id)))
+}
+
+// Update изменяет переданные колонки записи
+func (s *SQL) Update(ctx context.Context, id string, data map[string]interface{}) (map[string]interface{}, error) {
+	values := s.values(data)
+	delete(values, s.idColumn)
+	if len(values) == 0 {
+		return s.Get(ctx, id)
+	}
+
+	statement, args, err := s.sb.Update(s.table).SetMap(values).Where(sq.Eq{s.idColumn: id}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.exec(ctx, statement, args...); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// Delete удаляет запись
+func (s *SQL) Delete(ctx context.Context, id string) error {
+	statement, args, err := s.sb.Delete(s.table).Where(sq.Eq{s.idColumn: id}).ToSql()
+	if err != nil {
+		return err
+	}
+	return s.exec(ctx, statement, args...)
+}
+
+// runner выполняет запросы в транзакции отправки или через пул соединений
+type runner interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// runner возвращает транзакцию из контекста или соединение с БД
+func (s *SQL) runner(ctx context.Context) runner {
+	if tx, ok := transaction.SQLFromContext(ctx); ok {
+		return tx
+	}
+	return s.db
+}
+
+// exec выполняет изменение одной записи, ErrNotFound - если запись не найдена
+func (s *SQL) exec(ctx context.Context, statement string, args ...interface{}) error {
+	result, err := s.runner(ctx).ExecContext(ctx, statement, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// query выполняет выборку и возвращает строки как записи
+func (s *SQL) query(ctx context.Context, statement string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := s.runner(ctx).QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var records []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		record := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			record[column] = normalize(values[i])
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// values возвращает значения колонок таблицы из данных формы
+func (s *SQL) values(data map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, column := range s.columns {
+		if value, ok := data[column]; ok {
+			values[column] = value
+		}
+	}
+	return values
+}
+
+// where возвращает условия выборки: параметры таблицы, совпадающие с колонками, -
+// равенство, условия выражения фильтра - по оператору
+func (s *SQL) where(filters map[string]interface{}) (sq.And, error) {
+	where := sq.And{}
+	for _, key := range sortedKeys(filters) {
+		value, ok := filters[key].(string)
+		if ok && key != types.FilterParam && slices.Contains(s.columns, key) {
+			where = append(where, sq.Eq{key: value})
+		}
+	}
+
+	for _, condition := range types.FilterConditions(filters) {
+		if !slices.Contains(s.columns, condition.Field) {
+			return nil, fmt.Errorf("фильтр по неизвестной колонке %s", condition.Field)
+		}
+		column, value := condition.Field, condition.Value
+		switch condition.Operator {
+		case types.FilterEq, types.FilterIn:
+			where = append(where, sq.Eq{column: value})
+		case types.FilterNe, types.FilterNotIn:
+			where = append(where, sq.NotEq{column: value})
+		case types.FilterGt:
+			where = append(where, sq.Gt{column: value})
+		case types.FilterGte:
+			where = append(where, sq.GtOrEq{column: value})
+		case types.FilterLt:
+			where = append(where, sq.Lt{column: value})
+		case types.FilterLte:
+			where = append(where, sq.LtOrEq{column: value})
+		case types.FilterContains:
+			where = append(where, sq.Expr(column+" LIKE ? ESCAPE '!'", "%"+likeEscaper.Replace(fmt.Sprint(value))+"%"))
+		default:
+			return nil, fmt.Errorf("оператор %s не поддерживается SQL источником", condition.Operator)
+		}
+	}
+	return where, nil
+}
+
+// likeEscaper экранирует символы шаблона LIKE, чтобы contains искал подстроку буквально.
+// Экранирующий символ "!", а не "\": в MySQL обратная косая черта внутри строки сама экранирует кавычку
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// normalize приводит значения драйвера к значениям JSON: []byte - строка
+func normalize(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+// sortedKeys возвращает ключи в стабильном порядке
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,33 @@
+package datasource
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// TestWhereContainsEscapesWildcards проверяет, что % и _ в значении contains ищутся буквально
+func TestWhereContainsEscapesWildcards(t *testing.T) {
+	s, err := NewSQL(nil, "orders", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	where, err := s.where(map[string]interface{}{
+		types.FilterParam: []types.FilterCondition{{Field: "name", Operator: types.FilterContains, Value: "50%_off!"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement, args, err := where.ToSql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(name LIKE ? ESCAPE '!')"; statement != want {
+		t.Errorf("условие %q, ожидалось %q", statement, want)
+	}
+	if want := []interface{}{"%50!%!_off!!%"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("аргументы %v, ожидалось %v", args, want)
+	}
+}
@@ -0,0 +1,292 @@
+// Package demo генерирует правдоподобные данные форм и таблиц по типам полей,
+// чтобы фронтенд можно было разрабатывать без подключенных сервисов
+package demo
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// DefaultTableTotal количество строк демонстрационной таблицы по умолчанию
+const DefaultTableTotal = 57
+
+// Generator генерирует демонстрационные данные. Строки таблиц зависят только
+// от seed, таблицы и номера строки, поэтому постраничный просмотр согласован
+type Generator struct {
+	seed       uint64
+	tableTotal int
+}
+
+// New создает генератор. При seed == 0 выбирается случайный seed
+func New(seed uint64) *Generator {
+	if seed == 0 {
+		seed = gofakeit.New(0).Uint64()
+	}
+	return &Generator{seed: seed, tableTotal: DefaultTableTotal}
+}
+
+// WithTableTotal задает количество строк демонстрационных таблиц
+func (g *Generator) WithTableTotal(total int) *Generator {
+	if total >= 0 {
+		g.tableTotal = total
+	}
+	return g
+}
+
+// Record генерирует значения полей формы. Таблицы и файлы пропускаются
+func (g *Generator) Record(fields []types.Field) map[string]interface{} {
+	faker := gofakeit.New(0)
+	record := make(map[string]interface{}, len(fields))
+	for i := range fields {
+		if value := fieldValue(faker, &fields[i]); value != nil {
+			record[fields[i].Name] = value
+		}
+	}
+	return record
+}
+
+// Table генерирует страницу таблицы по колонкам
+func (g *Generator) Table(name string, columns []types.TableColumn, page, limit int) types.TableData {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	data := types.TableData{
+		Columns: columns,
+		Rows:    make([]map[string]interface{}, 0, limit),
+		Total:   g.tableTotal,
+		Page:    page,
+		Limit:   limit,
+	}
+
+	base := g.seed ^ hashName(name)
+	for i := (page - 1) * limit; i < g.tableTotal && i < page*limit; i++ {
+		faker := gofakeit.New(base + uint64(i) + 1)
+		row := make(map[string]interface{}, len(columns))
+		for _, column := range columns {
+			if strings.EqualFold(column.Key, "id") {
+				row[column.Key] = i + 1
+				continue
+			}
+			row[column.Key] = value(faker, column.Key, column.Type, column.Options, column.Multiple, nil, "")
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	return data
+}
+
+// GetHandler возвращает обработчик OnGet с демонстрационными данными формы
+func (g *Generator) GetHandler(form *types.Form) types.GetHandler {
+	return func(ctx context.Context) (interface{}, error) {
+		return g.Record(form.Fields), nil
+	}
+}
+
+// TableHandler возвращает обработчик таблицы с демонстрационными данными
+func (g *Generator) TableHandler(name string, config *types.TableConfig) types.TableHandler {
+	return func(ctx context.Context, page, limit int, filters map[string]interface{}) (types.TableData, error) {
+		return g.Table(name, config.Columns, page, limit), nil
+	}
+}
+
+// fieldValue генерирует значение поля формы
+func fieldValue(faker *gofakeit.Faker, field *types.Field) interface{} {
+	if field.Mask != "" {
+		return maskValue(faker, field.Mask)
+	}
+	return value(faker, field.Name, field.Type, field.Options, field.Multiple, field.Validation, field.Autocomplete)
+}
+
+// value генерирует значение по типу поля, уточняя текст по имени и autocomplete
+func value(faker *gofakeit.Faker, name string, fieldType types.FieldType, options []types.SelectOption,
+	multiple bool, rules []types.ValidationRule, autocomplete string) interface{} {
+	switch fieldType {
+	case types.FieldTypeTable, types.FieldTypeFile:
+		return nil
+	case types.FieldTypeEmail:
+		return faker.Email()
+	case types.FieldTypePassword:
+		return faker.Password(true, true, true, false, false, 12)
+	case types.FieldTypeNumber:
+		min, max := numberRange(rules)
+		return faker.IntRange(min, max)
+	case types.FieldTypeTextarea:
+		return limitLength(faker.Paragraph(), rules)
+	case types.FieldTypeCheckbox:
+		return faker.Bool()
+	case types.FieldTypeDate:
+		return recentDate(faker).Format("2006-01-02")
+	case types.FieldTypeTime:
+		return recentDate(faker).Format("15:04")
+	case types.FieldTypeDateTime:
+		return recentDate(faker).Format(time.RFC3339)
+	case types.FieldTypeHidden:
+		return faker.UUID()
+	case types.FieldTypeSelect, types.FieldTypeRadio:
+		return optionValue(faker, options, multiple)
+	default:
+		return limitLength(textValue(faker, strings.ToLower(name+" "+autocomplete)), rules)
+	}
+}
+
+// textValue подбирает текст по имени поля
+func textValue(faker *gofakeit.Faker, hint string) string {
+	switch {
+	case strings.Contains(hint, "email"):
+		return faker.Email()
+	case strings.Contains(hint, "phone") || strings.Contains(hint, "tel"):
+		return faker.Phone()
+	case strings.Contains(hint, "first") || strings.Contains(hint, "given-name"):
+		return faker.FirstName()
+	case strings.Contains(hint, "last") || strings.Contains(hint, "family-name"):
+		return faker.LastName()
+	case strings.Contains(hint, "user"), strings.Contains(hint, "login"):
+		return faker.Username()
+	case strings.Contains(hint, "company") || strings.Contains(hint, "organization"):
+		return faker.Company()
+	case strings.Contains(hint, "name"):
+		return faker.Name()
+	case strings.Contains(hint, "city"):
+		return faker.City()
+	case strings.Contains(hint, "country"):
+		return faker.Country()
+	case strings.Contains(hint, "zip") || strings.Contains(hint, "postal"):
+		return faker.Zip()
+	case strings.Contains(hint, "address") || strings.Contains(hint, "street"):
+		return faker.Street()
+	case strings.Contains(hint, "url") || strings.Contains(hint, "site"):
+		return faker.URL()
+	case strings.Contains(hint, "title") || strings.Contains(hint, "position"):
+		return faker.JobTitle()
+	default:
+		return faker.Sentence()
+	}
+}
+
+// optionValue выбирает значение из вариантов, для множественного выбора - подмножество
+func optionValue(faker *gofakeit.Faker, options []types.SelectOption, multiple bool) interface{} {
+	enabled := make([]string, 0, len(options))
+	for _, option := range options {
+		if !option.Disabled {
+			enabled = append(enabled, option.Value)
+		}
+	}
+
+	if !multiple {
+		if len(enabled) == 0 {
+			return nil
+		}
+		return enabled[faker.IntRange(0, len(enabled)-1)]
+	}
+
+	values := make([]interface{}, 0, len(enabled))
+	for _, option := range enabled {
+		if faker.Bool() {
+			values = append(values, option)
+		}
+	}
+	return values
+}
+
+// maskValue генерирует значение по маске: 9 - цифра, a - буква, * - цифра или буква
+func maskValue(faker *gofakeit.Faker, mask string) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+	const alnum = letters + "0123456789"
+
+	var b strings.Builder
+	escaped := false
+	for _, c := range mask {
+		switch {
+		case escaped:
+			b.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '9':
+			b.WriteByte(byte('0' + faker.IntRange(0, 9)))
+		case c == 'a':
+			b.WriteByte(letters[faker.IntRange(0, len(letters)-1)])
+		case c == '*':
+			b.WriteByte(alnum[faker.IntRange(0, len(alnum)-1)])
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// numberRange возвращает диапазон чисел из правил min и max
+func numberRange(rules []types.ValidationRule) (int, int) {
+	min, max := 0, 1000
+	for _, rule := range rules {
+		if rule.IsWarning() {
+			continue
+		}
+		if n, ok := ruleInt(rule.Value); ok {
+			switch rule.Type {
+			case "min":
+				min = n
+			case "max":
+				max = n
+			}
+		}
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+// limitLength обрезает текст до правила maxLength
+func limitLength(text string, rules []types.ValidationRule) string {
+	for _, rule := range rules {
+		if rule.Type != "maxLength" || rule.IsWarning() {
+			continue
+		}
+		if n, ok := ruleInt(rule.Value); ok && n >= 0 && len([]rune(text)) > n {
+			text = string([]rune(text)[:n])
+		}
+	}
+	return text
+}
+
+// ruleInt приводит значение правила к целому числу
+func ruleInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// recentDate возвращает дату в пределах последнего года. Диапазон меняется раз в сутки,
+// чтобы строки таблиц оставались одинаковыми между запросами
+func recentDate(faker *gofakeit.Faker) time.Time {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return faker.DateRange(today.AddDate(-1, 0, 0), today).Truncate(time.Minute)
+}
+
+// hashName возвращает хеш имени таблицы для seed ее строк
+func hashName(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
@@ -0,0 +1,108 @@
+package formist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/types"
+)
+
+// DiagnoseTimeout ограничение времени проверок storage при самодиагностике
+const DiagnoseTimeout = 5 * time.Second
+
+// Diagnose выполняет самодиагностику: доступность storage, наличие его таблиц,
+// повторяющиеся имена форм и роутов, формы без обработчиков, некорректные
+// правила валидации, слишком большие схемы, ошибки конфигурации форм (Lint) и ошибки
+// хуков запуска. Отчет также доступен через
+// GET /admin/diagnostics (разрешение diagnostics:read)
+func (a *Admin) Diagnose(ctx context.Context) *types.DiagnosticsReport {
+	report := &types.DiagnosticsReport{CheckedAt: time.Now().UTC()}
+
+	routes, storageCheck := a.diagnoseStorage(ctx)
+	report.Add(storageCheck)
+	report.Add(a.diagnoseMigrations(ctx))
+	report.Add(a.diagnoseStartup())
+
+	issues := a.router.DiagnoseForms()
+	issues[types.CheckDuplicateNames] = append(issues[types.CheckDuplicateNames], duplicateRoutes(routes)...)
+	for _, name := range types.FormChecks {
+		report.Add(types.NewDiagnosticCheck(name, issues[name]))
+	}
+	return report
+}
+
+// diagnoseStorage проверяет доступность storage и возвращает его роуты
+func (a *Admin) diagnoseStorage(ctx context.Context) ([]*storage.Route, types.DiagnosticCheck) {
+	if a.storage == nil {
+		return nil, types.DiagnosticCheck{Name: types.CheckStorage, Status: types.DiagnosticSkipped}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DiagnoseTimeout)
+	defer cancel()
+
+	start := time.Now()
+	routes, err := a.storage.GetRoutes(ctx)
+
+	var issues []types.DiagnosticIssue
+	if err != nil {
+		issues = append(issues, types.DiagnosticIssue{
+			Level:   types.DiagnosticError,
+			Message: fmt.Sprintf("Storage недоступен (%s): %v", storage.ClassifyError(err), err),
+		})
+	}
+	if pending := a.deadLetters.Len(); pending > 0 {
+		issues = append(issues, types.DiagnosticIssue{
+			Level:   types.DiagnosticWarning,
+			Message: fmt.Sprintf("В очереди неудачных записей %d роутов", pending),
+		})
+	}
+
+	check := types.NewDiagnosticCheck(types.CheckStorage, issues)
+	check.Duration = time.Since(start)
+	return routes, check
+}
+
+// diagnoseMigrations проверяет, что таблицы storage созданы
+func (a *Admin) diagnoseMigrations(ctx context.Context) types.DiagnosticCheck {
+	checker, ok := storage.SchemaCheckerOf(a.storage)
+	if !ok {
+		return types.DiagnosticCheck{Name: types.CheckMigrations, Status: types.DiagnosticSkipped}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DiagnoseTimeout)
+	defer cancel()
+
+	var issues []types.DiagnosticIssue
+	if err := checker.CheckSchema(ctx); err != nil {
+		issues = append(issues, types.DiagnosticIssue{Level: types.DiagnosticError, Message: err.Error()})
+	}
+	return types.NewDiagnosticCheck(types.CheckMigrations, issues)
+}
+
+// duplicateRoutes находит роуты storage с одинаковым путем
+func duplicateRoutes(routes []*storage.Route) []types.DiagnosticIssue {
+	names := make(map[string][]string)
+	var paths []string
+	for _, route := range routes {
+		if _, ok := names[route.Path]; !ok {
+			paths = append(paths, route.Path)
+		}
+		names[route.Path] = append(names[route.Path], route.Name)
+	}
+
+	var issues []types.DiagnosticIssue
+	for _, path := range paths {
+		if len(names[path]) < 2 {
+			continue
+		}
+		issues = append(issues, types.DiagnosticIssue{
+			Level:   types.DiagnosticError,
+			Target:  path,
+			Message: "Путь используется несколькими роутами: " + strings.Join(names[path], ", "),
+		})
+	}
+	return issues
+}
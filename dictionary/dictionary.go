@@ -0,0 +1,266 @@
+// Package dictionary управляет справочниками: редактируемыми списками значений
+// (статусы, категории), на которые ссылаются поля выбора форм через types.DictionaryRef.
+// Для каждого справочника генерируется форма с таблицей записей для редактирования
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/koteyye/go-formist/seed"
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/types"
+)
+
+// FormPrefix префикс имени формы редактирования справочника
+const FormPrefix = "dictionary-"
+
+// Ошибки справочников
+var (
+	ErrNotFound      = errors.New("справочник не найден")
+	ErrEntryNotFound = errors.New("значение не найдено в справочнике")
+	ErrDuplicate     = errors.New("значение уже есть в справочнике")
+	ErrInvalidEntry  = errors.New("не заданы значение и название записи")
+)
+
+// Store хранит записи справочников
+type Store interface {
+	// Entries возвращает записи справочника в порядке отображения, пустой список, если их нет
+	Entries(ctx context.Context, name string) ([]types.SelectOption, error)
+
+	// SaveEntries заменяет записи справочника
+	SaveEntries(ctx context.Context, name string, entries []types.SelectOption) error
+}
+
+// MemoryStore хранит записи справочников в памяти
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string][]types.SelectOption
+}
+
+// NewMemoryStore создает хранилище справочников в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string][]types.SelectOption),
+	}
+}
+
+// Entries возвращает копию записей справочника
+func (s *MemoryStore) Entries(ctx context.Context, name string) ([]types.SelectOption, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.entries[name]), nil
+}
+
+// SaveEntries сохраняет копию записей справочника
+func (s *MemoryStore) SaveEntries(ctx context.Context, name string, entries []types.SelectOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[name] = slices.Clone(entries)
+	return nil
+}
+
+// SettingsStore хранит справочники в настройках storage под ключами каталогов
+// начальных данных (seed.CatalogPrefix), поэтому каталоги из seed-файла становятся
+// начальным содержимым справочников
+type SettingsStore struct {
+	settings storage.SettingsStorage
+}
+
+// NewSettingsStore создает хранилище справочников в настройках storage
+func NewSettingsStore(settings storage.SettingsStorage) *SettingsStore {
+	return &SettingsStore{settings: settings}
+}
+
+// Entries читает записи справочника из настройки
+func (s *SettingsStore) Entries(ctx context.Context, name string) ([]types.SelectOption, error) {
+	value, err := s.settings.GetSetting(ctx, seed.CatalogPrefix+name)
+	if errors.Is(err, storage.ErrNotFound) {
+		return []types.SelectOption{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []types.SelectOption
+	if err := json.Unmarshal(value, &entries); err != nil {
+		return nil, fmt.Errorf("справочник %s: %w", name, err)
+	}
+	return entries, nil
+}
+
+// SaveEntries сохраняет записи справочника в настройку
+func (s *SettingsStore) SaveEntries(ctx context.Context, name string, entries []types.SelectOption) error {
+	value, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return s.settings.SaveSetting(ctx, seed.CatalogPrefix+name, value)
+}
+
+// Registry определения справочников и доступ к их записям
+type Registry struct {
+	mu           sync.RWMutex
+	store        Store
+	dictionaries map[string]types.Dictionary
+
+	// writes упорядочивает изменения записей: чтение, изменение и сохранение списка
+	writes sync.Mutex
+}
+
+// New создает реестр справочников. По умолчанию записи хранятся в памяти
+func New(store Store) *Registry {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Registry{
+		store:        store,
+		dictionaries: make(map[string]types.Dictionary),
+	}
+}
+
+// SetStore заменяет хранилище записей
+func (r *Registry) SetStore(store Store) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store = store
+}
+
+// Store возвращает хранилище записей
+func (r *Registry) Store() Store {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store
+}
+
+// Define добавляет или заменяет определение справочника
+func (r *Registry) Define(dictionary types.Dictionary) error {
+	if err := dictionary.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dictionaries[dictionary.Name] = dictionary
+	return nil
+}
+
+// Get возвращает определение справочника
+func (r *Registry) Get(name string) (types.Dictionary, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dictionary, ok := r.dictionaries[name]
+	return dictionary, ok
+}
+
+// List возвращает определения справочников, упорядоченные по имени
+func (r *Registry) List() []types.Dictionary {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]types.Dictionary, 0, len(r.dictionaries))
+	for _, dictionary := range r.dictionaries {
+		list = append(list, dictionary)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Entries возвращает записи справочника, включая отключенные
+func (r *Registry) Entries(ctx context.Context, name string) ([]types.SelectOption, error) {
+	if _, ok := r.Get(name); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return r.Store().Entries(ctx, name)
+}
+
+// Contains сообщает, что value - включенная запись справочника
+func (r *Registry) Contains(ctx context.Context, name, value string) (bool, error) {
+	entries, err := r.Entries(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(entries, func(entry types.SelectOption) bool {
+		return entry.Value == value && !entry.Disabled
+	}), nil
+}
+
+// Add добавляет запись в конец справочника
+func (r *Registry) Add(ctx context.Context, name string, entry types.SelectOption) error {
+	entry.Value, entry.Label = strings.TrimSpace(entry.Value), strings.TrimSpace(entry.Label)
+	if entry.Value == "" || entry.Label == "" {
+		return ErrInvalidEntry
+	}
+
+	return r.modify(ctx, name, func(entries []types.SelectOption) ([]types.SelectOption, error) {
+		if index(entries, entry.Value) >= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicate, entry.Value)
+		}
+		return append(entries, entry), nil
+	})
+}
+
+// Update изменяет название и признак отключения записи value
+func (r *Registry) Update(ctx context.Context, name, value string, update func(entry *types.SelectOption)) error {
+	return r.modify(ctx, name, func(entries []types.SelectOption) ([]types.SelectOption, error) {
+		i := index(entries, value)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, value)
+		}
+		update(&entries[i])
+		entries[i].Value = value
+		if strings.TrimSpace(entries[i].Label) == "" {
+			return nil, ErrInvalidEntry
+		}
+		return entries, nil
+	})
+}
+
+// Delete удаляет запись value. Уже сохраненные в данных значения не изменяются;
+// чтобы запретить выбор значения, сохранив его отображение, запись лучше отключить
+func (r *Registry) Delete(ctx context.Context, name, value string) error {
+	return r.modify(ctx, name, func(entries []types.SelectOption) ([]types.SelectOption, error) {
+		i := index(entries, value)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, value)
+		}
+		return slices.Delete(entries, i, i+1), nil
+	})
+}
+
+// modify читает, изменяет и сохраняет записи справочника
+func (r *Registry) modify(ctx context.Context, name string, change func([]types.SelectOption) ([]types.SelectOption, error)) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+
+	entries, err := r.Entries(ctx, name)
+	if err != nil {
+		return err
+	}
+	entries, err = change(entries)
+	if err != nil {
+		return err
+	}
+	return r.Store().SaveEntries(ctx, name, entries)
+}
+
+// index возвращает позицию записи value или -1
+func index(entries []types.SelectOption, value string) int {
+	return slices.IndexFunc(entries, func(entry types.SelectOption) bool {
+		return entry.Value == value
+	})
+}
@@ -0,0 +1,138 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// Поля формы редактирования справочника
+const (
+	fieldValue   = "value"
+	fieldLabel   = "label"
+	fieldEntries = "entries"
+
+	// ActionDelete действие формы справочника, удаляющее запись со значением из поля value
+	ActionDelete = "delete"
+
+	entriesPageSize = 50
+)
+
+// Form возвращает форму редактирования справочника name: отправка добавляет запись,
+// таблица entries показывает записи и позволяет менять название и отключать их,
+// действие delete удаляет запись
+func (r *Registry) Form(name string) (*types.Form, error) {
+	dictionary, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	return &types.Form{
+		Name:        FormPrefix + dictionary.Name,
+		Title:       dictionary.Title,
+		Description: dictionary.Description,
+		Tags:        []string{"dictionary"},
+		Fields: []types.Field{
+			{Name: fieldValue, Type: types.FieldTypeText, Label: "Значение", Required: true},
+			{Name: fieldLabel, Type: types.FieldTypeText, Label: "Название", Required: true},
+			{
+				Name:  fieldEntries,
+				Type:  types.FieldTypeTable,
+				Label: "Записи",
+				TableConfig: &types.TableConfig{
+					Columns: []types.TableColumn{
+						{Key: fieldValue, Title: "Значение", Type: types.FieldTypeText},
+						{Key: fieldLabel, Title: "Название", Type: types.FieldTypeText, Required: true},
+						{Key: "disabled", Title: "Отключено", Type: types.FieldTypeCheckbox},
+					},
+					Pagination:  true,
+					PageSize:    entriesPageSize,
+					Editable:    true,
+					RowKey:      &types.RowKey{Columns: []string{fieldValue}},
+					OnGet:       r.entriesTable(name),
+					OnRowUpdate: r.updateEntry(name),
+				},
+			},
+		},
+		Actions: &types.Actions{
+			SubmitLabel: "Добавить",
+			Custom: []types.Action{{
+				Name:    ActionDelete,
+				Label:   "Удалить",
+				Confirm: "Удалить запись справочника? Сохраненные значения не изменятся",
+				Handler: r.deleteEntry(name),
+			}},
+		},
+		OnPost: func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
+			entry := types.SelectOption{
+				Value: fmt.Sprint(data[fieldValue]),
+				Label: fmt.Sprint(data[fieldLabel]),
+			}
+			if err := r.Add(ctx, name, entry); err != nil {
+				return nil, err
+			}
+			return entry, nil
+		},
+	}, nil
+}
+
+// entriesTable возвращает страницу записей справочника
+func (r *Registry) entriesTable(name string) types.TableHandler {
+	return func(ctx context.Context, page, limit int, filters map[string]interface{}) (types.TableData, error) {
+		entries, err := r.Entries(ctx, name)
+		if err != nil {
+			return types.TableData{}, err
+		}
+		if page < 1 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = entriesPageSize
+		}
+
+		rows := make([]map[string]interface{}, 0, limit)
+		for i := (page - 1) * limit; i < len(entries) && len(rows) < limit; i++ {
+			rows = append(rows, map[string]interface{}{
+				fieldValue: entries[i].Value,
+				fieldLabel: entries[i].Label,
+				"disabled": entries[i].Disabled,
+			})
+		}
+		return types.TableData{Rows: rows, Total: len(entries), Page: page, Limit: limit}, nil
+	}
+}
+
+// updateEntry изменяет название или признак отключения записи
+func (r *Registry) updateEntry(name string) types.RowUpdateHandler {
+	return func(ctx context.Context, id, key string, value interface{}) (interface{}, error) {
+		var update func(entry *types.SelectOption)
+		switch key {
+		case fieldLabel:
+			label, _ := value.(string)
+			update = func(entry *types.SelectOption) { entry.Label = label }
+		case "disabled":
+			disabled, _ := value.(bool)
+			update = func(entry *types.SelectOption) { entry.Disabled = disabled }
+		default:
+			return nil, errors.New("значение записи не изменяется: добавьте новую запись и отключите старую")
+		}
+
+		if err := r.Update(ctx, name, id, update); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// deleteEntry удаляет запись со значением из поля value
+func (r *Registry) deleteEntry(name string) types.FormHandler {
+	return func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
+		value, _ := data[fieldValue].(string)
+		if err := r.Delete(ctx, name, value); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{fieldValue: value}, nil
+	}
+}
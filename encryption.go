@@ -0,0 +1,40 @@
+package formist
+
+import (
+	"context"
+
+	"github.com/koteyye/go-formist/encryption"
+)
+
+// EncryptedString строка, которая шифруется при кодировании в JSON и записи в базу,
+// см. encryption.EncryptedString
+type EncryptedString = encryption.EncryptedString
+
+// WithKeyProvider настраивает шифрование мастер-ключами provider для обработчиков:
+// Encrypt, Decrypt и EncryptedString. Шифрование становится шифрованием по умолчанию
+// процесса (encryption.SetDefault), его же можно передать в encryption.WithEncryption
+// и encryption.Store через Encryption()
+func (a *Admin) WithKeyProvider(provider encryption.KeyProvider) *Admin {
+	a.envelope = encryption.New(provider)
+	encryption.SetDefault(a.envelope)
+	return a
+}
+
+// Encryption возвращает шифрование, настроенное WithKeyProvider, или nil
+func (a *Admin) Encryption() *encryption.Envelope {
+	return a.envelope
+}
+
+// Encrypt шифрует чувствительное значение (номер документа, токен) для хранения
+// в обработчике. aad связывает шифротекст с местом хранения, например "users/42/ssn":
+// значение, перенесенное в другую запись, не расшифруется.
+// Без WithKeyProvider возвращает encryption.ErrNoKeys
+func Encrypt(ctx context.Context, plaintext, aad string) (string, error) {
+	return encryption.EncryptString(ctx, plaintext, aad)
+}
+
+// Decrypt расшифровывает значение, зашифрованное Encrypt с тем же aad.
+// Незашифрованное значение возвращается как есть
+func Decrypt(ctx context.Context, value, aad string) (string, error) {
+	return encryption.DecryptString(ctx, value, aad)
+}
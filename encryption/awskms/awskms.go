@@ -0,0 +1,60 @@
+// Package awskms реализует encryption.KeyProvider на AWS KMS
+package awskms
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// Client методы KMS, которые использует провайдер (реализуется *kms.Client)
+type Client interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// Provider выдает ключи данных, зашифрованные мастер-ключом KMS
+type Provider struct {
+	client Client
+	keyID  string
+}
+
+// New создает провайдер для мастер-ключа keyID (ID, ARN или алиас)
+func New(client Client, keyID string) *Provider {
+	return &Provider{client: client, keyID: keyID}
+}
+
+// GenerateDataKey запрашивает у KMS новый ключ данных AES-256
+func (p *Provider) GenerateDataKey(ctx context.Context) ([]byte, []byte, string, error) {
+	out, err := p.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(p.keyID),
+		KeySpec: types.DataKeySpecAes256,
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	keyID := p.keyID
+	if out.KeyId != nil {
+		keyID = *out.KeyId
+	}
+	return out.Plaintext, out.CiphertextBlob, keyID, nil
+}
+
+// DecryptDataKey расшифровывает ключ данных через KMS
+func (p *Provider) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	out, err := p.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(keyID),
+		CiphertextBlob: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Plaintext) == 0 {
+		return nil, errors.New("KMS вернул пустой ключ данных")
+	}
+	return out.Plaintext, nil
+}
@@ -0,0 +1,137 @@
+// Package encryption шифрует данные, которые formist хранит в базе (настройки,
+// данные заявок на согласование), конвертным методом: каждое значение шифруется
+// собственным ключом данных AES-256-GCM, а ключ данных - мастер-ключом KeyProvider.
+// EncryptString, DecryptString и EncryptedString дают тот же примитив обработчикам форм
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefix признак зашифрованного значения. Значения без него считаются
+// записанными до включения шифрования и возвращаются как есть
+const Prefix = "enc1:"
+
+// ErrDecrypt возвращается, если значение не удалось расшифровать
+var ErrDecrypt = errors.New("не удалось расшифровать значение")
+
+// KeyProvider управляет мастер-ключами: выдает ключи данных и расшифровывает их
+type KeyProvider interface {
+	// GenerateDataKey возвращает новый 32-байтовый ключ данных, его зашифрованную
+	// мастер-ключом копию и идентификатор мастер-ключа
+	GenerateDataKey(ctx context.Context) (plaintext, encrypted []byte, keyID string, err error)
+
+	// DecryptDataKey расшифровывает ключ данных мастер-ключом keyID
+	DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error)
+}
+
+// envelope сериализованное зашифрованное значение
+type envelope struct {
+	KeyID      string `json:"k"`
+	DataKey    []byte `json:"dk"` // ключ данных, зашифрованный мастер-ключом
+	Nonce      []byte `json:"n"`
+	Ciphertext []byte `json:"c"`
+}
+
+// Envelope шифрует и расшифровывает значения конвертным методом
+type Envelope struct {
+	keys KeyProvider
+}
+
+// New создает шифрование с мастер-ключами provider
+func New(provider KeyProvider) *Envelope {
+	return &Envelope{keys: provider}
+}
+
+// Seal шифрует plaintext. aad (например, ключ настройки) связывает значение с местом
+// хранения: значение, перенесенное в другую запись, не расшифруется.
+// Результат - ASCII строка с префиксом Prefix, пригодная для текстовых колонок
+func (e *Envelope) Seal(ctx context.Context, plaintext, aad []byte) ([]byte, error) {
+	dataKey, encryptedKey, keyID, err := e.keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить ключ данных: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(envelope{
+		KeyID:      keyID,
+		DataKey:    encryptedKey,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, aad),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(Prefix + base64.RawURLEncoding.EncodeToString(payload)), nil
+}
+
+// Open расшифровывает значение, зашифрованное Seal с тем же aad.
+// Значение без Prefix возвращается без изменений
+func (e *Envelope) Open(ctx context.Context, value, aad []byte) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(string(value), Prefix))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+
+	dataKey, err := e.keys.DecryptDataKey(ctx, env.KeyID, env.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ключ %s: %v", ErrDecrypt, env.KeyID, err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: некорректный nonce", ErrDecrypt)
+	}
+
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted сообщает, что значение зашифровано Seal
+func IsEncrypted(value []byte) bool {
+	return strings.HasPrefix(string(value), Prefix)
+}
+
+// newGCM создает AES-256-GCM для 32-байтового ключа
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("ключ должен быть 32 байта, получено %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,69 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// StaticKeys мастер-ключи, заданные в конфигурации приложения (например, из переменных
+// окружения). Новые значения шифруются текущим ключом, старые ключи остаются для расшифровки
+type StaticKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeys создает набор мастер-ключей с текущим ключом currentID.
+// Ключи должны быть 32 байта (AES-256)
+func NewStaticKeys(currentID string, keys map[string][]byte) (*StaticKeys, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("текущий ключ %s не задан", currentID)
+	}
+
+	copied := make(map[string][]byte, len(keys))
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("ключ %s должен быть 32 байта, получено %d", id, len(key))
+		}
+		copied[id] = append([]byte(nil), key...)
+	}
+	return &StaticKeys{current: currentID, keys: copied}, nil
+}
+
+// GenerateDataKey создает ключ данных и шифрует его текущим мастер-ключом
+func (s *StaticKeys) GenerateDataKey(ctx context.Context) ([]byte, []byte, string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, "", err
+	}
+
+	gcm, err := newGCM(s.keys[s.current])
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, "", err
+	}
+	return dataKey, gcm.Seal(nonce, nonce, dataKey, []byte(s.current)), s.current, nil
+}
+
+// DecryptDataKey расшифровывает ключ данных мастер-ключом keyID
+func (s *StaticKeys) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("мастер-ключ %s не найден", keyID)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, fmt.Errorf("некорректный зашифрованный ключ данных")
+	}
+
+	nonce, ciphertext := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, []byte(keyID))
+}
@@ -0,0 +1,50 @@
+package encryption
+
+import (
+	"context"
+
+	"github.com/koteyye/go-formist/storage"
+)
+
+// WithEncryption шифрует значения настроек storage (admin.WithStorage(s, encryption.WithEncryption(env))).
+// Ключ настройки используется как AAD. Роуты хранятся открыто: по ним строятся
+// сортировка и проверка уникальности, и они не содержат данных пользователей
+func WithEncryption(env *Envelope) storage.Option {
+	return func(s storage.Storage) storage.Storage {
+		settings, ok := s.(storage.SettingsStorage)
+		if !ok {
+			return s
+		}
+		return &encryptedStorage{Storage: s, settings: settings, env: env}
+	}
+}
+
+// encryptedStorage storage с шифрованием настроек
+type encryptedStorage struct {
+	storage.Storage
+	settings storage.SettingsStorage
+	env      *Envelope
+}
+
+// GetSetting возвращает расшифрованное значение настройки
+func (s *encryptedStorage) GetSetting(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.settings.GetSetting(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return s.env.Open(ctx, value, []byte(key))
+}
+
+// SaveSetting шифрует и сохраняет значение настройки
+func (s *encryptedStorage) SaveSetting(ctx context.Context, key string, value []byte) error {
+	sealed, err := s.env.Seal(ctx, value, []byte(key))
+	if err != nil {
+		return err
+	}
+	return s.settings.SaveSetting(ctx, key, sealed)
+}
+
+// Unwrap возвращает исходный storage
+func (s *encryptedStorage) Unwrap() storage.Storage {
+	return s.Storage
+}
@@ -0,0 +1,133 @@
+package encryption
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNoKeys возвращается помощниками шифрования, если шифрование по умолчанию не настроено
+var ErrNoKeys = errors.New("шифрование не настроено: задайте KeyProvider")
+
+var (
+	defaultMu       sync.RWMutex
+	defaultEnvelope *Envelope
+)
+
+// SetDefault устанавливает шифрование по умолчанию для EncryptString,
+// DecryptString и EncryptedString
+func SetDefault(env *Envelope) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultEnvelope = env
+}
+
+// Default возвращает шифрование по умолчанию или nil, если оно не настроено
+func Default() *Envelope {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	return defaultEnvelope
+}
+
+// EncryptString шифрует строку шифрованием по умолчанию. aad связывает шифротекст
+// с местом хранения (например, "users/42/ssn"); пустой aad допустим
+func EncryptString(ctx context.Context, plaintext, aad string) (string, error) {
+	env := Default()
+	if env == nil {
+		return "", ErrNoKeys
+	}
+	sealed, err := env.Seal(ctx, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(sealed), nil
+}
+
+// DecryptString расшифровывает строку, зашифрованную EncryptString с тем же aad.
+// Строка без Prefix возвращается как есть
+func DecryptString(ctx context.Context, value, aad string) (string, error) {
+	if !IsEncrypted([]byte(value)) {
+		return value, nil
+	}
+	env := Default()
+	if env == nil {
+		return "", ErrNoKeys
+	}
+	plaintext, err := env.Open(ctx, []byte(value), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// EncryptedString строка, которая хранится в памяти открытой, а при кодировании
+// в JSON и записи в базу через database/sql шифруется шифрованием по умолчанию.
+// При чтении зашифрованное значение расшифровывается, открытое принимается как есть.
+// Шифротекст не привязан к месту хранения (aad пустой)
+type EncryptedString string
+
+// String возвращает открытое значение
+func (s EncryptedString) String() string {
+	return string(s)
+}
+
+// MarshalJSON кодирует зашифрованное значение
+func (s EncryptedString) MarshalJSON() ([]byte, error) {
+	sealed, err := s.seal()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealed)
+}
+
+// UnmarshalJSON расшифровывает значение
+func (s *EncryptedString) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return s.open(value)
+}
+
+// Value шифрует значение для записи в базу
+func (s EncryptedString) Value() (driver.Value, error) {
+	return s.seal()
+}
+
+// Scan расшифровывает значение, прочитанное из базы
+func (s *EncryptedString) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case string:
+		return s.open(value)
+	case []byte:
+		return s.open(string(value))
+	default:
+		return fmt.Errorf("EncryptedString: неподдерживаемый тип %T", src)
+	}
+}
+
+// seal шифрует значение; пустая строка не шифруется
+func (s EncryptedString) seal() (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	return EncryptString(context.Background(), string(s), "")
+}
+
+// open расшифровывает значение
+func (s *EncryptedString) open(value string) error {
+	plaintext, err := DecryptString(context.Background(), value, "")
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
@@ -0,0 +1,94 @@
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/koteyye/go-formist/workflow"
+)
+
+// encryptedField ключ, под которым в Data хранится зашифрованное содержимое заявки
+const encryptedField = "$encrypted"
+
+// sealedSubmission зашифрованная часть заявки
+type sealedSubmission struct {
+	Data   map[string]interface{} `json:"data"`
+	Result interface{}            `json:"result,omitempty"`
+}
+
+// Store шифрует данные и результат заявок на согласование перед сохранением в next.
+// ID заявки используется как AAD; статус, роли и авторы остаются открытыми для фильтрации
+func Store(next workflow.Store, env *Envelope) workflow.Store {
+	return &encryptedStore{next: next, env: env}
+}
+
+// encryptedStore хранилище заявок с шифрованием
+type encryptedStore struct {
+	next workflow.Store
+	env  *Envelope
+}
+
+// Save шифрует и сохраняет заявку
+func (s *encryptedStore) Save(ctx context.Context, submission *workflow.Submission) error {
+	plaintext, err := json.Marshal(sealedSubmission{Data: submission.Data, Result: submission.Result})
+	if err != nil {
+		return err
+	}
+
+	sealed, err := s.env.Seal(ctx, plaintext, []byte(submission.ID))
+	if err != nil {
+		return err
+	}
+
+	clone := *submission
+	clone.Data = map[string]interface{}{encryptedField: string(sealed)}
+	clone.Result = nil
+	return s.next.Save(ctx, &clone)
+}
+
+// Get возвращает расшифрованную заявку
+func (s *encryptedStore) Get(ctx context.Context, id string) (*workflow.Submission, error) {
+	submission, err := s.next.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(ctx, submission)
+}
+
+// List возвращает расшифрованные заявки
+func (s *encryptedStore) List(ctx context.Context, status workflow.Status) ([]*workflow.Submission, error) {
+	submissions, err := s.next.List(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, submission := range submissions {
+		if submissions[i], err = s.open(ctx, submission); err != nil {
+			return nil, err
+		}
+	}
+	return submissions, nil
+}
+
+// open расшифровывает заявку. Заявки, сохраненные до включения шифрования, возвращаются как есть
+func (s *encryptedStore) open(ctx context.Context, submission *workflow.Submission) (*workflow.Submission, error) {
+	sealed, ok := submission.Data[encryptedField].(string)
+	if !ok || len(submission.Data) != 1 {
+		return submission, nil
+	}
+
+	plaintext, err := s.env.Open(ctx, []byte(sealed), []byte(submission.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	var content sealedSubmission
+	if err := json.Unmarshal(plaintext, &content); err != nil {
+		return nil, err
+	}
+
+	clone := *submission
+	clone.Data = content.Data
+	clone.Result = content.Result
+	return &clone, nil
+}
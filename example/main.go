@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -31,7 +32,7 @@ func main() {
 		}).
 		AddCheckboxField("active", "Активен").
 		AddTextareaField("bio", "Биография").
-		OnGet(func() (interface{}, error) {
+		OnGet(func(ctx context.Context) (interface{}, error) {
 			// Возвращаем тестовые данные
 			return map[string]interface{}{
 				"name":   "Иван Иванов",
@@ -41,7 +42,7 @@ func main() {
 				"bio":    "Тестовый пользователь",
 			}, nil
 		}).
-		OnPost(func(data map[string]interface{}) (interface{}, error) {
+		OnPost(func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
 			// Обрабатываем данные формы
 			fmt.Printf("Получены данные: %+v\n", data)
 			return map[string]interface{}{
@@ -72,7 +73,7 @@ func main() {
 		WithPagination(true).
 		WithPageSize(20).
 		WithSelectable(true).
-		OnGet(func(page, limit int, filters map[string]interface{}) (types.TableData, error) {
+		OnGet(func(ctx context.Context, page, limit int, filters map[string]interface{}) (types.TableData, error) {
 			// Генерируем тестовые данные таблицы
 			rows := []map[string]interface{}{
 				{
@@ -129,7 +130,7 @@ func main() {
 	}
 
 	productForm := formist.FromStruct("products", "Товары", Product{}).
-		OnPost(func(data map[string]interface{}) (interface{}, error) {
+		OnPost(func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
 			fmt.Printf("Данные товара: %+v\n", data)
 			return map[string]interface{}{
 				"id":      456,
@@ -68,7 +68,7 @@ func main() {
 			formist.SelectOption("moderator", "Модератор"),
 		}).
 		AddCheckboxField("active", "Активен").
-		OnPost(func(data map[string]interface{}) (interface{}, error) {
+		OnPost(func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
 			// Здесь логика сохранения пользователя
 			fmt.Printf("Создание пользователя: %+v\n", data)
 			return map[string]string{
@@ -84,7 +84,7 @@ func main() {
 		AddTextareaField("site_description", "Описание сайта").
 		AddNumberField("items_per_page", "Элементов на странице").
 		AddCheckboxField("maintenance_mode", "Режим обслуживания").
-		OnGet(func() (interface{}, error) {
+		OnGet(func(ctx context.Context) (interface{}, error) {
 			// Загружаем текущие настройки
 			return map[string]interface{}{
 				"site_name":        "Мой сайт",
@@ -93,7 +93,7 @@ func main() {
 				"maintenance_mode": false,
 			}, nil
 		}).
-		OnPost(func(data map[string]interface{}) (interface{}, error) {
+		OnPost(func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
 			fmt.Printf("Сохранение настроек: %+v\n", data)
 			return map[string]string{"message": "Настройки сохранены"}, nil
 		}).
@@ -244,7 +244,7 @@ func main() {
 				Pagination: false,
 				Sortable:   true,
 				Editable:   true,
-				OnGet: func(page, limit int, filters map[string]interface{}) (types.TableData, error) {
+				OnGet: func(ctx context.Context, page, limit int, filters map[string]interface{}) (types.TableData, error) {
 					return types.TableData{
 						Columns: []types.TableColumn{
 							{Key: "skill", Title: "Навык", Type: types.FieldTypeText},
@@ -263,7 +263,7 @@ func main() {
 				},
 			},
 		}).
-		OnGet(func() (interface{}, error) {
+		OnGet(func(ctx context.Context) (interface{}, error) {
 			// Возвращаем предзаполненные данные для демонстрации
 			return map[string]interface{}{
 				"username":       "demo_user",
@@ -278,7 +278,7 @@ func main() {
 				"user_id":        "hidden_user_123",
 			}, nil
 		}).
-		OnPost(func(data map[string]interface{}) (interface{}, error) {
+		OnPost(func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
 			fmt.Printf("Полная форма отправлена: %+v\n", data)
 			return map[string]interface{}{
 				"message": "Форма успешно обработана!",
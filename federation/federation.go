@@ -0,0 +1,362 @@
+// Package federation объединяет каталоги нескольких админок formist: одна админка
+// (агрегатор) периодически загружает /admin/config и /api/routes удаленных админок
+// и показывает их пункты меню в общей навигации со ссылками или проксированием
+package federation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Mode способ открытия форм удаленной админки
+type Mode string
+
+// Способы открытия
+const (
+	// ModeLink - пункты меню ведут напрямую на удаленную админку
+	ModeLink Mode = "link"
+	// ModeProxy - запросы идут через агрегатор (/admin/remote/{name}/...) от имени
+	// пользователя агрегатора, см. Remote.Authorize
+	ModeProxy Mode = "proxy"
+)
+
+// DefaultTimeout ограничение времени загрузки каталога одной админки
+const DefaultTimeout = 10 * time.Second
+
+// maxCatalogSize максимальный размер ответа удаленной админки
+const maxCatalogSize = 10 << 20
+
+// remoteName допустимое имя удаленной админки (используется в путях)
+var remoteName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Remote описывает удаленную админку
+type Remote struct {
+	Name    string            // имя в путях и меню, например billing
+	Title   string            // заголовок группы в навигации (по умолчанию заголовок удаленной админки)
+	BaseURL string            // адрес с префиксом админки, например https://billing.internal/ops
+	APIKey  string            // API ключ удаленной админки для загрузки каталога (заголовок X-API-Key)
+	Headers map[string]string // дополнительные заголовки запросов к удаленной админке
+	Mode    Mode              // по умолчанию ModeLink
+
+	// Roles роли пользователей агрегатора, которым доступен прокси (пустой - всем
+	// с разрешением federation:access)
+	Roles []string
+	// Authorize, если задан, подставляет учетные данные пользователя агрегатора
+	// (auth.UserFromContext(req.Context())) в каждый запрос прокси. APIKey в запросы
+	// прокси не передается
+	Authorize func(req *http.Request) error
+}
+
+// Catalog последний загруженный каталог удаленной админки
+type Catalog struct {
+	Remote    Remote                `json:"-"`
+	Config    *types.ConfigResponse `json:"config,omitempty"`
+	Routes    []*storage.Route      `json:"routes,omitempty"`
+	Error     string                `json:"error,omitempty"`     // ошибка последней загрузки
+	FetchedAt time.Time             `json:"fetchedAt,omitempty"` // время последней успешной загрузки
+}
+
+// Federation каталоги удаленных админок
+type Federation struct {
+	client  *http.Client
+	remotes []Remote
+
+	mu        sync.RWMutex
+	catalogs  map[string]*Catalog
+	changedAt time.Time
+}
+
+// New создает федерацию удаленных админок. client может быть nil (http.DefaultClient)
+func New(client *http.Client, remotes ...Remote) (*Federation, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	f := &Federation{
+		client:   client,
+		catalogs: make(map[string]*Catalog, len(remotes)),
+	}
+	for _, remote := range remotes {
+		if !remoteName.MatchString(remote.Name) {
+			return nil, fmt.Errorf("некорректное имя удаленной админки: %q", remote.Name)
+		}
+		if _, ok := f.catalogs[remote.Name]; ok {
+			return nil, fmt.Errorf("удаленная админка %s указана дважды", remote.Name)
+		}
+
+		base, err := url.Parse(remote.BaseURL)
+		if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+			return nil, fmt.Errorf("удаленная админка %s: некорректный адрес %q", remote.Name, remote.BaseURL)
+		}
+		remote.BaseURL = strings.TrimRight(remote.BaseURL, "/")
+
+		switch remote.Mode {
+		case "":
+			remote.Mode = ModeLink
+		case ModeLink, ModeProxy:
+		default:
+			return nil, fmt.Errorf("удаленная админка %s: неизвестный режим %q", remote.Name, remote.Mode)
+		}
+
+		f.remotes = append(f.remotes, remote)
+		f.catalogs[remote.Name] = &Catalog{Remote: remote}
+	}
+	return f, nil
+}
+
+// Remote возвращает удаленную админку по имени
+func (f *Federation) Remote(name string) (Remote, bool) {
+	for _, remote := range f.remotes {
+		if remote.Name == name {
+			return remote, true
+		}
+	}
+	return Remote{}, false
+}
+
+// Catalogs возвращает копии каталогов в порядке подключения
+func (f *Federation) Catalogs() []Catalog {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	catalogs := make([]Catalog, 0, len(f.remotes))
+	for _, remote := range f.remotes {
+		catalogs = append(catalogs, *f.catalogs[remote.Name])
+	}
+	return catalogs
+}
+
+// ChangedAt возвращает время последнего изменения каталогов
+func (f *Federation) ChangedAt() time.Time {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.changedAt
+}
+
+// Refresh загружает каталоги всех удаленных админок параллельно. Недоступная
+// админка сохраняет последний успешно загруженный каталог и получает Error
+func (f *Federation) Refresh(ctx context.Context) error {
+	errs := make([]error, len(f.remotes))
+
+	var wg sync.WaitGroup
+	for i, remote := range f.remotes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = f.refresh(ctx, remote)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Start загружает каталоги сразу и затем каждые interval до отмены ctx.
+// onError, если задан, получает ошибки загрузки
+func (f *Federation) Start(ctx context.Context, interval time.Duration, onError func(error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := f.Refresh(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// refresh загружает каталог одной удаленной админки
+func (f *Federation) refresh(ctx context.Context, remote Remote) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	var config types.ConfigResponse
+	err := f.fetch(ctx, remote, "/admin/config", &config)
+
+	var routes []*storage.Route
+	if err == nil {
+		// Storage у удаленной админки может быть не подключен
+		if routesErr := f.fetch(ctx, remote, "/api/routes", &routes); routesErr != nil && !errors.Is(routesErr, errUnavailable) {
+			err = routesErr
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current := f.catalogs[remote.Name]
+	next := *current
+	if err != nil {
+		next.Error = err.Error()
+	} else {
+		next.Config, next.Routes, next.Error = &config, routes, ""
+		next.FetchedAt = time.Now().UTC()
+	}
+
+	if changed(current, &next) {
+		f.changedAt = time.Now()
+	}
+	f.catalogs[remote.Name] = &next
+
+	if err != nil {
+		return fmt.Errorf("удаленная админка %s: %w", remote.Name, err)
+	}
+	return nil
+}
+
+// errUnavailable возвращается, если эндпоинт удаленной админки не поддерживается
+var errUnavailable = errors.New("эндпоинт не поддерживается")
+
+// fetch выполняет GET запрос к удаленной админке и разбирает поле data ответа
+func (f *Federation) fetch(ctx context.Context, remote Remote, path string, data interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, remote.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	remote.authorize(req.Header)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented:
+		return errUnavailable
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s: статус %d", path, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCatalogSize))
+	if err != nil {
+		return err
+	}
+
+	response := types.APIResponse{Data: data}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("%s: некорректный ответ: %w", path, err)
+	}
+	if !response.Success {
+		return fmt.Errorf("%s: %s", path, response.Error)
+	}
+	return nil
+}
+
+// authorize подставляет учетные данные удаленной админки
+func (r Remote) authorize(header http.Header) {
+	if r.APIKey != "" {
+		header.Set("X-API-Key", r.APIKey)
+	}
+	for name, value := range r.Headers {
+		header.Set(name, value)
+	}
+}
+
+// Menu возвращает пункты меню удаленных админок. Для ModeLink URL ведет на удаленную
+// админку, для ModeProxy - на proxyBase/{name} агрегатора
+func (f *Federation) Menu(proxyBase string) []types.MenuItem {
+	items := make([]types.MenuItem, 0)
+	for _, catalog := range f.Catalogs() {
+		if catalog.Config == nil {
+			continue
+		}
+
+		base := catalog.Remote.BaseURL
+		if catalog.Remote.Mode == ModeProxy {
+			base = proxyBase + "/" + catalog.Remote.Name
+		}
+		for _, item := range catalog.Config.Menu {
+			item.Remote = catalog.Remote.Name
+			item.URL = base + itemPath(item)
+			items = append(items, item)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Remote < items[j].Remote
+	})
+	return items
+}
+
+// Info возвращает описание удаленных админок для конфигурации UI
+func (f *Federation) Info(proxyBase string) []types.RemoteInfo {
+	catalogs := f.Catalogs()
+	info := make([]types.RemoteInfo, 0, len(catalogs))
+	for _, catalog := range catalogs {
+		remote := catalog.Remote
+		item := types.RemoteInfo{
+			Name:      remote.Name,
+			Title:     remote.Title,
+			URL:       remote.BaseURL,
+			Mode:      string(remote.Mode),
+			Available: catalog.Error == "",
+			FetchedAt: catalog.FetchedAt,
+		}
+		if remote.Mode == ModeProxy {
+			item.URL = proxyBase + "/" + remote.Name
+		}
+		if item.Title == "" && catalog.Config != nil {
+			item.Title = catalog.Config.Title
+		}
+		info = append(info, item)
+	}
+	return info
+}
+
+// itemPath возвращает путь пункта меню в админке
+func itemPath(item types.MenuItem) string {
+	switch item.Type {
+	case types.MenuItemForm:
+		if item.Module != "" {
+			return "/admin/modules/" + item.Module + "/forms/" + strings.TrimPrefix(item.Key, item.Module+"/")
+		}
+		return "/admin/forms/" + item.Key
+	case types.MenuItemPage:
+		return "/admin/pages/" + item.Key
+	case types.MenuItemModule:
+		return "/admin/modules/" + item.Key
+	default:
+		return "/admin"
+	}
+}
+
+// changed сообщает, изменилось ли видимое содержимое каталога
+func changed(current, next *Catalog) bool {
+	if (current.Error == "") != (next.Error == "") {
+		return true
+	}
+
+	a, _ := json.Marshal(struct {
+		Config *types.ConfigResponse
+		Routes []*storage.Route
+	}{current.Config, current.Routes})
+	b, _ := json.Marshal(struct {
+		Config *types.ConfigResponse
+		Routes []*storage.Route
+	}{next.Config, next.Routes})
+	return !bytes.Equal(a, b)
+}
@@ -0,0 +1,110 @@
+package federation
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/koteyye/go-formist/auth"
+)
+
+// localCredentials заголовки с учетными данными агрегатора, которые не передаются удаленной админке
+var localCredentials = []string{"Authorization", "Cookie", "X-API-Key"}
+
+// ForwardedUserHeader заголовок с ID пользователя агрегатора в запросах прокси
+const ForwardedUserHeader = "X-Forwarded-User"
+
+// Handler возвращает прокси к удаленным админкам в режиме ModeProxy.
+// Запрос prefix/{name}/admin/forms/orders передается на {BaseURL}/admin/forms/orders
+// (prefix может быть пустым, если путь уже указан относительно прокси)
+// от имени пользователя агрегатора: вместо его учетных данных передаются ID
+// пользователя, Headers и учетные данные, подставленные Remote.Authorize
+func (f *Federation) Handler(prefix string) http.Handler {
+	proxies := make(map[string]*httputil.ReverseProxy)
+	for _, remote := range f.remotes {
+		if remote.Mode == ModeProxy {
+			proxies[remote.Name] = f.newProxy(remote)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rest, ok := strings.CutPrefix(req.URL.Path, strings.TrimRight(prefix, "/")+"/")
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+
+		name, path, _ := strings.Cut(rest, "/")
+		proxy, ok := proxies[name]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+
+		remote, _ := f.Remote(name)
+		if len(remote.Roles) > 0 {
+			user, _ := auth.UserFromContext(req.Context())
+			if !user.HasAnyRole(remote.Roles) {
+				http.Error(w, "Нет доступа к удаленной админке", http.StatusForbidden)
+				return
+			}
+		}
+
+		out := req.Clone(req.Context())
+		out.URL.Path = "/" + path
+		out.URL.RawPath = ""
+		proxy.ServeHTTP(w, out)
+	})
+}
+
+// newProxy создает обратный прокси к удаленной админке
+func (f *Federation) newProxy(remote Remote) *httputil.ReverseProxy {
+	base, _ := url.Parse(remote.BaseURL)
+
+	transport := f.client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(base)
+			r.SetXForwarded()
+
+			for _, name := range localCredentials {
+				r.Out.Header.Del(name)
+			}
+			r.Out.Header.Del(ForwardedUserHeader)
+			if user, ok := auth.UserFromContext(r.In.Context()); ok {
+				r.Out.Header.Set(ForwardedUserHeader, user.ID)
+			}
+			for name, value := range remote.Headers {
+				r.Out.Header.Set(name, value)
+			}
+		},
+		Transport: authorizingTransport{base: transport, authorize: remote.Authorize},
+		ModifyResponse: func(resp *http.Response) error {
+			// Cookie удаленной админки относятся к ее домену
+			resp.Header.Del("Set-Cookie")
+			return nil
+		},
+	}
+}
+
+// authorizingTransport подставляет учетные данные пользователя перед отправкой запроса
+type authorizingTransport struct {
+	base      http.RoundTripper
+	authorize func(req *http.Request) error
+}
+
+// RoundTrip выполняет запрос
+func (t authorizingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.authorize != nil {
+		if err := t.authorize(req); err != nil {
+			return nil, fmt.Errorf("авторизация: %w", err)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,399 @@
+// Package filter разбирает компактные выражения фильтров таблиц
+// (status in (paid,shipped) and total > 100 and created_at within last_7d)
+// в условия types.FilterCondition с проверкой по колонкам таблицы
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// MaxLength максимальная длина выражения фильтра
+const MaxLength = 4096
+
+// Error ошибка разбора выражения с позицией (в символах от начала выражения)
+type Error struct {
+	Pos     int
+	Message string
+}
+
+// Error возвращает текст ошибки
+func (e *Error) Error() string {
+	return fmt.Sprintf("фильтр: позиция %d: %s", e.Pos, e.Message)
+}
+
+// tokenKind вид лексемы выражения
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOperator
+	tokenOpen
+	tokenClose
+	tokenComma
+	tokenEnd
+)
+
+// token лексема выражения
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keyword сообщает, что лексема - ключевое слово name (без учета регистра)
+func (t token) keyword(name string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, name)
+}
+
+// comparisons операторы сравнения выражения
+var comparisons = map[string]types.FilterOperator{
+	"=":  types.FilterEq,
+	"!=": types.FilterNe,
+	">":  types.FilterGt,
+	">=": types.FilterGte,
+	"<":  types.FilterLt,
+	"<=": types.FilterLte,
+}
+
+// Parse разбирает выражение фильтра и проверяет его по колонкам таблицы.
+// Поле доступно для фильтрации, если у колонки или таблицы задан Filterable.
+// Относительные периоды within отсчитываются от now
+func Parse(expr string, config *types.TableConfig, now time.Time) ([]types.FilterCondition, error) {
+	if len([]rune(expr)) > MaxLength {
+		return nil, &Error{Message: fmt.Sprintf("выражение длиннее %d символов", MaxLength)}
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, config: config, now: now}
+	return p.parse()
+}
+
+// parser разбирает последовательность лексем
+type parser struct {
+	tokens []token
+	pos    int
+	config *types.TableConfig
+	now    time.Time
+}
+
+// next возвращает текущую лексему и переходит к следующей
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+// peek возвращает текущую лексему
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// parse разбирает условия, объединенные and
+func (p *parser) parse() ([]types.FilterCondition, error) {
+	if p.peek().kind == tokenEnd {
+		return nil, nil
+	}
+
+	var conditions []types.FilterCondition
+	for {
+		condition, err := p.condition()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+
+		t := p.next()
+		if t.kind == tokenEnd {
+			return conditions, nil
+		}
+		if !t.keyword("and") {
+			return nil, &Error{Pos: t.pos, Message: fmt.Sprintf("ожидается and, получено %q", t.text)}
+		}
+	}
+}
+
+// condition разбирает условие: поле, оператор и значение
+func (p *parser) condition() (types.FilterCondition, error) {
+	t := p.next()
+	if t.kind != tokenWord || t.keyword("and") {
+		return types.FilterCondition{}, &Error{Pos: t.pos, Message: "ожидается имя колонки"}
+	}
+
+	column := p.column(t.text)
+	if column == nil {
+		return types.FilterCondition{}, &Error{Pos: t.pos, Message: fmt.Sprintf("колонка %q недоступна для фильтрации", t.text)}
+	}
+	condition := types.FilterCondition{Field: column.Key}
+
+	op := p.next()
+	switch {
+	case op.kind == tokenOperator:
+		condition.Operator = comparisons[op.text]
+		if isOrdering(condition.Operator) && !isOrdered(column.Type) {
+			return condition, &Error{Pos: op.pos, Message: fmt.Sprintf("оператор %s не поддерживается для колонки %q", op.text, column.Key)}
+		}
+		value, err := p.value(column)
+		if err != nil {
+			return condition, err
+		}
+		condition.Value = value
+
+	case op.keyword("in"), op.keyword("not"):
+		condition.Operator = types.FilterIn
+		if op.keyword("not") {
+			if in := p.next(); !in.keyword("in") {
+				return condition, &Error{Pos: in.pos, Message: "ожидается in после not"}
+			}
+			condition.Operator = types.FilterNotIn
+		}
+		values, err := p.list(column)
+		if err != nil {
+			return condition, err
+		}
+		condition.Value = values
+
+	case op.keyword("contains"):
+		if !isText(column) {
+			return condition, &Error{Pos: op.pos, Message: fmt.Sprintf("оператор contains не поддерживается для колонки %q", column.Key)}
+		}
+		t := p.next()
+		if t.kind != tokenWord && t.kind != tokenString {
+			return condition, &Error{Pos: t.pos, Message: "ожидается значение"}
+		}
+		condition.Operator = types.FilterContains
+		condition.Value = t.text
+
+	case op.keyword("within"):
+		if column.Type != types.FieldTypeDate && column.Type != types.FieldTypeDateTime {
+			return condition, &Error{Pos: op.pos, Message: fmt.Sprintf("оператор within поддерживается только для дат, колонка %q", column.Key)}
+		}
+		t := p.next()
+		period, ok := parsePeriod(t.text)
+		if t.kind != tokenWord || !ok {
+			return condition, &Error{Pos: t.pos, Message: "ожидается период вида last_7d (единицы m, h, d, w, не больше 100 лет)"}
+		}
+		// within last_N сводится к сравнению с началом периода
+		condition.Operator = types.FilterGte
+		condition.Value = p.now.Add(-period)
+
+	default:
+		return condition, &Error{Pos: op.pos, Message: fmt.Sprintf("ожидается оператор после %q", column.Key)}
+	}
+
+	return condition, nil
+}
+
+// list разбирает список значений в скобках
+func (p *parser) list(column *types.TableColumn) ([]interface{}, error) {
+	if t := p.next(); t.kind != tokenOpen {
+		return nil, &Error{Pos: t.pos, Message: "ожидается ("}
+	}
+
+	var values []interface{}
+	for {
+		value, err := p.value(column)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t := p.next()
+		switch t.kind {
+		case tokenComma:
+		case tokenClose:
+			return values, nil
+		default:
+			return nil, &Error{Pos: t.pos, Message: "ожидается , или )"}
+		}
+	}
+}
+
+// value разбирает значение и приводит его к типу колонки
+func (p *parser) value(column *types.TableColumn) (interface{}, error) {
+	t := p.next()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return nil, &Error{Pos: t.pos, Message: "ожидается значение"}
+	}
+
+	value, err := convert(column, t.text)
+	if err != nil {
+		return nil, &Error{Pos: t.pos, Message: fmt.Sprintf("колонка %q: %v", column.Key, err)}
+	}
+	return value, nil
+}
+
+// column возвращает колонку, доступную для фильтрации
+func (p *parser) column(key string) *types.TableColumn {
+	for i := range p.config.Columns {
+		column := &p.config.Columns[i]
+		if column.Key == key && (column.Filterable || p.config.Filterable) {
+			return column
+		}
+	}
+	return nil
+}
+
+// convert приводит значение к типу колонки
+func convert(column *types.TableColumn, raw string) (interface{}, error) {
+	if len(column.Options) > 0 {
+		for _, option := range column.Options {
+			if fmt.Sprint(option.Value) == raw {
+				return option.Value, nil
+			}
+		}
+		return nil, fmt.Errorf("значение %q не входит в список допустимых", raw)
+	}
+
+	switch column.Type {
+	case types.FieldTypeNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ожидается число, получено %q", raw)
+		}
+		return number, nil
+	case types.FieldTypeCheckbox:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("ожидается true или false, получено %q", raw)
+		}
+		return flag, nil
+	case types.FieldTypeDate, types.FieldTypeDateTime:
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("ожидается дата в формате 2006-01-02 или RFC 3339, получено %q", raw)
+	default:
+		return raw, nil
+	}
+}
+
+// isOrdering сообщает, что оператор сравнивает значения по порядку
+func isOrdering(op types.FilterOperator) bool {
+	return op == types.FilterGt || op == types.FilterGte || op == types.FilterLt || op == types.FilterLte
+}
+
+// isOrdered сообщает, что значения колонки упорядочены
+func isOrdered(fieldType types.FieldType) bool {
+	switch fieldType {
+	case types.FieldTypeNumber, types.FieldTypeDate, types.FieldTypeDateTime, types.FieldTypeTime:
+		return true
+	}
+	return false
+}
+
+// isText сообщает, что колонка текстовая
+func isText(column *types.TableColumn) bool {
+	if len(column.Options) > 0 {
+		return false
+	}
+	switch column.Type {
+	case types.FieldTypeNumber, types.FieldTypeCheckbox, types.FieldTypeDate, types.FieldTypeDateTime, types.FieldTypeTime:
+		return false
+	}
+	return true
+}
+
+// periodUnits единицы относительных периодов
+var periodUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// maxPeriod наибольший относительный период; ограничение не дает переполнить time.Duration
+const maxPeriod = 100 * 365 * 24 * time.Hour
+
+// parsePeriod разбирает период вида last_7d
+func parsePeriod(text string) (time.Duration, bool) {
+	rest, ok := strings.CutPrefix(strings.ToLower(text), "last_")
+	if !ok || len(rest) < 2 {
+		return 0, false
+	}
+
+	unit, ok := periodUnits[rest[len(rest)-1]]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest[:len(rest)-1])
+	if err != nil || n <= 0 || int64(n) > int64(maxPeriod/unit) {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
+
+// tokenize разбивает выражение на лексемы
+func tokenize(expr string) ([]token, error) {
+	runes := []rune(expr)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			text := string(runes[start:i])
+			if _, ok := comparisons[text]; !ok {
+				return nil, &Error{Pos: start, Message: fmt.Sprintf("неизвестный оператор %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: text, pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, &Error{Pos: start, Message: "незакрытая строка"}
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					sb.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == r {
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("(),=!<>\"'", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), pos: start})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, text: "конец выражения", pos: len(runes)}), nil
+}
@@ -0,0 +1,29 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// TestParsePeriodOverflow проверяет, что слишком длинный период отклоняется, а не переполняет time.Duration
+func TestParsePeriodOverflow(t *testing.T) {
+	config := &types.TableConfig{
+		Filterable: true,
+		Columns:    []types.TableColumn{{Key: "created_at", Type: types.FieldTypeDate}},
+	}
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := Parse("created_at within last_999999999999d", config, now); err == nil {
+		t.Fatal("период last_999999999999d принят")
+	}
+
+	conditions, err := Parse("created_at within last_7d", config, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(-7 * 24 * time.Hour); conditions[0].Value != want {
+		t.Errorf("начало периода %v, ожидалось %v", conditions[0].Value, want)
+	}
+}
@@ -1,6 +1,10 @@
 package form
 
 import (
+	"time"
+
+	"github.com/koteyye/go-formist/datasource"
+	"github.com/koteyye/go-formist/transaction"
 	"github.com/koteyye/go-formist/types"
 )
 
@@ -32,6 +36,30 @@ func (fb *FormBuilder) WithDescription(description string) *FormBuilder {
 	return fb
 }
 
+// WithIcon задает иконку формы для навигации: имя из набора иконок или URL изображения
+func (fb *FormBuilder) WithIcon(icon string) *FormBuilder {
+	fb.form.Icon = icon
+	return fb
+}
+
+// WithMeta задает метаданные ссылок на форму: шаблоны заголовка и описания, изображение превью
+func (fb *FormBuilder) WithMeta(meta types.Meta) *FormBuilder {
+	fb.form.Meta = &meta
+	return fb
+}
+
+// WithTags задает метки формы для фильтрации в навигации
+func (fb *FormBuilder) WithTags(tags ...string) *FormBuilder {
+	fb.form.Tags = tags
+	return fb
+}
+
+// InModule помещает форму в модуль (пространство имен), например billing
+func (fb *FormBuilder) InModule(module string) *FormBuilder {
+	fb.form.Module = module
+	return fb
+}
+
 // AddField добавляет поле в форму
 func (fb *FormBuilder) AddField(field types.Field) *FormBuilder {
 	fb.form.Fields = append(fb.form.Fields, field)
@@ -48,6 +76,18 @@ func (fb *FormBuilder) AddTextField(name, label string) *FormBuilder {
 	return fb.AddField(field)
 }
 
+// AddMaskedField добавляет текстовое поле с маской ввода (например, types.MaskPhoneRU).
+// Маска передается в UI Schema и проверяется на сервере
+func (fb *FormBuilder) AddMaskedField(name, label, mask string) *FormBuilder {
+	field := types.Field{
+		Name:  name,
+		Type:  types.FieldTypeText,
+		Label: label,
+		Mask:  mask,
+	}
+	return fb.AddField(field)
+}
+
 // AddEmailField добавляет поле email
 func (fb *FormBuilder) AddEmailField(name, label string) *FormBuilder {
 	field := types.Field{
@@ -104,6 +144,17 @@ func (fb *FormBuilder) AddMultiSelectField(name, label string, options []types.S
 	return fb.AddField(field)
 }
 
+// AddDictionaryField добавляет поле выбора, варианты которого берутся из справочника dictionary
+func (fb *FormBuilder) AddDictionaryField(name, label, dictionary string) *FormBuilder {
+	field := types.Field{
+		Name:       name,
+		Type:       types.FieldTypeSelect,
+		Label:      label,
+		Dictionary: &types.DictionaryRef{Name: dictionary},
+	}
+	return fb.AddField(field)
+}
+
 // AddCheckboxField добавляет поле чекбокса
 func (fb *FormBuilder) AddCheckboxField(name, label string) *FormBuilder {
 	field := types.Field{
@@ -134,6 +185,17 @@ func (fb *FormBuilder) AddDateField(name, label string) *FormBuilder {
 	return fb.AddField(field)
 }
 
+// AddDateTimeField добавляет поле даты и времени. Значения хранятся в UTC
+// и отображаются в часовом поясе пользователя или админки
+func (fb *FormBuilder) AddDateTimeField(name, label string) *FormBuilder {
+	field := types.Field{
+		Name:  name,
+		Type:  types.FieldTypeDateTime,
+		Label: label,
+	}
+	return fb.AddField(field)
+}
+
 // AddFileField добавляет поле файла
 func (fb *FormBuilder) AddFileField(name, label string) *FormBuilder {
 	field := types.Field{
@@ -191,12 +253,269 @@ func (fb *FormBuilder) OnPost(handler types.FormHandler) *FormBuilder {
 	return fb
 }
 
+// OnDryRun устанавливает обработчик пробного запуска (?dry_run=true).
+// Без него пробный запуск формы отклоняется с 501 Not Implemented
+func (fb *FormBuilder) OnDryRun(handler types.FormHandler) *FormBuilder {
+	fb.form.OnDryRun = handler
+	return fb
+}
+
+// DryRunWithOnPost использует установленный OnPost и для пробного запуска.
+// OnPost должен проверять types.IsDryRun и не сохранять изменения в этом режиме
+func (fb *FormBuilder) DryRunWithOnPost() *FormBuilder {
+	fb.form.OnDryRun = fb.form.OnPost
+	return fb
+}
+
+// OnPostTx устанавливает обработчик POST запросов, который выполняется в транзакции
+// begin: она доступна через transaction.FromContext и фиксируется при успехе обработчика.
+// Пакетная отправка формы выполняется в одной общей транзакции. При пробном запуске
+// обработчик выполняется в той же транзакции, которая затем откатывается
+func (fb *FormBuilder) OnPostTx(begin transaction.Beginner, handler types.FormHandler) *FormBuilder {
+	fb.form.OnPost = transaction.Handler(begin, handler)
+	fb.form.OnDryRun = fb.form.OnPost
+	fb.form.BatchTx = transaction.Batch(begin)
+	return fb
+}
+
+// OnBatchTx выполняет пакетную отправку (POST /forms/{name}/batch) в транзакции tx:
+// при ошибке любого элемента изменения всего пакета откатываются
+func (fb *FormBuilder) OnBatchTx(tx types.TxFunc) *FormBuilder {
+	fb.form.BatchTx = tx
+	return fb
+}
+
 // OnGet устанавливает обработчик GET запросов
 func (fb *FormBuilder) OnGet(handler types.GetHandler) *FormBuilder {
 	fb.form.OnGet = handler
 	return fb
 }
 
+// RequireApproval требует согласования отправки формы пользователем с одной из ролей.
+// До одобрения заявка ожидает в очереди, а OnPost не вызывается
+func (fb *FormBuilder) RequireApproval(roles ...string) *FormBuilder {
+	fb.form.Approval = &types.Approval{Roles: roles}
+	return fb
+}
+
+// WithLocale задает локаль ввода чисел и дат (например, ru: "1 234,56", "31.12.2024").
+// Локаль, явно указанная в запросе (?locale= или Content-Language), имеет приоритет
+func (fb *FormBuilder) WithLocale(tag string) *FormBuilder {
+	fb.form.Locale = tag
+	return fb
+}
+
+// Concurrency ограничивает количество одновременных вызовов OnPost и длину очереди ожидания.
+// Запросы сверх очереди или дольше queueTimeout получают 503 Service Unavailable
+func (fb *FormBuilder) Concurrency(maxConcurrent, queueSize int, queueTimeout time.Duration) *FormBuilder {
+	fb.form.Concurrency = &types.Concurrency{
+		MaxConcurrent: maxConcurrent,
+		QueueSize:     queueSize,
+		QueueTimeout:  queueTimeout,
+	}
+	return fb
+}
+
+// WithSLO задает цели уровня обслуживания формы: доступность (например, 0.999),
+// порог задержки и долю запросов быстрее него (например, 500ms и 0.95).
+// Сводки доступны в /api/slo, правила оповещений - в /api/slo/rules
+func (fb *FormBuilder) WithSLO(availability float64, latency time.Duration, latencyTarget float64) *FormBuilder {
+	fb.form.SLO = &types.SLO{
+		Availability:  availability,
+		Latency:       latency,
+		LatencyTarget: latencyTarget,
+	}
+	return fb
+}
+
+// CoalesceReads объединяет одновременные одинаковые вызовы OnGet и обработчиков таблиц:
+// источник данных вызывается один раз, результат получают все ожидающие запросы.
+// Подходит только для данных, не зависящих от пользователя
+func (fb *FormBuilder) CoalesceReads() *FormBuilder {
+	fb.form.Coalesce = true
+	return fb
+}
+
+// AllowPrefill разрешает заполнять поля fields из ссылки параметрами ?prefill[поле]=значение
+func (fb *FormBuilder) AllowPrefill(fields ...string) *FormBuilder {
+	fb.form.Prefill = append(fb.form.Prefill, fields...)
+	return fb
+}
+
+// OnPatch включает частичное обновление записи PATCH /admin/forms/{name}/{id}: handler получает
+// только измененные поля. С load (может быть nil) поддерживаются все операции JSON Patch
+func (fb *FormBuilder) OnPatch(handler types.PatchHandler, load types.RecordHandler) *FormBuilder {
+	fb.form.Patch = &types.Patch{Load: load, Handler: handler}
+	return fb
+}
+
+// WithDataSource подключает форму к источнику записей src: OnGet загружает запись ?id=
+// (без него форма открывается пустой), OnPost создает запись или изменяет запись ?id=,
+// PATCH /admin/forms/{name}/{id} изменяет только переданные поля
+func (fb *FormBuilder) WithDataSource(src datasource.Source) *FormBuilder {
+	fb.form.OnGet = datasource.GetHandler(src)
+	fb.form.OnPost = datasource.PostHandler(src)
+	fb.form.OnDryRun = fb.form.OnPost // при пробном запуске источник не изменяется
+	return fb.OnPatch(datasource.PatchHandler(src), datasource.RecordHandler(src))
+}
+
+// WithPartTimeout ограничивает время каждой части GET формы: схем, OnGet и таблиц ?include=tables.
+// Части выполняются одновременно; не уложившаяся часть прерывает запрос с ошибкой 504
+func (fb *FormBuilder) WithPartTimeout(timeout time.Duration) *FormBuilder {
+	fb.form.PartTimeout = timeout
+	return fb
+}
+
+// TrackProvenance включает учет автора и времени последнего изменения каждого поля записи:
+// отправка с ?id= отмечает измененные поля, а GET с ?id= возвращает их в meta.provenance
+func (fb *FormBuilder) TrackProvenance() *FormBuilder {
+	fb.form.Provenance = true
+	return fb
+}
+
+// WithSubmitLabel задает текст кнопки отправки
+func (fb *FormBuilder) WithSubmitLabel(label string) *FormBuilder {
+	fb.actions().SubmitLabel = label
+	return fb
+}
+
+// WithCancelLabel задает текст кнопки отмены
+func (fb *FormBuilder) WithCancelLabel(label string) *FormBuilder {
+	fb.actions().CancelLabel = label
+	return fb
+}
+
+// ConfirmSubmit задает текст подтверждения, который UI показывает перед отправкой
+func (fb *FormBuilder) ConfirmSubmit(text string) *FormBuilder {
+	fb.actions().ConfirmSubmit = text
+	return fb
+}
+
+// RequireWarningsConfirm требует подтверждения отправки, при которой сработали
+// предупреждающие правила: без ?confirm_warnings=true сервер отвечает 409 со списком предупреждений
+func (fb *FormBuilder) RequireWarningsConfirm() *FormBuilder {
+	fb.form.ConfirmWarnings = true
+	return fb
+}
+
+// AddAction добавляет кнопку формы, вызывающую action.Handler
+// (POST /forms/{name}/actions/{action})
+func (fb *FormBuilder) AddAction(action types.Action) *FormBuilder {
+	actions := fb.actions()
+	actions.Custom = append(actions.Custom, action)
+	return fb
+}
+
+// AddShortcut добавляет сочетание клавиш keys (например, Mod+S), вызывающее action:
+// types.ShortcutSubmit, types.ShortcutCancel, types.ShortcutSearch или действие формы по имени
+func (fb *FormBuilder) AddShortcut(keys, action, label string) *FormBuilder {
+	fb.form.Shortcuts = append(fb.form.Shortcuts, types.Shortcut{Keys: keys, Action: action, Label: label})
+	return fb
+}
+
+// WithDefaultShortcuts добавляет стандартные сочетания types.DefaultShortcuts:
+// Mod+Enter - отправка, Escape - отмена, / - поиск
+func (fb *FormBuilder) WithDefaultShortcuts() *FormBuilder {
+	fb.form.Shortcuts = append(fb.form.Shortcuts, types.DefaultShortcuts()...)
+	return fb
+}
+
+// WithDuplicate добавляет кнопку копирования записи: load загружает запись по ID,
+// поля exclude (ID, уникальные номера) не копируются в новую запись
+func (fb *FormBuilder) WithDuplicate(load types.RecordHandler, exclude ...string) *FormBuilder {
+	fb.actions().Duplicate = &types.Duplicate{Load: load, Exclude: exclude}
+	return fb
+}
+
+// actions возвращает настройки кнопок формы, создавая их при необходимости
+func (fb *FormBuilder) actions() *types.Actions {
+	if fb.form.Actions == nil {
+		fb.form.Actions = &types.Actions{}
+	}
+	return fb.form.Actions
+}
+
+// WithHelp задает справку поля name (поддерживает markdown).
+// Если поле не найдено, вызов игнорируется
+func (fb *FormBuilder) WithHelp(name, help string) *FormBuilder {
+	if field := fb.field(name); field != nil {
+		field.Help = help
+	}
+	return fb
+}
+
+// WithTooltip задает краткую подсказку поля name.
+// Если поле не найдено, вызов игнорируется
+func (fb *FormBuilder) WithTooltip(name, tooltip string) *FormBuilder {
+	if field := fb.field(name); field != nil {
+		field.Tooltip = tooltip
+	}
+	return fb
+}
+
+// WithExamples задает примеры значений поля name.
+// Если поле не найдено, вызов игнорируется
+func (fb *FormBuilder) WithExamples(name string, examples ...string) *FormBuilder {
+	if field := fb.field(name); field != nil {
+		field.Examples = examples
+	}
+	return fb
+}
+
+// WithSuggest задает обработчик подсказок значений поля name
+// (GET /forms/{name}/fields/{field}/suggest?q=префикс).
+// Если поле не найдено, вызов игнорируется
+func (fb *FormBuilder) WithSuggest(name string, handler types.SuggestHandler) *FormBuilder {
+	if field := fb.field(name); field != nil {
+		field.Suggest = handler
+	}
+	return fb
+}
+
+// WithLink делает значение поля name ссылкой на запись формы link.Form (связь между сущностями).
+// Если поле не найдено, вызов игнорируется
+func (fb *FormBuilder) WithLink(name string, link types.Link) *FormBuilder {
+	if field := fb.field(name); field != nil {
+		field.Link = &link
+	}
+	return fb
+}
+
+// WithAutocomplete задает токены HTML autocomplete поля name, например "email" или "shipping postal-code".
+// Если поле не найдено, вызов игнорируется
+func (fb *FormBuilder) WithAutocomplete(name, autocomplete string) *FormBuilder {
+	if field := fb.field(name); field != nil {
+		field.Autocomplete = autocomplete
+	}
+	return fb
+}
+
+// WithAccessibility задает aria-label, aria-describedby и порядок обхода поля name.
+// Если поле не найдено, вызов игнорируется
+func (fb *FormBuilder) WithAccessibility(name string, accessibility types.Accessibility) *FormBuilder {
+	if field := fb.field(name); field != nil {
+		field.Accessibility = &accessibility
+	}
+	return fb
+}
+
+// field возвращает добавленное поле по имени
+func (fb *FormBuilder) field(name string) *types.Field {
+	for i := range fb.form.Fields {
+		if fb.form.Fields[i].Name == name {
+			return &fb.form.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Validate проверяет правила валидации формы, в том числе компилирует паттерны.
+// Позволяет получить ошибку некорректного регулярного выражения до регистрации формы
+func (fb *FormBuilder) Validate() error {
+	_, err := NewValidator(fb.form)
+	return err
+}
+
 // Build завершает построение формы
 func (fb *FormBuilder) Build() *types.Form {
 	return fb.form
@@ -319,6 +638,73 @@ func (tfb *TableFieldBuilder) WithAlign(align string) *TableFieldBuilder {
 	return tfb
 }
 
+// WithLink делает ячейки колонки ссылками на записи другой формы
+func (tfb *TableFieldBuilder) WithLink(link types.Link) *TableFieldBuilder {
+	if len(tfb.field.TableConfig.Columns) > 0 {
+		lastIdx := len(tfb.field.TableConfig.Columns) - 1
+		tfb.field.TableConfig.Columns[lastIdx].Link = &link
+	}
+	return tfb
+}
+
+// WithFormat задает форматирование значений колонки
+func (tfb *TableFieldBuilder) WithFormat(format types.ColumnFormat) *TableFieldBuilder {
+	if len(tfb.field.TableConfig.Columns) > 0 {
+		lastIdx := len(tfb.field.TableConfig.Columns) - 1
+		tfb.field.TableConfig.Columns[lastIdx].Format = &format
+	}
+	return tfb
+}
+
+// WithCurrency отображает значения колонки как денежные суммы в валюте code (ISO 4217)
+func (tfb *TableFieldBuilder) WithCurrency(code string) *TableFieldBuilder {
+	return tfb.WithFormat(types.ColumnFormat{Kind: types.ColumnFormatCurrency, Currency: code})
+}
+
+// WithPercent отображает доли как проценты
+func (tfb *TableFieldBuilder) WithPercent() *TableFieldBuilder {
+	return tfb.WithFormat(types.ColumnFormat{Kind: types.ColumnFormatPercent})
+}
+
+// WithBytes отображает значения колонки как размер в байтах
+func (tfb *TableFieldBuilder) WithBytes() *TableFieldBuilder {
+	return tfb.WithFormat(types.ColumnFormat{Kind: types.ColumnFormatBytes})
+}
+
+// WithDateTimePattern отображает даты по шаблону в нотации Unicode (dd.MM.yyyy HH:mm)
+func (tfb *TableFieldBuilder) WithDateTimePattern(pattern string) *TableFieldBuilder {
+	return tfb.WithFormat(types.ColumnFormat{Kind: types.ColumnFormatDateTime, Pattern: pattern})
+}
+
+// WithBadges отображает значения колонки бейджами с цветами по значению
+func (tfb *TableFieldBuilder) WithBadges(colors map[string]string) *TableFieldBuilder {
+	return tfb.WithFormat(types.ColumnFormat{Kind: types.ColumnFormatBadge, Badges: colors})
+}
+
+// WithLinkTemplate отображает значения колонки ссылками по шаблону с {value} и {ключ колонки}
+func (tfb *TableFieldBuilder) WithLinkTemplate(template string) *TableFieldBuilder {
+	return tfb.WithFormat(types.ColumnFormat{Kind: types.ColumnFormatLink, Link: template})
+}
+
+// WithRequired запрещает очищать ячейки колонки при редактировании
+func (tfb *TableFieldBuilder) WithRequired() *TableFieldBuilder {
+	if len(tfb.field.TableConfig.Columns) > 0 {
+		lastIdx := len(tfb.field.TableConfig.Columns) - 1
+		tfb.field.TableConfig.Columns[lastIdx].Required = true
+	}
+	return tfb
+}
+
+// WithColumnValidation добавляет правила проверки ячеек колонки при редактировании
+func (tfb *TableFieldBuilder) WithColumnValidation(rules ...types.ValidationRule) *TableFieldBuilder {
+	if len(tfb.field.TableConfig.Columns) > 0 {
+		lastIdx := len(tfb.field.TableConfig.Columns) - 1
+		column := &tfb.field.TableConfig.Columns[lastIdx]
+		column.Validation = append(column.Validation, rules...)
+	}
+	return tfb
+}
+
 // WithPagination включает/выключает пагинацию
 func (tfb *TableFieldBuilder) WithPagination(enabled bool) *TableFieldBuilder {
 	tfb.field.TableConfig.Pagination = enabled
@@ -343,12 +729,38 @@ func (tfb *TableFieldBuilder) WithEditable(enabled bool) *TableFieldBuilder {
 	return tfb
 }
 
+// WithRowKey задает колонки ключа строки (несколько колонок - составной ключ)
+func (tfb *TableFieldBuilder) WithRowKey(columns ...string) *TableFieldBuilder {
+	tfb.field.TableConfig.RowKey = &types.RowKey{Columns: columns}
+	return tfb
+}
+
+// WithRowKeyFunc задает функцию, вычисляющую ключ строки
+func (tfb *TableFieldBuilder) WithRowKeyFunc(fn func(row map[string]interface{}) string) *TableFieldBuilder {
+	tfb.field.TableConfig.RowKey = &types.RowKey{Func: fn}
+	return tfb
+}
+
 // OnGet устанавливает обработчик получения данных таблицы
 func (tfb *TableFieldBuilder) OnGet(handler types.TableHandler) *TableFieldBuilder {
 	tfb.field.TableConfig.OnGet = handler
 	return tfb
 }
 
+// WithDataSource загружает строки таблицы из источника записей src с учетом страницы и фильтров.
+// Редактирование ячеек включается через OnRowUpdate(datasource.RowUpdateHandler(src))
+func (tfb *TableFieldBuilder) WithDataSource(src datasource.Source) *TableFieldBuilder {
+	return tfb.OnGet(datasource.TableHandler(src))
+}
+
+// OnRowUpdate устанавливает обработчик сохранения ячейки редактируемой таблицы
+// и делает строки редактируемыми
+func (tfb *TableFieldBuilder) OnRowUpdate(handler types.RowUpdateHandler) *TableFieldBuilder {
+	tfb.field.TableConfig.Editable = true
+	tfb.field.TableConfig.OnRowUpdate = handler
+	return tfb
+}
+
 // Build завершает построение поля таблицы и возвращает FormBuilder
 func (tfb *TableFieldBuilder) Build(fb *FormBuilder) *FormBuilder {
 	return fb.AddField(*tfb.field)
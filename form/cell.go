@@ -0,0 +1,92 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/koteyye/go-formist/locale"
+	"github.com/koteyye/go-formist/types"
+)
+
+// ValidateCell проверяет значение ячейки таблицы по типу колонки, опциям
+// и правилам Validation. Возвращает *FieldError
+func ValidateCell(column *types.TableColumn, value interface{}) error {
+	field := types.Field{
+		Name:       column.Key,
+		Type:       column.Type,
+		Label:      column.Title,
+		Required:   column.Required,
+		Options:    column.Options,
+		Multiple:   column.Multiple,
+		Validation: column.Validation,
+	}
+
+	if !isEmpty(value) {
+		if err := checkCellType(&field, value); err != nil {
+			return &FieldError{Field: field.Name, Label: field.Label, Err: err}
+		}
+	}
+
+	validator, err := NewValidator(&types.Form{Fields: []types.Field{field}})
+	if err != nil {
+		return err
+	}
+	return validator.ValidateValue(field.Name, value)
+}
+
+// checkCellType проверяет, что значение соответствует типу колонки
+func checkCellType(field *types.Field, value interface{}) error {
+	if len(field.Options) > 0 {
+		values := []interface{}{value}
+		if field.Multiple {
+			list, ok := value.([]interface{})
+			if !ok {
+				return errors.New("ожидается список значений")
+			}
+			values = list
+		}
+		for _, v := range values {
+			if !hasOptionValue(field.Options, v) {
+				return fmt.Errorf("значение %v не входит в список допустимых", v)
+			}
+		}
+		return nil
+	}
+
+	switch field.Type {
+	case types.FieldTypeNumber:
+		if _, ok := value.(float64); !ok {
+			return errors.New("ожидается число")
+		}
+	case types.FieldTypeCheckbox:
+		if _, ok := value.(bool); !ok {
+			return errors.New("ожидается true или false")
+		}
+	case types.FieldTypeDate:
+		str, ok := value.(string)
+		if _, err := time.Parse(locale.ISODate, str); !ok || err != nil {
+			return fmt.Errorf("ожидается дата в формате %s", locale.ISODate)
+		}
+	case types.FieldTypeDateTime:
+		str, ok := value.(string)
+		if _, err := time.Parse(time.RFC3339, str); !ok || err != nil {
+			return errors.New("ожидается дата и время в формате RFC 3339")
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			return errors.New("ожидается строка")
+		}
+	}
+	return nil
+}
+
+// hasOptionValue сообщает, что значение входит в список опций
+func hasOptionValue(options []types.SelectOption, value interface{}) bool {
+	for _, option := range options {
+		if fmt.Sprint(option.Value) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
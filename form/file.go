@@ -0,0 +1,103 @@
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/koteyye/go-formist/schema"
+	"github.com/koteyye/go-formist/types"
+)
+
+// IsDefinitionFile проверяет, является ли файл определением формы (JSON или YAML)
+func IsDefinitionFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// LoadFile загружает определение формы из JSON или YAML файла.
+// Обработчики (OnGet, OnPost) в файле не задаются и должны быть привязаны в коде
+func LoadFile(path string) (*types.Form, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл %s: %w", path, err)
+	}
+
+	return ParseDefinition(data, filepath.Ext(path))
+}
+
+// ParseDefinition разбирает определение формы в формате JSON или YAML.
+// Формат определяется по расширению ext (".json", ".yaml", ".yml")
+func ParseDefinition(data []byte, ext string) (*types.Form, error) {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		// YAML приводим к JSON, чтобы использовать единые json теги типов
+		var raw interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("некорректный YAML: %w", err)
+		}
+
+		converted, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось преобразовать YAML: %w", err)
+		}
+		data = converted
+
+	case ".json":
+
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат определения формы: %s", ext)
+	}
+
+	var form types.Form
+	if err := json.Unmarshal(data, &form); err != nil {
+		return nil, fmt.Errorf("некорректное определение формы: %w", err)
+	}
+
+	if err := schema.ValidateForm(&form); err != nil {
+		return nil, err
+	}
+
+	if _, err := NewValidator(&form); err != nil {
+		return nil, err
+	}
+
+	return &form, nil
+}
+
+// LoadDir загружает определения всех форм из директории (без вложенных директорий)
+func LoadDir(dir string) (map[string]*types.Form, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать директорию %s: %w", dir, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && IsDefinitionFile(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	forms := make(map[string]*types.Form, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		form, err := LoadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		forms[path] = form
+	}
+
+	return forms, nil
+}
@@ -0,0 +1,36 @@
+package form
+
+import (
+	"github.com/koteyye/go-formist/locale"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Normalize приводит локализованные значения к каноническим типам до валидации:
+// строки числовых полей ("1 234,56") - к числам, даты ("31.12.2024") - к формату ISO 8601.
+// Возвращает *FieldError для значения, которое не удалось разобрать
+func Normalize(f *types.Form, data map[string]interface{}, loc locale.Locale) error {
+	for i := range f.Fields {
+		field := &f.Fields[i]
+
+		str, ok := data[field.Name].(string)
+		if !ok || str == "" {
+			continue
+		}
+
+		switch field.Type {
+		case types.FieldTypeNumber:
+			number, err := loc.ParseNumber(str)
+			if err != nil {
+				return &FieldError{Field: field.Name, Label: field.Label, Err: err}
+			}
+			data[field.Name] = number
+		case types.FieldTypeDate:
+			date, err := loc.ParseDate(str)
+			if err != nil {
+				return &FieldError{Field: field.Name, Label: field.Label, Err: err}
+			}
+			data[field.Name] = date.Format(locale.ISODate)
+		}
+	}
+	return nil
+}
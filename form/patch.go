@@ -0,0 +1,385 @@
+package form
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Типы содержимого частичного обновления записи
+const (
+	ContentTypeJSONPatch  = "application/json-patch+json"  // RFC 6902
+	ContentTypeMergePatch = "application/merge-patch+json" // RFC 7396
+)
+
+// errPathNotFound путь операции отсутствует в записи
+var errPathNotFound = errors.New("путь не найден")
+
+// PatchOperation операция JSON Patch (RFC 6902)
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// value возвращает значение операции; add, replace и test требуют его
+func (o PatchOperation) value() (interface{}, error) {
+	if o.Value == nil {
+		return nil, fmt.Errorf("операция %s %s: не задано значение", o.Op, o.Path)
+	}
+	var value interface{}
+	if err := json.Unmarshal(o.Value, &value); err != nil {
+		return nil, fmt.Errorf("операция %s %s: %w", o.Op, o.Path, err)
+	}
+	return value, nil
+}
+
+// ApplyJSONPatch применяет операции JSON Patch к копии записи doc.
+// Запись целиком (пустой путь) заменить нельзя
+func ApplyJSONPatch(doc map[string]interface{}, ops []PatchOperation) (map[string]interface{}, error) {
+	patched, err := normalizeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var node interface{} = patched
+	for _, op := range ops {
+		if node, err = applyOperation(node, op); err != nil {
+			return nil, err
+		}
+	}
+	return node.(map[string]interface{}), nil
+}
+
+// applyOperation применяет одну операцию JSON Patch
+func applyOperation(doc interface{}, op PatchOperation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	switch op.Op {
+	case "add", "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		result, err = setValue(doc, path, value, op.Op == "add")
+		if err != nil {
+			return nil, fmt.Errorf("операция %s %s: %w", op.Op, op.Path, err)
+		}
+	case "remove":
+		if result, _, err = removeValue(doc, path); err != nil {
+			return nil, fmt.Errorf("операция remove %s: %w", op.Path, err)
+		}
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if len(path) > len(from) && slices.Equal(path[:len(from)], from) {
+				return nil, fmt.Errorf("операция move %s: нельзя переместить значение внутрь самого себя", op.From)
+			}
+			doc, value, err = removeValue(doc, from)
+		} else {
+			value, err = getValue(doc, from)
+			if err == nil {
+				value, err = copyValue(value)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("операция %s %s: %w", op.Op, op.From, err)
+		}
+		if result, err = setValue(doc, path, value, true); err != nil {
+			return nil, fmt.Errorf("операция %s %s: %w", op.Op, op.Path, err)
+		}
+	case "test":
+		expected, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := getValue(doc, path)
+		if err != nil {
+			return nil, fmt.Errorf("операция test %s: %w", op.Path, err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return nil, fmt.Errorf("операция test %s: значение не совпадает", op.Path)
+		}
+		result = doc
+	default:
+		return nil, fmt.Errorf("неизвестная операция %q", op.Op)
+	}
+	return result, nil
+}
+
+// JSONPatchFields возвращает изменения полей без загрузки записи: допускаются только
+// add, replace и remove полей верхнего уровня. Удаленное поле получает значение nil
+func JSONPatchFields(ops []PatchOperation) (map[string]interface{}, error) {
+	changes := make(map[string]interface{}, len(ops))
+	for _, op := range ops {
+		path, err := parsePointer(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) != 1 || (op.Op != "add" && op.Op != "replace" && op.Op != "remove") {
+			return nil, fmt.Errorf("операция %s %s: без загрузки записи поддерживаются только add, replace и remove полей", op.Op, op.Path)
+		}
+
+		if op.Op == "remove" {
+			changes[path[0]] = nil
+			continue
+		}
+		if changes[path[0]], err = op.value(); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// ApplyMergePatch применяет JSON Merge Patch к копии записи doc: null удаляет поле,
+// объекты объединяются рекурсивно, остальные значения заменяются
+func ApplyMergePatch(doc, patch map[string]interface{}) (map[string]interface{}, error) {
+	patched, err := normalizeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := normalizeDocument(patch)
+	if err != nil {
+		return nil, err
+	}
+	return mergeObject(patched, normalized), nil
+}
+
+// MergePatchFields возвращает изменения полей без загрузки записи:
+// значения верхнего уровня заменяют поля целиком, null - удаление (nil)
+func MergePatchFields(patch map[string]interface{}) (map[string]interface{}, error) {
+	normalized, err := normalizeDocument(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]interface{}, len(normalized))
+	for name, value := range normalized {
+		if object, ok := value.(map[string]interface{}); ok {
+			value = mergeObject(map[string]interface{}{}, object)
+		}
+		changes[name] = value
+	}
+	return changes, nil
+}
+
+// PatchChanges возвращает поля верхнего уровня, значения которых в after отличаются от before.
+// Удаленное поле получает значение nil
+func PatchChanges(before, after map[string]interface{}) (map[string]interface{}, error) {
+	before, err := normalizeDocument(before)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]interface{})
+	for name, value := range after {
+		if previous, ok := before[name]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[name] = value
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changes[name] = nil
+		}
+	}
+	return changes, nil
+}
+
+// mergeObject объединяет patch с target по RFC 7396
+func mergeObject(target, patch map[string]interface{}) map[string]interface{} {
+	for name, value := range patch {
+		if value == nil {
+			delete(target, name)
+			continue
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			target[name] = value
+			continue
+		}
+		existing, ok := target[name].(map[string]interface{})
+		if !ok {
+			existing = map[string]interface{}{}
+		}
+		target[name] = mergeObject(existing, object)
+	}
+	return target
+}
+
+// normalizeDocument возвращает копию записи в представлении JSON:
+// числа - float64, списки - []interface{}, вложенные объекты - map[string]interface{}
+func normalizeDocument(doc map[string]interface{}) (map[string]interface{}, error) {
+	normalized := make(map[string]interface{})
+	if doc == nil {
+		return normalized, nil
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// copyValue возвращает глубокую копию значения записи
+func copyValue(value interface{}) (interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var copied interface{}
+	err = json.Unmarshal(raw, &copied)
+	return copied, err
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901) в список ключей
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, fmt.Errorf("запись целиком изменить нельзя, укажите путь к полю")
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("некорректный путь %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex разбирает индекс списка; end разрешает индекс, равный длине (вставка в конец)
+func arrayIndex(token string, length int, end bool) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("некорректный индекс %q", token)
+	}
+	if index > length || (index == length && !end) {
+		return 0, fmt.Errorf("индекс %d вне списка", index)
+	}
+	return index, nil
+}
+
+// getValue возвращает значение по пути
+func getValue(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, errPathNotFound
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, errPathNotFound
+		}
+	}
+	return node, nil
+}
+
+// setValue добавляет (add) или заменяет значение по пути и возвращает измененный узел
+func setValue(node interface{}, path []string, value interface{}, add bool) (interface{}, error) {
+	token, last := path[0], len(path) == 1
+	switch container := node.(type) {
+	case map[string]interface{}:
+		if last {
+			if _, ok := container[token]; !ok && !add {
+				return nil, errPathNotFound
+			}
+			container[token] = value
+			return container, nil
+		}
+		child, ok := container[token]
+		if !ok {
+			return nil, errPathNotFound
+		}
+		updated, err := setValue(child, path[1:], value, add)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = updated
+		return container, nil
+	case []interface{}:
+		if last && add && token == "-" {
+			return append(container, value), nil
+		}
+		index, err := arrayIndex(token, len(container), last && add)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			if add {
+				return slices.Insert(container, index, value), nil
+			}
+			container[index] = value
+			return container, nil
+		}
+		updated, err := setValue(container[index], path[1:], value, add)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = updated
+		return container, nil
+	}
+	return nil, errPathNotFound
+}
+
+// removeValue удаляет значение по пути и возвращает измененный узел и удаленное значение
+func removeValue(node interface{}, path []string) (interface{}, interface{}, error) {
+	token, last := path[0], len(path) == 1
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, nil, errPathNotFound
+		}
+		if last {
+			delete(container, token)
+			return container, child, nil
+		}
+		updated, removed, err := removeValue(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = updated
+		return container, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(container), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := container[index]
+			return slices.Delete(container, index, index+1), removed, nil
+		}
+		updated, removed, err := removeValue(container[index], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[index] = updated
+		return container, removed, nil
+	}
+	return nil, nil, errPathNotFound
+}
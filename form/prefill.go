@@ -0,0 +1,107 @@
+package form
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// PrefillParam шаблон параметра предзаполнения: prefill[поле]=значение
+const PrefillParam = "prefill"
+
+// ParsePrefill разбирает параметры ?prefill[поле]=значение для полей из form.Prefill.
+// Значения приводятся к типу поля (число, true/false, список для множественного выбора)
+// и проверяются по опциям и правилам валидации. Поле вне списка - ошибка
+func ParsePrefill(form *types.Form, query url.Values) (map[string]interface{}, error) {
+	var validator *Validator
+	values := make(map[string]interface{})
+
+	for param, raw := range query {
+		name, ok := prefillField(param)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(form.Prefill, name) {
+			return nil, fmt.Errorf("поле %s нельзя заполнить из ссылки", name)
+		}
+
+		if validator == nil {
+			var err error
+			if validator, err = NewValidator(form); err != nil {
+				return nil, err
+			}
+		}
+		field, ok := validator.Field(name)
+		if !ok {
+			return nil, fmt.Errorf("поле %s не найдено", name)
+		}
+
+		value, err := coercePrefill(field, raw)
+		if err != nil {
+			return nil, &FieldError{Field: field.Name, Label: field.Label, Err: err}
+		}
+		if err := checkCellType(field, value); err != nil {
+			return nil, &FieldError{Field: field.Name, Label: field.Label, Err: err}
+		}
+		if err := validator.ValidateValue(name, value); err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values, nil
+}
+
+// prefillField возвращает имя поля из параметра prefill[поле]
+func prefillField(param string) (string, bool) {
+	name, ok := strings.CutPrefix(param, PrefillParam+"[")
+	if !ok {
+		return "", false
+	}
+	name, ok = strings.CutSuffix(name, "]")
+	return name, ok && name != ""
+}
+
+// coercePrefill приводит строковые значения параметра к типу поля
+func coercePrefill(field *types.Field, raw []string) (interface{}, error) {
+	if field.Multiple {
+		list := make([]interface{}, 0, len(raw))
+		for _, value := range raw {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+		}
+		return list, nil
+	}
+	if len(raw) != 1 {
+		return nil, fmt.Errorf("ожидается одно значение")
+	}
+
+	value := raw[0]
+	switch field.Type {
+	case types.FieldTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ожидается число")
+		}
+		return number, nil
+	case types.FieldTypeCheckbox:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("ожидается true или false")
+		}
+		return flag, nil
+	case types.FieldTypeTable, types.FieldTypeFile, types.FieldTypePassword:
+		return nil, fmt.Errorf("поле типа %s не заполняется из ссылки", field.Type)
+	}
+	return value, nil
+}
@@ -0,0 +1,85 @@
+package form
+
+import (
+	"time"
+
+	"github.com/koteyye/go-formist/locale"
+	"github.com/koteyye/go-formist/types"
+)
+
+// NormalizeDateTimes приводит значения полей datetime к UTC в формате RFC 3339.
+// Значения без смещения считаются временем в часовом поясе отображения tz
+func NormalizeDateTimes(f *types.Form, data map[string]interface{}, loc locale.Locale, tz *time.Location) error {
+	for i := range f.Fields {
+		field := &f.Fields[i]
+		if field.Type != types.FieldTypeDateTime {
+			continue
+		}
+
+		str, ok := data[field.Name].(string)
+		if !ok || str == "" {
+			continue
+		}
+
+		t, err := loc.ParseDateTime(str, tz)
+		if err != nil {
+			return &FieldError{Field: field.Name, Label: field.Label, Err: err}
+		}
+		data[field.Name] = t.UTC().Format(time.RFC3339)
+	}
+	return nil
+}
+
+// PresentDateTimes возвращает копию данных формы, в которой значения полей datetime
+// (time.Time или строки RFC 3339) переведены в часовой пояс отображения tz.
+// Данные другого типа, чем map[string]interface{}, возвращаются без изменений
+func PresentDateTimes(f *types.Form, data interface{}, tz *time.Location) interface{} {
+	values, ok := data.(map[string]interface{})
+	if !ok {
+		return data
+	}
+
+	var presented map[string]interface{}
+	for i := range f.Fields {
+		field := &f.Fields[i]
+		if field.Type != types.FieldTypeDateTime {
+			continue
+		}
+
+		t, ok := dateTimeValue(values[field.Name])
+		if !ok {
+			continue
+		}
+
+		if presented == nil {
+			presented = make(map[string]interface{}, len(values))
+			for key, value := range values {
+				presented[key] = value
+			}
+		}
+		presented[field.Name] = t.In(tz).Format(time.RFC3339)
+	}
+
+	if presented == nil {
+		return data
+	}
+	return presented
+}
+
+// dateTimeValue извлекает время из значения поля
+func dateTimeValue(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, !v.IsZero()
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/koteyye/go-formist/types"
 )
 
+// emailRegex компилируется один раз при инициализации пакета
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
 // FromStruct создает форму из Go структуры
 func FromStruct(name, title string, structType interface{}) *FormBuilder {
 	fb := NewForm(name, title)
@@ -44,11 +47,24 @@ func FromStruct(name, title string, structType interface{}) *FormBuilder {
 // createFieldFromStructField создает поле формы из поля структуры
 func createFieldFromStructField(field reflect.StructField) types.Field {
 	formField := types.Field{
-		Name:       getFieldName(field),
-		Label:      getFieldLabel(field),
-		Type:       getFieldType(field),
-		Required:   getFieldRequired(field),
-		Validation: make([]types.ValidationRule, 0),
+		Name:         getFieldName(field),
+		Label:        getFieldLabel(field),
+		Type:         getFieldType(field),
+		Required:     getFieldRequired(field),
+		Sensitive:    getFieldSensitive(field),
+		Mask:         field.Tag.Get("mask"),
+		Autocomplete: field.Tag.Get("autocomplete"),
+		Help:         field.Tag.Get("help"),
+		Tooltip:      field.Tag.Get("tooltip"),
+		Validation:   make([]types.ValidationRule, 0),
+	}
+
+	if example := field.Tag.Get("example"); example != "" {
+		formField.Examples = []string{example}
+	}
+
+	if ariaLabel := field.Tag.Get("aria-label"); ariaLabel != "" {
+		formField.Accessibility = &types.Accessibility{AriaLabel: ariaLabel}
 	}
 
 	// Добавляем валидацию для email полей
@@ -99,6 +115,8 @@ func getFieldType(field reflect.StructField) types.FieldType {
 			return types.FieldTypeDate
 		case "time":
 			return types.FieldTypeTime
+		case "datetime":
+			return types.FieldTypeDateTime
 		case "file":
 			return types.FieldTypeFile
 		case "hidden":
@@ -137,6 +155,12 @@ func getFieldRequired(field reflect.StructField) bool {
 	return required == "true" || required == "1"
 }
 
+// getFieldSensitive проверяет, отмечено ли поле как чувствительное
+func getFieldSensitive(field reflect.StructField) bool {
+	sensitive := field.Tag.Get("sensitive")
+	return sensitive == "true" || sensitive == "1"
+}
+
 // NewPage создает новую страницу
 func NewPage(name, title string) *PageBuilder {
 	return &PageBuilder{
@@ -152,12 +176,30 @@ type PageBuilder struct {
 	page *types.Page
 }
 
+// WithIcon задает иконку страницы: имя из набора иконок или URL изображения
+func (pb *PageBuilder) WithIcon(icon string) *PageBuilder {
+	pb.page.Icon = icon
+	return pb
+}
+
+// WithMeta задает метаданные ссылок на страницу
+func (pb *PageBuilder) WithMeta(meta types.Meta) *PageBuilder {
+	pb.page.Meta = &meta
+	return pb
+}
+
 // WithContent устанавливает содержимое страницы
 func (pb *PageBuilder) WithContent(content string) *PageBuilder {
 	pb.page.Content = content
 	return pb
 }
 
+// WithProxy делает страницу прокси к внешнему инструменту (/admin/proxy/{name}/)
+func (pb *PageBuilder) WithProxy(proxy types.PageProxy) *PageBuilder {
+	pb.page.Proxy = &proxy
+	return pb
+}
+
 // Build завершает построение страницы
 func (pb *PageBuilder) Build() *types.Page {
 	return pb.page
@@ -228,7 +270,6 @@ func validateEmail(value interface{}, message string) error {
 		return errors.New("значение должно быть строкой")
 	}
 
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	if !emailRegex.MatchString(str) {
 		if message != "" {
 			return errors.New(message)
@@ -329,8 +370,7 @@ func validateMaxLength(value interface{}, maxLength interface{}, message string)
 
 // validatePattern валидирует по регулярному выражению
 func validatePattern(value interface{}, pattern interface{}, message string) error {
-	str, ok := value.(string)
-	if !ok {
+	if _, ok := value.(string); !ok {
 		return errors.New("значение должно быть строкой")
 	}
 
@@ -344,14 +384,7 @@ func validatePattern(value interface{}, pattern interface{}, message string) err
 		return fmt.Errorf("некорректное регулярное выражение: %v", err)
 	}
 
-	if !regex.MatchString(str) {
-		if message != "" {
-			return errors.New(message)
-		}
-		return errors.New("значение не соответствует требуемому формату")
-	}
-
-	return nil
+	return matchPattern(value, regex, message)
 }
 
 // toFloat64 конвертирует значение в float64
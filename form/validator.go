@@ -0,0 +1,208 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/koteyye/go-formist/schema"
+	"github.com/koteyye/go-formist/types"
+)
+
+// errRequired возвращается для незаполненного обязательного поля
+var errRequired = errors.New("обязательно для заполнения")
+
+// FieldError представляет ошибку валидации конкретного поля
+type FieldError struct {
+	Field string
+	Label string
+	Err   error
+}
+
+// Error возвращает текст ошибки
+func (e *FieldError) Error() string {
+	if e.Err == errRequired {
+		return fmt.Sprintf("поле '%s' обязательно для заполнения", e.Label)
+	}
+	return fmt.Sprintf("поле '%s': %v", e.Label, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// compiledField содержит поле формы с заранее скомпилированными регулярными выражениями
+type compiledField struct {
+	field    *types.Field
+	patterns []*regexp.Regexp // по индексу правила, nil для правил без паттерна
+	mask     *regexp.Regexp   // выражение маски ввода
+}
+
+// Validator валидирует данные формы с заранее подготовленными правилами.
+// Создается один раз при регистрации формы и безопасен для конкурентного использования
+type Validator struct {
+	fields []compiledField
+	index  map[string]int
+}
+
+// NewValidator создает валидатор формы, компилируя паттерны всех полей
+func NewValidator(form *types.Form) (*Validator, error) {
+	v := &Validator{
+		fields: make([]compiledField, len(form.Fields)),
+		index:  make(map[string]int, len(form.Fields)),
+	}
+
+	for i := range form.Fields {
+		field := &form.Fields[i]
+		compiled := compiledField{field: field}
+
+		if field.Mask != "" {
+			pattern, err := schema.MaskPattern(field.Mask)
+			if err != nil {
+				return nil, fmt.Errorf("поле %s: %w", field.Name, err)
+			}
+			compiled.mask = regexp.MustCompile(pattern)
+		}
+
+		for j, rule := range field.Validation {
+			if rule.Type != "pattern" {
+				continue
+			}
+
+			pattern, ok := rule.Value.(string)
+			if !ok {
+				return nil, fmt.Errorf("поле %s: паттерн должен быть строкой", field.Name)
+			}
+
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("поле %s: некорректное регулярное выражение: %w", field.Name, err)
+			}
+
+			if compiled.patterns == nil {
+				compiled.patterns = make([]*regexp.Regexp, len(field.Validation))
+			}
+			compiled.patterns[j] = regex
+		}
+
+		v.fields[i] = compiled
+		v.index[field.Name] = i
+	}
+
+	return v, nil
+}
+
+// Field возвращает поле формы по имени
+func (v *Validator) Field(name string) (*types.Field, bool) {
+	i, ok := v.index[name]
+	if !ok {
+		return nil, false
+	}
+	return v.fields[i].field, true
+}
+
+// Validate валидирует данные формы и возвращает *FieldError для первого некорректного поля
+func (v *Validator) Validate(data map[string]interface{}) error {
+	for i := range v.fields {
+		value, exists := data[v.fields[i].field.Name]
+		if err := v.validateCompiled(&v.fields[i], value, exists); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateValue валидирует значение отдельного поля по имени
+func (v *Validator) ValidateValue(name string, value interface{}) error {
+	i, ok := v.index[name]
+	if !ok {
+		return fmt.Errorf("поле %s не найдено", name)
+	}
+	return v.validateCompiled(&v.fields[i], value, true)
+}
+
+// validateCompiled валидирует значение поля, не выделяя память при успешной проверке
+func (v *Validator) validateCompiled(cf *compiledField, value interface{}, exists bool) error {
+	field := cf.field
+
+	// Проверяем обязательные поля
+	if !exists || isEmpty(value) {
+		if field.Required {
+			return &FieldError{Field: field.Name, Label: field.Label, Err: errRequired}
+		}
+		return nil
+	}
+
+	// Проверяем соответствие маске ввода
+	if cf.mask != nil {
+		if str, ok := value.(string); !ok || !cf.mask.MatchString(str) {
+			err := fmt.Errorf("значение не соответствует формату %s", field.Mask)
+			return &FieldError{Field: field.Name, Label: field.Label, Err: err}
+		}
+	}
+
+	// Применяем правила валидации, кроме предупреждающих
+	for j, rule := range field.Validation {
+		if rule.IsWarning() {
+			continue
+		}
+		if err := cf.checkRule(j, value); err != nil {
+			return &FieldError{Field: field.Name, Label: field.Label, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Warnings возвращает сработавшие предупреждающие правила для заполненных полей
+func (v *Validator) Warnings(data map[string]interface{}) []types.ValidationWarning {
+	var warnings []types.ValidationWarning
+	for i := range v.fields {
+		cf := &v.fields[i]
+		value, exists := data[cf.field.Name]
+		if !exists || isEmpty(value) {
+			continue
+		}
+
+		for j, rule := range cf.field.Validation {
+			if !rule.IsWarning() {
+				continue
+			}
+			if err := cf.checkRule(j, value); err != nil {
+				warnings = append(warnings, types.ValidationWarning{
+					Field:   cf.field.Name,
+					Label:   cf.field.Label,
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// checkRule проверяет значение правилом с индексом j
+func (cf *compiledField) checkRule(j int, value interface{}) error {
+	rule := cf.field.Validation[j]
+	if rule.Type == "pattern" && cf.patterns != nil && cf.patterns[j] != nil {
+		return matchPattern(value, cf.patterns[j], rule.Message)
+	}
+	return validateRule(value, rule)
+}
+
+// matchPattern проверяет значение скомпилированным регулярным выражением
+func matchPattern(value interface{}, regex *regexp.Regexp, message string) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("значение должно быть строкой")
+	}
+
+	if !regex.MatchString(str) {
+		if message != "" {
+			return errors.New(message)
+		}
+		return errors.New("значение не соответствует требуемому формату")
+	}
+
+	return nil
+}
@@ -3,32 +3,170 @@ package formist
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/koteyye/go-formist/accesslog"
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/backup"
+	"github.com/koteyye/go-formist/bundle"
+	"github.com/koteyye/go-formist/chaos"
+	"github.com/koteyye/go-formist/codec"
+	"github.com/koteyye/go-formist/comments"
+	"github.com/koteyye/go-formist/demo"
+	"github.com/koteyye/go-formist/dictionary"
+	"github.com/koteyye/go-formist/encryption"
+	"github.com/koteyye/go-formist/federation"
 	"github.com/koteyye/go-formist/form"
+	"github.com/koteyye/go-formist/icons"
+	"github.com/koteyye/go-formist/id"
+	"github.com/koteyye/go-formist/leader"
+	"github.com/koteyye/go-formist/privacy"
+	"github.com/koteyye/go-formist/provenance"
+	"github.com/koteyye/go-formist/registry"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/retention"
 	"github.com/koteyye/go-formist/router"
+	"github.com/koteyye/go-formist/serialize"
+	"github.com/koteyye/go-formist/snapshot"
 	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/tokens"
 	"github.com/koteyye/go-formist/types"
+	"github.com/koteyye/go-formist/workflow"
 )
 
 // Admin представляет основной объект админ-панели с поддержкой storage
 type Admin struct {
-	router  *router.Router
-	storage storage.Storage
+	router      *router.Router
+	storage     storage.Storage
+	retryPolicy storage.RetryPolicy
+	deadLetters *storage.DeadLetterQueue
+	ids         id.Generator
+	envelope    *encryption.Envelope
+
+	// consistencyWait ожидание видимости изменения по токену согласованности
+	consistencyWait time.Duration
+
+	pregenerateSchemas bool
+	schemaDistDir      string
+
+	fileFormsMu sync.Mutex
+	fileForms   map[string]string // путь к файлу -> имя формы
+
+	syncMu     sync.Mutex
+	syncETag   string
+	syncForms  map[string]bool
+	syncSource registry.Source
+
+	elector atomic.Pointer[leader.Elector]
+
+	startup startupState
 }
 
 // New создает новую админ-панель
 func New() *Admin {
 	return &Admin{
-		router: router.NewRouter(),
+		router:      router.NewRouter(),
+		retryPolicy: storage.DefaultRetryPolicy(),
+		deadLetters: storage.NewDeadLetterQueue(0),
+		fileForms:   make(map[string]string),
+		ids:         id.Default(),
+	}
+}
+
+// WithStorage подключает storage для сохранения роутов.
+// Опции, например storage.WithInstrumentation(...), оборачивают storage по порядку
+func (a *Admin) WithStorage(s storage.Storage, opts ...storage.Option) *Admin {
+	a.storage = storage.Apply(s, opts...)
+	return a
+}
+
+// StorageMetrics возвращает метрики операций storage, если он инструментирован
+func (a *Admin) StorageMetrics() map[string]storage.OperationStats {
+	if provider, ok := a.storage.(storage.MetricsProvider); ok {
+		return provider.Metrics()
+	}
+	return nil
+}
+
+// SLOReports возвращает сводки ошибок и задержек форм за последние 5 минут, час и с запуска
+func (a *Admin) SLOReports() []types.FormSLOReport {
+	return a.router.SLOReports()
+}
+
+// SLOAlertRules возвращает пример правил оповещений Prometheus (YAML) для форм с SLO
+func (a *Admin) SLOAlertRules() ([]byte, error) {
+	return a.router.SLOAlertRules()
+}
+
+// WithIDGenerator устанавливает генератор идентификаторов роутов, заявок и комментариев
+// (по умолчанию UUIDv7, доступны id.ULID() и id.Snowflake(node))
+func (a *Admin) WithIDGenerator(g id.Generator) *Admin {
+	a.ids = g
+	a.router.Workflow().SetIDGenerator(g)
+	a.router.Comments().SetIDGenerator(g)
+	a.router.Tokens().SetIDGenerator(g)
+	return a
+}
+
+// WithRetryPolicy устанавливает политику повторов для записи в storage
+func (a *Admin) WithRetryPolicy(policy storage.RetryPolicy) *Admin {
+	a.retryPolicy = policy
+	return a
+}
+
+// WithCodec заменяет encoding/json в ответах и телах запросов админки совместимым
+// кодеком, например sonic или go-json, через адаптер к интерфейсу codec.Codec
+func (a *Admin) WithCodec(c codec.Codec) *Admin {
+	a.router.SetCodec(c)
+	return a
+}
+
+// WithConsistencyWait задает, сколько список роутов с ?consistency= ждет видимости изменения
+// в реплицированном или кэширующем storage (по умолчанию storage.DefaultConsistencyWait)
+func (a *Admin) WithConsistencyWait(wait time.Duration) *Admin {
+	a.consistencyWait = wait
+	return a
+}
+
+// WithSchemaPregeneration включает генерацию и проверку схем всех форм при вызове Handler().
+// Если distDir не пустой, схемы дополнительно записываются в эту директорию
+func (a *Admin) WithSchemaPregeneration(distDir string) *Admin {
+	a.pregenerateSchemas = true
+	a.schemaDistDir = distDir
+	return a
+}
+
+// PregenerateSchemas проверяет формы и генерирует их схемы заранее.
+// Если задана директория dist, схемы записываются в нее
+func (a *Admin) PregenerateSchemas() error {
+	if err := a.router.PregenerateSchemas(); err != nil {
+		return err
 	}
+
+	if a.schemaDistDir != "" {
+		return a.router.WriteSchemas(a.schemaDistDir)
+	}
+
+	return nil
+}
+
+// DeadLetters возвращает очередь записей, которые не удалось сохранить в storage
+func (a *Admin) DeadLetters() *storage.DeadLetterQueue {
+	return a.deadLetters
 }
 
-// WithStorage подключает storage для сохранения роутов
-func (a *Admin) WithStorage(s storage.Storage) *Admin {
-	a.storage = s
+// WithPrefix монтирует маршруты админки под префиксом (например, /billing/admin/...).
+// Позволяет запускать несколько независимых админок в одном процессе
+func (a *Admin) WithPrefix(prefix string) *Admin {
+	a.router.SetPrefix(prefix)
 	return a
 }
 
@@ -50,56 +188,701 @@ func (a *Admin) EnableCORS(enabled bool, origins ...string) *Admin {
 	return a
 }
 
+// SetEnvironment задает окружение (например, staging или production) для баннеров UI
+func (a *Admin) SetEnvironment(name string, options types.EnvironmentOptions) *Admin {
+	a.router.SetEnvironment(name, options)
+	return a
+}
+
+// SetBranding задает логотип и цвета интерфейса. Контраст цветов по WCAG и текст логотипа
+// проверяются самодиагностикой (проверка accessibility)
+func (a *Admin) SetBranding(branding types.Branding) *Admin {
+	a.router.SetBranding(branding)
+	return a
+}
+
+// SetReadOnly включает режим только для чтения: изменяющие запросы получают 423 Locked.
+// Переключается во время работы, например на время инцидента
+func (a *Admin) SetReadOnly(enabled bool, message string) *Admin {
+	a.router.SetReadOnly(enabled, message)
+	return a
+}
+
+// EnableDemoMode включает демонстрационный режим: OnGet форм и таблицы возвращают
+// сгенерированные по типам полей данные, чтобы разрабатывать фронтенд без реальных сервисов
+func (a *Admin) EnableDemoMode() *Admin {
+	return a.WithDemo(demo.New(0))
+}
+
+// WithDemo включает демонстрационный режим с заданным генератором, например с фиксированным seed
+func (a *Admin) WithDemo(generator *demo.Generator) *Admin {
+	a.router.SetDemo(generator)
+	return a
+}
+
+// maintenanceSettingKey ключ настройки режима обслуживания в storage
+const maintenanceSettingKey = "maintenance"
+
+// SetMaintenance включает или выключает режим обслуживания.
+// Если storage поддерживает storage.SettingsStorage, состояние сохраняется в нем
+func (a *Admin) SetMaintenance(ctx context.Context, maintenance types.Maintenance) error {
+	maintenance.UpdatedAt = time.Now()
+	if err := a.persistMaintenance(ctx, &maintenance); err != nil {
+		return err
+	}
+
+	a.router.SetMaintenance(maintenance)
+	a.router.Audit().Record(ctx, audit.ActionMaintenanceChange, "", map[string]interface{}{
+		"enabled": maintenance.Enabled,
+		"message": maintenance.Message,
+	})
+	return nil
+}
+
+// LoadMaintenance загружает сохраненное в storage состояние режима обслуживания
+func (a *Admin) LoadMaintenance(ctx context.Context) error {
+	settings, ok := a.storage.(storage.SettingsStorage)
+	if !ok {
+		return nil
+	}
+
+	data, err := settings.GetSetting(ctx, maintenanceSettingKey)
+	if errors.Is(err, storage.ErrSettingNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var maintenance types.Maintenance
+	if err := json.Unmarshal(data, &maintenance); err != nil {
+		return fmt.Errorf("некорректное состояние режима обслуживания: %w", err)
+	}
+
+	a.router.SetMaintenance(maintenance)
+	return nil
+}
+
+// persistMaintenance сохраняет состояние режима обслуживания в storage
+func (a *Admin) persistMaintenance(ctx context.Context, maintenance *types.Maintenance) error {
+	settings, ok := a.storage.(storage.SettingsStorage)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(maintenance)
+	if err != nil {
+		return err
+	}
+
+	_, err = storage.Retry(ctx, a.retryPolicy, func(ctx context.Context) error {
+		return settings.SaveSetting(ctx, maintenanceSettingKey, data)
+	})
+	return err
+}
+
+// WithSchemaCacheControl задает Cache-Control для GET /admin/forms/{name}/schema,
+// например "public, max-age=3600" для раздачи схем через CDN
+func (a *Admin) WithSchemaCacheControl(value string) *Admin {
+	a.router.SetSchemaCacheControl(value)
+	return a
+}
+
+// SetLocale устанавливает локаль ввода чисел и дат по умолчанию (например, ru),
+// если она не указана в запросе, форме или Accept-Language
+func (a *Admin) SetLocale(tag string) *Admin {
+	a.router.SetLocale(tag)
+	return a
+}
+
+// SetTimezone устанавливает часовой пояс отображения значений datetime (по умолчанию UTC).
+// Часовой пояс пользователя (auth.User.Timezone) имеет приоритет
+func (a *Admin) SetTimezone(tz *time.Location) *Admin {
+	a.router.SetTimezone(tz)
+	return a
+}
+
+// SetTemplateVars задает дополнительные переменные для подстановки в тексты форм
+// (например, {{tenant}}). Встроенные переменные: {{user.id}}, {{user.name}}, {{today}}
+func (a *Admin) SetTemplateVars(fn func(req *http.Request) map[string]string) *Admin {
+	a.router.SetTemplateVars(fn)
+	return a
+}
+
+// EnableCompression включает gzip/deflate сжатие ответов размером от minSize байт
+func (a *Admin) EnableCompression(enabled bool, minSize int) *Admin {
+	a.router.EnableCompression(enabled, minSize)
+	return a
+}
+
+// WithSerializer задает представление в ответах значений типа sample, которые возвращают
+// OnGet и обработчики таблиц, например serialize.TimeUnix для time.Time{}
+func (a *Admin) WithSerializer(sample interface{}, fn serialize.Func) *Admin {
+	a.router.Serializers().Register(sample, fn)
+	return a
+}
+
+// Serializers возвращает реестр представлений значений в ответах
+func (a *Admin) Serializers() *serialize.Registry {
+	return a.router.Serializers()
+}
+
+// EnableLongPolling сообщает UI через capabilities в /admin/config, что события нужно получать
+// long polling вместо Server-Sent Events: для сетей, где прокси обрывают потоковые ответы
+func (a *Admin) EnableLongPolling() *Admin {
+	a.router.SetEventTransport(router.EventTransportLongPoll)
+	return a
+}
+
+// EnableProfiling монтирует /admin/debug/pprof (CPU профиль, трассировка, heap, goroutine...)
+// для клиентов из сетей networks (CIDR или IP, по умолчанию только loopback).
+// При включенной авторизации или API ключах требуется разрешение profiling:read.
+// Паникует при некорректном адресе
+func (a *Admin) EnableProfiling(networks ...string) *Admin {
+	if len(networks) == 0 {
+		networks = []string{"127.0.0.0/8", "::1/128"}
+	}
+
+	prefixes := make([]netip.Prefix, 0, len(networks))
+	for _, network := range networks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(network)
+			if addrErr != nil {
+				panic(fmt.Sprintf("formist: профилирование: некорректная сеть %q", network))
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	a.router.SetProfiling(prefixes)
+	return a
+}
+
+// WithLoadShedding ограничивает одновременные запросы к админке и длину очереди,
+// общие и по префиксам путей. Запросы сверх лимита получают 503 с Retry-After.
+// Паникует при некорректных лимитах
+func (a *Admin) WithLoadShedding(config types.LoadShedding) *Admin {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	a.router.SetLoadShedding(&config)
+	return a
+}
+
+// LoadSheddingStats возвращает состояние ограничения нагрузки (nil, если не включено)
+func (a *Admin) LoadSheddingStats() *types.LoadSheddingStats {
+	return a.router.LoadSheddingStats()
+}
+
+// WithQuotas включает суточные квоты отправок и загрузок и лимит строк таблицы в запросе.
+// subject определяет пользователя или арендатора, nil - ID пользователя или IP клиента.
+// Паникует при некорректных лимитах
+func (a *Admin) WithQuotas(quotas types.Quotas, subject router.QuotaSubject) *Admin {
+	if err := quotas.Validate(); err != nil {
+		panic(err)
+	}
+	a.router.SetQuotas(&quotas, subject)
+	return a
+}
+
+// QuotaStats возвращает использование квот за текущие сутки (nil, если квоты не включены)
+func (a *Admin) QuotaStats() *types.QuotaStats {
+	return a.router.QuotaStats()
+}
+
+// SchemaVersion возвращает версию схемы зарегистрированной формы (заголовок X-Schema-Version)
+func (a *Admin) SchemaVersion(key string) (string, bool) {
+	return a.router.SchemaVersion(key)
+}
+
+// WithAccessLog включает журнал доступа в формате JSON Lines (например, для отправки в ELK):
+// метод, путь, статус, задержка, пользователь, форма и имена отправленных полей.
+// Значения чувствительных полей никогда не записываются
+func (a *Admin) WithAccessLog(w io.Writer, options accesslog.Options) *Admin {
+	a.router.SetAccessLog(accesslog.New(w, options))
+	return a
+}
+
+// WithDebugLog устанавливает журнал отладочного режима форм (по умолчанию stderr).
+// Отладочный режим включается через PUT /api/debug/forms/{form} с разрешением debug:write
+func (a *Admin) WithDebugLog(w io.Writer, options accesslog.Options) *Admin {
+	a.router.SetDebugLog(accesslog.NewDebug(w, options))
+	return a
+}
+
+// EnableSnapshots сохраняет обезличенные снимки отправок форм, завершившихся статусом >= 400.
+// Ответ содержит X-Request-Id; снимок по этому ID доступен через /api/snapshots
+// с разрешением snapshots:read в течение options.TTL
+func (a *Admin) EnableSnapshots(options snapshot.Options) *Admin {
+	a.router.SetSnapshots(snapshot.New(options))
+	return a
+}
+
+// EnableFormDebug включает запись тел запросов и ответов формы на ttl
+func (a *Admin) EnableFormDebug(name string, ttl time.Duration) types.FormDebug {
+	return a.router.EnableFormDebug(name, ttl, "")
+}
+
+// WithChaos внедряет задержки и ошибки по правилам, чтобы проверить поведение UI
+// при медленном или отказывающем бэкенде. В окружении production (см. SetEnvironment)
+// правила не применяются. Паникует при некорректных правилах
+func (a *Admin) WithChaos(rules ...chaos.Rule) *Admin {
+	injector, err := chaos.New(rules...)
+	if err != nil {
+		panic(err)
+	}
+	a.router.SetChaos(injector)
+	return a
+}
+
+// WithRemotes подключает удаленные админки formist: их меню объединяется с локальным
+// в /admin/config, а роуты доступны через /api/federation. Каталоги загружаются
+// StartFederation. Паникует при некорректном описании удаленной админки
+func (a *Admin) WithRemotes(remotes ...federation.Remote) *Admin {
+	f, err := federation.New(nil, remotes...)
+	if err != nil {
+		panic(err)
+	}
+	a.router.SetFederation(f)
+	return a
+}
+
+// StartFederation загружает каталоги удаленных админок сразу и затем каждые interval
+// до отмены ctx. Выполняется на каждой реплике, так как каталоги хранятся в памяти
+func (a *Admin) StartFederation(ctx context.Context, interval time.Duration, onError func(error)) {
+	if f := a.router.Federation(); f != nil {
+		f.Start(ctx, interval, onError)
+	}
+}
+
+// OnError устанавливает получателя ошибок обработчиков, panic, некорректной конфигурации
+// валидации и сбоев storage. Для Sentry используйте reporting.Sentry
+func (a *Admin) OnError(handler reporting.Handler) *Admin {
+	a.router.SetErrorHandler(handler)
+	return a
+}
+
+// WithAPIKey добавляет API ключ для автоматизации (например, CI/CD) с указанными разрешениями.
+// Ключ передается в заголовке X-API-Key или Authorization: Bearer
+func (a *Admin) WithAPIKey(name, key string, permissions ...string) *Admin {
+	a.router.AddAPIKey(name, key, permissions...)
+	return a
+}
+
 // AddMiddleware добавляет middleware
 func (a *Admin) AddMiddleware(middleware types.MiddlewareFunc) *Admin {
 	a.router.AddMiddleware(middleware)
 	return a
 }
 
-// RegisterForm регистрирует форму и сохраняет роут в storage
+// RegisterModule регистрирует модуль форм с собственными middleware и ролями доступа.
+// Паникует, если иконка модуля не входит в набор иконок
+func (a *Admin) RegisterModule(module *types.Module) *Admin {
+	if err := a.router.ValidateIcon(module.Icon); err != nil {
+		panic(fmt.Sprintf("formist: модуль %s: %v", module.Name, err))
+	}
+
+	a.router.RegisterModule(module)
+	return a
+}
+
+// WithIcons ограничивает иконки форм, страниц, модулей и роутов набором registry.
+// URL изображений допускаются всегда
+func (a *Admin) WithIcons(registry *icons.Registry) *Admin {
+	a.router.SetIcons(registry)
+	return a
+}
+
+// WithApprovals настраивает хранилище заявок на согласование и получателя событий
+// (уведомления, аудит). По умолчанию заявки хранятся в памяти
+func (a *Admin) WithApprovals(store workflow.Store, notifier workflow.Notifier) *Admin {
+	engine := workflow.NewEngine(store, notifier)
+	engine.SetIDGenerator(a.ids)
+	a.router.SetWorkflow(engine)
+	return a
+}
+
+// WithComments настраивает хранилище комментариев к записям и получателя упоминаний.
+// По умолчанию комментарии хранятся в памяти, а упоминания никуда не отправляются
+func (a *Admin) WithComments(store comments.Store, notifier comments.Notifier) *Admin {
+	service := comments.New(store, notifier)
+	service.SetIDGenerator(a.ids)
+	a.router.SetComments(service)
+	return a
+}
+
+// WithProvenance настраивает хранилище изменений полей записей для форм с TrackProvenance.
+// По умолчанию изменения хранятся в памяти
+func (a *Admin) WithProvenance(store provenance.Store) *Admin {
+	a.router.SetProvenance(provenance.New(store))
+	return a
+}
+
+// WithDictionaries настраивает хранилище записей справочников. По умолчанию записи
+// хранятся в памяти; dictionary.NewSettingsStore сохраняет их в настройках storage
+func (a *Admin) WithDictionaries(store dictionary.Store) *Admin {
+	a.router.Dictionaries().SetStore(store)
+	return a
+}
+
+// DefineDictionary определяет справочник и регистрирует форму его редактирования
+// dictionary-<имя>. Некорректное определение вызывает панику
+func (a *Admin) DefineDictionary(d types.Dictionary) *Admin {
+	registry := a.router.Dictionaries()
+	if err := registry.Define(d); err != nil {
+		panic(fmt.Sprintf("formist: %v", err))
+	}
+
+	form, err := registry.Form(d.Name)
+	if err != nil {
+		panic(fmt.Sprintf("formist: %v", err))
+	}
+	return a.RegisterForm(form)
+}
+
+// Dictionaries возвращает реестр справочников
+func (a *Admin) Dictionaries() *dictionary.Registry {
+	return a.router.Dictionaries()
+}
+
+// WithTokens настраивает хранилище персональных токенов доступа.
+// По умолчанию токены хранятся в памяти и теряются при перезапуске
+func (a *Admin) WithTokens(store tokens.Store) *Admin {
+	service := tokens.New(store)
+	service.SetIDGenerator(a.ids)
+	a.router.SetTokens(service)
+	return a
+}
+
+// WithAudit подключает журнал аудита действий в админке
+func (a *Admin) WithAudit(log *audit.Log) *Admin {
+	a.router.SetAudit(log)
+	return a
+}
+
+// WithRetention подключает политики хранения данных.
+// Очистку можно запустить через API или периодически с помощью StartRetention
+func (a *Admin) WithRetention(policies ...retention.Policy) *Admin {
+	a.router.SetRetention(retention.NewPurger(policies...))
+	return a
+}
+
+// StartRetention запускает периодическую очистку устаревших данных до отмены ctx.
+// При включенных выборах лидера общие хранилища (Policy.Shared) очищает только лидер
+func (a *Admin) StartRetention(ctx context.Context, interval time.Duration, onReport func([]retention.Report)) {
+	if purger := a.router.Retention(); purger != nil {
+		purger.SetLeader(a.IsLeader)
+		purger.Start(ctx, interval, onReport)
+	}
+}
+
+// WithBackup включает выгрузку и восстановление резервных копий через /api/backup
+// (разрешение backup:manage). В копию попадают роуты и настройки storage,
+// заявки на согласование и журнал аудита
+func (a *Admin) WithBackup() *Admin {
+	a.router.SetBackup(a.backupSources)
+	return a
+}
+
+// WithConfigImport включает выгрузку и импорт пакетов конфигурации через /api/config
+// (разрешение routes:write): импорт сначала возвращает план изменений роутов и настроек,
+// а применяется отдельным запросом. С approvalRoles применение проходит согласование
+func (a *Admin) WithConfigImport(approvalRoles ...string) *Admin {
+	a.router.SetConfigImport(func() bundle.Sources {
+		return bundle.Sources{
+			Storage:  a.storage,
+			Settings: []string{maintenanceSettingKey},
+		}
+	}, approvalRoles...)
+	return a
+}
+
+// StartBackups запускает периодическое резервное копирование в каталог schedule.Dir
+// до отмены ctx. При включенных выборах лидера копии создает только лидер.
+// onResult, если задан, получает путь к копии или ошибку каждого запуска
+func (a *Admin) StartBackups(ctx context.Context, schedule backup.Schedule, onResult func(path string, err error)) {
+	a.RunScheduled(ctx, schedule.Interval, func(ctx context.Context) {
+		path, _, err := backup.WriteFile(ctx, schedule.Dir, a.backupSources())
+		if err == nil && schedule.Keep > 0 {
+			_, err = backup.Prune(schedule.Dir, schedule.Keep)
+		}
+		if onResult != nil {
+			onResult(path, err)
+		}
+	})
+}
+
+// backupSources возвращает хранилища, которые попадают в резервную копию
+func (a *Admin) backupSources() backup.Sources {
+	sources := backup.Sources{
+		Storage:  a.storage,
+		Settings: []string{maintenanceSettingKey},
+		Audit:    a.router.Audit(),
+	}
+	if engine := a.router.Workflow(); engine != nil {
+		sources.Submissions = engine.Store()
+	}
+	return sources
+}
+
+// leaderLockName имя блокировки выборов лидера в storage
+const leaderLockName = "formist:leader"
+
+// StartLeaderElection включает выборы лидера среди реплик через блокировки storage
+// (advisory locks в PostgreSQL). Периодическая очистка (StartRetention) и задачи,
+// запущенные через RunScheduled, выполняются только на лидере. Работает до отмены ctx
+func (a *Admin) StartLeaderElection(ctx context.Context, interval time.Duration, onError func(error)) error {
+	locker, ok := storage.LockerOf(a.storage)
+	if !ok {
+		return storage.ErrLockingNotSupported
+	}
+
+	elector := leader.NewElector(locker, leaderLockName)
+	a.elector.Store(elector)
+	elector.Run(ctx, interval, onError)
+	return nil
+}
+
+// IsLeader сообщает, является ли реплика лидером. Без выборов лидера
+// каждая реплика считается лидером
+func (a *Admin) IsLeader() bool {
+	elector := a.elector.Load()
+	return elector == nil || elector.IsLeader()
+}
+
+// RunScheduled выполняет task каждые interval до отмены ctx.
+// При включенных выборах лидера задача выполняется только на лидере
+func (a *Admin) RunScheduled(ctx context.Context, interval time.Duration, task func(ctx context.Context)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if a.IsLeader() {
+					task(ctx)
+				}
+			}
+		}
+	}()
+}
+
+// AuditRetention возвращает политику хранения журнала аудита (подключите журнал до вызова)
+func (a *Admin) AuditRetention(days int) retention.Policy {
+	policy := retention.Policy{Name: "audit", Days: days}
+	if log := a.router.Audit(); log != nil {
+		policy.Store = log
+	}
+	return policy
+}
+
+// StartAuditSink запускает доставку новых записей журнала аудита в sink (syslog, HTTP) до отмены ctx.
+// Паникует, если журнал аудита не подключен
+func (a *Admin) StartAuditSink(ctx context.Context, sink audit.Sink, options audit.ForwardOptions) *audit.Forwarder {
+	log := a.router.Audit()
+	if log == nil {
+		panic("formist: StartAuditSink требует журнал аудита (WithAudit)")
+	}
+
+	forwarder := audit.NewForwarder(sink, options)
+	log.AddForwarder(forwarder)
+	forwarder.Start(ctx)
+	return forwarder
+}
+
+// SubmissionsRetention возвращает политику хранения заявок на согласование,
+// если их хранилище поддерживает очистку
+func (a *Admin) SubmissionsRetention(days int) retention.Policy {
+	policy := retention.Policy{Name: "submissions", Days: days}
+	if store, ok := a.router.Workflow().Store().(retention.Purgeable); ok {
+		_, local := store.(*workflow.MemoryStore)
+		policy.Store, policy.Shared = store, !local
+	}
+	return policy
+}
+
+// AddPrivacySource подключает собственное хранилище с данными субъектов
+// к выгрузке и обезличиванию (журнал аудита и заявки подключены автоматически)
+func (a *Admin) AddPrivacySource(name string, source privacy.Source) *Admin {
+	a.router.AddPrivacySource(name, source)
+	return a
+}
+
+// ExportSubject выгружает все данные субъекта из подключенных хранилищ
+func (a *Admin) ExportSubject(ctx context.Context, subject string) (map[string][]interface{}, privacy.Report) {
+	return privacy.Export(ctx, subject, a.router.PrivacySources())
+}
+
+// EraseSubject обезличивает данные субъекта во всех подключенных хранилищах
+func (a *Admin) EraseSubject(ctx context.Context, subject string) privacy.Report {
+	return privacy.Erase(ctx, subject, a.router.PrivacySources())
+}
+
+// Approvals возвращает движок согласования отправок форм
+func (a *Admin) Approvals() *workflow.Engine {
+	return a.router.Workflow()
+}
+
+// Comments возвращает сервис комментариев к записям и заявкам
+func (a *Admin) Comments() *comments.Service {
+	return a.router.Comments()
+}
+
+// Provenance возвращает учет изменений полей записей
+func (a *Admin) Provenance() *provenance.Tracker {
+	return a.router.Provenance()
+}
+
+// Tokens возвращает сервис персональных токенов доступа
+func (a *Admin) Tokens() *tokens.Service {
+	return a.router.Tokens()
+}
+
+// RegisterForm регистрирует форму и сохраняет роут в storage.
+// Паникует, если правила валидации формы некорректны (например, невалидный паттерн)
+// или иконка не входит в набор иконок
 func (a *Admin) RegisterForm(form *types.Form) *Admin {
-	a.router.RegisterForm(form)
+	if err := a.router.RegisterForm(form); err != nil {
+		panic(fmt.Sprintf("formist: форма %s: %v", form.Key(), err))
+	}
 
 	// Сохраняем роут в storage если он подключен
 	if a.storage != nil {
 		route := &storage.Route{
-			Name:  form.Name,
-			Path:  fmt.Sprintf("/admin/forms/%s", form.Name),
+			Name:  form.Key(),
+			Path:  a.router.FormPath(form),
 			Title: form.Title,
-			Type:  "form",
+			Icon:  form.Icon,
+			Type:  storage.RouteTypeForm,
 		}
 
 		if form.Description != "" {
 			route.Description = form.Description
 		}
 
-		// Игнорируем ошибку, чтобы не ломать работу если storage недоступен
-		_ = a.storage.SaveRoute(context.Background(), route)
+		// Ошибка не прерывает регистрацию: неудачная запись попадает в dead-letter очередь
+		_ = a.saveRoute(context.Background(), route)
 	}
 
 	return a
 }
 
-// RegisterPage регистрирует страницу и сохраняет роут в storage
+// RegisterPage регистрирует страницу и сохраняет роут в storage.
+// Паникует, если иконка страницы не входит в набор иконок
 func (a *Admin) RegisterPage(page *types.Page) *Admin {
+	if err := a.router.ValidateIcon(page.Icon); err != nil {
+		panic(fmt.Sprintf("formist: страница %s: %v", page.Name, err))
+	}
+	if err := router.ValidatePageProxy(page); err != nil {
+		panic(fmt.Sprintf("formist: страница %s: %v", page.Name, err))
+	}
+
 	a.router.RegisterPage(page)
 
 	// Сохраняем роут в storage если он подключен
 	if a.storage != nil {
 		route := &storage.Route{
 			Name:  page.Name,
-			Path:  fmt.Sprintf("/admin/pages/%s", page.Name),
+			Path:  fmt.Sprintf("%s/admin/pages/%s", a.router.Prefix(), page.Name),
 			Title: page.Title,
-			Type:  "page",
+			Icon:  page.Icon,
+			Type:  storage.RouteTypePage,
 		}
 
-		// Игнорируем ошибку, чтобы не ломать работу если storage недоступен
-		_ = a.storage.SaveRoute(context.Background(), route)
+		// Ошибка не прерывает регистрацию: неудачная запись попадает в dead-letter очередь
+		_ = a.saveRoute(context.Background(), route)
 	}
 
 	return a
 }
 
+// saveRoute сохраняет роут формы или страницы с повторами согласно политике.
+// Роут с тем же именем, сохраненный при прошлом запуске, обновляется под своим ID.
+// Если все попытки неудачны, запись помещается в dead-letter очередь
+func (a *Admin) saveRoute(ctx context.Context, route *storage.Route) error {
+	attempts, err := storage.Retry(ctx, a.retryPolicy, func(ctx context.Context) error {
+		if err := a.reuseRouteID(ctx, route); err != nil {
+			return err
+		}
+		return a.storage.SaveRoute(ctx, route)
+	})
+	if err != nil {
+		a.deadLetters.Push(storage.FailedWrite{
+			Op:       "saveRoute",
+			Route:    route,
+			Error:    err.Error(),
+			Attempts: attempts,
+			FailedAt: time.Now(),
+		})
+		a.reportStorageError(ctx, err, "saveRoute", map[string]interface{}{
+			"route":    route.Name,
+			"attempts": attempts,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// reportStorageError передает ошибку storage получателю ошибок
+func (a *Admin) reportStorageError(ctx context.Context, err error, op string, extra map[string]interface{}) {
+	a.router.ReportError(ctx, err, reporting.Meta{
+		Kind:  reporting.KindStorage,
+		Op:    op,
+		Extra: extra,
+	})
+}
+
+// reuseRouteID берет ID и время создания сохраненного роута с тем же именем,
+// иначе задает ID генератором админки
+func (a *Admin) reuseRouteID(ctx context.Context, route *storage.Route) error {
+	routes, err := a.storage.GetRoutes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, existing := range routes {
+		if existing.Name == route.Name {
+			route.ID = existing.ID
+			route.CreatedAt = existing.CreatedAt
+			return nil
+		}
+	}
+	a.assignRouteID(route)
+	return nil
+}
+
+// assignRouteID задает ID роута генератором админки, если он не указан
+func (a *Admin) assignRouteID(route *storage.Route) {
+	if route.ID == "" {
+		route.ID = a.ids.NewID()
+	}
+}
+
+// ReplayDeadLetters повторяет запись всех элементов dead-letter очереди.
+// Возвращает количество успешно сохраненных записей
+func (a *Admin) ReplayDeadLetters(ctx context.Context) (int, error) {
+	if a.storage == nil {
+		return 0, fmt.Errorf("storage не подключен")
+	}
+
+	replayed := 0
+	for _, item := range a.deadLetters.Drain() {
+		if item.Route == nil {
+			continue
+		}
+		if err := a.saveRoute(ctx, item.Route); err == nil {
+			replayed++
+		}
+	}
+
+	return replayed, nil
+}
+
 // GetRoutes возвращает все роуты из storage
 func (a *Admin) GetRoutes(ctx context.Context) ([]*storage.Route, error) {
 	if a.storage == nil {
@@ -118,8 +901,25 @@ func (a *Admin) DeleteRoute(ctx context.Context, id string) error {
 	return a.storage.DeleteRoute(ctx, id)
 }
 
-// Handler возвращает HTTP handler для использования с любым HTTP сервером
+// Handler возвращает HTTP handler для использования с любым HTTP сервером.
+// Перед возвратом выполняет хуки OnStartup.
+// При включенной пре-генерации схем паникует на некорректном определении формы
 func (a *Admin) Handler() http.Handler {
+	if a.pregenerateSchemas {
+		if err := a.PregenerateSchemas(); err != nil {
+			panic(fmt.Sprintf("formist: %v", err))
+		}
+	}
+
+	// Хуки запуска: ошибки попадают в отчет самодиагностики
+	a.Startup(context.Background())
+
+	// Восстанавливаем режим обслуживания; при ошибке storage он остается выключенным
+	a.LoadMaintenance(context.Background())
+	a.router.SetMaintenancePersister(a.persistMaintenance)
+
+	a.router.SetDiagnostics(a.Diagnose)
+
 	// Добавляем эндпоинты для работы с роутами через storage
 	if a.storage != nil {
 		// Создаем map с обработчиками
@@ -129,6 +929,11 @@ func (a *Admin) Handler() http.Handler {
 			"createRoute": a.handleCreateRoute,
 			"updateRoute": a.handleUpdateRoute,
 			"deleteRoute": a.handleDeleteRoute,
+
+			"getDeadLetters":    a.handleGetDeadLetters,
+			"replayDeadLetters": a.handleReplayDeadLetters,
+			"drainDeadLetters":  a.handleDrainDeadLetters,
+			"health":            a.handleHealth,
 		}
 
 		// Устанавливаем обработчики в роутер
@@ -140,16 +945,80 @@ func (a *Admin) Handler() http.Handler {
 
 // handleGetRoutes обрабатывает получение списка роутов
 func (a *Admin) handleGetRoutes(w http.ResponseWriter, r *http.Request) {
-	routes, err := a.GetRoutes(r.Context())
+	tokens, err := consistencyTokens(r)
 	if err != nil {
+		a.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	routes, err := a.waitRoutes(r.Context(), tokens)
+	if errors.Is(err, storage.ErrNotVisible) {
+		w.Header().Set("Retry-After", "1")
+		a.sendError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		a.reportStorageError(r.Context(), err, "getRoutes", nil)
 		a.sendError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	a.sendJSON(w, map[string]interface{}{
-		"success": true,
-		"routes":  routes,
+	// ETag вычисляется по содержимому, Last-Modified - по последнему updated_at
+	var lastModified time.Time
+	for _, route := range routes {
+		if route.UpdatedAt.After(lastModified) {
+			lastModified = route.UpdatedAt
+		}
+	}
+
+	body, err := a.router.Codec().Marshal(types.APIResponse{
+		Success: true,
+		Data:    routes,
 	})
+	if err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if router.CheckNotModified(w, r, router.ETag(body), lastModified) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// consistencyTokens разбирает токены согласованности ?consistency= запроса
+func consistencyTokens(r *http.Request) ([]storage.ConsistencyToken, error) {
+	values := r.URL.Query()[storage.ConsistencyParam]
+	tokens := make([]storage.ConsistencyToken, 0, len(values))
+	for _, value := range values {
+		token, err := storage.ParseConsistencyToken(value)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// waitRoutes возвращает роуты, в которых видны изменения из tokens
+func (a *Admin) waitRoutes(ctx context.Context, tokens []storage.ConsistencyToken) ([]*storage.Route, error) {
+	if len(tokens) == 0 {
+		return a.GetRoutes(ctx)
+	}
+
+	wait := a.consistencyWait
+	if wait <= 0 {
+		wait = storage.DefaultConsistencyWait
+	}
+	return storage.WaitVisible(ctx, tokens, wait, a.GetRoutes)
+}
+
+// sendConsistency передает токен согласованности изменения в заголовке ответа
+func sendConsistency(w http.ResponseWriter, token string) *types.ResponseMeta {
+	w.Header().Set(storage.ConsistencyHeader, token)
+	return &types.ResponseMeta{Consistency: token}
 }
 
 // handleGetRoute обрабатывает получение роута по ID
@@ -166,21 +1035,31 @@ func (a *Admin) handleGetRoute(w http.ResponseWriter, r *http.Request) {
 
 // handleCreateRoute обрабатывает создание нового роута
 func (a *Admin) handleCreateRoute(w http.ResponseWriter, r *http.Request) {
-	var route storage.Route
-	if err := json.NewDecoder(r.Body).Decode(&route); err != nil {
-		a.sendError(w, http.StatusBadRequest, "Invalid JSON")
+	route, ok := a.decodeRoute(w, r)
+	if !ok {
 		return
 	}
 
-	if err := a.storage.SaveRoute(r.Context(), &route); err != nil {
+	a.assignRouteID(route)
+	if err := a.storage.SaveRoute(r.Context(), route); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			a.sendError(w, http.StatusConflict, err.Error())
+			return
+		}
+		a.reportStorageError(r.Context(), err, "saveRoute", map[string]interface{}{"route": route.Name})
 		a.sendError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	a.router.Audit().Record(r.Context(), audit.ActionRouteCreate, route.ID, map[string]interface{}{
+		"name": route.Name,
+		"path": route.Path,
+	})
 
-	a.sendJSON(w, map[string]interface{}{
-		"success": true,
-		"message": "Route created successfully",
-		"route":   route,
+	a.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    route,
+		Message: "Route created successfully",
+		Meta:    sendConsistency(w, storage.NewConsistencyToken(storage.ChangeOpSave, route)),
 	})
 }
 
@@ -192,9 +1071,8 @@ func (a *Admin) handleUpdateRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var route storage.Route
-	if err := json.NewDecoder(r.Body).Decode(&route); err != nil {
-		a.sendError(w, http.StatusBadRequest, "Invalid JSON")
+	route, ok := a.decodeRoute(w, r)
+	if !ok {
 		return
 	}
 
@@ -204,6 +1082,43 @@ func (a *Admin) handleUpdateRoute(w http.ResponseWriter, r *http.Request) {
 	a.sendError(w, http.StatusNotImplemented, "Update route not implemented yet")
 }
 
+// decodeRoute разбирает, нормализует и проверяет роут из тела запроса.
+// При ошибке валидации отвечает 422 с ошибками полей
+func (a *Admin) decodeRoute(w http.ResponseWriter, r *http.Request) (*storage.Route, bool) {
+	var route storage.Route
+	if err := codec.Decode(a.router.Codec(), r.Body, &route); err != nil {
+		a.sendError(w, http.StatusBadRequest, "Invalid JSON")
+		return nil, false
+	}
+
+	route.Normalize()
+	if err := route.Validate(); err != nil {
+		var validationErr *storage.ValidationError
+		if errors.As(err, &validationErr) {
+			a.sendResponse(w, http.StatusUnprocessableEntity, types.APIResponse{
+				Success: false,
+				Data:    validationErr,
+				Error:   err.Error(),
+			})
+			return nil, false
+		}
+		a.sendError(w, http.StatusUnprocessableEntity, err.Error())
+		return nil, false
+	}
+
+	if err := a.router.ValidateIcon(route.Icon); err != nil {
+		validationErr := &storage.ValidationError{Fields: map[string]string{"icon": err.Error()}}
+		a.sendResponse(w, http.StatusUnprocessableEntity, types.APIResponse{
+			Success: false,
+			Data:    validationErr,
+			Error:   validationErr.Error(),
+		})
+		return nil, false
+	}
+
+	return &route, true
+}
+
 // handleDeleteRoute обрабатывает удаление роута
 func (a *Admin) handleDeleteRoute(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -213,29 +1128,95 @@ func (a *Admin) handleDeleteRoute(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := a.DeleteRoute(r.Context(), id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.sendError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		a.reportStorageError(r.Context(), err, "deleteRoute", map[string]interface{}{"id": id})
 		a.sendError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	a.router.Audit().Record(r.Context(), audit.ActionRouteDelete, id, nil)
+
+	a.sendJSON(w, types.APIResponse{
+		Success: true,
+		Message: "Route deleted successfully",
+		Meta:    sendConsistency(w, storage.NewConsistencyToken(storage.ChangeOpDelete, &storage.Route{ID: id})),
+	})
+}
+
+// handleGetDeadLetters обрабатывает получение dead-letter очереди
+func (a *Admin) handleGetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	a.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    types.DeadLettersData{Items: a.deadLetters.Items()},
+	})
+}
+
+// handleReplayDeadLetters обрабатывает повторную запись dead-letter очереди
+func (a *Admin) handleReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	replayed, err := a.ReplayDeadLetters(r.Context())
+	if err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	a.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data: types.ReplayData{
+			Replayed:  replayed,
+			Remaining: a.deadLetters.Len(),
+		},
+	})
+}
+
+// handleDrainDeadLetters обрабатывает очистку dead-letter очереди
+func (a *Admin) handleDrainDeadLetters(w http.ResponseWriter, r *http.Request) {
+	a.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    types.DeadLettersData{Items: a.deadLetters.Drain()},
+	})
+}
+
+// handleHealth обрабатывает проверку состояния с учетом storage
+func (a *Admin) handleHealth(w http.ResponseWriter, r *http.Request) {
+	pending := a.deadLetters.Len()
+
+	status := "ok"
+	httpStatus := http.StatusOK
+	if pending > 0 {
+		status = "degraded"
+		httpStatus = http.StatusServiceUnavailable
+	}
 
-	a.sendJSON(w, map[string]interface{}{
-		"success": true,
-		"message": "Route deleted successfully",
+	a.sendResponse(w, httpStatus, types.APIResponse{
+		Success: pending == 0,
+		Data: types.HealthData{
+			Status:            status,
+			DeadLetters:       pending,
+			FailedWritesTotal: a.deadLetters.Total(),
+			Storage:           a.StorageMetrics(),
+		},
 	})
 }
 
 // sendJSON отправляет JSON ответ
-func (a *Admin) sendJSON(w http.ResponseWriter, data interface{}) {
+func (a *Admin) sendJSON(w http.ResponseWriter, response types.APIResponse) {
+	a.sendResponse(w, http.StatusOK, response)
+}
+
+// sendResponse отправляет JSON ответ с указанным статусом
+func (a *Admin) sendResponse(w http.ResponseWriter, status int, response types.APIResponse) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	w.WriteHeader(status)
+	codec.Encode(a.router.Codec(), w, response)
 }
 
 // sendError отправляет ошибку в формате JSON
 func (a *Admin) sendError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": false,
-		"error":   message,
+	a.sendResponse(w, status, types.APIResponse{
+		Success: false,
+		Error:   message,
 	})
 }
 
@@ -256,6 +1237,21 @@ func NewPage(name, title string) *form.PageBuilder {
 	return form.NewPage(name, title)
 }
 
+// IsDryRun сообщает, что обработчик формы вызван в режиме пробного запуска
+func IsDryRun(ctx context.Context) bool {
+	return types.IsDryRun(ctx)
+}
+
+// Undoable оборачивает результат обработчика функцией отмены, доступной в течение ttl
+func Undoable(data interface{}, ttl time.Duration, undo types.UndoFunc) *types.UndoableResult {
+	return types.Undoable(data, ttl, undo)
+}
+
+// Cacheable оборачивает результат OnGet или обработчика таблицы политикой кеширования
+func Cacheable(data interface{}, policy types.CachePolicy) *types.CachedResult {
+	return types.Cacheable(data, policy)
+}
+
 // FromStruct создает форму из Go структуры
 func FromStruct(name, title string, structType interface{}) *form.FormBuilder {
 	return form.FromStruct(name, title, structType)
@@ -277,3 +1273,14 @@ func ValidationRule(ruleType string, value interface{}, message string) types.Va
 		Message: message,
 	}
 }
+
+// WarningRule создает предупреждающее правило: при срабатывании отправка не отклоняется,
+// а сообщение возвращается в meta.warnings ответа
+func WarningRule(ruleType string, value interface{}, message string) types.ValidationRule {
+	return types.ValidationRule{
+		Type:    ruleType,
+		Value:   value,
+		Message: message,
+		Level:   types.ValidationLevelWarning,
+	}
+}
@@ -0,0 +1,87 @@
+package icons
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// namePattern допустимый формат имени иконки, например user, shopping-cart или mdi:account
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_:-]*$`)
+
+// Registry содержит набор допустимых имен иконок.
+// Пустой набор разрешает любое корректное имя; URL допускаются всегда
+type Registry struct {
+	mu    sync.RWMutex
+	names map[string]bool
+}
+
+// NewRegistry создает набор иконок с именами names
+func NewRegistry(names ...string) *Registry {
+	r := &Registry{names: make(map[string]bool, len(names))}
+	r.Register(names...)
+	return r
+}
+
+// Register добавляет имена иконок в набор
+func (r *Registry) Register(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		r.names[name] = true
+	}
+}
+
+// Names возвращает имена иконок набора в алфавитном порядке
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.names))
+	for name := range r.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate проверяет иконку: пустое значение, http(s) URL, абсолютный путь
+// или имя из набора. Для nil набора проверяется только формат имени
+func (r *Registry) Validate(icon string) error {
+	if icon == "" || IsURL(icon) {
+		return nil
+	}
+
+	if !namePattern.MatchString(icon) {
+		return fmt.Errorf("некорректное имя иконки %q", icon)
+	}
+
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.names) > 0 && !r.names[icon] {
+		return fmt.Errorf("неизвестная иконка %q", icon)
+	}
+	return nil
+}
+
+// IsURL сообщает, что иконка задана адресом изображения: http(s) URL или абсолютным путем
+func IsURL(icon string) bool {
+	if strings.HasPrefix(icon, "/") && !strings.HasPrefix(icon, "//") {
+		return !strings.ContainsAny(icon, " \t\n")
+	}
+
+	u, err := url.Parse(icon)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
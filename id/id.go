@@ -0,0 +1,166 @@
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Generator генерирует уникальные идентификаторы сущностей
+// (роутов, заявок и т.д.)
+type Generator interface {
+	NewID() string
+}
+
+// GeneratorFunc адаптер функции к интерфейсу Generator
+type GeneratorFunc func() string
+
+// NewID вызывает функцию
+func (f GeneratorFunc) NewID() string {
+	return f()
+}
+
+var (
+	defaultMu        sync.RWMutex
+	defaultGenerator Generator = UUIDv7()
+)
+
+// SetDefault устанавливает генератор по умолчанию
+func SetDefault(g Generator) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultGenerator = g
+}
+
+// Default возвращает генератор по умолчанию (UUIDv7, если не изменен)
+func Default() Generator {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	return defaultGenerator
+}
+
+// New генерирует идентификатор генератором по умолчанию
+func New() string {
+	return Default().NewID()
+}
+
+// UUIDv7 возвращает генератор UUID версии 7 (RFC 9562): идентификаторы
+// упорядочены по времени создания, что удобно для индексов БД
+func UUIDv7() Generator {
+	return GeneratorFunc(func() string {
+		var u [16]byte
+		rand.Read(u[6:])
+
+		ms := uint64(time.Now().UnixMilli())
+		u[0] = byte(ms >> 40)
+		u[1] = byte(ms >> 32)
+		u[2] = byte(ms >> 24)
+		u[3] = byte(ms >> 16)
+		u[4] = byte(ms >> 8)
+		u[5] = byte(ms)
+
+		u[6] = (u[6] & 0x0f) | 0x70 // версия 7
+		u[8] = (u[8] & 0x3f) | 0x80 // вариант RFC 9562
+
+		var buf [36]byte
+		hex.Encode(buf[0:8], u[0:4])
+		buf[8] = '-'
+		hex.Encode(buf[9:13], u[4:6])
+		buf[13] = '-'
+		hex.Encode(buf[14:18], u[6:8])
+		buf[18] = '-'
+		hex.Encode(buf[19:23], u[8:10])
+		buf[23] = '-'
+		hex.Encode(buf[24:], u[10:])
+		return string(buf[:])
+	})
+}
+
+// crockford алфавит Crockford Base32 для ULID
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID возвращает генератор ULID: 26 символов, лексикографически упорядочены по времени
+func ULID() Generator {
+	return GeneratorFunc(func() string {
+		var u [16]byte
+		rand.Read(u[6:])
+
+		ms := uint64(time.Now().UnixMilli())
+		u[0] = byte(ms >> 40)
+		u[1] = byte(ms >> 32)
+		u[2] = byte(ms >> 24)
+		u[3] = byte(ms >> 16)
+		u[4] = byte(ms >> 8)
+		u[5] = byte(ms)
+
+		// 128 бит кодируются 26 символами по 5 бит, старшие 2 бита первого символа нулевые
+		hi := binary.BigEndian.Uint64(u[0:8])
+		lo := binary.BigEndian.Uint64(u[8:16])
+
+		var buf [26]byte
+		for i := 25; i >= 0; i-- {
+			buf[i] = crockford[lo&0x1f]
+			lo = lo>>5 | hi<<59
+			hi >>= 5
+		}
+		return string(buf[:])
+	})
+}
+
+// snowflakeEpoch начало отсчета времени для Snowflake (2024-01-01 UTC)
+var snowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+// ErrInvalidNode возвращается при некорректном номере узла Snowflake
+var ErrInvalidNode = errors.New("номер узла должен быть от 0 до 1023")
+
+// snowflake генератор Snowflake идентификаторов
+type snowflake struct {
+	mu       sync.Mutex
+	node     int64
+	lastMs   int64
+	sequence int64
+}
+
+// Snowflake возвращает генератор 64-битных Snowflake идентификаторов
+// (41 бит времени, 10 бит номера узла, 12 бит последовательности).
+// Каждой реплике нужен уникальный номер узла от 0 до 1023
+func Snowflake(node int64) (Generator, error) {
+	if node < 0 || node > 1023 {
+		return nil, ErrInvalidNode
+	}
+	return &snowflake{node: node}, nil
+}
+
+// NewID генерирует идентификатор
+func (s *snowflake) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := time.Now().UnixMilli() - snowflakeEpoch
+	if ms < s.lastMs {
+		// Часы ушли назад: продолжаем с последнего значения, чтобы не повторяться
+		ms = s.lastMs
+	}
+
+	if ms == s.lastMs {
+		s.sequence = (s.sequence + 1) & 0xfff
+		if s.sequence == 0 {
+			// Последовательность исчерпана: ждем следующую миллисекунду
+			for ms <= s.lastMs {
+				time.Sleep(100 * time.Microsecond)
+				ms = time.Now().UnixMilli() - snowflakeEpoch
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastMs = ms
+
+	return strconv.FormatInt(ms<<22|s.node<<12|s.sequence, 10)
+}
@@ -0,0 +1,89 @@
+package interpolate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// Встроенные переменные
+const (
+	VarUserID   = "user.id"
+	VarUserName = "user.name"
+	VarToday    = "today" // текущая дата в часовом поясе отображения, 2006-01-02
+)
+
+// variablePattern соответствует {{ имя }}; имя состоит из букв, цифр, _ и .
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// Vars содержит значения переменных запроса
+type Vars map[string]string
+
+// HasVariables сообщает, содержит ли строка переменные
+func HasVariables(s string) bool {
+	return strings.Contains(s, "{{") && variablePattern.MatchString(s)
+}
+
+// Expand подставляет значения переменных в строку.
+// Подстановка однопроходная: переменные внутри значений не раскрываются,
+// неизвестные переменные остаются без изменений
+func Expand(s string, vars Vars) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// FormHasVariables сообщает, содержат ли тексты формы переменные
+func FormHasVariables(f *types.Form) bool {
+	if HasVariables(f.Title) || HasVariables(f.Description) {
+		return true
+	}
+
+	for _, field := range f.Fields {
+		if HasVariables(field.Label) || HasVariables(field.Placeholder) || HasVariables(field.Description) ||
+			HasVariables(field.Help) || HasVariables(field.Tooltip) {
+			return true
+		}
+	}
+
+	for _, group := range f.Groups {
+		if HasVariables(group.Title) || HasVariables(group.Description) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Form возвращает копию формы с подставленными переменными в заголовке, описании,
+// метках, placeholder, описаниях и подсказках полей и групп
+func Form(f *types.Form, vars Vars) *types.Form {
+	clone := f.Clone()
+	clone.Title = Expand(clone.Title, vars)
+	clone.Description = Expand(clone.Description, vars)
+
+	for i := range clone.Fields {
+		field := &clone.Fields[i]
+		field.Label = Expand(field.Label, vars)
+		field.Placeholder = Expand(field.Placeholder, vars)
+		field.Description = Expand(field.Description, vars)
+		field.Help = Expand(field.Help, vars)
+		field.Tooltip = Expand(field.Tooltip, vars)
+	}
+
+	for i := range clone.Groups {
+		clone.Groups[i].Title = Expand(clone.Groups[i].Title, vars)
+		clone.Groups[i].Description = Expand(clone.Groups[i].Description, vars)
+	}
+
+	return clone
+}
@@ -0,0 +1,116 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/koteyye/go-formist/storage"
+)
+
+// DefaultInterval интервал попыток захвата и проверки лидерства по умолчанию
+const DefaultInterval = 10 * time.Second
+
+// Elector выбирает среди реплик лидера с помощью распределенной блокировки storage.
+// Периодические задачи выполняются только на лидере, поэтому при нескольких
+// репликах каждая задача выполняется один раз за интервал
+type Elector struct {
+	locker storage.Locker
+	name   string
+	leader atomic.Bool
+
+	mu       sync.Mutex
+	onChange func(leader bool)
+}
+
+// NewElector создает выборы лидера по блокировке с именем name
+func NewElector(locker storage.Locker, name string) *Elector {
+	return &Elector{
+		locker: locker,
+		name:   name,
+	}
+}
+
+// OnChange устанавливает обработчик смены лидерства
+func (e *Elector) OnChange(fn func(leader bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.onChange = fn
+}
+
+// IsLeader сообщает, является ли реплика лидером
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run участвует в выборах до отмены ctx: не лидер раз в interval пытается захватить
+// блокировку, лидер с тем же интервалом проверяет, что она не потеряна.
+// При отмене ctx блокировка освобождается, ошибки передаются в onError
+func (e *Elector) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	go func() {
+		var lock storage.Lock
+		defer func() {
+			if lock != nil {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				lock.Release(releaseCtx)
+				e.setLeader(false)
+			}
+		}()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			var err error
+			if lock == nil {
+				lock, err = e.tryLock(ctx)
+			} else if err = lock.Check(ctx); err != nil {
+				lock.Release(ctx)
+				lock = nil
+				e.setLeader(false)
+			}
+			if err != nil && ctx.Err() == nil && onError != nil {
+				onError(err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// tryLock пытается захватить блокировку
+func (e *Elector) tryLock(ctx context.Context) (storage.Lock, error) {
+	lock, acquired, err := e.locker.TryLock(ctx, e.name)
+	if err != nil || !acquired {
+		return nil, err
+	}
+
+	e.setLeader(true)
+	return lock, nil
+}
+
+// setLeader обновляет статус лидерства и уведомляет об изменении
+func (e *Elector) setLeader(leader bool) {
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+
+	e.mu.Lock()
+	onChange := e.onChange
+	e.mu.Unlock()
+
+	if onChange != nil {
+		onChange(leader)
+	}
+}
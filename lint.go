@@ -0,0 +1,14 @@
+package formist
+
+import (
+	"github.com/koteyye/go-formist/lint"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Lint проверяет определение формы на ошибки конфигурации: обязательные скрытые
+// и недоступные для ввода поля без значения, поля выбора с одним вариантом, поля без названия,
+// противоречивые правила (min больше max) и ссылки групп на несуществующие поля.
+// Проблемы зарегистрированных форм также входят в самодиагностику (проверка lint)
+func Lint(form *types.Form) []types.DiagnosticIssue {
+	return lint.Form(form)
+}
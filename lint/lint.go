@@ -0,0 +1,145 @@
+// Package lint находит ошибки конфигурации форм до того, как на них наткнутся пользователи:
+// обязательные поля, которые нельзя заполнить, противоречивые правила валидации,
+// поля выбора из одного варианта, поля без названия и ссылки групп на несуществующие поля
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// Form проверяет определение формы и возвращает найденные проблемы.
+// Ошибки (DiagnosticError) гарантированно мешают отправке, предупреждения (DiagnosticWarning) -
+// вероятные недосмотры
+func Form(form *types.Form) []types.DiagnosticIssue {
+	l := &linter{form: form}
+
+	fields := make(map[string]bool, len(form.Fields))
+	for _, field := range form.Fields {
+		fields[field.Name] = true
+		l.field(field)
+	}
+	l.groups(fields)
+	return l.issues
+}
+
+// linter собирает проблемы формы
+type linter struct {
+	form   *types.Form
+	issues []types.DiagnosticIssue
+}
+
+// add добавляет проблему формы
+func (l *linter) add(level, format string, args ...interface{}) {
+	l.issues = append(l.issues, types.DiagnosticIssue{
+		Level:   level,
+		Target:  l.form.Key(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// field проверяет поле
+func (l *linter) field(field types.Field) {
+	name := field.Name
+	// Значение может прийти из значения по умолчанию, ссылки (?prefill) или данных OnGet
+	filled := field.DefaultValue != nil || slices.Contains(l.form.Prefill, name) || l.form.OnGet != nil
+
+	switch {
+	case !field.Required || filled:
+	case field.Type == types.FieldTypeHidden:
+		l.add(types.DiagnosticWarning, "Поле %s: обязательное скрытое поле без значения по умолчанию", name)
+	case field.Disabled:
+		l.add(types.DiagnosticWarning, "Поле %s: обязательное поле недоступно для ввода и не имеет значения по умолчанию", name)
+	}
+
+	if field.Label == "" && field.Type != types.FieldTypeHidden &&
+		(field.Accessibility == nil || field.Accessibility.AriaLabel == "") {
+		l.add(types.DiagnosticWarning, "Поле %s без названия", name)
+	}
+
+	if (field.Type == types.FieldTypeSelect || field.Type == types.FieldTypeRadio) && field.Dictionary == nil {
+		switch len(field.Options) {
+		case 0:
+			l.add(types.DiagnosticWarning, "Поле %s: поле выбора без вариантов", name)
+		case 1:
+			l.add(types.DiagnosticWarning, "Поле %s: поле выбора с единственным вариантом", name)
+		}
+	}
+
+	l.bounds(name, field.Validation, "min", "max")
+	l.bounds(name, field.Validation, "minLength", "maxLength")
+}
+
+// bounds проверяет, что нижняя граница блокирующих правил не больше верхней
+func (l *linter) bounds(name string, rules []types.ValidationRule, lowerType, upperType string) {
+	lower, hasLower := ruleBound(rules, lowerType)
+	upper, hasUpper := ruleBound(rules, upperType)
+	if hasLower && hasUpper && lower > upper {
+		l.add(types.DiagnosticError, "Поле %s: %s (%v) больше %s (%v), ни одно значение не пройдет проверку",
+			name, lowerType, lower, upperType, upper)
+	}
+}
+
+// groups проверяет ссылки групп на поля: поле из группы без определения никогда не будет показано
+func (l *linter) groups(fields map[string]bool) {
+	groups := make(map[string]bool, len(l.form.Groups))
+	for _, group := range l.form.Groups {
+		groups[group.Name] = true
+		for _, name := range group.Fields {
+			if !fields[name] {
+				l.add(types.DiagnosticWarning, "Группа %s ссылается на несуществующее поле %s", group.Name, name)
+			}
+		}
+	}
+
+	for _, field := range l.form.Fields {
+		if field.Group != "" && !groups[field.Group] {
+			l.add(types.DiagnosticWarning, "Поле %s относится к несуществующей группе %s", field.Name, field.Group)
+		}
+	}
+}
+
+// ruleBound возвращает самую строгую границу правил типа ruleType уровня ошибки:
+// наибольшую для min и minLength, наименьшую для max и maxLength
+func ruleBound(rules []types.ValidationRule, ruleType string) (float64, bool) {
+	var bound float64
+	found := false
+	for _, rule := range rules {
+		if rule.Type != ruleType || rule.IsWarning() {
+			continue
+		}
+		value, ok := number(rule.Value)
+		if !ok {
+			continue
+		}
+
+		lower := ruleType == "min" || ruleType == "minLength"
+		if !found || (lower && value > bound) || (!lower && value < bound) {
+			bound, found = value, true
+		}
+	}
+	return bound, found
+}
+
+// number возвращает числовое значение правила
+func number(value interface{}) (float64, bool) {
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
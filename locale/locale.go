@@ -0,0 +1,177 @@
+package locale
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ISODate канонический формат даты (JSON Schema format: date)
+const ISODate = "2006-01-02"
+
+// ErrInvalidNumber возвращается, если строку не удалось разобрать как число
+var ErrInvalidNumber = errors.New("некорректное число")
+
+// ErrInvalidDate возвращается, если строку не удалось разобрать как дату
+var ErrInvalidDate = errors.New("некорректная дата")
+
+// Locale описывает форматы ввода чисел и дат
+type Locale struct {
+	Tag              string   // код языка, например ru
+	DecimalSeparator string   // разделитель дробной части
+	GroupSeparator   string   // разделитель разрядов (кроме пробелов, которые допустимы всегда)
+	DateLayouts      []string // форматы дат в нотации time.Parse
+}
+
+// Встроенные локали
+var (
+	Russian = Locale{
+		Tag:              "ru",
+		DecimalSeparator: ",",
+		DateLayouts:      []string{"02.01.2006", "2.1.2006", "02.01.06"},
+	}
+	English = Locale{
+		Tag:              "en",
+		DecimalSeparator: ".",
+		GroupSeparator:   ",",
+		DateLayouts:      []string{"01/02/2006", "1/2/2006"},
+	}
+	German = Locale{
+		Tag:              "de",
+		DecimalSeparator: ",",
+		GroupSeparator:   ".",
+		DateLayouts:      []string{"02.01.2006", "2.1.2006"},
+	}
+)
+
+// locales зарегистрированные локали по коду языка
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]Locale{
+		"ru": Russian,
+		"en": English,
+		"de": German,
+	}
+)
+
+// Register добавляет или заменяет локаль
+func Register(l Locale) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+
+	locales[strings.ToLower(l.Tag)] = l
+}
+
+// Lookup возвращает локаль по коду языка (ru, ru-RU, ru_RU)
+func Lookup(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+
+	if l, ok := locales[tag]; ok {
+		return l, true
+	}
+
+	if i := strings.IndexAny(tag, "-_"); i > 0 {
+		l, ok := locales[tag[:i]]
+		return l, ok
+	}
+	return Locale{}, false
+}
+
+// FromRequest определяет локаль, явно указанную в запросе:
+// параметр ?locale= или заголовок Content-Language
+func FromRequest(req *http.Request) (Locale, bool) {
+	if tag := req.URL.Query().Get("locale"); tag != "" {
+		return Lookup(tag)
+	}
+	if tag := req.Header.Get("Content-Language"); tag != "" {
+		return Lookup(firstTag(tag))
+	}
+	return Locale{}, false
+}
+
+// FromAcceptLanguage определяет локаль по заголовку Accept-Language
+func FromAcceptLanguage(req *http.Request) (Locale, bool) {
+	for _, part := range strings.Split(req.Header.Get("Accept-Language"), ",") {
+		if l, ok := Lookup(firstTag(part)); ok {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// RequestTag возвращает язык, на котором клиент ожидает ответ: параметр ?locale=
+// или первый язык Accept-Language. Код может не соответствовать зарегистрированной локали
+func RequestTag(req *http.Request) string {
+	if tag := req.URL.Query().Get("locale"); tag != "" {
+		return tag
+	}
+	return firstTag(req.Header.Get("Accept-Language"))
+}
+
+// firstTag возвращает первый код языка без параметров (;q=0.9)
+func firstTag(header string) string {
+	tag := strings.Split(header, ",")[0]
+	return strings.TrimSpace(strings.Split(tag, ";")[0])
+}
+
+// ParseNumber разбирает число в формате локали: "1 234,56" для ru, "1,234.56" для en.
+// Точка как разделитель дробной части допустима, если она не разделяет разряды
+func (l Locale) ParseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(s)
+	if l.GroupSeparator != "" {
+		s = strings.ReplaceAll(s, l.GroupSeparator, "")
+	}
+	if l.DecimalSeparator != "" && l.DecimalSeparator != "." {
+		s = strings.Replace(s, l.DecimalSeparator, ".", 1)
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || s == "" {
+		return 0, ErrInvalidNumber
+	}
+	return value, nil
+}
+
+// ParseDateTime разбирает дату и время. Значение со смещением (RFC 3339) сохраняет его,
+// значение без смещения ("2024-12-31T15:04", "31.12.2024 15:04") считается временем в tz
+func (l Locale) ParseDateTime(s string, tz *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	layouts := []string{ISODate}
+	layouts = append(layouts, l.DateLayouts...)
+	for _, dateLayout := range layouts {
+		for _, sep := range []string{"T", " "} {
+			for _, timeLayout := range []string{"15:04", "15:04:05"} {
+				if t, err := time.ParseInLocation(dateLayout+sep+timeLayout, s, tz); err == nil {
+					return t, nil
+				}
+			}
+		}
+	}
+	return time.Time{}, ErrInvalidDate
+}
+
+// ParseDate разбирает дату в формате локали (например, 31.12.2024 для ru) или ISO 8601
+func (l Locale) ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(ISODate, s); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range l.DateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidDate
+}
@@ -0,0 +1,140 @@
+package privacy
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Erased значение, которым заменяются удаленные персональные данные
+const Erased = "[erased]"
+
+// Source хранилище, содержащее данные субъекта (пользователя, клиента)
+type Source interface {
+	// ExportSubject возвращает все записи, связанные с субъектом
+	ExportSubject(ctx context.Context, subject string) ([]interface{}, error)
+
+	// EraseSubject обезличивает записи, связанные с субъектом, и возвращает их количество
+	EraseSubject(ctx context.Context, subject string) (int, error)
+}
+
+// StoreReport результат обработки одного хранилища
+type StoreReport struct {
+	Store   string `json:"store"`
+	Records int    `json:"records"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report отчет о затронутых записях по всем хранилищам
+type Report struct {
+	Subject string        `json:"subject"`
+	Total   int           `json:"total"`
+	Stores  []StoreReport `json:"stores"`
+}
+
+// Export собирает данные субъекта из всех хранилищ
+func Export(ctx context.Context, subject string, sources map[string]Source) (map[string][]interface{}, Report) {
+	data := make(map[string][]interface{}, len(sources))
+	report := Report{Subject: subject, Stores: make([]StoreReport, 0, len(sources))}
+
+	for _, name := range sortedNames(sources) {
+		records, err := sources[name].ExportSubject(ctx, subject)
+		storeReport := StoreReport{Store: name, Records: len(records)}
+		if err != nil {
+			storeReport.Error = err.Error()
+		}
+		if len(records) > 0 {
+			data[name] = records
+		}
+
+		report.Total += storeReport.Records
+		report.Stores = append(report.Stores, storeReport)
+	}
+
+	return data, report
+}
+
+// Erase обезличивает данные субъекта во всех хранилищах
+func Erase(ctx context.Context, subject string, sources map[string]Source) Report {
+	report := Report{Subject: subject, Stores: make([]StoreReport, 0, len(sources))}
+
+	for _, name := range sortedNames(sources) {
+		count, err := sources[name].EraseSubject(ctx, subject)
+		storeReport := StoreReport{Store: name, Records: count}
+		if err != nil {
+			storeReport.Error = err.Error()
+		}
+
+		report.Total += count
+		report.Stores = append(report.Stores, storeReport)
+	}
+
+	return report
+}
+
+// Matches проверяет, совпадает ли значение с идентификатором субъекта (без учета регистра)
+func Matches(value interface{}, subject string) bool {
+	str, ok := value.(string)
+	return ok && subject != "" && strings.EqualFold(str, subject)
+}
+
+// Contains проверяет, содержит ли значение (в том числе вложенные map и срезы) идентификатор субъекта
+func Contains(value interface{}, subject string) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			if Contains(item, subject) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if Contains(item, subject) {
+				return true
+			}
+		}
+	default:
+		return Matches(v, subject)
+	}
+	return false
+}
+
+// Redact заменяет значения, совпадающие с идентификатором субъекта, на Erased.
+// Возвращает новое значение и признак изменения
+func Redact(value interface{}, subject string) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		changed := false
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			var itemChanged bool
+			redacted[key], itemChanged = Redact(item, subject)
+			changed = changed || itemChanged
+		}
+		return redacted, changed
+	case []interface{}:
+		changed := false
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			var itemChanged bool
+			redacted[i], itemChanged = Redact(item, subject)
+			changed = changed || itemChanged
+		}
+		return redacted, changed
+	default:
+		if Matches(v, subject) {
+			return Erased, true
+		}
+		return v, false
+	}
+}
+
+// sortedNames возвращает имена хранилищ в стабильном порядке
+func sortedNames(sources map[string]Source) []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
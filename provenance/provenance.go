@@ -0,0 +1,240 @@
+// Package provenance хранит происхождение значений записей: для каждого поля -
+// кто и когда последним изменил его значение
+package provenance
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/privacy"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Entry последнее изменение поля записи
+type Entry struct {
+	Fingerprint string    `json:"fingerprint,omitempty"` // отпечаток значения; пустой для паролей
+	UserID      string    `json:"userId,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	ChangedAt   time.Time `json:"changedAt"`
+}
+
+// Change возвращает изменение для ответа UI без отпечатка значения
+func (e Entry) Change() types.FieldChange {
+	return types.FieldChange{
+		UserID:    e.UserID,
+		Name:      e.Name,
+		ChangedAt: e.ChangedAt,
+	}
+}
+
+// Store хранит изменения полей записей
+type Store interface {
+	// Get возвращает изменения полей записи формы по имени поля
+	Get(ctx context.Context, form, record string) (map[string]Entry, error)
+
+	// Save сохраняет изменения полей записи, заменяя прежние для тех же полей
+	Save(ctx context.Context, form, record string, entries map[string]Entry) error
+}
+
+// MemoryStore хранит изменения полей в памяти
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[recordKey]map[string]Entry
+}
+
+// recordKey запись формы
+type recordKey struct {
+	form   string
+	record string
+}
+
+// NewMemoryStore создает хранилище изменений в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[recordKey]map[string]Entry),
+	}
+}
+
+// Get возвращает копию изменений полей записи
+func (s *MemoryStore) Get(ctx context.Context, form, record string) (map[string]Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make(map[string]Entry, len(s.records[recordKey{form, record}]))
+	for field, entry := range s.records[recordKey{form, record}] {
+		entries[field] = entry
+	}
+	return entries, nil
+}
+
+// Save сохраняет изменения полей записи
+func (s *MemoryStore) Save(ctx context.Context, form, record string, entries map[string]Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := recordKey{form, record}
+	stored, ok := s.records[key]
+	if !ok {
+		stored = make(map[string]Entry, len(entries))
+		s.records[key] = stored
+	}
+	for field, entry := range entries {
+		stored[field] = entry
+	}
+	return nil
+}
+
+// subjectChange изменение поля, выгружаемое по запросу субъекта
+type subjectChange struct {
+	Form   string `json:"form"`
+	Record string `json:"record"`
+	Field  string `json:"field"`
+	types.FieldChange
+}
+
+// ExportSubject возвращает изменения полей, внесенные субъектом
+func (s *MemoryStore) ExportSubject(ctx context.Context, subject string) ([]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]interface{}, 0)
+	for key, entries := range s.records {
+		for field, entry := range entries {
+			if privacy.Matches(entry.UserID, subject) {
+				records = append(records, subjectChange{
+					Form:        key.form,
+					Record:      key.record,
+					Field:       field,
+					FieldChange: entry.Change(),
+				})
+			}
+		}
+	}
+	return records, nil
+}
+
+// EraseSubject заменяет автора изменений субъекта на privacy.Erased
+func (s *MemoryStore) EraseSubject(ctx context.Context, subject string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, entries := range s.records {
+		for field, entry := range entries {
+			if privacy.Matches(entry.UserID, subject) {
+				entry.UserID, entry.Name = privacy.Erased, ""
+				entries[field] = entry
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// Tracker отмечает изменения полей записей при отправке форм
+type Tracker struct {
+	store Store
+
+	// mu упорядочивает чтение и запись изменений одной записи
+	mu sync.Mutex
+}
+
+// New создает учет изменений. По умолчанию изменения хранятся в памяти
+func New(store Store) *Tracker {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Tracker{store: store}
+}
+
+// Store возвращает хранилище изменений
+func (t *Tracker) Store() Store {
+	return t.store
+}
+
+// Record сравнивает значения полей формы из data с сохраненными и отмечает отличающиеся
+// как измененные пользователем из контекста. Пароль считается измененным, если он не пустой.
+// Возвращает имена измененных полей
+func (t *Tracker) Record(ctx context.Context, form *types.Form, record string, data map[string]interface{}) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stored, err := t.store.Get(ctx, form.Key(), record)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID, name string
+	if user, ok := auth.UserFromContext(ctx); ok {
+		userID, name = user.ID, user.Name
+	}
+	now := time.Now().UTC()
+
+	changed := make(map[string]Entry)
+	var fields []string
+	for _, field := range form.Fields {
+		value, ok := data[field.Name]
+		if !ok {
+			continue
+		}
+
+		var print string
+		if field.Type == types.FieldTypePassword {
+			if value == nil || value == "" {
+				continue
+			}
+		} else {
+			if print, err = fingerprint(value); err != nil {
+				return nil, err
+			}
+			if previous, ok := stored[field.Name]; ok && previous.Fingerprint == print {
+				continue
+			}
+		}
+
+		changed[field.Name] = Entry{
+			Fingerprint: print,
+			UserID:      userID,
+			Name:        name,
+			ChangedAt:   now,
+		}
+		fields = append(fields, field.Name)
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	if err := t.store.Save(ctx, form.Key(), record, changed); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Fields возвращает последние изменения полей записи формы
+func (t *Tracker) Fields(ctx context.Context, form, record string) (map[string]types.FieldChange, error) {
+	entries, err := t.store.Get(ctx, form, record)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]types.FieldChange, len(entries))
+	for field, entry := range entries {
+		changes[field] = entry.Change()
+	}
+	return changes, nil
+}
+
+// fingerprint возвращает отпечаток значения поля: само значение не хранится
+func fingerprint(value interface{}) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:16]), nil
+}
@@ -0,0 +1,157 @@
+package registry
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/koteyye/go-formist/form"
+	"github.com/koteyye/go-formist/schema"
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/types"
+)
+
+// SignatureHeader заголовок с base64 подписью тела ответа реестра
+const SignatureHeader = "X-Formist-Signature"
+
+// ErrInvalidSignature возвращается, если подпись снимка не прошла проверку
+var ErrInvalidSignature = errors.New("некорректная подпись реестра")
+
+// Snapshot представляет набор определений форм и пунктов меню из реестра
+type Snapshot struct {
+	Forms  []*types.Form    `json:"forms"`
+	Routes []*storage.Route `json:"routes,omitempty"`
+}
+
+// Validate проверяет все формы снимка
+func (s *Snapshot) Validate() error {
+	for _, f := range s.Forms {
+		if err := schema.ValidateForm(f); err != nil {
+			return err
+		}
+		if _, err := form.NewValidator(f); err != nil {
+			return fmt.Errorf("форма %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// Source представляет источник определений форм.
+// Если данные не изменились с версии etag, возвращается snapshot == nil
+type Source interface {
+	Fetch(ctx context.Context, etag string) (snapshot *Snapshot, newETag string, err error)
+}
+
+// SourceFunc адаптер функции к интерфейсу Source
+type SourceFunc func(ctx context.Context, etag string) (*Snapshot, string, error)
+
+// Fetch вызывает функцию
+func (f SourceFunc) Fetch(ctx context.Context, etag string) (*Snapshot, string, error) {
+	return f(ctx, etag)
+}
+
+// Verifier проверяет подпись содержимого реестра
+type Verifier interface {
+	Verify(payload, signature []byte) error
+}
+
+// HMACVerifier проверяет подпись HMAC-SHA256 общим секретом
+type HMACVerifier struct {
+	Secret []byte
+}
+
+// Verify проверяет подпись
+func (v HMACVerifier) Verify(payload, signature []byte) error {
+	mac := hmac.New(sha256.New, v.Secret)
+	mac.Write(payload)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Ed25519Verifier проверяет подпись Ed25519 публичным ключом
+type Ed25519Verifier struct {
+	PublicKey ed25519.PublicKey
+}
+
+// Verify проверяет подпись
+func (v Ed25519Verifier) Verify(payload, signature []byte) error {
+	if !ed25519.Verify(v.PublicKey, payload, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// HTTPSource загружает снимок реестра по HTTP с поддержкой ETag
+type HTTPSource struct {
+	URL      string
+	Client   *http.Client
+	Verifier Verifier
+	Header   http.Header
+}
+
+// Fetch загружает снимок, если он изменился с версии etag
+func (s *HTTPSource) Fetch(ctx context.Context, etag string) (*Snapshot, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, etag, fmt.Errorf("не удалось создать запрос: %w", err)
+	}
+
+	for key, values := range s.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, etag, fmt.Errorf("не удалось запросить реестр: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, etag, fmt.Errorf("реестр вернул статус %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, etag, fmt.Errorf("не удалось прочитать ответ реестра: %w", err)
+	}
+
+	if s.Verifier != nil {
+		signature, err := base64.StdEncoding.DecodeString(resp.Header.Get(SignatureHeader))
+		if err != nil || len(signature) == 0 {
+			return nil, etag, ErrInvalidSignature
+		}
+		if err := s.Verifier.Verify(body, signature); err != nil {
+			return nil, etag, err
+		}
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(body, &snapshot); err != nil {
+		return nil, etag, fmt.Errorf("некорректный снимок реестра: %w", err)
+	}
+
+	return &snapshot, resp.Header.Get("ETag"), nil
+}
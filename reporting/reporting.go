@@ -0,0 +1,106 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Kind вид ошибки
+type Kind string
+
+const (
+	KindHandler    Kind = "handler"    // обработчик формы или страницы вернул ошибку
+	KindPanic      Kind = "panic"      // panic в обработчике или middleware
+	KindValidation Kind = "validation" // некорректная конфигурация валидации формы
+	KindStorage    Kind = "storage"    // ошибка записи или чтения storage
+)
+
+// Meta контекст ошибки для трекера
+type Meta struct {
+	Kind      Kind                   `json:"kind"`
+	Form      string                 `json:"form,omitempty"`
+	Op        string                 `json:"op,omitempty"` // onPost, onGet, saveRoute и т.п.
+	Method    string                 `json:"method,omitempty"`
+	Path      string                 `json:"path,omitempty"`
+	RequestID string                 `json:"requestId,omitempty"`
+	User      string                 `json:"user,omitempty"`
+	Stack     []byte                 `json:"-"` // стек вызовов для panic
+	Extra     map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Handler получает ошибки админки (например, для отправки в Sentry)
+type Handler func(ctx context.Context, err error, meta Meta)
+
+// PanicError ошибка, полученная из panic
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error возвращает текст ошибки
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic в обработчике: %v", e.Value)
+}
+
+// IsPanic проверяет, получена ли ошибка из panic
+func IsPanic(err error) bool {
+	var panicErr *PanicError
+	return errors.As(err, &panicErr)
+}
+
+// SentryEvent событие для отправки в Sentry
+type SentryEvent struct {
+	Err   error
+	Level string // error или fatal (для panic)
+	Tags  map[string]string
+	Extra map[string]interface{}
+}
+
+// Sentry возвращает Handler, преобразующий ошибки в события Sentry. Библиотека не зависит
+// от sentry-go, отправку выполняет capture, например:
+//
+//	reporting.Sentry(func(e reporting.SentryEvent) {
+//		sentry.WithScope(func(scope *sentry.Scope) {
+//			scope.SetLevel(sentry.Level(e.Level))
+//			scope.SetTags(e.Tags)
+//			scope.SetContext("formist", e.Extra)
+//			sentry.CaptureException(e.Err)
+//		})
+//	})
+func Sentry(capture func(SentryEvent)) Handler {
+	return func(ctx context.Context, err error, meta Meta) {
+		event := SentryEvent{
+			Err:   err,
+			Level: "error",
+			Tags:  map[string]string{"formist.kind": string(meta.Kind)},
+			Extra: make(map[string]interface{}, len(meta.Extra)+1),
+		}
+		if meta.Kind == KindPanic {
+			event.Level = "fatal"
+		}
+
+		tags := map[string]string{
+			"formist.form": meta.Form,
+			"formist.op":   meta.Op,
+			"http.method":  meta.Method,
+			"http.path":    meta.Path,
+			"request_id":   meta.RequestID,
+			"user.id":      meta.User,
+		}
+		for key, value := range tags {
+			if value != "" {
+				event.Tags[key] = value
+			}
+		}
+
+		for key, value := range meta.Extra {
+			event.Extra[key] = value
+		}
+		if len(meta.Stack) > 0 {
+			event.Extra["stack"] = string(meta.Stack)
+		}
+
+		capture(event)
+	}
+}
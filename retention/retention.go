@@ -0,0 +1,178 @@
+package retention
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Purgeable хранилище, из которого можно удалять устаревшие записи
+type Purgeable interface {
+	// Purge удаляет записи старше before и возвращает их количество.
+	// При dryRun записи только подсчитываются
+	Purge(ctx context.Context, before time.Time, dryRun bool) (int, error)
+}
+
+// Policy задает срок хранения записей одного хранилища
+type Policy struct {
+	Name  string // имя хранилища в отчетах, например "audit"
+	Store Purgeable
+	Days  int // срок хранения в днях, 0 - хранить бессрочно
+
+	// Shared хранилище общее для всех реплик (например, PostgreSQL): периодическая
+	// очистка выполняется только на лидере. Хранилища в памяти процесса
+	// очищаются на каждой реплике
+	Shared bool
+}
+
+// Report результат очистки одного хранилища
+type Report struct {
+	Store  string    `json:"store"`
+	Before time.Time `json:"before"`
+	Purged int       `json:"purged"`
+	DryRun bool      `json:"dryRun,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Stats метрики очистки хранилища
+type Stats struct {
+	Runs        int       `json:"runs"`
+	PurgedTotal int       `json:"purgedTotal"`
+	Errors      int       `json:"errors"`
+	LastRun     time.Time `json:"lastRun,omitempty"`
+	LastPurged  int       `json:"lastPurged"`
+}
+
+// Purger удаляет устаревшие записи согласно политикам хранения
+type Purger struct {
+	policies []Policy
+	now      func() time.Time
+	isLeader func() bool
+
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+// NewPurger создает очистку с указанными политиками
+func NewPurger(policies ...Policy) *Purger {
+	return &Purger{
+		policies: policies,
+		now:      time.Now,
+		stats:    make(map[string]*Stats),
+	}
+}
+
+// SetLeader задает проверку лидерства реплики: периодическая очистка (Start)
+// общих хранилищ (Policy.Shared) выполняется только на лидере. Ручной запуск (Run)
+// не ограничивается
+func (p *Purger) SetLeader(isLeader func() bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.isLeader = isLeader
+}
+
+// Policies возвращает политики хранения
+func (p *Purger) Policies() []Policy {
+	return append([]Policy(nil), p.policies...)
+}
+
+// Run выполняет очистку всех хранилищ. При dryRun возвращает отчет о том,
+// что было бы удалено, не изменяя данные и метрики
+func (p *Purger) Run(ctx context.Context, dryRun bool) []Report {
+	return p.run(ctx, dryRun, true)
+}
+
+// run выполняет очистку хранилищ; без shared общие хранилища пропускаются
+func (p *Purger) run(ctx context.Context, dryRun, shared bool) []Report {
+	reports := make([]Report, 0, len(p.policies))
+
+	for _, policy := range p.policies {
+		if policy.Days <= 0 || policy.Store == nil || (policy.Shared && !shared) {
+			continue
+		}
+
+		report := Report{
+			Store:  policy.Name,
+			Before: p.now().AddDate(0, 0, -policy.Days),
+			DryRun: dryRun,
+		}
+
+		purged, err := policy.Store.Purge(ctx, report.Before, dryRun)
+		report.Purged = purged
+		if err != nil {
+			report.Error = err.Error()
+		}
+
+		if !dryRun {
+			p.record(report)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// Start запускает периодическую очистку до отмены ctx. Реплика, не являющаяся
+// лидером, очищает только хранилища в памяти процесса.
+// onReport, если задан, получает отчет каждого запуска
+func (p *Purger) Start(ctx context.Context, interval time.Duration, onReport func([]Report)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				leading := p.leading()
+				reports := p.run(ctx, false, leading)
+				if onReport != nil && (leading || len(reports) > 0) {
+					onReport(reports)
+				}
+			}
+		}
+	}()
+}
+
+// Stats возвращает метрики очистки по хранилищам
+func (p *Purger) Stats() map[string]Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]Stats, len(p.stats))
+	for name, s := range p.stats {
+		stats[name] = *s
+	}
+	return stats
+}
+
+// leading сообщает, должна ли реплика выполнять периодическую очистку общих хранилищ
+func (p *Purger) leading() bool {
+	p.mu.Lock()
+	isLeader := p.isLeader
+	p.mu.Unlock()
+
+	return isLeader == nil || isLeader()
+}
+
+// record обновляет метрики по отчету
+func (p *Purger) record(report Report) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.stats[report.Store]
+	if !ok {
+		s = &Stats{}
+		p.stats[report.Store] = s
+	}
+
+	s.Runs++
+	s.LastRun = p.now()
+	s.LastPurged = report.Purged
+	s.PurgedTotal += report.Purged
+	if report.Error != "" {
+		s.Errors++
+	}
+}
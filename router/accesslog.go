@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/koteyye/go-formist/accesslog"
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/types"
+)
+
+// accessRecordKey ключ записи журнала доступа в контексте запроса
+type accessRecordKey struct{}
+
+// accessRecord запись журнала доступа, дополняемая обработчиками
+type accessRecord struct {
+	mu    sync.Mutex
+	entry accesslog.Entry
+}
+
+// SetAccessLog подключает журнал доступа в формате JSON
+func (r *Router) SetAccessLog(log *accesslog.Logger) {
+	r.accessLog = log
+	r.rebuild()
+}
+
+// accessLogMiddleware записывает строку журнала доступа после обработки запроса
+func (r *Router) accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		record := &accessRecord{}
+		ww := middleware.NewWrapResponseWriter(w, req.ProtoMajor)
+
+		defer func() {
+			status := ww.Status()
+			if p := recover(); p != nil {
+				status = http.StatusInternalServerError
+				defer panic(p)
+			}
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			record.mu.Lock()
+			entry := record.entry
+			record.mu.Unlock()
+
+			entry.Time = start.UTC()
+			entry.Method = req.Method
+			entry.Path = req.URL.Path
+			entry.Status = status
+			entry.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+			entry.Bytes = ww.BytesWritten()
+			entry.RequestID = middleware.GetReqID(req.Context())
+			entry.RemoteIP = remoteIP(req)
+			r.accessLog.Write(entry)
+		}()
+
+		ctx := context.WithValue(req.Context(), accessRecordKey{}, record)
+		next.ServeHTTP(ww, req.WithContext(ctx))
+	})
+}
+
+// accessLogUser добавляет в запись журнала пользователя, определенного middleware авторизации
+func (r *Router) accessLogUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if record, ok := req.Context().Value(accessRecordKey{}).(*accessRecord); ok {
+			if user, ok := auth.UserFromContext(req.Context()); ok {
+				record.mu.Lock()
+				record.entry.User = user.ID
+				record.mu.Unlock()
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// noteAccess добавляет в запись журнала форму и имена отправленных полей
+func (r *Router) noteAccess(req *http.Request, form *types.Form, data map[string]interface{}) {
+	record, ok := req.Context().Value(accessRecordKey{}).(*accessRecord)
+	if !ok {
+		return
+	}
+
+	record.mu.Lock()
+	defer record.mu.Unlock()
+
+	r.accessLog.Submission(&record.entry, form, data)
+}
+
+// remoteIP возвращает IP клиента без порта
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,87 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// handleFormAction вызывает обработчик дополнительного действия формы.
+// Тело запроса (данные формы) необязательно
+func (r *Router) handleFormAction(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+
+	action, exists := form.Action(chi.URLParam(req, "action"))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Действие не найдено")
+		return
+	}
+	if action.Handler == nil {
+		r.sendError(w, http.StatusMethodNotAllowed, "Действие не поддерживается")
+		return
+	}
+
+	if err := r.validatorError(form.Key()); err != nil {
+		r.reportRequestError(req, err, reporting.KindValidation, form.Key(), "validator")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка конфигурации формы: %v", err))
+		return
+	}
+
+	data := make(map[string]interface{})
+	if err := r.decodeJSON(req.Body, &data); err != nil && !errors.Is(err, io.EOF) {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return
+	}
+
+	if r.accessLog != nil {
+		r.noteAccess(req, form, data)
+	}
+
+	if err := r.normalizeFormData(req, form, data); err != nil {
+		r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Ошибка валидации: %v", err))
+		return
+	}
+
+	var warnings []types.ValidationWarning
+	if action.Validate {
+		if err := r.validateFormData(req.Context(), form, data); err != nil {
+			r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Ошибка валидации: %v", err))
+			return
+		}
+		warnings = r.formWarnings(form, data)
+	}
+
+	result, err := r.callFormHandler(req.Context(), form, func(ctx context.Context) (interface{}, error) {
+		return action.Handler(ctx, data)
+	})
+	if aborted(req) {
+		return
+	}
+	if errors.Is(err, errOverloaded) {
+		r.sendOverloaded(w)
+		return
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "action:"+action.Name)
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка обработки: %v", err))
+		return
+	}
+
+	r.responses.invalidate(form.Key())
+	r.Audit().Record(req.Context(), audit.ActionFormAction, form.Key(), map[string]interface{}{
+		"action": action.Name,
+	})
+	r.sendResult(w, req, result, warnings)
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/koteyye/go-formist/auth"
+)
+
+// APIKeyHeader заголовок с API ключом
+const APIKeyHeader = "X-API-Key"
+
+// apiKey представляет API ключ с хешем значения
+type apiKey struct {
+	name        string
+	hash        [sha256.Size]byte
+	permissions []string
+}
+
+// AddAPIKey добавляет API ключ (например, для CI/CD) с указанными разрешениями.
+// Хранится только хеш ключа
+func (r *Router) AddAPIKey(name, key string, permissions ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.apiKeys = append(r.apiKeys, apiKey{
+		name:        name,
+		hash:        sha256.Sum256([]byte(key)),
+		permissions: append([]string(nil), permissions...),
+	})
+}
+
+// apiKeyUser возвращает пользователя для API ключа из запроса
+func (r *Router) apiKeyUser(req *http.Request) (*auth.User, bool) {
+	key := req.Header.Get(APIKeyHeader)
+	if key == "" {
+		key, _ = strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	}
+	if key == "" {
+		return nil, false
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], k.hash[:]) == 1 {
+			return &auth.User{
+				ID:          "apikey:" + k.name,
+				Name:        k.name,
+				Permissions: k.permissions,
+			}, true
+		}
+	}
+	return nil, false
+}
+
+// requirePermission пропускает изменяющие запросы только пользователям с разрешением.
+// Проверка действует, если включена авторизация или настроены API ключи
+func (r *Router) requirePermission(permission string) func(http.Handler) http.Handler {
+	return r.permissionGuard(permission, false)
+}
+
+// requireReadPermission как requirePermission, но проверяет и читающие запросы
+func (r *Router) requireReadPermission(permission string) func(http.Handler) http.Handler {
+	return r.permissionGuard(permission, true)
+}
+
+// permissionGuard проверяет разрешение пользователя; без reads читающие запросы пропускаются
+func (r *Router) permissionGuard(permission string, reads bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.Method {
+			case http.MethodOptions:
+				next.ServeHTTP(w, req)
+				return
+			case http.MethodGet, http.MethodHead:
+				if !reads {
+					next.ServeHTTP(w, req)
+					return
+				}
+			}
+
+			if user, ok := r.apiKeyUser(req); ok {
+				req = req.WithContext(auth.WithUser(req.Context(), user))
+			}
+
+			r.mu.RLock()
+			enforced := r.authEnabled || len(r.apiKeys) > 0
+			r.mu.RUnlock()
+
+			if !enforced {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			user, ok := auth.UserFromContext(req.Context())
+			if !ok {
+				r.sendError(w, http.StatusUnauthorized, "Требуется авторизация")
+				return
+			}
+			if !user.HasPermission(permission) {
+				r.sendError(w, http.StatusForbidden, "Недостаточно прав: требуется "+permission)
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
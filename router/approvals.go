@@ -0,0 +1,197 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+	"github.com/koteyye/go-formist/workflow"
+)
+
+// approvalDecision представляет тело запроса решения по заявке
+type approvalDecision struct {
+	Comment string `json:"comment"`
+}
+
+// SetWorkflow устанавливает движок согласования отправок
+func (r *Router) SetWorkflow(engine *workflow.Engine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.workflow = engine
+}
+
+// Workflow возвращает движок согласования отправок
+func (r *Router) Workflow() *workflow.Engine {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.workflow
+}
+
+// submitForApproval ставит отправку формы в очередь согласования
+func (r *Router) submitForApproval(w http.ResponseWriter, req *http.Request, form *types.Form, data map[string]interface{}, warnings []types.ValidationWarning) {
+	submission, err := r.Workflow().Submit(req.Context(), form.Key(), data, form.Approval.Roles)
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindStorage, form.Key(), "submitApproval")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка создания заявки: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	r.encodeJSON(w, types.APIResponse{
+		Success: true,
+		Data:    submission,
+		Message: "Заявка отправлена на согласование",
+		Meta:    warningsMeta(warnings),
+	})
+}
+
+// handleApprovalsList возвращает заявки, по умолчанию ожидающие решения
+func (r *Router) handleApprovalsList(w http.ResponseWriter, req *http.Request) {
+	status := workflow.Status(req.URL.Query().Get("status"))
+	if status == "" {
+		status = workflow.StatusPending
+	} else if status == "all" {
+		status = ""
+	}
+
+	items, err := r.Workflow().List(req.Context(), status)
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindStorage, "", "listApprovals")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения заявок: %v", err))
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    items,
+	})
+}
+
+// handleApprovalGet возвращает заявку по ID
+func (r *Router) handleApprovalGet(w http.ResponseWriter, req *http.Request) {
+	submission, err := r.Workflow().Get(req.Context(), chi.URLParam(req, "id"))
+	if err != nil {
+		r.sendWorkflowError(w, err)
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    submission,
+	})
+}
+
+// handleApprovalApprove одобряет заявку и выполняет OnPost формы
+func (r *Router) handleApprovalApprove(w http.ResponseWriter, req *http.Request) {
+	decision, ok := r.decodeDecision(w, req)
+	if !ok {
+		return
+	}
+
+	submission, err := r.Workflow().Approve(req.Context(), chi.URLParam(req, "id"), decision.Comment, r.executeSubmission)
+	if err != nil {
+		r.sendWorkflowError(w, err)
+		return
+	}
+	r.auditDecision(req, audit.ActionApprovalApprove, submission)
+
+	r.sendJSON(w, types.APIResponse{
+		Success: submission.Status == workflow.StatusApproved,
+		Data:    submission,
+		Error:   submission.Error,
+	})
+}
+
+// handleApprovalReject отклоняет заявку
+func (r *Router) handleApprovalReject(w http.ResponseWriter, req *http.Request) {
+	decision, ok := r.decodeDecision(w, req)
+	if !ok {
+		return
+	}
+
+	submission, err := r.Workflow().Reject(req.Context(), chi.URLParam(req, "id"), decision.Comment)
+	if err != nil {
+		r.sendWorkflowError(w, err)
+		return
+	}
+	r.auditDecision(req, audit.ActionApprovalReject, submission)
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    submission,
+	})
+}
+
+// executeSubmission выполняет OnPost формы одобренной заявки
+// или применяет план импорта конфигурации
+func (r *Router) executeSubmission(ctx context.Context, submission *workflow.Submission) (interface{}, error) {
+	if submission.Form == ConfigApplyForm {
+		return r.executeConfigApply(ctx, submission)
+	}
+
+	form, exists := r.lookupForm(submission.Form)
+	if !exists || form.OnPost == nil {
+		return nil, fmt.Errorf("форма %s недоступна", submission.Form)
+	}
+
+	result, err := r.callFormHandler(ctx, form, func(ctx context.Context) (interface{}, error) {
+		return form.OnPost(ctx, submission.Data)
+	})
+	if err != nil {
+		r.ReportError(ctx, err, reporting.Meta{
+			Kind:  reporting.KindHandler,
+			Form:  form.Key(),
+			Op:    "onPost",
+			Extra: map[string]interface{}{"submission": submission.ID},
+		})
+	}
+
+	// Согласованное действие не отменяется через окно отмены
+	return unwrapUndoable(result), err
+}
+
+// auditDecision записывает решение по заявке в журнал аудита
+func (r *Router) auditDecision(req *http.Request, action string, submission *workflow.Submission) {
+	r.Audit().Record(req.Context(), action, submission.Form, map[string]interface{}{
+		"submission": submission.ID,
+		"status":     string(submission.Status),
+		"comment":    submission.Comment,
+	})
+}
+
+// decodeDecision разбирает необязательный комментарий к решению
+func (r *Router) decodeDecision(w http.ResponseWriter, req *http.Request) (approvalDecision, bool) {
+	var decision approvalDecision
+	if req.ContentLength == 0 {
+		return decision, true
+	}
+
+	if err := r.decodeJSON(req.Body, &decision); err != nil {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return decision, false
+	}
+	return decision, true
+}
+
+// sendWorkflowError отправляет ошибку workflow с соответствующим статусом
+func (r *Router) sendWorkflowError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, workflow.ErrNotFound):
+		r.sendError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, workflow.ErrAlreadyDecided):
+		r.sendError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, workflow.ErrForbidden), errors.Is(err, workflow.ErrSelfApproval):
+		r.sendError(w, http.StatusForbidden, err.Error())
+	default:
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+	}
+}
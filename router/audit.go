@@ -0,0 +1,99 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/types"
+)
+
+// SetAudit устанавливает журнал аудита
+func (r *Router) SetAudit(log *audit.Log) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.audit = log
+}
+
+// Audit возвращает журнал аудита (nil, если он не подключен)
+func (r *Router) Audit() *audit.Log {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.audit
+}
+
+// handleAuditExport экспортирует журнал аудита в формате ?format=jsonl|csv
+func (r *Router) handleAuditExport(w http.ResponseWriter, req *http.Request) {
+	log := r.Audit()
+	if log == nil {
+		r.sendError(w, http.StatusNotImplemented, "Журнал аудита не подключен")
+		return
+	}
+
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = audit.FormatJSONL
+	}
+
+	switch format {
+	case audit.FormatJSONL:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	case audit.FormatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	default:
+		r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Неподдерживаемый формат: %s", format))
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="audit.%s"`, format))
+	audit.Export(w, log.Entries(), format)
+}
+
+// handleAuditVerify проверяет цепочку журнала аудита.
+// Без тела проверяется текущий журнал, иначе - переданный экспорт (JSONL или CSV по Content-Type)
+func (r *Router) handleAuditVerify(w http.ResponseWriter, req *http.Request) {
+	log := r.Audit()
+	if log == nil {
+		r.sendError(w, http.StatusNotImplemented, "Журнал аудита не подключен")
+		return
+	}
+
+	var err error
+	count := 0
+
+	if req.Method == http.MethodGet || req.ContentLength == 0 {
+		entries := log.Entries()
+		count = len(entries)
+		err = log.Verify()
+	} else {
+		format := audit.FormatJSONL
+		if strings.Contains(req.Header.Get("Content-Type"), "csv") {
+			format = audit.FormatCSV
+		}
+
+		entries, readErr := audit.Read(req.Body, format)
+		if readErr != nil {
+			r.sendError(w, http.StatusBadRequest, readErr.Error())
+			return
+		}
+		count = len(entries)
+		err = log.VerifyEntries(entries)
+	}
+
+	if err != nil {
+		r.sendJSON(w, types.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+			Data:    map[string]interface{}{"valid": false, "entries": count},
+		})
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    map[string]interface{}{"valid": true, "entries": count},
+	})
+}
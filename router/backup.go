@@ -0,0 +1,104 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/backup"
+	"github.com/koteyye/go-formist/types"
+)
+
+// MaxRestoreSize максимальный размер архива при восстановлении
+const MaxRestoreSize = 512 << 20
+
+// SetBackup включает выгрузку и восстановление резервных копий.
+// sources вызывается при каждом запросе и возвращает текущие хранилища
+func (r *Router) SetBackup(sources func() backup.Sources) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.backup = sources
+}
+
+// backupSources возвращает хранилища резервной копии (false, если она не включена)
+func (r *Router) backupSources() (backup.Sources, bool) {
+	r.mu.RLock()
+	sources := r.backup
+	r.mu.RUnlock()
+
+	if sources == nil {
+		return backup.Sources{}, false
+	}
+	return sources(), true
+}
+
+// handleBackup выгружает резервную копию архивом tar.gz
+func (r *Router) handleBackup(w http.ResponseWriter, req *http.Request) {
+	sources, ok := r.backupSources()
+	if !ok {
+		r.sendError(w, http.StatusNotImplemented, "Резервное копирование не подключено")
+		return
+	}
+
+	// Хранилища читаются до записи архива, поэтому ошибка чтения возвращается обычным ответом
+	name := fmt.Sprintf("formist-backup-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+
+	manifest, err := backup.Write(req.Context(), w, sources)
+	if err != nil {
+		w.Header().Del("Content-Disposition")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка резервного копирования: %v", err))
+		return
+	}
+
+	r.Audit().Record(req.Context(), audit.ActionBackupCreate, "", map[string]interface{}{
+		"routes":      manifest.Routes,
+		"submissions": manifest.Submissions,
+		"audit":       manifest.Audit,
+	})
+}
+
+// handleRestore восстанавливает данные из архива в теле запроса;
+// с ?dry_run=true архив только проверяется
+func (r *Router) handleRestore(w http.ResponseWriter, req *http.Request) {
+	sources, ok := r.backupSources()
+	if !ok {
+		r.sendError(w, http.StatusNotImplemented, "Резервное копирование не подключено")
+		return
+	}
+
+	dryRun := isDryRunRequest(req)
+	body := http.MaxBytesReader(w, req.Body, MaxRestoreSize)
+	manifest, err := backup.Restore(req.Context(), body, sources, dryRun)
+
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		r.sendError(w, http.StatusRequestEntityTooLarge, "Архив слишком большой")
+		return
+	case errors.Is(err, backup.ErrInvalidArchive), errors.Is(err, audit.ErrTampered):
+		r.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка восстановления: %v", err))
+		return
+	}
+
+	if !dryRun {
+		r.Audit().Record(req.Context(), audit.ActionBackupRestore, "", map[string]interface{}{
+			"createdAt":   manifest.CreatedAt,
+			"routes":      manifest.Routes,
+			"submissions": manifest.Submissions,
+			"audit":       manifest.Audit,
+		})
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    manifest,
+	})
+}
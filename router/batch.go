@@ -0,0 +1,278 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// MaxBatchSize максимальное количество элементов пакетной отправки
+const MaxBatchSize = 1000
+
+// errBatchRolledBack отмечает успешно обработанные элементы откатанного пакета
+var errBatchRolledBack = errors.New("изменения отменены откатом транзакции")
+
+// handleFormBatch принимает массив данных формы, проверяет каждый элемент и вызывает
+// OnPost для каждого корректного элемента. Если задан BatchTx, пакет применяется
+// в транзакции целиком: при ошибке любого элемента не применяется ни один
+func (r *Router) handleFormBatch(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+
+	dryRun := isDryRunRequest(req)
+	handler := form.OnPost
+	if dryRun {
+		if form.OnDryRun == nil {
+			r.sendError(w, http.StatusNotImplemented, "Пробный запуск не поддерживается для этой формы")
+			return
+		}
+		handler = form.OnDryRun
+	}
+	if handler == nil {
+		r.sendError(w, http.StatusMethodNotAllowed, "POST не поддерживается для этой формы")
+		return
+	}
+	if form.Approval != nil {
+		r.sendError(w, http.StatusMethodNotAllowed, "Пакетная отправка недоступна для формы с согласованием")
+		return
+	}
+
+	if err := r.validatorError(form.Key()); err != nil {
+		r.reportRequestError(req, err, reporting.KindValidation, form.Key(), "validator")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка конфигурации формы: %v", err))
+		return
+	}
+
+	var items []map[string]interface{}
+	if err := r.decodeJSON(req.Body, &items); err != nil {
+		r.sendError(w, http.StatusBadRequest, "Ожидается JSON массив данных формы")
+		return
+	}
+	if len(items) == 0 {
+		r.sendError(w, http.StatusBadRequest, "Пустой пакет")
+		return
+	}
+	if len(items) > MaxBatchSize {
+		r.sendError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Пакет превышает %d элементов", MaxBatchSize))
+		return
+	}
+
+	if r.accessLog != nil {
+		r.noteAccess(req, form, nil)
+	}
+
+	// Валидируем все элементы до вызова обработчика
+	result := types.BatchResult{Total: len(items), Items: make([]types.BatchItemResult, len(items))}
+	valid := make([]bool, len(items))
+	invalid, warned := 0, false
+	for i := range items {
+		item := &result.Items[i]
+		item.Index = i
+		if items[i] == nil {
+			items[i] = make(map[string]interface{})
+		}
+
+		err := r.normalizeFormData(req, form, items[i])
+		if err == nil {
+			err = r.validateFormData(req.Context(), form, items[i])
+		}
+		if err != nil {
+			item.Error = fmt.Sprintf("Ошибка валидации: %v", err)
+			invalid++
+			continue
+		}
+
+		item.Warnings = r.formWarnings(form, items[i])
+		warned = warned || len(item.Warnings) > 0
+		valid[i] = true
+	}
+
+	atomic := form.BatchTx != nil && !dryRun
+	if atomic && invalid > 0 {
+		r.sendBatchResult(w, http.StatusBadRequest, &result, "Пакет не применен: есть элементы с ошибками")
+		return
+	}
+	if warned && form.ConfirmWarnings && !dryRun && !warningsConfirmed(req) {
+		r.sendBatchResult(w, http.StatusConflict, &result, "Требуется подтверждение предупреждений")
+		return
+	}
+
+	ctx := req.Context()
+	release := func(int) {}
+	if dryRun {
+		ctx = types.WithDryRun(ctx)
+	} else {
+		// Каждый корректный элемент расходует квоту отправок как одиночная отправка
+		var ok bool
+		if release, ok = r.reserveBatch(w, req, form.Key(), len(items)-invalid); !ok {
+			return
+		}
+	}
+	// Обработчик может продолжить работу после ухода клиента, поэтому квоту несохраненных
+	// элементов возвращает он сам по завершении. Если обработчик не был запущен
+	// (перегрузка, отмена в очереди), квоту всего пакета возвращает запрос
+	var claim sync.Once
+	claimed := func() (first bool) {
+		claim.Do(func() { first = true })
+		return first
+	}
+	defer func() {
+		if claimed() {
+			release(len(items) - invalid)
+		}
+	}()
+
+	var itemErr error
+	_, err := r.callFormHandler(ctx, form, func(ctx context.Context) (_ interface{}, err error) {
+		if !claimed() {
+			return nil, ctx.Err()
+		}
+		defer func() {
+			release(len(items) - invalid - savedItems(&result, err, atomic))
+		}()
+
+		run := func(ctx context.Context) error {
+			itemErr = r.runBatch(ctx, req, form, handler, items, valid, &result, atomic)
+			return itemErr
+		}
+		if atomic {
+			return nil, form.BatchTx(ctx, run)
+		}
+		return nil, run(ctx)
+	})
+	if aborted(req) {
+		return
+	}
+	if errors.Is(err, errOverloaded) {
+		r.sendOverloaded(w)
+		return
+	}
+
+	if err != nil {
+		if err != itemErr {
+			r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "batchTx")
+		}
+		result.RolledBack = true
+		for i := range result.Items {
+			if result.Items[i].Success {
+				result.Items[i].Success = false
+				result.Items[i].Data = nil
+				result.Items[i].Error = errBatchRolledBack.Error()
+			}
+		}
+	}
+
+	r.countBatch(&result)
+	if !dryRun {
+		r.responses.invalidate(form.Key())
+		r.Audit().Record(req.Context(), audit.ActionFormBatch, form.Key(), map[string]interface{}{
+			"total":      result.Total,
+			"succeeded":  result.Succeeded,
+			"failed":     result.Failed,
+			"rolledBack": result.RolledBack,
+		})
+	}
+
+	message := ""
+	if dryRun {
+		message = "Пробный запуск: изменения не сохранены"
+	}
+	r.sendJSON(w, types.APIResponse{
+		Success: result.Failed == 0,
+		Data:    result,
+		Message: message,
+	})
+}
+
+// runBatch последовательно обрабатывает корректные элементы пакета.
+// В атомарном режиме останавливается на первой ошибке и возвращает ее
+func (r *Router) runBatch(ctx context.Context, req *http.Request, form *types.Form, handler types.FormHandler,
+	items []map[string]interface{}, valid []bool, result *types.BatchResult, atomic bool) error {
+	for i := range items {
+		if !valid[i] {
+			continue
+		}
+
+		item := &result.Items[i]
+		if err := ctx.Err(); err != nil {
+			item.Error = "Запрос отменен"
+			if atomic {
+				return err
+			}
+			continue
+		}
+
+		data, err := callBatchItem(ctx, handler, items[i])
+		if err != nil {
+			r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "batch")
+			item.Error = fmt.Sprintf("Ошибка обработки: %v", err)
+			if atomic {
+				return err
+			}
+			continue
+		}
+
+		item.Success = true
+		item.Data = unwrapUndoable(data)
+	}
+	return nil
+}
+
+// savedItems возвращает число сохраненных элементов пакета: при ошибке атомарного
+// пакета транзакция откатывается целиком
+func savedItems(result *types.BatchResult, err error, atomic bool) int {
+	if err != nil && atomic {
+		return 0
+	}
+	saved := 0
+	for _, item := range result.Items {
+		if item.Success {
+			saved++
+		}
+	}
+	return saved
+}
+
+// callBatchItem вызывает обработчик для элемента пакета, превращая panic в ошибку
+func callBatchItem(ctx context.Context, handler types.FormHandler, data map[string]interface{}) (result interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = panicError(p)
+		}
+	}()
+	return handler(ctx, data)
+}
+
+// countBatch подсчитывает успешные и неуспешные элементы пакета
+func (r *Router) countBatch(result *types.BatchResult) {
+	result.Succeeded, result.Failed = 0, 0
+	for _, item := range result.Items {
+		if item.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+}
+
+// sendBatchResult отправляет результат пакета, который не был применен
+func (r *Router) sendBatchResult(w http.ResponseWriter, status int, result *types.BatchResult, message string) {
+	r.countBatch(result)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	r.encodeJSON(w, types.APIResponse{
+		Success: false,
+		Data:    result,
+		Error:   message,
+	})
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// TestBatchQuotaAfterDisconnect проверяет, что элементы, сохраненные после ухода клиента,
+// расходуют квоту отправок, а не сохраненные - возвращают ее
+func TestBatchQuotaAfterDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	calls := 0
+
+	r := NewRouter()
+	r.SetQuotas(&types.Quotas{SubmissionsPerDay: 10}, nil)
+	if err := r.RegisterForm(&types.Form{
+		Name:   "orders",
+		Title:  "Заказы",
+		Fields: []types.Field{{Name: "title", Type: types.FieldTypeText, Label: "Название"}},
+		OnPost: func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
+			calls++
+			if calls == 2 {
+				// Клиент уходит, пока второй элемент сохраняется
+				cancel()
+				<-release
+			}
+			return data, nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/forms/orders/batch",
+		strings.NewReader(`[{"title":"a"},{"title":"b"},{"title":"c"}]`)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	close(release)
+
+	// Первые два элемента сохранены, третий пропущен после отмены
+	deadline := time.Now().Add(time.Second)
+	for {
+		usage := r.QuotaStats().Usage["192.0.2.1"].Submissions["orders"]
+		if usage == 2 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("израсходовано отправок: %d, ожидалось 2", usage)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
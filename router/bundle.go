@@ -0,0 +1,257 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/bundle"
+	"github.com/koteyye/go-formist/id"
+	"github.com/koteyye/go-formist/types"
+	"github.com/koteyye/go-formist/workflow"
+)
+
+const (
+	// ConfigApplyForm имя заявки на согласование применения плана импорта
+	ConfigApplyForm = "config.apply"
+
+	// ConfigPlanTTL время, в течение которого план импорта можно применить
+	ConfigPlanTTL = 24 * time.Hour
+
+	// MaxBundleSize максимальный размер пакета конфигурации
+	MaxBundleSize = 16 << 20
+)
+
+// errPlanNotFound возвращается для неизвестного или просроченного плана
+var errPlanNotFound = errors.New("план не найден или истек")
+
+// configImport импорт пакетов конфигурации и построенные планы
+type configImport struct {
+	sources func() bundle.Sources
+	roles   []string // роли согласующих применение, пусто - без согласования
+
+	mu    sync.Mutex
+	plans map[string]*bundle.Plan
+}
+
+// SetConfigImport включает выгрузку и импорт пакетов конфигурации через /api/config.
+// sources вызывается при каждом запросе; с approvalRoles применение плана
+// проходит согласование пользователем с одной из ролей
+func (r *Router) SetConfigImport(sources func() bundle.Sources, approvalRoles ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.configImport = &configImport{
+		sources: sources,
+		roles:   slices.Clone(approvalRoles),
+		plans:   make(map[string]*bundle.Plan),
+	}
+}
+
+// configImporter возвращает импорт конфигурации или отправляет 501, если он не включен
+func (r *Router) configImporter(w http.ResponseWriter) (*configImport, bool) {
+	r.mu.RLock()
+	ci := r.configImport
+	r.mu.RUnlock()
+
+	if ci == nil {
+		r.sendError(w, http.StatusNotImplemented, "Импорт конфигурации не подключен")
+		return nil, false
+	}
+	return ci, true
+}
+
+// plan возвращает непросроченный план по ID
+func (c *configImport) plan(id string) (*bundle.Plan, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for planID, plan := range c.plans {
+		if now.After(plan.ExpiresAt) {
+			delete(c.plans, planID)
+		}
+	}
+	plan, ok := c.plans[id]
+	return plan, ok
+}
+
+// handleConfigExport выгружает текущую конфигурацию пакетом
+func (r *Router) handleConfigExport(w http.ResponseWriter, req *http.Request) {
+	ci, ok := r.configImporter(w)
+	if !ok {
+		return
+	}
+
+	b, err := bundle.Export(req.Context(), ci.sources())
+	if err != nil {
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка выгрузки конфигурации: %v", err))
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    b,
+	})
+}
+
+// handleConfigPlan сравнивает пакет из тела запроса с текущей конфигурацией и сохраняет план
+func (r *Router) handleConfigPlan(w http.ResponseWriter, req *http.Request) {
+	ci, ok := r.configImporter(w)
+	if !ok {
+		return
+	}
+
+	var b bundle.Bundle
+	if err := r.decodeJSON(http.MaxBytesReader(w, req.Body, MaxBundleSize), &b); err != nil {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return
+	}
+
+	plan, err := bundle.NewPlan(req.Context(), ci.sources(), &b)
+	switch {
+	case errors.Is(err, bundle.ErrInvalid):
+		r.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка построения плана: %v", err))
+		return
+	}
+
+	plan.ID = id.New()
+	plan.ExpiresAt = plan.CreatedAt.Add(ConfigPlanTTL)
+	if !plan.Empty() {
+		ci.mu.Lock()
+		ci.plans[plan.ID] = plan
+		ci.mu.Unlock()
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    plan,
+	})
+}
+
+// handleConfigPlanGet возвращает сохраненный план
+func (r *Router) handleConfigPlanGet(w http.ResponseWriter, req *http.Request) {
+	ci, ok := r.configImporter(w)
+	if !ok {
+		return
+	}
+
+	plan, ok := ci.plan(chi.URLParam(req, "id"))
+	if !ok {
+		r.sendError(w, http.StatusNotFound, errPlanNotFound.Error())
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    plan,
+	})
+}
+
+// handleConfigApply применяет план. Если включено согласование,
+// создает заявку, а план применяется после ее одобрения
+func (r *Router) handleConfigApply(w http.ResponseWriter, req *http.Request) {
+	ci, ok := r.configImporter(w)
+	if !ok {
+		return
+	}
+
+	planID := chi.URLParam(req, "id")
+	plan, ok := ci.plan(planID)
+	if !ok {
+		r.sendError(w, http.StatusNotFound, errPlanNotFound.Error())
+		return
+	}
+
+	if len(ci.roles) > 0 {
+		submission, err := r.Workflow().Submit(req.Context(), ConfigApplyForm, map[string]interface{}{
+			"plan":        plan.ID,
+			"summary":     plan.Summary,
+			"destructive": plan.Destructive,
+		}, ci.roles)
+		if err != nil {
+			r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка создания заявки: %v", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		r.encodeJSON(w, types.APIResponse{
+			Success: true,
+			Data:    submission,
+			Message: "Применение плана отправлено на согласование",
+		})
+		return
+	}
+
+	if err := r.applyConfigPlan(req.Context(), planID); err != nil {
+		r.sendConfigError(w, err)
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    plan,
+		Message: "План применен",
+	})
+}
+
+// applyConfigPlan применяет план и удаляет его. Каждое применение записывается в журнал аудита
+func (r *Router) applyConfigPlan(ctx context.Context, planID string) error {
+	r.mu.RLock()
+	ci := r.configImport
+	r.mu.RUnlock()
+	if ci == nil {
+		return errors.New("импорт конфигурации не подключен")
+	}
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	plan, ok := ci.plans[planID]
+	if !ok || time.Now().After(plan.ExpiresAt) {
+		return errPlanNotFound
+	}
+	if err := bundle.Apply(ctx, ci.sources(), plan); err != nil {
+		return err
+	}
+	delete(ci.plans, planID)
+
+	r.Audit().Record(ctx, audit.ActionConfigApply, plan.ID, map[string]interface{}{
+		"create": plan.Summary.Create,
+		"update": plan.Summary.Update,
+		"delete": plan.Summary.Delete,
+	})
+	return nil
+}
+
+// executeConfigApply применяет план одобренной заявки
+func (r *Router) executeConfigApply(ctx context.Context, submission *workflow.Submission) (interface{}, error) {
+	planID, _ := submission.Data["plan"].(string)
+	if err := r.applyConfigPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"plan": planID}, nil
+}
+
+// sendConfigError отправляет ошибку применения плана с соответствующим статусом
+func (r *Router) sendConfigError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errPlanNotFound):
+		r.sendError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, bundle.ErrStale):
+		r.sendError(w, http.StatusConflict, err.Error())
+	default:
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка применения плана: %v", err))
+	}
+}
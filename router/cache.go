@@ -0,0 +1,132 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/types"
+)
+
+// MaxCachedResponses максимальное число данных в кеше роутера
+const MaxCachedResponses = 1024
+
+// cacheEntry данные обработчика чтения в кеше роутера
+type cacheEntry struct {
+	result    *types.CachedResult
+	expiresAt time.Time
+}
+
+// responseCache хранит данные обработчиков чтения с политикой Shared
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// newResponseCache создает кеш роутера
+func newResponseCache() *responseCache {
+	return &responseCache{
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// get возвращает актуальные данные: сначала копию пользователя, затем общую
+func (c *responseCache) get(key, userID string) (*types.CachedResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, k := range []string{userCacheKey(key, userID), key} {
+		if entry, ok := c.entries[k]; ok && now.Before(entry.expiresAt) {
+			return entry.result, true
+		}
+	}
+	return nil, false
+}
+
+// put сохраняет данные до истечения MaxAge политики.
+// Если кеш заполнен и устаревших данных нет, данные не сохраняются
+func (c *responseCache) put(key, userID string, result *types.CachedResult) {
+	if result.Policy.MaxAge <= 0 {
+		return
+	}
+	if result.Policy.VaryByUser {
+		key = userCacheKey(key, userID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= MaxCachedResponses {
+		c.purge()
+		if len(c.entries) >= MaxCachedResponses {
+			return
+		}
+	}
+	c.entries[key] = &cacheEntry{
+		result:    result,
+		expiresAt: time.Now().Add(result.Policy.MaxAge),
+	}
+}
+
+// invalidate удаляет данные формы (OnGet и таблиц)
+func (c *responseCache) invalidate(formKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, "get:"+formKey+"#") || key == "get:"+formKey ||
+			strings.HasPrefix(key, "table:"+formKey+"/") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// purge удаляет устаревшие данные. Вызывается под блокировкой
+func (c *responseCache) purge() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// userCacheKey ключ копии данных пользователя
+func userCacheKey(key, userID string) string {
+	return key + "#" + userID
+}
+
+// requestUserID возвращает ID пользователя запроса или пустую строку
+func requestUserID(req *http.Request) string {
+	if user, ok := auth.UserFromContext(req.Context()); ok {
+		return user.ID
+	}
+	return ""
+}
+
+// cachedRead вызывает обработчик чтения с учетом кеша роутера и объединения вызовов.
+// Возвращает данные и политику кеширования, если обработчик ее задал
+func (r *Router) cachedRead(req *http.Request, form *types.Form, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, *types.CachePolicy, error) {
+	userID := requestUserID(req)
+	if cached, ok := r.responses.get(key, userID); ok {
+		return cached.Data, &cached.Policy, nil
+	}
+
+	data, err := callHandler(req.Context(), r.coalesced(form, key, userID, fn))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cached, ok := data.(*types.CachedResult)
+	if !ok || cached == nil {
+		return data, nil, nil
+	}
+	if cached.Policy.Shared {
+		r.responses.put(key, userID, cached)
+	}
+	return cached.Data, &cached.Policy, nil
+}
@@ -0,0 +1,111 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/form"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// cellUpdate тело запроса изменения ячейки
+type cellUpdate struct {
+	Value interface{} `json:"value"`
+}
+
+// handleTableCellUpdate проверяет значение ячейки по колонке и передает его в OnRowUpdate.
+// {id} - ключ строки по RowKey таблицы.
+// С ?dry_run=true только проверяет значение (мгновенная проверка при вводе)
+func (r *Router) handleTableCellUpdate(w http.ResponseWriter, req *http.Request) {
+	f, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+
+	fieldName := chi.URLParam(req, "field")
+	config := tableConfig(f, fieldName)
+	if config == nil {
+		r.sendError(w, http.StatusNotFound, "Таблица не найдена")
+		return
+	}
+
+	dryRun := isDryRunRequest(req)
+	if !config.Editable || (config.OnRowUpdate == nil && !dryRun) {
+		r.sendError(w, http.StatusMethodNotAllowed, "Редактирование не поддерживается для этой таблицы")
+		return
+	}
+
+	key := chi.URLParam(req, "key")
+	var column *types.TableColumn
+	for i := range config.Columns {
+		if config.Columns[i].Key == key {
+			column = &config.Columns[i]
+			break
+		}
+	}
+	if column == nil {
+		r.sendError(w, http.StatusNotFound, "Колонка не найдена")
+		return
+	}
+
+	id := chi.URLParam(req, "id")
+	if _, err := config.RowKey.Parse(id); err != nil {
+		r.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var update cellUpdate
+	if err := r.decodeJSON(req.Body, &update); err != nil {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return
+	}
+
+	if r.accessLog != nil {
+		r.noteAccess(req, f, map[string]interface{}{key: update.Value})
+	}
+
+	if err := form.ValidateCell(column, update.Value); err != nil {
+		r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Ошибка валидации: %v", err))
+		return
+	}
+
+	if dryRun {
+		r.sendJSON(w, types.APIResponse{
+			Success: true,
+			Data:    update.Value,
+			Message: "Пробный запуск: изменения не сохранены",
+		})
+		return
+	}
+
+	result, err := r.callFormHandler(req.Context(), f, func(ctx context.Context) (interface{}, error) {
+		return config.OnRowUpdate(ctx, id, key, update.Value)
+	})
+	if aborted(req) {
+		return
+	}
+	if errors.Is(err, errOverloaded) {
+		r.sendOverloaded(w)
+		return
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, f.Key(), "rowUpdate:"+fieldName)
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка обработки: %v", err))
+		return
+	}
+
+	r.responses.invalidate(f.Key())
+	r.Audit().Record(req.Context(), audit.ActionTableCellUpdate, f.Key(), map[string]interface{}{
+		"table":  fieldName,
+		"row":    id,
+		"column": key,
+	})
+	r.sendResult(w, req, result, nil)
+}
@@ -0,0 +1,43 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/koteyye/go-formist/chaos"
+)
+
+// SetChaos подключает внедрение задержек и ошибок. nil отключает его.
+// В окружении production правила не применяются
+func (r *Router) SetChaos(injector *chaos.Injector) {
+	r.chaos = injector
+	r.rebuild()
+}
+
+// isProduction сообщает, что админка работает в продакшен окружении
+func (r *Router) isProduction() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.environment == nil {
+		return false
+	}
+	name := strings.ToLower(r.environment.Name)
+	return name == "production" || name == "prod"
+}
+
+// chaosMiddleware применяет правила внедрения сбоев к путям без префикса
+func (r *Router) chaosMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.isProduction() {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		rule, ok := r.chaos.Match(req.Method, strings.TrimPrefix(req.URL.Path, r.prefix))
+		if ok && !r.chaos.Serve(w, req, rule) {
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
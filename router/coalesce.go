@@ -0,0 +1,59 @@
+package router
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// flightResult результат общего вызова и пользователь, в контексте которого он выполнен
+type flightResult struct {
+	data   interface{}
+	userID string
+}
+
+// coalesced объединяет одновременные вызовы fn с одинаковым key, если форма это разрешает.
+// Общий вызов выполняется без отмены контекста первого запроса, чтобы уход одного
+// клиента не прерывал загрузку для остальных. Если общий вызов вернул данные с политикой
+// VaryByUser для другого пользователя, fn вызывается повторно в контексте запроса
+func (r *Router) coalesced(form *types.Form, key, userID string, fn func(ctx context.Context) (interface{}, error)) func(ctx context.Context) (interface{}, error) {
+	if !form.Coalesce {
+		return fn
+	}
+
+	return func(ctx context.Context) (interface{}, error) {
+		result, err, _ := r.flights.Do(key, func() (interface{}, error) {
+			data, err := fn(context.WithoutCancel(ctx))
+			return flightResult{data: data, userID: userID}, err
+		})
+		flight := result.(flightResult)
+		if cached, ok := flight.data.(*types.CachedResult); ok && cached != nil &&
+			cached.Policy.VaryByUser && flight.userID != userID {
+			return fn(ctx)
+		}
+		return flight.data, err
+	}
+}
+
+// getFlightKey ключ объединения вызовов OnGet формы для записи record (пустой - новая запись)
+func getFlightKey(form *types.Form, record string) string {
+	if record == "" {
+		return "get:" + form.Key()
+	}
+	return "get:" + form.Key() + "?" + types.LinkParamDefault + "=" + url.QueryEscape(record)
+}
+
+// tableFlightKey ключ объединения вызовов обработчика таблицы с параметрами запроса
+func tableFlightKey(form *types.Form, field string, page, limit int, filters map[string]interface{}) string {
+	params := make(url.Values, len(filters)+2)
+	for key, value := range filters {
+		params.Set(key, value.(string))
+	}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+
+	// Encode сортирует параметры, поэтому порядок в запросе не влияет на ключ
+	return "table:" + form.Key() + "/" + field + "?" + params.Encode()
+}
@@ -0,0 +1,38 @@
+package router
+
+import (
+	"io"
+
+	"github.com/koteyye/go-formist/codec"
+)
+
+// codecBox хранит кодек в atomic.Value: у всех значений должен быть один тип
+type codecBox struct {
+	codec codec.Codec
+}
+
+// SetCodec устанавливает кодек JSON ответов и тел запросов. nil - encoding/json
+func (r *Router) SetCodec(c codec.Codec) {
+	if c == nil {
+		c = codec.Standard{}
+	}
+	r.codec.Store(codecBox{codec: c})
+}
+
+// Codec возвращает кодек JSON ответов и тел запросов
+func (r *Router) Codec() codec.Codec {
+	if box, ok := r.codec.Load().(codecBox); ok {
+		return box.codec
+	}
+	return codec.Standard{}
+}
+
+// encodeJSON записывает v в w кодеком роутера
+func (r *Router) encodeJSON(w io.Writer, v interface{}) error {
+	return codec.Encode(r.Codec(), w, v)
+}
+
+// decodeJSON декодирует тело запроса кодеком роутера. Пустое тело - io.EOF
+func (r *Router) decodeJSON(body io.Reader, v interface{}) error {
+	return codec.Decode(r.Codec(), body, v)
+}
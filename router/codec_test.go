@@ -0,0 +1,119 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	gojson "github.com/goccy/go-json"
+
+	"github.com/koteyye/go-formist/codec"
+	"github.com/koteyye/go-formist/types"
+)
+
+// goJSON подключаемый кодек на основе github.com/goccy/go-json
+type goJSON struct{}
+
+func (goJSON) Marshal(v interface{}) ([]byte, error)      { return gojson.Marshal(v) }
+func (goJSON) Unmarshal(data []byte, v interface{}) error { return gojson.Unmarshal(data, v) }
+
+// benchmarkCodecs кодеки для сравнения
+var benchmarkCodecs = []struct {
+	name  string
+	codec codec.Codec
+}{
+	{"encoding/json", codec.Standard{}},
+	{"go-json", goJSON{}},
+}
+
+// benchmarkTable ответ с данными таблицы из 100 строк
+func benchmarkTable() types.APIResponse {
+	data := types.TableData{
+		Columns: []types.TableColumn{
+			{Key: "id", Title: "ID", Type: types.FieldTypeText},
+			{Key: "customer", Title: "Клиент", Type: types.FieldTypeText},
+			{Key: "amount", Title: "Сумма", Type: types.FieldTypeNumber},
+			{Key: "status", Title: "Статус", Type: types.FieldTypeSelect},
+			{Key: "createdAt", Title: "Создан", Type: types.FieldTypeDateTime},
+		},
+		Total: 1000,
+		Page:  1,
+		Limit: 100,
+	}
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		data.Rows = append(data.Rows, map[string]interface{}{
+			"id":        fmt.Sprintf("order-%d", i),
+			"customer":  "ООО <Ромашка> & партнеры",
+			"amount":    float64(i) * 12.5,
+			"status":    "new",
+			"createdAt": created.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+		})
+	}
+	return types.APIResponse{Success: true, Data: data}
+}
+
+// TestCodecsCompatible проверяет, что подключаемый кодек дает тот же ответ, что encoding/json
+func TestCodecsCompatible(t *testing.T) {
+	response := benchmarkTable()
+
+	var want bytes.Buffer
+	if err := NewRouter().encodeJSON(&want, response); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouter()
+	r.SetCodec(goJSON{})
+	var got bytes.Buffer
+	if err := r.encodeJSON(&got, response); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("ответы кодеков различаются:\n%s\n%s", got.String(), want.String())
+	}
+}
+
+// BenchmarkEncodeJSON кодирует ответ с таблицей кодеком роутера
+func BenchmarkEncodeJSON(b *testing.B) {
+	response := benchmarkTable()
+
+	for _, c := range benchmarkCodecs {
+		b.Run(c.name, func(b *testing.B) {
+			r := NewRouter()
+			r.SetCodec(c.codec)
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if err := r.encodeJSON(io.Discard, response); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDecodeJSON декодирует тело запроса с таблицей кодеком роутера
+func BenchmarkDecodeJSON(b *testing.B) {
+	body, err := codec.Standard{}.Marshal(benchmarkTable())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, c := range benchmarkCodecs {
+		b.Run(c.name, func(b *testing.B) {
+			r := NewRouter()
+			r.SetCodec(c.codec)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+
+			for i := 0; i < b.N; i++ {
+				var data map[string]interface{}
+				if err := r.decodeJSON(bytes.NewReader(body), &data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/comments"
+	"github.com/koteyye/go-formist/types"
+)
+
+// commentRequest тело запроса создания комментария
+type commentRequest struct {
+	Form   string `json:"form"`
+	Record string `json:"record"`
+	Text   string `json:"text"`
+}
+
+// SetComments устанавливает сервис комментариев
+func (r *Router) SetComments(service *comments.Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.comments = service
+}
+
+// Comments возвращает сервис комментариев
+func (r *Router) Comments() *comments.Service {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.comments
+}
+
+// commentForm проверяет форму комментария и доступ к ней. При ошибке отправляет ответ
+func (r *Router) commentForm(w http.ResponseWriter, req *http.Request, key, record string) bool {
+	if key == "" || record == "" {
+		r.sendError(w, http.StatusBadRequest, "Не заданы форма и ID записи")
+		return false
+	}
+
+	form, exists := r.lookupForm(key)
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return false
+	}
+	if status, message := r.formAccess(req, form); status != http.StatusOK {
+		r.sendError(w, status, message)
+		return false
+	}
+	return true
+}
+
+// handleCommentsList возвращает комментарии записи (?form=ключ&record=ID)
+func (r *Router) handleCommentsList(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	key, record := query.Get("form"), query.Get("record")
+	if !r.commentForm(w, req, key, record) {
+		return
+	}
+
+	items, err := r.Comments().List(req.Context(), key, record)
+	if err != nil {
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    items,
+	})
+}
+
+// handleCommentCreate создает комментарий и уведомляет упомянутых пользователей
+func (r *Router) handleCommentCreate(w http.ResponseWriter, req *http.Request) {
+	var request commentRequest
+	if err := r.decodeJSON(req.Body, &request); err != nil {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return
+	}
+	if !r.commentForm(w, req, request.Form, request.Record) {
+		return
+	}
+
+	comment, err := r.Comments().Add(req.Context(), request.Form, request.Record, request.Text)
+	if err != nil {
+		r.sendCommentError(w, err)
+		return
+	}
+	r.Audit().Record(req.Context(), audit.ActionCommentCreate, comment.Form, map[string]interface{}{
+		"comment": comment.ID,
+		"record":  comment.Record,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	r.encodeJSON(w, types.APIResponse{
+		Success: true,
+		Data:    comment,
+	})
+}
+
+// handleCommentDelete удаляет комментарий. Чужие комментарии удаляет
+// только пользователь с разрешением auth.PermissionCommentsModerate
+func (r *Router) handleCommentDelete(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	moderator := !r.authEnabled && len(r.apiKeys) == 0
+	r.mu.RUnlock()
+	if user, ok := auth.UserFromContext(req.Context()); ok && user.HasPermission(auth.PermissionCommentsModerate) {
+		moderator = true
+	}
+
+	comment, err := r.Comments().Delete(req.Context(), chi.URLParam(req, "id"), moderator)
+	if err != nil {
+		r.sendCommentError(w, err)
+		return
+	}
+	r.Audit().Record(req.Context(), audit.ActionCommentDelete, comment.Form, map[string]interface{}{
+		"comment": comment.ID,
+		"record":  comment.Record,
+	})
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Message: "Комментарий удален",
+	})
+}
+
+// sendCommentError отправляет ошибку комментариев с соответствующим статусом
+func (r *Router) sendCommentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, comments.ErrNotFound):
+		r.sendError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, comments.ErrForbidden):
+		r.sendError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, comments.ErrEmpty), errors.Is(err, comments.ErrTooLong):
+		r.sendError(w, http.StatusBadRequest, err.Error())
+	default:
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+	}
+}
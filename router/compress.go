@@ -0,0 +1,226 @@
+package router
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// defaultCompressMinSize минимальный размер ответа для сжатия по умолчанию
+const defaultCompressMinSize = 1024
+
+// EnableCompression включает сжатие ответов gzip/deflate.
+// Ответы меньше minSize байт отправляются без сжатия
+func (r *Router) EnableCompression(enabled bool, minSize int) {
+	r.compressEnabled = enabled
+	if minSize <= 0 {
+		minSize = defaultCompressMinSize
+	}
+	r.compressMinSize = minSize
+	r.rebuild()
+}
+
+// compressMiddleware сжимает ответы в зависимости от Accept-Encoding клиента
+func compressMiddleware(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        minSize,
+				status:         http.StatusOK,
+			}
+			defer cw.Close()
+
+			w.Header().Add("Vary", "Accept-Encoding")
+			next.ServeHTTP(cw, req)
+		})
+	}
+}
+
+// incompressibleTypes типы содержимого, которые уже сжаты и повторно не сжимаются
+var incompressibleTypes = map[string]bool{
+	"application/octet-stream":     true,
+	"application/zip":              true,
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/x-bzip2":          true,
+	"application/x-xz":             true,
+	"application/x-7z-compressed":  true,
+	"application/x-rar-compressed": true,
+	"application/vnd.rar":          true,
+	"application/zstd":             true,
+	"font/woff":                    true,
+	"font/woff2":                   true,
+}
+
+// isIncompressible проверяет, что ответ с таким Content-Type сжимать бессмысленно:
+// изображения (кроме SVG), аудио, видео и архивы
+func isIncompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if mediaType == "image/svg+xml" {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "image/") || strings.HasPrefix(mediaType, "audio/") ||
+		strings.HasPrefix(mediaType, "video/") {
+		return true
+	}
+	return incompressibleTypes[mediaType]
+}
+
+// negotiateEncoding выбирает поддерживаемую кодировку из заголовка Accept-Encoding
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+			continue
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	switch {
+	case accepted["gzip"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressWriter буферизует начало ответа до достижения порога,
+// после чего передает данные в компрессор потоком
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	minSize     int
+	status      int
+	wroteHeader bool
+	buf         []byte
+	compressor  io.WriteCloser
+	passthrough bool
+}
+
+// WriteHeader запоминает статус до решения о сжатии
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+
+	// Ответы без тела, уже сжатые ответы и сжатое содержимое (изображения, архивы) не трогаем
+	if status == http.StatusNoContent || status == http.StatusNotModified ||
+		cw.Header().Get("Content-Encoding") != "" || isIncompressible(cw.Header().Get("Content-Type")) {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write пишет данные в буфер или компрессор
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(p)
+	}
+
+	if cw.compressor != nil {
+		return cw.compressor.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// startCompression отправляет заголовки и начинает потоковое сжатие
+func (cw *compressWriter) startCompression() error {
+	header := cw.Header()
+	header.Del("Content-Length")
+	header.Set("Content-Encoding", cw.encoding)
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if cw.encoding == "gzip" {
+		cw.compressor = gzip.NewWriter(cw.ResponseWriter)
+	} else {
+		compressor, err := flate.NewWriter(cw.ResponseWriter, flate.DefaultCompression)
+		if err != nil {
+			return err
+		}
+		cw.compressor = compressor
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+	_, err := cw.compressor.Write(buf)
+	return err
+}
+
+// Flush сбрасывает сжатые данные клиенту. После сброса заголовки уже отправлены,
+// поэтому сжатие начинается до него, даже если буфер меньше порога
+func (cw *compressWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(cw.status)
+	}
+	if cw.compressor == nil && !cw.passthrough {
+		if err := cw.startCompression(); err != nil {
+			return
+		}
+	}
+
+	if flusher, ok := cw.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController
+// (SetWriteDeadline при снятии профилей и т.п.)
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Close завершает ответ: маленькие ответы отправляются без сжатия
+func (cw *compressWriter) Close() error {
+	if cw.passthrough {
+		return nil
+	}
+
+	if cw.compressor != nil {
+		return cw.compressor.Close()
+	}
+
+	if !cw.wroteHeader {
+		return nil
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) > 0 {
+		_, err := cw.ResponseWriter.Write(cw.buf)
+		return err
+	}
+	return nil
+}
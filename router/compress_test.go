@@ -0,0 +1,72 @@
+package router
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestCompressFlushBeforeWrite проверяет, что ответ, сброшенный до первой записи,
+// отправляется со сжатием, согласованным с заголовками
+func TestCompressFlushBeforeWrite(t *testing.T) {
+	body := strings.Repeat("событие\n", 10)
+	handler := compressMiddleware(defaultCompressMinSize)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, body)
+		w.(http.Flusher).Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if encoding := w.Result().Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("Content-Encoding = %q, ожидался gzip", encoding)
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Errorf("тело ответа %q, ожидалось %q", got, body)
+	}
+}
+
+// TestCompressSkipsIncompressible проверяет, что уже сжатое содержимое отправляется как есть,
+// а http.ResponseController добирается до исходного ResponseWriter
+func TestCompressSkipsIncompressible(t *testing.T) {
+	body := strings.Repeat("\x00", 2*defaultCompressMinSize)
+	var unwrapped bool
+	handler := compressMiddleware(defaultCompressMinSize)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, unwrapped = w.(interface{ Unwrap() http.ResponseWriter })
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush через ResponseController: %v", err)
+		}
+		io.WriteString(w, body)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if !unwrapped {
+		t.Error("обертка сжатия не реализует Unwrap")
+	}
+	if encoding := w.Result().Header.Get("Content-Encoding"); encoding != "" {
+		t.Fatalf("Content-Encoding = %q, ожидался ответ без сжатия", encoding)
+	}
+	if w.Body.String() != body {
+		t.Errorf("тело ответа изменено: %d байт, ожидалось %d", w.Body.Len(), len(body))
+	}
+}
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag вычисляет слабый ETag по содержимому ответа
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// CheckNotModified устанавливает заголовки ETag и Last-Modified и отвечает 304,
+// если условия запроса выполнены. Возвращает true, если ответ уже отправлен
+func CheckNotModified(w http.ResponseWriter, req *http.Request, etag string, lastModified time.Time) bool {
+	header := w.Header()
+	if etag != "" {
+		header.Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		if etag != "" && etagMatches(match, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+		// If-None-Match имеет приоритет над If-Modified-Since
+		return false
+	}
+
+	if since := req.Header.Get("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		if err == nil && !lastModified.Truncate(time.Second).After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// etagMatches проверяет, содержит ли заголовок If-None-Match указанный ETag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/koteyye/go-formist/accesslog"
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/types"
+)
+
+const (
+	// DefaultDebugTTL время действия отладочного режима формы по умолчанию
+	DefaultDebugTTL = 15 * time.Minute
+
+	// MaxDebugTTL максимальное время действия отладочного режима формы
+	MaxDebugTTL = 24 * time.Hour
+)
+
+// debugRequest тело запроса включения отладочного режима
+type debugRequest struct {
+	Duration string `json:"duration"` // например "30m", по умолчанию DefaultDebugTTL
+}
+
+// SetDebugLog устанавливает журнал, в который пишутся тела запросов и ответов
+// форм в отладочном режиме. По умолчанию используется stderr
+func (r *Router) SetDebugLog(log *accesslog.DebugLogger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.debugLog = log
+}
+
+// EnableFormDebug включает отладочный режим формы на ttl (не более MaxDebugTTL)
+func (r *Router) EnableFormDebug(key string, ttl time.Duration, enabledBy string) types.FormDebug {
+	if ttl <= 0 {
+		ttl = DefaultDebugTTL
+	}
+	if ttl > MaxDebugTTL {
+		ttl = MaxDebugTTL
+	}
+
+	debug := types.FormDebug{
+		Form:      key,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+		EnabledBy: enabledBy,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.debugForms[key] = &debug
+	return debug
+}
+
+// DisableFormDebug выключает отладочный режим формы
+func (r *Router) DisableFormDebug(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.debugForms[key]
+	delete(r.debugForms, key)
+	return exists
+}
+
+// DebugForms возвращает формы с действующим отладочным режимом, удаляя истекшие
+func (r *Router) DebugForms() []types.FormDebug {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	items := make([]types.FormDebug, 0, len(r.debugForms))
+	for key, debug := range r.debugForms {
+		if !now.Before(debug.ExpiresAt) {
+			delete(r.debugForms, key)
+			continue
+		}
+		items = append(items, *debug)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Form < items[j].Form
+	})
+	return items
+}
+
+// debugLogger возвращает журнал отладки, если отладочный режим формы действует
+func (r *Router) debugLogger(key string) *accesslog.DebugLogger {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	debug, exists := r.debugForms[key]
+	if !exists || !time.Now().Before(debug.ExpiresAt) {
+		return nil
+	}
+	return r.debugLog
+}
+
+// debugWriter сохраняет копию ответа для отладочного журнала
+type debugWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+// WriteHeader запоминает статус ответа
+func (w *debugWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write копирует ответ, не превышая accesslog.MaxDebugPayload
+func (w *debugWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if room := accesslog.MaxDebugPayload - w.body.Len(); room < len(p) {
+		w.body.Write(p[:max(room, 0)])
+		w.truncated = true
+	} else {
+		w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// debugPayloads записывает тела запросов и ответов форм в отладочном режиме.
+// Подключается к маршрутам форм, поэтому ключ формы уже известен
+func (r *Router) debugPayloads(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := formKey(req)
+		log := r.debugLogger(key)
+		if log == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		start := time.Now()
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			r.sendError(w, http.StatusBadRequest, "Ошибка чтения тела запроса")
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		dw := &debugWriter{ResponseWriter: w}
+		defer func() {
+			form, _ := r.lookupForm(key)
+			if form == nil {
+				form = &types.Form{}
+			}
+
+			entry := accesslog.DebugEntry{
+				Time:      start.UTC(),
+				Form:      key,
+				Method:    req.Method,
+				Path:      req.URL.Path,
+				Status:    dw.status,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+				RequestID: middleware.GetReqID(req.Context()),
+				Truncated: dw.truncated || len(body) > accesslog.MaxDebugPayload,
+			}
+			if user, ok := auth.UserFromContext(req.Context()); ok {
+				entry.User = user.ID
+			}
+			if len(body) > accesslog.MaxDebugPayload {
+				body = body[:accesslog.MaxDebugPayload]
+			}
+			entry.Request = log.Payload(form, body)
+			entry.Response = log.Payload(form, dw.body.Bytes())
+			log.Write(entry)
+		}()
+
+		next.ServeHTTP(dw, req)
+	})
+}
+
+// handleDebugList возвращает формы с действующим отладочным режимом
+func (r *Router) handleDebugList(w http.ResponseWriter, req *http.Request) {
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    r.DebugForms(),
+	})
+}
+
+// handleDebugEnable включает отладочный режим формы
+func (r *Router) handleDebugEnable(w http.ResponseWriter, req *http.Request) {
+	key := chi.URLParam(req, "*")
+	if _, exists := r.lookupForm(key); !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+
+	var body debugRequest
+	if err := r.decodeJSON(req.Body, &body); err != nil && !errors.Is(err, io.EOF) {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return
+	}
+
+	var ttl time.Duration
+	if body.Duration != "" {
+		parsed, err := time.ParseDuration(body.Duration)
+		if err != nil || parsed <= 0 {
+			r.sendError(w, http.StatusBadRequest, "Некорректная длительность отладочного режима")
+			return
+		}
+		ttl = parsed
+	}
+
+	var enabledBy string
+	if user, ok := auth.UserFromContext(req.Context()); ok {
+		enabledBy = user.ID
+	}
+
+	debug := r.EnableFormDebug(key, ttl, enabledBy)
+	r.Audit().Record(req.Context(), audit.ActionDebugEnable, key, map[string]interface{}{
+		"expiresAt": debug.ExpiresAt,
+	})
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    debug,
+	})
+}
+
+// handleDebugDisable выключает отладочный режим формы
+func (r *Router) handleDebugDisable(w http.ResponseWriter, req *http.Request) {
+	key := chi.URLParam(req, "*")
+	if !r.DisableFormDebug(key) {
+		r.sendError(w, http.StatusNotFound, "Отладочный режим формы не включен")
+		return
+	}
+	r.Audit().Record(req.Context(), audit.ActionDebugDisable, key, nil)
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Message: "Отладочный режим выключен",
+	})
+}
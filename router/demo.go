@@ -0,0 +1,42 @@
+package router
+
+import (
+	"time"
+
+	"github.com/koteyye/go-formist/demo"
+	"github.com/koteyye/go-formist/types"
+)
+
+// SetDemo включает демонстрационный режим: OnGet форм и обработчики таблиц заменяются
+// данными генератора. nil выключает режим
+func (r *Router) SetDemo(generator *demo.Generator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.demo = generator
+	r.updatedAt = time.Now()
+}
+
+// formGetHandler возвращает обработчик OnGet формы с учетом демонстрационного режима
+func (r *Router) formGetHandler(form *types.Form) types.GetHandler {
+	r.mu.RLock()
+	generator := r.demo
+	r.mu.RUnlock()
+
+	if generator != nil {
+		return generator.GetHandler(form)
+	}
+	return form.OnGet
+}
+
+// tableHandler возвращает обработчик таблицы с учетом демонстрационного режима
+func (r *Router) tableHandler(form *types.Form, field string, config *types.TableConfig) types.TableHandler {
+	r.mu.RLock()
+	generator := r.demo
+	r.mu.RUnlock()
+
+	if generator != nil {
+		return generator.TableHandler(form.Key()+"/"+field, config)
+	}
+	return config.OnGet
+}
@@ -0,0 +1,202 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/koteyye/go-formist/a11y"
+	"github.com/koteyye/go-formist/lint"
+	"github.com/koteyye/go-formist/schema"
+	"github.com/koteyye/go-formist/types"
+)
+
+// MaxSchemaSize размер схем формы (JSON Schema и UI Schema), после которого
+// самодиагностика предупреждает о слишком большой форме
+const MaxSchemaSize = 256 << 10
+
+// SetDiagnostics устанавливает функцию самодиагностики для GET /admin/diagnostics
+func (r *Router) SetDiagnostics(diagnose func(ctx context.Context) *types.DiagnosticsReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.diagnose = diagnose
+}
+
+// DiagnoseForms проверяет зарегистрированные формы, страницы и оформление и возвращает найденные
+// проблемы по проверкам CheckDuplicateNames, CheckHandlers, CheckPatterns, CheckSchemaSize,
+// CheckLint и CheckAccessibility
+func (r *Router) DiagnoseForms() map[string][]types.DiagnosticIssue {
+	forms := r.sortedForms()
+
+	issues := map[string][]types.DiagnosticIssue{
+		types.CheckDuplicateNames: duplicateFormNames(forms),
+		types.CheckHandlers:       nil,
+		types.CheckPatterns:       nil,
+		types.CheckSchemaSize:     nil,
+		types.CheckLint:           nil,
+		types.CheckAccessibility:  r.accessibilityIssues(),
+	}
+
+	for _, form := range forms {
+		key := form.Key()
+		issues[types.CheckHandlers] = append(issues[types.CheckHandlers], formHandlerIssues(form)...)
+
+		validationErr := r.validatorError(key)
+		if validationErr == nil {
+			validationErr = schema.ValidateForm(form)
+		}
+		if validationErr != nil {
+			issues[types.CheckPatterns] = append(issues[types.CheckPatterns], types.DiagnosticIssue{
+				Level:   types.DiagnosticError,
+				Target:  key,
+				Message: validationErr.Error(),
+			})
+		}
+
+		if issue, ok := r.schemaSizeIssue(form); ok {
+			issues[types.CheckSchemaSize] = append(issues[types.CheckSchemaSize], issue)
+		}
+		issues[types.CheckLint] = append(issues[types.CheckLint], lint.Form(form)...)
+	}
+	return issues
+}
+
+// accessibilityIssues проверяет доступность HTML страниц и контраст цветов оформления
+func (r *Router) accessibilityIssues() []types.DiagnosticIssue {
+	r.mu.RLock()
+	pages := make([]*types.Page, 0, len(r.pages))
+	for _, page := range r.pages {
+		pages = append(pages, page)
+	}
+	branding := r.branding
+	r.mu.RUnlock()
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Name < pages[j].Name
+	})
+
+	var issues []types.DiagnosticIssue
+	for _, page := range pages {
+		issues = append(issues, a11y.Page(page)...)
+	}
+	return append(issues, a11y.Branding(branding)...)
+}
+
+// duplicateFormNames находит формы с одинаковым именем в разных модулях:
+// их легко перепутать в навигации и журналах, где указано только имя
+func duplicateFormNames(forms []*types.Form) []types.DiagnosticIssue {
+	keys := make(map[string][]string)
+	for _, form := range forms {
+		keys[form.Name] = append(keys[form.Name], form.Key())
+	}
+
+	var issues []types.DiagnosticIssue
+	for _, form := range forms {
+		duplicates := keys[form.Name]
+		if len(duplicates) < 2 || duplicates[0] != form.Key() {
+			continue
+		}
+		issues = append(issues, types.DiagnosticIssue{
+			Level:   types.DiagnosticWarning,
+			Target:  form.Name,
+			Message: "Имя формы используется несколько раз: " + strings.Join(duplicates, ", "),
+		})
+	}
+	return issues
+}
+
+// formHandlerIssues находит обработчики, которых не хватает форме
+func formHandlerIssues(form *types.Form) []types.DiagnosticIssue {
+	var issues []types.DiagnosticIssue
+	add := func(level, message string) {
+		issues = append(issues, types.DiagnosticIssue{Level: level, Target: form.Key(), Message: message})
+	}
+
+	if form.Approval != nil && form.OnPost == nil {
+		add(types.DiagnosticError, "Форма с согласованием без обработчика отправки")
+	}
+	if form.Actions != nil {
+		for _, action := range form.Actions.Custom {
+			if action.Handler == nil {
+				add(types.DiagnosticError, fmt.Sprintf("Действие %s без обработчика", action.Name))
+			}
+		}
+	}
+
+	tables := false
+	for _, field := range form.Fields {
+		if field.TableConfig == nil {
+			continue
+		}
+		if field.TableConfig.OnGet == nil {
+			add(types.DiagnosticWarning, fmt.Sprintf("Табличное поле %s без обработчика данных", field.Name))
+		} else {
+			tables = true
+		}
+	}
+
+	if form.OnPost == nil && form.OnGet == nil && !tables && (form.Actions == nil || len(form.Actions.Custom) == 0) {
+		add(types.DiagnosticWarning, "Форма без обработчиков")
+	}
+	return issues
+}
+
+// schemaSizeIssue проверяет размер схем формы
+func (r *Router) schemaSizeIssue(form *types.Form) (types.DiagnosticIssue, bool) {
+	issue := types.DiagnosticIssue{Level: types.DiagnosticError, Target: form.Key()}
+
+	response, err := r.formSchemas(form)
+	if err != nil {
+		issue.Message = fmt.Sprintf("Не удалось сгенерировать схему: %v", err)
+		return issue, true
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		issue.Message = fmt.Sprintf("Не удалось сериализовать схему: %v", err)
+		return issue, true
+	}
+	if len(data) <= MaxSchemaSize {
+		return types.DiagnosticIssue{}, false
+	}
+
+	issue.Level = types.DiagnosticWarning
+	issue.Message = fmt.Sprintf("Схема формы занимает %d КБ (больше %d КБ)", len(data)>>10, MaxSchemaSize>>10)
+	return issue, true
+}
+
+// handleDiagnostics возвращает отчет самодиагностики.
+// При ошибках в отчете отвечает 503, чтобы проверку можно было использовать в CI и при деплое
+func (r *Router) handleDiagnostics(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	diagnose := r.diagnose
+	r.mu.RUnlock()
+
+	var report *types.DiagnosticsReport
+	if diagnose != nil {
+		report = diagnose(req.Context())
+	} else {
+		report = &types.DiagnosticsReport{CheckedAt: time.Now().UTC()}
+		issues := r.DiagnoseForms()
+		for _, name := range types.FormChecks {
+			report.Add(types.NewDiagnosticCheck(name, issues[name]))
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status == types.DiagnosticError {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	r.encodeJSON(w, types.APIResponse{
+		Success: report.Status != types.DiagnosticError,
+		Data:    report,
+	})
+}
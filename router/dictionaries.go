@@ -0,0 +1,104 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/dictionary"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Dictionaries возвращает реестр справочников
+func (r *Router) Dictionaries() *dictionary.Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.dictionaries
+}
+
+// resolveDictionaries возвращает копию формы, в которой варианты выбора полей
+// со ссылкой на справочник заполнены его записями
+func (r *Router) resolveDictionaries(ctx context.Context, form *types.Form) (*types.Form, error) {
+	resolved := form.Clone()
+	for i := range resolved.Fields {
+		field := &resolved.Fields[i]
+		if field.Dictionary == nil {
+			continue
+		}
+
+		entries, err := r.Dictionaries().Entries(ctx, field.Dictionary.Name)
+		if err != nil {
+			return nil, fmt.Errorf("поле %s: %w", field.Name, err)
+		}
+		field.Options = entries
+	}
+	return resolved, nil
+}
+
+// validateDictionaryValues проверяет, что значения полей со ссылкой на справочник
+// являются его включенными записями
+func (r *Router) validateDictionaryValues(ctx context.Context, form *types.Form, data map[string]interface{}) error {
+	for _, field := range form.Fields {
+		if field.Dictionary == nil {
+			continue
+		}
+
+		values := []interface{}{data[field.Name]}
+		if list, ok := data[field.Name].([]interface{}); ok {
+			values = list
+		}
+		for _, value := range values {
+			if value == nil || value == "" {
+				continue
+			}
+			ok, err := r.Dictionaries().Contains(ctx, field.Dictionary.Name, fmt.Sprint(value))
+			if err != nil {
+				return fmt.Errorf("%s: %w", field.Label, err)
+			}
+			if !ok {
+				return fmt.Errorf("%s: значение %v отсутствует в справочнике", field.Label, value)
+			}
+		}
+	}
+	return nil
+}
+
+// handleDictionariesList возвращает определения справочников
+func (r *Router) handleDictionariesList(w http.ResponseWriter, req *http.Request) {
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    r.Dictionaries().List(),
+	})
+}
+
+// handleDictionaryEntries возвращает включенные записи справочника (?all=1 - все записи)
+func (r *Router) handleDictionaryEntries(w http.ResponseWriter, req *http.Request) {
+	entries, err := r.Dictionaries().Entries(req.Context(), chi.URLParam(req, "name"))
+	if errors.Is(err, dictionary.ErrNotFound) {
+		r.sendError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if req.URL.Query().Get("all") == "" {
+		enabled := make([]types.SelectOption, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.Disabled {
+				enabled = append(enabled, entry)
+			}
+		}
+		entries = enabled
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// handleFormDuplicate загружает запись ?id=... и возвращает ее копию для формы создания:
+// без полей Exclude, паролей и файлов, только с полями формы создания
+func (r *Router) handleFormDuplicate(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+	if form.Actions == nil || form.Actions.Duplicate == nil {
+		r.sendError(w, http.StatusNotFound, "Копирование не настроено")
+		return
+	}
+	duplicate := form.Actions.Duplicate
+	if duplicate.Load == nil {
+		r.sendError(w, http.StatusMethodNotAllowed, "Копирование не поддерживается")
+		return
+	}
+
+	id := req.URL.Query().Get(types.LinkParamDefault)
+	if id == "" {
+		r.sendError(w, http.StatusBadRequest, "Не задан ID записи")
+		return
+	}
+
+	target := form
+	if duplicate.Form != "" {
+		if target, exists = r.lookupForm(duplicate.Form); !exists {
+			r.sendError(w, http.StatusNotFound, "Форма создания не найдена")
+			return
+		}
+		if status, message := r.formAccess(req, target); status != http.StatusOK {
+			r.sendError(w, status, message)
+			return
+		}
+	}
+
+	if r.accessLog != nil {
+		r.noteAccess(req, form, nil)
+	}
+
+	result, err := r.callFormHandler(req.Context(), form, func(ctx context.Context) (interface{}, error) {
+		return duplicate.Load(ctx, id)
+	})
+	if aborted(req) {
+		return
+	}
+	if errors.Is(err, errOverloaded) {
+		r.sendOverloaded(w)
+		return
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "action:"+types.ActionDuplicate)
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения записи: %v", err))
+		return
+	}
+	record, _ := result.(map[string]interface{})
+	if record == nil {
+		r.sendError(w, http.StatusNotFound, "Запись не найдена")
+		return
+	}
+
+	data := make(map[string]interface{}, len(target.Fields))
+	for _, field := range target.Fields {
+		value, ok := record[field.Name]
+		if !ok || slices.Contains(duplicate.Exclude, field.Name) {
+			continue
+		}
+		switch field.Type {
+		case types.FieldTypePassword, types.FieldTypeFile:
+			continue
+		}
+		data[field.Name] = value
+	}
+	presented, timezone := r.presentFormData(req, target, data)
+	if presented, err = r.serializeData(presented); err != nil {
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка сериализации: %v", err))
+		return
+	}
+	data, _ = presented.(map[string]interface{})
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data: types.DuplicateResult{
+			Form:     target.Key(),
+			Path:     r.FormPath(target),
+			Data:     data,
+			Timezone: timezone,
+		},
+	})
+}
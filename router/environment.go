@@ -0,0 +1,135 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// SetEnvironment устанавливает окружение, которое передается в конфигурации для баннеров UI
+func (r *Router) SetEnvironment(name string, options types.EnvironmentOptions) {
+	r.mu.Lock()
+	r.environment = &types.Environment{
+		Name:   name,
+		Label:  options.Label,
+		Color:  options.Color,
+		Banner: options.Banner,
+	}
+	r.updatedAt = time.Now()
+	r.mu.Unlock()
+
+	if options.ReadOnly {
+		r.SetReadOnly(true, "")
+	}
+}
+
+// SetBranding устанавливает оформление, которое передается в конфигурации UI.
+// Контраст цветов и текст логотипа проверяются самодиагностикой (проверка accessibility)
+func (r *Router) SetBranding(branding types.Branding) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.branding = &branding
+	r.updatedAt = time.Now()
+}
+
+// SetReadOnly включает или выключает режим только для чтения.
+// В этом режиме все изменяющие запросы отклоняются со статусом 423 Locked
+func (r *Router) SetReadOnly(enabled bool, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.readOnly = nil
+	if enabled {
+		r.readOnly = &types.ReadOnlyInfo{Message: message}
+	}
+	r.updatedAt = time.Now()
+}
+
+// ReadOnly сообщает, включен ли режим только для чтения
+func (r *Router) ReadOnly() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readOnly != nil
+}
+
+// readOnlyGuard отклоняет изменяющие запросы в режиме только для чтения
+func (r *Router) readOnlyGuard(next http.Handler) http.Handler {
+	mux := r.mux
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		readOnly := r.readOnly
+		r.mu.RUnlock()
+
+		if readOnly == nil || !isMutating(mux, req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		message := readOnly.Message
+		if message == "" {
+			message = "Админка работает в режиме только для чтения"
+		}
+		r.sendError(w, http.StatusLocked, message)
+	})
+}
+
+// dryRunRoutes маршруты, обработчики которых выполняют ?dry_run=true без изменений:
+// метод и окончание шаблона маршрута
+var dryRunRoutes = []struct {
+	method  string
+	pattern string
+}{
+	{http.MethodPost, "/{name}"},
+	{http.MethodPost, "/{name}/batch"},
+	{http.MethodPatch, "/{name}/{id}"},
+	{http.MethodPatch, "/{name}/tables/{field}/rows/{id}/cells/{key}"},
+	{http.MethodPost, "/api/backup/restore"},
+}
+
+// isMutating проверяет, изменяет ли запрос данные.
+// Пробный запуск маршрутов dryRunRoutes, вход/выход, управление режимом обслуживания,
+// отладкой форм и снимками запросов изменяющими не считаются
+func isMutating(mux *chi.Mux, req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	if isDryRunRequest(req) && dryRunRoute(mux, req) {
+		return false
+	}
+
+	// Режимом обслуживания, отладкой и снимками можно управлять и в режиме только для чтения
+	path := strings.TrimRight(req.URL.Path, "/")
+	if strings.Contains(path, "/api/debug/") || strings.Contains(path, "/api/snapshots/") {
+		return false
+	}
+	for _, suffix := range []string{"/admin/login", "/admin/logout", "/api/maintenance"} {
+		if strings.HasSuffix(path, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// dryRunRoute сообщает, что запрос попадает в маршрут, поддерживающий пробный запуск.
+// Маршрут определяется по дереву роутера: middleware выполняется до маршрутизации
+func dryRunRoute(mux *chi.Mux, req *http.Request) bool {
+	rctx := chi.NewRouteContext()
+	if !mux.Match(rctx, req.Method, req.URL.Path) {
+		return false
+	}
+	pattern := strings.TrimRight(rctx.RoutePattern(), "/")
+	for _, route := range dryRunRoutes {
+		if req.Method == route.method && strings.HasSuffix(pattern, route.pattern) {
+			return true
+		}
+	}
+	return false
+}
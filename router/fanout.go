@@ -0,0 +1,239 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/koteyye/go-formist/datasource"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// IncludeTables значение ?include=: первые страницы табличных полей в ответе GET формы
+const IncludeTables = "tables"
+
+// Части ответа GET формы, получаемые одновременно
+const (
+	partSchema = "schema"
+	partData   = "onGet"
+	partTable  = "table:"
+)
+
+// errPartTimeout возвращается, если часть ответа не уложилась в PartTimeout формы
+var errPartTimeout = errors.New("превышено время ожидания")
+
+// formPart часть ответа GET формы из отдельного источника
+type formPart struct {
+	name string
+	run  func(ctx context.Context) (interface{}, error)
+}
+
+// formRead данные OnGet с политикой кеширования
+type formRead struct {
+	data   interface{}
+	policy *types.CachePolicy
+}
+
+// partError ошибка части ответа
+type partError struct {
+	part string
+	err  error
+}
+
+// Error возвращает текст ошибки с именем части
+func (e *partError) Error() string {
+	return fmt.Sprintf("%s: %v", e.part, e.err)
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *partError) Unwrap() error {
+	return e.err
+}
+
+// runParts выполняет части одновременно и возвращает их результаты в том же порядке.
+// Первая ошибка отменяет остальные части; timeout ограничивает каждую часть отдельно
+func runParts(ctx context.Context, timeout time.Duration, parts []formPart) ([]interface{}, error) {
+	results := make([]interface{}, len(parts))
+	group, ctx := errgroup.WithContext(ctx)
+	for i, part := range parts {
+		group.Go(func() error {
+			result, err := runPart(ctx, timeout, part)
+			if err != nil {
+				return &partError{part: part.name, err: err}
+			}
+			results[i] = result
+			return nil
+		})
+	}
+	return results, group.Wait()
+}
+
+// runPart выполняет часть с ограничением времени. Зависшая часть не задерживает ответ
+func runPart(ctx context.Context, timeout time.Duration, part formPart) (interface{}, error) {
+	if timeout <= 0 {
+		return callHandler(ctx, part.run)
+	}
+
+	partCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := callHandler(partCtx, part.run)
+	if err != nil && ctx.Err() == nil && errors.Is(partCtx.Err(), context.DeadlineExceeded) {
+		return nil, errPartTimeout
+	}
+	return result, err
+}
+
+// includeTables разбирает ?include=tables
+func includeTables(req *http.Request) (bool, error) {
+	raw := req.URL.Query().Get("include")
+	if raw == "" {
+		return false, nil
+	}
+
+	include := false
+	for _, name := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(name) {
+		case IncludeTables:
+			include = true
+		case "":
+		default:
+			return false, fmt.Errorf("неизвестная часть ?include: %s (доступна tables)", name)
+		}
+	}
+	return include, nil
+}
+
+// tableParts возвращает части с первыми страницами табличных полей формы.
+// Данные проходят ту же обработку, что и ответ /tables/{field}/data
+func (r *Router) tableParts(req *http.Request, form *types.Form, labelsMode string) []formPart {
+	var parts []formPart
+	for _, field := range form.Fields {
+		config := tableConfig(form, field.Name)
+		if config == nil {
+			continue
+		}
+		onGet := r.tableHandler(form, field.Name, config)
+		if onGet == nil {
+			continue
+		}
+
+		name, filters := field.Name, map[string]interface{}{}
+		key := tableFlightKey(form, name, 1, config.PageSize, filters)
+		parts = append(parts, formPart{
+			name: partTable + name,
+			run: func(ctx context.Context) (interface{}, error) {
+				data, _, err := r.cachedRead(req.WithContext(ctx), form, key, func(ctx context.Context) (interface{}, error) {
+					return onGet(ctx, 1, config.PageSize, filters)
+				})
+				if err != nil {
+					return nil, err
+				}
+				return r.serializeData(r.tableLabels(req, config, labelsMode, withRowKeys(data, config.RowKey)))
+			},
+		})
+	}
+	return parts
+}
+
+// fanOutFormGet одновременно получает схемы, данные OnGet и первые страницы таблиц формы
+// и собирает их в response. При ошибке отправляет ответ и возвращает false
+func (r *Router) fanOutFormGet(w http.ResponseWriter, req *http.Request, form *types.Form, fields responseFields, response *types.FormResponse) (*types.CachePolicy, bool) {
+	tables, err := includeTables(req)
+	if err != nil {
+		r.sendError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	onGet := r.formGetHandler(form)
+	withData := onGet != nil && fields.has(FieldData)
+
+	var labelsMode string
+	if withData || tables {
+		if labelsMode, err = parseLabelsMode(req); err != nil {
+			r.sendError(w, http.StatusBadRequest, err.Error())
+			return nil, false
+		}
+	}
+
+	var parts []formPart
+	if fields.schemas() {
+		parts = append(parts, formPart{
+			name: partSchema,
+			run: func(ctx context.Context) (interface{}, error) {
+				return r.requestSchemas(req.WithContext(ctx), form)
+			},
+		})
+	}
+	if withData {
+		parts = append(parts, formPart{
+			name: partData,
+			run: func(ctx context.Context) (interface{}, error) {
+				data, policy, err := r.cachedRead(req.WithContext(ctx), form, getFlightKey(form, types.RecordID(ctx)), onGet)
+				return formRead{data: data, policy: policy}, err
+			},
+		})
+	}
+	if tables {
+		parts = append(parts, r.tableParts(req, form, labelsMode)...)
+	}
+
+	results, err := runParts(req.Context(), form.PartTimeout, parts)
+	if aborted(req) {
+		return nil, false
+	}
+	if err != nil {
+		r.sendPartError(w, req, form, err)
+		return nil, false
+	}
+
+	var policy *types.CachePolicy
+	for i, part := range parts {
+		switch {
+		case part.name == partSchema:
+			*response = *results[i].(*types.FormResponse)
+		case part.name == partData:
+			read := results[i].(formRead)
+			if !r.completeFormData(w, req, form, read.data, labelsMode, response) {
+				return nil, false
+			}
+			policy = read.policy
+		default:
+			if response.Tables == nil {
+				response.Tables = make(map[string]interface{})
+			}
+			response.Tables[strings.TrimPrefix(part.name, partTable)] = results[i]
+		}
+	}
+
+	return policy, true
+}
+
+// sendPartError отправляет ошибку части ответа GET формы
+func (r *Router) sendPartError(w http.ResponseWriter, req *http.Request, form *types.Form, err error) {
+	var failed *partError
+	if !errors.As(err, &failed) {
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch {
+	case errors.Is(err, errPartTimeout):
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), failed.part)
+		r.sendError(w, http.StatusGatewayTimeout, fmt.Sprintf("Часть ответа %s: %v", failed.part, failed.err))
+	case errors.Is(err, datasource.ErrNotFound):
+		r.sendError(w, http.StatusNotFound, "Запись не найдена")
+	case failed.part == partSchema:
+		r.reportRequestError(req, failed.err, reporting.KindValidation, form.Key(), "schema")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка генерации схемы: %v", failed.err))
+	default:
+		r.reportRequestError(req, failed.err, reporting.KindHandler, form.Key(), failed.part)
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения данных: %v", failed.err))
+	}
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/federation"
+	"github.com/koteyye/go-formist/types"
+)
+
+// remotePath путь прокси удаленных админок относительно /admin
+const remotePath = "/remote"
+
+// SetFederation подключает каталоги удаленных админок: их пункты меню добавляются
+// в конфигурацию, а админки в режиме proxy доступны через /admin/remote/{name}/...
+func (r *Router) SetFederation(f *federation.Federation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.federation = f
+	r.federationProxy = nil
+	if f != nil {
+		r.federationProxy = f.Handler("")
+	}
+}
+
+// Federation возвращает каталоги удаленных админок (nil, если федерация не подключена)
+func (r *Router) Federation() *federation.Federation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.federation
+}
+
+// remoteBase возвращает путь прокси удаленных админок с учетом префикса
+func (r *Router) remoteBase() string {
+	return r.prefix + "/admin" + remotePath
+}
+
+// handleRemoteProxy передает запрос удаленной админке в режиме proxy
+func (r *Router) handleRemoteProxy(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	proxy := r.federationProxy
+	r.mu.RUnlock()
+
+	if proxy == nil {
+		r.sendError(w, http.StatusNotFound, "Удаленная админка не найдена")
+		return
+	}
+
+	// Путь относительно /admin/remote не зависит от префикса роутера
+	out := req.Clone(req.Context())
+	out.URL.Path = "/" + chi.URLParam(req, "*")
+	out.URL.RawPath = ""
+	proxy.ServeHTTP(w, out)
+}
+
+// handleFederation возвращает состояние и каталоги роутов удаленных админок
+func (r *Router) handleFederation(w http.ResponseWriter, req *http.Request) {
+	f := r.Federation()
+	if f == nil {
+		r.sendError(w, http.StatusNotImplemented, "Федерация админок не подключена")
+		return
+	}
+
+	base := r.remoteBase()
+	info := f.Info(base)
+	catalogs := f.Catalogs()
+
+	remotes := make([]map[string]interface{}, 0, len(catalogs))
+	for i, catalog := range catalogs {
+		remotes = append(remotes, map[string]interface{}{
+			"remote": info[i],
+			"error":  catalog.Error,
+			"routes": catalog.Routes,
+		})
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    remotes,
+	})
+}
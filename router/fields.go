@@ -0,0 +1,86 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// Части ответа GET формы для ?fields=
+const (
+	FieldSchema   = "schema"
+	FieldUISchema = "uiSchema"
+	FieldData     = "data"
+)
+
+// responseFields набор запрошенных частей ответа формы; nil - все части
+type responseFields map[string]bool
+
+// parseResponseFields разбирает ?fields=schema,uiSchema. Без параметра возвращает nil
+func parseResponseFields(req *http.Request) (responseFields, error) {
+	raw := req.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	fields := make(responseFields)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case FieldSchema, FieldUISchema, FieldData:
+			fields[name] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("неизвестное поле ответа: %s (доступны schema, uiSchema, data)", name)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("не указаны поля ответа")
+	}
+	return fields, nil
+}
+
+// has сообщает, запрошена ли часть ответа
+func (f responseFields) has(name string) bool {
+	return f == nil || f[name]
+}
+
+// schemas сообщает, нужны ли схемы формы
+func (f responseFields) schemas() bool {
+	return f.has(FieldSchema) || f.has(FieldUISchema)
+}
+
+// apply оставляет в ответе только запрошенные части.
+// Часовой пояс значений, названия и предзаполнение возвращаются вместе с data,
+// таблицы ?include=tables - всегда
+func (f responseFields) apply(response types.FormResponse) interface{} {
+	if f == nil {
+		return response
+	}
+
+	sparse := make(map[string]interface{}, len(f)+1)
+	if f[FieldSchema] {
+		sparse[FieldSchema] = response.Schema
+	}
+	if f[FieldUISchema] {
+		sparse[FieldUISchema] = response.UISchema
+	}
+	if f[FieldData] {
+		sparse[FieldData] = response.Data
+		if response.Timezone != "" {
+			sparse["timezone"] = response.Timezone
+		}
+		if response.Labels != nil {
+			sparse["labels"] = response.Labels
+		}
+		if response.Prefill != nil {
+			sparse["prefill"] = response.Prefill
+		}
+	}
+	if response.Tables != nil {
+		sparse["tables"] = response.Tables
+	}
+	return sparse
+}
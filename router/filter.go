@@ -0,0 +1,60 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/filter"
+	"github.com/koteyye/go-formist/types"
+)
+
+// parseTableFilter заменяет выражение фильтра в filters разобранными условиями
+func parseTableFilter(filters map[string]interface{}, config *types.TableConfig) error {
+	expr, ok := filters[types.FilterParam].(string)
+	if !ok {
+		return nil
+	}
+
+	conditions, err := filter.Parse(expr, config, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(conditions) == 0 {
+		delete(filters, types.FilterParam)
+		return nil
+	}
+	filters[types.FilterParam] = conditions
+	return nil
+}
+
+// handleTableFilter проверяет выражение фильтра таблицы без загрузки данных
+// и возвращает разобранные условия (например, для сохраненных представлений)
+func (r *Router) handleTableFilter(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+
+	config := tableConfig(form, chi.URLParam(req, "field"))
+	if config == nil {
+		r.sendError(w, http.StatusNotFound, "Таблица не найдена")
+		return
+	}
+
+	conditions, err := filter.Parse(req.URL.Query().Get(types.FilterParam), config, time.Now())
+	if err != nil {
+		r.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if conditions == nil {
+		conditions = []types.FilterCondition{}
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    conditions,
+	})
+}
@@ -0,0 +1,45 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/codegen"
+	"github.com/koteyye/go-formist/types"
+)
+
+// handleCodegenForm возвращает код FormBuilder зарегистрированной формы
+// (GET /api/config/codegen/{form}?package=forms)
+func (r *Router) handleCodegenForm(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(chi.URLParam(req, "*"))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+	r.sendGoCode(w, req, form)
+}
+
+// handleCodegen возвращает код FormBuilder определения формы из тела запроса,
+// например собранного в визуальном конструкторе
+func (r *Router) handleCodegen(w http.ResponseWriter, req *http.Request) {
+	var form types.Form
+	if err := r.decodeJSON(http.MaxBytesReader(w, req.Body, MaxBundleSize), &form); err != nil {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return
+	}
+	r.sendGoCode(w, req, &form)
+}
+
+// sendGoCode отправляет сгенерированный код формы
+func (r *Router) sendGoCode(w http.ResponseWriter, req *http.Request, form *types.Form) {
+	source, err := codegen.Generate(codegen.Options{Package: req.URL.Query().Get("package")}, form)
+	if err != nil {
+		r.sendError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Ошибка генерации кода: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-go; charset=utf-8")
+	w.Write(source)
+}
@@ -0,0 +1,223 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// handlerResult содержит результат вызова пользовательского обработчика
+type handlerResult struct {
+	data interface{}
+	err  error
+}
+
+// callHandler вызывает пользовательский обработчик и прекращает ожидание,
+// если контекст запроса отменен (например, клиент разорвал соединение)
+func callHandler(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	done := make(chan handlerResult, 1)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- handlerResult{err: panicError(p)}
+			}
+		}()
+
+		data, err := fn(ctx)
+		done <- handlerResult{data: data, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-done:
+		return res.data, res.err
+	}
+}
+
+// aborted сообщает, что клиент отменил запрос и ответ отправлять не нужно
+func aborted(req *http.Request) bool {
+	return req.Context().Err() != nil
+}
+
+// isDryRunRequest проверяет параметр ?dry_run=true
+func isDryRunRequest(req *http.Request) bool {
+	dryRun, _ := strconv.ParseBool(req.URL.Query().Get("dry_run"))
+	return dryRun
+}
+
+// handleDryRun выполняет пробный запуск обработки формы обработчиком OnDryRun
+// с признаком types.IsDryRun в контексте. Форма с согласованием только проверяет данные:
+// до одобрения заявки обработчики формы не вызываются
+func (r *Router) handleDryRun(w http.ResponseWriter, req *http.Request, form *types.Form, data map[string]interface{}, warnings []types.ValidationWarning) {
+	if form.Approval != nil {
+		r.sendJSON(w, types.APIResponse{
+			Success: true,
+			Data:    data,
+			Message: "Пробный запуск: изменения не сохранены",
+			Meta:    warningsMeta(warnings),
+		})
+		return
+	}
+	if form.OnDryRun == nil {
+		r.sendError(w, http.StatusNotImplemented, "Пробный запуск не поддерживается для этой формы")
+		return
+	}
+
+	result, err := r.callFormHandler(types.WithDryRun(req.Context()), form, func(ctx context.Context) (interface{}, error) {
+		return form.OnDryRun(ctx, data)
+	})
+	if aborted(req) {
+		return
+	}
+	if errors.Is(err, errOverloaded) {
+		r.sendOverloaded(w)
+		return
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "onDryRun")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка обработки: %v", err))
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    unwrapUndoable(result),
+		Message: "Пробный запуск: изменения не сохранены",
+		Meta:    warningsMeta(warnings),
+	})
+}
+
+// tableConfig возвращает конфигурацию табличного поля формы или nil
+func tableConfig(form *types.Form, field string) *types.TableConfig {
+	for i := range form.Fields {
+		if form.Fields[i].Name == field && form.Fields[i].Type == types.FieldTypeTable {
+			return form.Fields[i].TableConfig
+		}
+	}
+	return nil
+}
+
+// handleTableGet обрабатывает запрос данных табличного поля формы.
+// Выражение фильтра из параметра types.FilterParam передается обработчику разобранным
+func (r *Router) handleTableGet(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+	if r.accessLog != nil {
+		r.noteAccess(req, form, nil)
+	}
+
+	fieldName := chi.URLParam(req, "field")
+	config := tableConfig(form, fieldName)
+	if config == nil {
+		r.sendError(w, http.StatusNotFound, "Таблица не найдена")
+		return
+	}
+	onGet := r.tableHandler(form, fieldName, config)
+	if onGet == nil {
+		r.sendError(w, http.StatusNotFound, "Таблица не найдена")
+		return
+	}
+
+	query := req.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	explicit := err == nil && limit >= 1
+	if !explicit {
+		limit = config.PageSize
+	}
+	limit, ok := r.exportRowsQuota(w, limit, explicit)
+	if !ok {
+		return
+	}
+
+	labelsMode, err := parseLabelsMode(req)
+	if err != nil {
+		r.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Остальные параметры запроса считаются фильтрами
+	filters := make(map[string]interface{})
+	for key, values := range query {
+		if key == "page" || key == "limit" || key == "labels" || len(values) == 0 {
+			continue
+		}
+		filters[key] = values[0]
+	}
+
+	key := tableFlightKey(form, fieldName, page, limit, filters)
+	if err := parseTableFilter(filters, config); err != nil {
+		r.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, policy, err := r.cachedRead(req, form, key, func(ctx context.Context) (interface{}, error) {
+		return onGet(ctx, page, limit, filters)
+	})
+	if aborted(req) {
+		return
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "table:"+fieldName)
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения данных: %v", err))
+		return
+	}
+
+	data = r.tableLabels(req, config, labelsMode, withRowKeys(data, config.RowKey))
+	data, err = r.serializeData(data)
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "table:"+fieldName)
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка сериализации: %v", err))
+		return
+	}
+
+	if policy != nil {
+		w.Header().Set("Cache-Control", policy.CacheControl())
+	}
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// withRowKeys добавляет к строкам таблицы с RowKey поле types.RowKeyField.
+// Строки копируются: данные обработчика могут быть общими для нескольких запросов
+func withRowKeys(data interface{}, rowKey *types.RowKey) interface{} {
+	table, ok := data.(types.TableData)
+	if !ok || rowKey == nil {
+		return data
+	}
+
+	rows := make([]map[string]interface{}, len(table.Rows))
+	for i, row := range table.Rows {
+		rows[i] = row
+		key, ok := rowKey.Of(row)
+		if !ok {
+			continue
+		}
+		keyed := make(map[string]interface{}, len(row)+1)
+		for name, value := range row {
+			keyed[name] = value
+		}
+		keyed[types.RowKeyField] = key
+		rows[i] = keyed
+	}
+	table.Rows = rows
+	return table
+}
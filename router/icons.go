@@ -0,0 +1,65 @@
+package router
+
+import (
+	"sort"
+
+	"github.com/koteyye/go-formist/icons"
+	"github.com/koteyye/go-formist/interpolate"
+	"github.com/koteyye/go-formist/types"
+)
+
+// SetIcons устанавливает набор допустимых иконок форм, страниц и модулей
+func (r *Router) SetIcons(registry *icons.Registry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.icons = registry
+}
+
+// ValidateIcon проверяет иконку по набору админки
+func (r *Router) ValidateIcon(icon string) error {
+	r.mu.RLock()
+	registry := r.icons
+	r.mu.RUnlock()
+
+	return registry.Validate(icon)
+}
+
+// menuItems собирает пункты меню форм, страниц и модулей с иконками.
+// Вызывается под r.mu
+func (r *Router) menuItems(vars interpolate.Vars) []types.MenuItem {
+	items := make([]types.MenuItem, 0, len(r.forms)+len(r.pages)+len(r.modules))
+	for _, module := range r.modules {
+		items = append(items, types.MenuItem{
+			Type:  types.MenuItemModule,
+			Key:   module.Name,
+			Title: module.Title,
+			Icon:  module.Icon,
+		})
+	}
+	for key, form := range r.forms {
+		items = append(items, types.MenuItem{
+			Type:   types.MenuItemForm,
+			Key:    key,
+			Title:  interpolate.Expand(form.Title, vars),
+			Icon:   form.Icon,
+			Module: form.Module,
+		})
+	}
+	for name, page := range r.pages {
+		items = append(items, types.MenuItem{
+			Type:  types.MenuItemPage,
+			Key:   name,
+			Title: page.Title,
+			Icon:  page.Icon,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Type != items[j].Type {
+			return items[i].Type < items[j].Type
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
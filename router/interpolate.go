@@ -0,0 +1,65 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/interpolate"
+	"github.com/koteyye/go-formist/types"
+)
+
+// SetTemplateVars устанавливает функцию дополнительных переменных запроса (например, tenant).
+// Ее значения переопределяют встроенные переменные
+func (r *Router) SetTemplateVars(fn func(req *http.Request) map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templateVars = fn
+}
+
+// requestVars вычисляет переменные для подстановки в тексты формы
+func (r *Router) requestVars(req *http.Request) interpolate.Vars {
+	vars := interpolate.Vars{
+		interpolate.VarUserID:   "",
+		interpolate.VarUserName: "",
+		interpolate.VarToday:    time.Now().In(r.displayLocation(req)).Format("2006-01-02"),
+	}
+	if user, ok := auth.UserFromContext(req.Context()); ok {
+		vars[interpolate.VarUserID] = user.ID
+		vars[interpolate.VarUserName] = user.Name
+	}
+
+	r.mu.RLock()
+	custom := r.templateVars
+	r.mu.RUnlock()
+
+	if custom != nil {
+		for name, value := range custom(req) {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// requestSchemas возвращает схемы формы для запроса. Формы с переменными в текстах
+// и ссылками на справочники генерируются заново, остальные берутся из кеша
+func (r *Router) requestSchemas(req *http.Request, form *types.Form) (*types.FormResponse, error) {
+	dictionaries := len(form.Dictionaries()) > 0
+	if dictionaries {
+		resolved, err := r.resolveDictionaries(req.Context(), form)
+		if err != nil {
+			return nil, err
+		}
+		form = resolved
+	}
+
+	switch {
+	case interpolate.FormHasVariables(form):
+		return generateFormSchemas(interpolate.Form(form, r.requestVars(req)))
+	case dictionaries:
+		return generateFormSchemas(form)
+	default:
+		return r.formSchemas(form)
+	}
+}
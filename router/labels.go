@@ -0,0 +1,169 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/koteyye/go-formist/locale"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Режимы названий значений полей выбора в данных (?labels=)
+const (
+	LabelsInline = "inline" // рядом со значением поле <поле>_label
+	LabelsMap    = "map"    // названия отдельно: labels[поле][значение]
+)
+
+// LabelSuffix суффикс поля с названием значения в режиме LabelsInline
+const LabelSuffix = "_label"
+
+// parseLabelsMode разбирает ?labels=inline|map. Без параметра возвращает пустую строку
+func parseLabelsMode(req *http.Request) (string, error) {
+	switch mode := req.URL.Query().Get("labels"); mode {
+	case "", LabelsInline, LabelsMap:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("неизвестный режим названий: %s (доступны %s, %s)", mode, LabelsInline, LabelsMap)
+	}
+}
+
+// labelLanguage возвращает язык названий: из запроса или локаль админки по умолчанию
+func (r *Router) labelLanguage(req *http.Request) string {
+	if tag := locale.RequestTag(req); tag != "" {
+		return tag
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.locale
+}
+
+// formOptions возвращает варианты выбора полей формы, включая записи справочников
+func (r *Router) formOptions(ctx context.Context, form *types.Form) (map[string][]types.SelectOption, error) {
+	options := make(map[string][]types.SelectOption)
+	for _, field := range form.Fields {
+		switch {
+		case field.Dictionary != nil:
+			entries, err := r.Dictionaries().Entries(ctx, field.Dictionary.Name)
+			if err != nil {
+				return nil, fmt.Errorf("поле %s: %w", field.Name, err)
+			}
+			options[field.Name] = entries
+		case len(field.Options) > 0:
+			options[field.Name] = field.Options
+		}
+	}
+	return options, nil
+}
+
+// columnOptions возвращает варианты выбора колонок таблицы
+func columnOptions(config *types.TableConfig) map[string][]types.SelectOption {
+	options := make(map[string][]types.SelectOption)
+	for _, column := range config.Columns {
+		if len(column.Options) > 0 {
+			options[column.Key] = column.Options
+		}
+	}
+	return options
+}
+
+// valueLabels находит названия значений полей выбора в values.
+// В режиме LabelsInline возвращает копию values с полями <поле>_label,
+// в режиме LabelsMap добавляет названия в labels
+func valueLabels(values map[string]interface{}, options map[string][]types.SelectOption, lang, mode string, labels map[string]map[string]string) map[string]interface{} {
+	out, copied := values, false
+	for name, fieldOptions := range options {
+		value, ok := values[name]
+		if !ok || value == nil {
+			continue
+		}
+
+		list, multiple := value.([]interface{})
+		if !multiple {
+			list = []interface{}{value}
+		}
+		// Названия списка совпадают со значениями по позициям: значение без опции остается как есть
+		names := make([]interface{}, 0, len(list))
+		found := false
+		for _, item := range list {
+			key := fmt.Sprint(item)
+			i := slices.IndexFunc(fieldOptions, func(option types.SelectOption) bool {
+				return option.Value == key
+			})
+			if i < 0 {
+				names = append(names, item)
+				continue
+			}
+
+			found = true
+			label := fieldOptions[i].LocalizedLabel(lang)
+			names = append(names, label)
+			if mode == LabelsMap {
+				if labels[name] == nil {
+					labels[name] = make(map[string]string)
+				}
+				labels[name][key] = label
+			}
+		}
+		if mode != LabelsInline || !found {
+			continue
+		}
+
+		// Данные обработчика могут быть общими для нескольких запросов (кеш роутера)
+		if !copied {
+			out, copied = maps.Clone(values), true
+		}
+		if multiple {
+			out[name+LabelSuffix] = names
+		} else {
+			out[name+LabelSuffix] = names[0]
+		}
+	}
+	return out
+}
+
+// formLabels добавляет названия значений полей выбора к данным формы
+func (r *Router) formLabels(req *http.Request, form *types.Form, mode string, response *types.FormResponse) error {
+	values, ok := response.Data.(map[string]interface{})
+	if !ok || mode == "" {
+		return nil
+	}
+
+	options, err := r.formOptions(req.Context(), form)
+	if err != nil {
+		return err
+	}
+
+	labels := make(map[string]map[string]string)
+	response.Data = valueLabels(values, options, r.labelLanguage(req), mode, labels)
+	if len(labels) > 0 {
+		response.Labels = labels
+	}
+	return nil
+}
+
+// tableLabels добавляет названия значений колонок выбора к строкам таблицы
+func (r *Router) tableLabels(req *http.Request, config *types.TableConfig, mode string, data interface{}) interface{} {
+	table, ok := data.(types.TableData)
+	if !ok || mode == "" {
+		return data
+	}
+
+	options := columnOptions(config)
+	lang := r.labelLanguage(req)
+	labels := make(map[string]map[string]string)
+
+	rows := make([]map[string]interface{}, len(table.Rows))
+	for i, row := range table.Rows {
+		rows[i] = valueLabels(row, options, lang, mode, labels)
+	}
+	table.Rows = rows
+	if len(labels) > 0 {
+		table.Labels = labels
+	}
+	return table
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// errOverloaded возвращается, если лимит одновременных вызовов формы и очередь заполнены
+var errOverloaded = errors.New("форма перегружена")
+
+// formLimiter ограничивает одновременные вызовы обработчика формы
+type formLimiter struct {
+	slots   chan struct{}
+	queue   int32
+	waiting atomic.Int32
+	timeout time.Duration
+}
+
+// newFormLimiter создает ограничитель по настройкам формы
+func newFormLimiter(concurrency *types.Concurrency) *formLimiter {
+	if concurrency == nil || concurrency.MaxConcurrent <= 0 {
+		return nil
+	}
+
+	queue := concurrency.QueueSize
+	if queue < 0 {
+		queue = 0
+	}
+
+	return &formLimiter{
+		slots:   make(chan struct{}, concurrency.MaxConcurrent),
+		queue:   int32(queue),
+		timeout: concurrency.QueueTimeout,
+	}
+}
+
+// acquire занимает слот, при необходимости ожидая в очереди
+func (l *formLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if l.waiting.Add(1) > l.queue {
+		l.waiting.Add(-1)
+		return errOverloaded
+	}
+	defer l.waiting.Add(-1)
+
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		timer := time.NewTimer(l.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+		return errOverloaded
+	}
+}
+
+// release освобождает слот
+func (l *formLimiter) release() {
+	<-l.slots
+}
+
+// lookupLimiter возвращает ограничитель формы, если он настроен
+func (r *Router) lookupLimiter(key string) *formLimiter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.limiters[key]
+}
+
+// callFormHandler вызывает обработчик формы с учетом ограничения одновременных вызовов.
+// Слот освобождается по завершении обработчика, даже если клиент уже отменил запрос
+func (r *Router) callFormHandler(ctx context.Context, form *types.Form, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	limiter := r.lookupLimiter(form.Key())
+	if limiter == nil {
+		return callHandler(ctx, fn)
+	}
+
+	if err := limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	return callHandler(ctx, func(ctx context.Context) (interface{}, error) {
+		defer limiter.release()
+		return fn(ctx)
+	})
+}
+
+// sendOverloaded отвечает 503, предлагая повторить запрос позже
+func (r *Router) sendOverloaded(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	r.sendError(w, http.StatusServiceUnavailable, "Форма перегружена, повторите запрос позже")
+}
@@ -0,0 +1,74 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/types"
+)
+
+// handleLinkResolve проверяет ссылку на запись (?form=ключ&id=...) для текущего пользователя
+// и возвращает путь и заголовок записи для перехода
+func (r *Router) handleLinkResolve(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	key, id := query.Get("form"), query.Get("id")
+	if key == "" || id == "" {
+		r.sendError(w, http.StatusBadRequest, "Не заданы форма и ID записи")
+		return
+	}
+
+	form, exists := r.lookupForm(key)
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+	if status, message := r.formAccess(req, form); status != http.StatusOK {
+		r.sendError(w, status, message)
+		return
+	}
+
+	param := query.Get("param")
+	if param == "" {
+		param = types.LinkParamDefault
+	}
+	path := r.FormPath(form) + "?" + url.Values{param: {id}}.Encode()
+
+	vars := r.metaVars(req)
+	vars["query."+param] = id
+
+	resolved, status, message := r.formMeta(form.Key(), vars)
+	if status != http.StatusOK {
+		r.sendError(w, status, message)
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data: types.ResolvedLink{
+			Form:  form.Key(),
+			ID:    id,
+			Path:  path,
+			Title: resolved.Title,
+			Icon:  form.Icon,
+		},
+	})
+}
+
+// formAccess проверяет доступ пользователя к модулю формы
+func (r *Router) formAccess(req *http.Request, form *types.Form) (int, string) {
+	if form.Module == "" {
+		return http.StatusOK, ""
+	}
+
+	module, exists := r.lookupModule(form.Module)
+	if !exists {
+		return http.StatusNotFound, "Модуль не найден"
+	}
+
+	user, _ := auth.UserFromContext(req.Context())
+	if !user.HasAnyRole(module.Roles) {
+		return http.StatusForbidden, "Нет доступа к модулю"
+	}
+	return http.StatusOK, ""
+}
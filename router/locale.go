@@ -0,0 +1,38 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/koteyye/go-formist/locale"
+	"github.com/koteyye/go-formist/types"
+)
+
+// SetLocale устанавливает локаль ввода по умолчанию
+func (r *Router) SetLocale(tag string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locale = tag
+}
+
+// inputLocale определяет локаль ввода данных формы: явно указанная в запросе,
+// локаль формы, Accept-Language, локаль админки по умолчанию
+func (r *Router) inputLocale(req *http.Request, form *types.Form) (locale.Locale, bool) {
+	if loc, ok := locale.FromRequest(req); ok {
+		return loc, true
+	}
+	if form.Locale != "" {
+		if loc, ok := locale.Lookup(form.Locale); ok {
+			return loc, true
+		}
+	}
+	if loc, ok := locale.FromAcceptLanguage(req); ok {
+		return loc, true
+	}
+
+	r.mu.RLock()
+	defaultLocale := r.locale
+	r.mu.RUnlock()
+
+	return locale.Lookup(defaultLocale)
+}
@@ -0,0 +1,214 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// PresencePollTimeout максимальное ожидание изменений в запросе long polling.
+// Меньше типичного таймаута простоя прокси
+const PresencePollTimeout = 25 * time.Second
+
+// presencePollLease время, в течение которого зритель long polling остается
+// присутствующим после окончания запроса и ждет следующего
+const presencePollLease = 2 * PresencePollTimeout
+
+// Транспорты событий админки
+const (
+	EventTransportSSE      = "sse"
+	EventTransportLongPoll = "longpoll"
+)
+
+// pollSession сессия присутствия long polling: зритель остается в реестре,
+// пока клиент повторяет запросы не реже presencePollLease
+type pollSession struct {
+	target string
+	userID string
+	connID uint64
+	conn   *presenceConn
+	leave  func()
+	expiry *time.Timer
+	active int // выполняющиеся запросы сессии, защищено pollSessions.mu
+
+	// busy упорядочивает одновременные запросы одной сессии
+	busy sync.Mutex
+}
+
+// pollSessions реестр сессий long polling
+type pollSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*pollSession
+}
+
+// newPollSessions создает реестр сессий long polling
+func newPollSessions() *pollSessions {
+	return &pollSessions{
+		sessions: make(map[string]*pollSession),
+	}
+}
+
+// open регистрирует зрителя в hub и создает сессию для текущего запроса
+func (p *pollSessions) open(hub *presenceHub, target string, viewer types.Viewer) (string, *pollSession) {
+	var raw [16]byte
+	rand.Read(raw[:])
+	id := hex.EncodeToString(raw[:])
+
+	connID, conn, leave := hub.join(target, viewer)
+	session := &pollSession{
+		target: target,
+		userID: viewer.UserID,
+		connID: connID,
+		conn:   conn,
+		leave:  leave,
+		active: 1,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	session.expiry = time.AfterFunc(presencePollLease, func() { p.expire(id) })
+	p.sessions[id] = session
+	return id, session
+}
+
+// get возвращает сессию для текущего запроса. Пока запрос выполняется, сессия не истекает
+func (p *pollSessions) get(id string) (*pollSession, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	session, ok := p.sessions[id]
+	if ok {
+		session.active++
+	}
+	return session, ok
+}
+
+// release завершает запрос сессии: после последнего запроса сессия живет presencePollLease
+func (p *pollSessions) release(session *pollSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	session.active--
+	if session.active == 0 {
+		session.expiry.Reset(presencePollLease)
+	}
+}
+
+// expire удаляет сессию без выполняющихся запросов, и зритель перестает отображаться
+func (p *pollSessions) expire(id string) {
+	p.mu.Lock()
+	session, ok := p.sessions[id]
+	if !ok || session.active > 0 {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.sessions, id)
+	p.mu.Unlock()
+
+	session.leave()
+}
+
+// SetEventTransport задает транспорт событий, который UI выбирает по capabilities
+// в /admin/config: EventTransportSSE (по умолчанию) или EventTransportLongPoll
+// для сетей, где прокси обрывают потоковые ответы
+func (r *Router) SetEventTransport(transport string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.eventTransport = transport
+	r.updatedAt = time.Now()
+}
+
+// capabilities возвращает транспорты событий для UI. Вызывается под блокировкой
+func (r *Router) capabilities() *types.Capabilities {
+	transport := r.eventTransport
+	if transport == "" {
+		transport = EventTransportSSE
+	}
+	return &types.Capabilities{
+		Events:          transport,
+		EventTransports: []string{EventTransportSSE, EventTransportLongPoll},
+	}
+}
+
+// handlePresencePoll реализует канал присутствия через long polling (?form=ключ&id=запись).
+// Первый запрос открывает сессию, следующие передают ?session= и ?cursor= из предыдущего ответа.
+// Ответ возвращается сразу, если с курсора были изменения (или курсор устарел), иначе после изменения
+// или PresencePollTimeout с пустым списком событий
+func (r *Router) handlePresencePoll(w http.ResponseWriter, req *http.Request) {
+	target, ok := r.presenceRequest(w, req)
+	if !ok {
+		return
+	}
+
+	query := req.URL.Query()
+	var cursor uint64
+	if raw := query.Get("cursor"); raw != "" {
+		var err error
+		if cursor, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			r.sendError(w, http.StatusBadRequest, "Некорректный курсор")
+			return
+		}
+	}
+
+	viewer := presenceViewer(req)
+	polls := r.presence.polls
+	sessionID := query.Get("session")
+	var session *pollSession
+	if sessionID == "" {
+		sessionID, session = polls.open(r.presence, target, viewer)
+		cursor = 0
+	} else if session, ok = polls.get(sessionID); !ok {
+		r.sendError(w, http.StatusNotFound, "Сессия не найдена, начните новую без ?session")
+		return
+	}
+	defer polls.release(session)
+
+	if session.target != target || session.userID != viewer.UserID {
+		r.sendError(w, http.StatusNotFound, "Сессия не найдена, начните новую без ?session")
+		return
+	}
+
+	session.busy.Lock()
+	defer session.busy.Unlock()
+
+	timeout := time.NewTimer(PresencePollTimeout)
+	defer timeout.Stop()
+
+wait:
+	for r.presence.version(target) == cursor {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-session.conn.updates:
+		case <-timeout.C:
+			break wait
+		}
+	}
+
+	// События с курсора: присутствие передается текущим состоянием
+	poll := types.EventPoll{
+		Session: sessionID,
+		Cursor:  r.presence.version(target),
+		Events:  []types.Event{},
+	}
+	if poll.Cursor != cursor {
+		viewers := r.presence.viewers(target, session.connID, viewer.UserID)
+		if viewers == nil {
+			viewers = []types.Viewer{}
+		}
+		poll.Events = append(poll.Events, types.Event{Event: "presence", Data: viewers})
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    poll,
+	})
+}
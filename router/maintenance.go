@@ -0,0 +1,143 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// MaintenancePersister сохраняет состояние режима обслуживания
+type MaintenancePersister func(ctx context.Context, maintenance *types.Maintenance) error
+
+// SetMaintenance устанавливает режим обслуживания
+func (r *Router) SetMaintenance(maintenance types.Maintenance) {
+	maintenance.AllowedRoles = append([]string(nil), maintenance.AllowedRoles...)
+	if maintenance.UpdatedAt.IsZero() {
+		maintenance.UpdatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maintenance = &maintenance
+	r.updatedAt = time.Now()
+}
+
+// Maintenance возвращает текущее состояние режима обслуживания
+func (r *Router) Maintenance() types.Maintenance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.maintenance == nil {
+		return types.Maintenance{}
+	}
+	return *r.maintenance
+}
+
+// SetMaintenancePersister устанавливает функцию сохранения режима обслуживания,
+// вызываемую при изменении режима через API
+func (r *Router) SetMaintenancePersister(persist MaintenancePersister) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maintenancePersist = persist
+}
+
+// maintenanceInfo возвращает режим обслуживания для конфигурации, если он включен
+func (r *Router) maintenanceInfo() *types.Maintenance {
+	if r.maintenance == nil || !r.maintenance.Enabled {
+		return nil
+	}
+	return r.maintenance
+}
+
+// maintenanceGuard отвечает 503 в режиме обслуживания всем, кроме разрешенных ролей
+func (r *Router) maintenanceGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		maintenance := r.maintenanceInfo()
+		r.mu.RUnlock()
+
+		if maintenance == nil || maintenanceExempt(req) || maintenanceAllowed(req, maintenance) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		message := maintenance.Message
+		if message == "" {
+			message = "Админка на обслуживании"
+		}
+		w.Header().Set("Retry-After", "120")
+		r.sendError(w, http.StatusServiceUnavailable, message)
+	})
+}
+
+// maintenanceAllowed проверяет, входит ли пользователь в список разрешенных ролей
+func maintenanceAllowed(req *http.Request, maintenance *types.Maintenance) bool {
+	if len(maintenance.AllowedRoles) == 0 {
+		return false
+	}
+	user, _ := auth.UserFromContext(req.Context())
+	return user.HasAnyRole(maintenance.AllowedRoles)
+}
+
+// maintenanceExempt проверяет маршруты, доступные в режиме обслуживания:
+// конфигурация (для показа сообщения в UI), проверка состояния, вход и управление
+// самим режимом (изменение защищено разрешением maintenance:write)
+func maintenanceExempt(req *http.Request) bool {
+	path := strings.TrimRight(req.URL.Path, "/")
+	for _, suffix := range []string{"/admin/config", "/admin/health", "/admin/login", "/admin/logout", "/api/maintenance"} {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleGetMaintenance возвращает состояние режима обслуживания
+func (r *Router) handleGetMaintenance(w http.ResponseWriter, req *http.Request) {
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    r.Maintenance(),
+	})
+}
+
+// handleSetMaintenance включает или выключает режим обслуживания
+func (r *Router) handleSetMaintenance(w http.ResponseWriter, req *http.Request) {
+	var maintenance types.Maintenance
+	if err := r.decodeJSON(req.Body, &maintenance); err != nil {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return
+	}
+	maintenance.UpdatedAt = time.Now()
+
+	r.mu.RLock()
+	persist := r.maintenancePersist
+	r.mu.RUnlock()
+
+	if persist != nil {
+		if err := persist(req.Context(), &maintenance); err != nil {
+			r.reportRequestError(req, err, reporting.KindStorage, "", "saveMaintenance")
+			r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка сохранения режима обслуживания: %v", err))
+			return
+		}
+	}
+
+	r.SetMaintenance(maintenance)
+	r.Audit().Record(req.Context(), audit.ActionMaintenanceChange, "", map[string]interface{}{
+		"enabled": maintenance.Enabled,
+		"message": maintenance.Message,
+	})
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    r.Maintenance(),
+	})
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/koteyye/go-formist/auth"
+)
+
+// TestMaintenanceToggleThroughAPI проверяет, что режим обслуживания без разрешенных ролей
+// выключается через API, которым был включен
+func TestMaintenanceToggleThroughAPI(t *testing.T) {
+	r := NewRouter()
+	r.AddAPIKey("ops", "secret", auth.PermissionMaintenance)
+	handler := r.Handler()
+
+	send := func(method, body string) int {
+		req := httptest.NewRequest(method, "/api/maintenance", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(APIKeyHeader, "secret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if status := send(http.MethodPut, `{"enabled":true,"message":"Работы"}`); status != http.StatusOK {
+		t.Fatalf("включение: статус %d", status)
+	}
+	if status := send(http.MethodGet, ""); status != http.StatusOK {
+		t.Fatalf("чтение во время обслуживания: статус %d", status)
+	}
+	if status := send(http.MethodPut, `{"enabled":false}`); status != http.StatusOK {
+		t.Fatalf("выключение: статус %d", status)
+	}
+	if r.Maintenance().Enabled {
+		t.Fatal("режим обслуживания не выключен")
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/forms/", nil))
+	if w.Code == http.StatusServiceUnavailable {
+		t.Fatal("после выключения админка отвечает 503")
+	}
+}
@@ -0,0 +1,124 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/interpolate"
+	"github.com/koteyye/go-formist/types"
+)
+
+// handleMetaResolve возвращает метаданные формы или страницы по пути ссылки (?path=...).
+// Путь может содержать префикс админки и параметры запроса, доступные в шаблонах как {{query.<имя>}}
+func (r *Router) handleMetaResolve(w http.ResponseWriter, req *http.Request) {
+	target, err := url.Parse(req.URL.Query().Get("path"))
+	if err != nil || target.Path == "" {
+		r.sendError(w, http.StatusBadRequest, "Некорректный путь")
+		return
+	}
+
+	vars := r.metaVars(req)
+	for name, values := range target.Query() {
+		if len(values) > 0 {
+			vars["query."+name] = values[0]
+		}
+	}
+
+	resolved, status, message := r.resolveMeta(req, target.Path, vars)
+	if status != http.StatusOK {
+		r.sendError(w, status, message)
+		return
+	}
+	resolved.Path = target.String()
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    resolved,
+	})
+}
+
+// metaVars возвращает переменные запроса для шаблонов метаданных
+func (r *Router) metaVars(req *http.Request) interpolate.Vars {
+	vars := r.requestVars(req)
+
+	r.mu.RLock()
+	vars["admin.title"] = r.title
+	r.mu.RUnlock()
+
+	return vars
+}
+
+// resolveMeta находит форму или страницу по пути и вычисляет ее метаданные
+func (r *Router) resolveMeta(req *http.Request, path string, vars interpolate.Vars) (*types.ResolvedMeta, int, string) {
+	path = strings.TrimPrefix(path, r.Prefix())
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case len(segments) == 3 && segments[0] == "admin" && segments[1] == "forms":
+		return r.formMeta(segments[2], vars)
+
+	case len(segments) == 5 && segments[0] == "admin" && segments[1] == "modules" && segments[3] == "forms":
+		module, exists := r.lookupModule(segments[2])
+		if !exists {
+			return nil, http.StatusNotFound, "Модуль не найден"
+		}
+		user, _ := auth.UserFromContext(req.Context())
+		if !user.HasAnyRole(module.Roles) {
+			return nil, http.StatusForbidden, "Нет доступа к модулю"
+		}
+		return r.formMeta(segments[2]+"/"+segments[4], vars)
+
+	case len(segments) == 3 && segments[0] == "admin" && segments[1] == "pages":
+		page, exists := r.lookupPage(segments[2])
+		if !exists {
+			return nil, http.StatusNotFound, "Страница не найдена"
+		}
+		resolved := resolveMetaTemplate(page.Meta, page.Title, "", vars)
+		resolved.Type = types.MenuItemPage
+		resolved.Key = page.Name
+		resolved.Icon = page.Icon
+		return resolved, http.StatusOK, ""
+	}
+
+	return nil, http.StatusNotFound, "Путь не относится к форме или странице"
+}
+
+// formMeta вычисляет метаданные формы по ключу
+func (r *Router) formMeta(key string, vars interpolate.Vars) (*types.ResolvedMeta, int, string) {
+	form, exists := r.lookupForm(key)
+	if !exists {
+		return nil, http.StatusNotFound, "Форма не найдена"
+	}
+
+	resolved := resolveMetaTemplate(form.Meta, form.Title, form.Description, vars)
+	resolved.Type = types.MenuItemForm
+	resolved.Key = form.Key()
+	resolved.Icon = form.Icon
+	return resolved, http.StatusOK, ""
+}
+
+// resolveMetaTemplate подставляет переменные в шаблоны метаданных.
+// Без шаблона используются заголовок и описание формы или страницы
+func resolveMetaTemplate(meta *types.Meta, title, description string, vars interpolate.Vars) *types.ResolvedMeta {
+	title = interpolate.Expand(title, vars)
+	vars["title"] = title
+
+	resolved := &types.ResolvedMeta{
+		Title:       title,
+		Description: interpolate.Expand(description, vars),
+	}
+	if meta == nil {
+		return resolved
+	}
+
+	if meta.Title != "" {
+		resolved.Title = interpolate.Expand(meta.Title, vars)
+	}
+	if meta.Description != "" {
+		resolved.Description = interpolate.Expand(meta.Description, vars)
+	}
+	resolved.Image = meta.Image
+	return resolved
+}
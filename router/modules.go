@@ -0,0 +1,110 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/types"
+)
+
+// RegisterModule регистрирует модуль форм
+func (r *Router) RegisterModule(module *types.Module) {
+	clone := *module
+	clone.Roles = append([]string(nil), module.Roles...)
+	clone.Middlewares = append([]types.MiddlewareFunc(nil), module.Middlewares...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.modules[module.Name] = &clone
+	r.updatedAt = time.Now()
+}
+
+// lookupModule возвращает зарегистрированный модуль по имени
+func (r *Router) lookupModule(name string) (*types.Module, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	module, exists := r.modules[name]
+	return module, exists
+}
+
+// FormPath возвращает путь к форме с учетом префикса и модуля
+func (r *Router) FormPath(form *types.Form) string {
+	if form.Module == "" {
+		return r.prefix + "/admin/forms/" + form.Name
+	}
+	return r.prefix + "/admin/modules/" + form.Module + "/forms/" + form.Name
+}
+
+// formKey возвращает ключ формы из параметров запроса
+func formKey(req *http.Request) string {
+	name := chi.URLParam(req, "name")
+	if module := chi.URLParam(req, "module"); module != "" {
+		return module + "/" + name
+	}
+	return name
+}
+
+// moduleGuard проверяет доступ к модулю и применяет его middleware
+func (r *Router) moduleGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		module, exists := r.lookupModule(chi.URLParam(req, "module"))
+		if !exists {
+			r.sendError(w, http.StatusNotFound, "Модуль не найден")
+			return
+		}
+
+		user, _ := auth.UserFromContext(req.Context())
+		if !user.HasAnyRole(module.Roles) {
+			r.sendError(w, http.StatusForbidden, "Нет доступа к модулю")
+			return
+		}
+
+		handler := next
+		for i := len(module.Middlewares) - 1; i >= 0; i-- {
+			handler = module.Middlewares[i](handler)
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// mountModuleRoutes монтирует маршруты форм модулей
+func (r *Router) mountModuleRoutes(adminRouter chi.Router) {
+	adminRouter.Route("/modules/{module}", func(moduleRouter chi.Router) {
+		moduleRouter.Use(r.moduleGuard)
+		r.mountFormRoutes(moduleRouter, "/forms/{name}")
+	})
+}
+
+// mountFormRoutes монтирует маршруты отдельной формы с путем base
+func (r *Router) mountFormRoutes(router chi.Router, base string) {
+	router.Group(func(formRouter chi.Router) {
+		formRouter.Use(r.tokenScope, r.trackSLO, r.debugPayloads, r.captureSnapshots, r.enforceQuotas, r.checkSchemaVersion, recordContext)
+		formRouter.Get(base, r.handleFormGet)
+		formRouter.Post(base, r.handleFormPost)
+		formRouter.Patch(base+"/{id}", r.handleFormPatch)
+		formRouter.Get(base+"/schema", r.handleFormSchema)
+		formRouter.Get(base+"/data", r.handleFormData)
+		formRouter.Get(base+"/tables/{field}", r.handleTableGet)
+		formRouter.Get(base+"/tables/{field}/filter", r.handleTableFilter)
+		formRouter.Patch(base+"/tables/{field}/rows/{id}/cells/{key}", r.handleTableCellUpdate)
+		formRouter.Post(base+"/batch", r.handleFormBatch)
+		formRouter.Post(base+"/actions/{action}", r.handleFormAction)
+		formRouter.Get(base+"/duplicate", r.handleFormDuplicate)
+		formRouter.Get(base+"/fields/{field}/suggest", r.handleFieldSuggest)
+	})
+}
+
+// recordContext передает обработчикам формы ID записи из параметра ?id= (types.RecordID)
+func recordContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if id := req.URL.Query().Get(types.LinkParamDefault); id != "" {
+			req = req.WithContext(types.WithRecordID(req.Context(), id))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
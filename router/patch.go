@@ -0,0 +1,180 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/form"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// handleFormPatch частично обновляет запись {id}: JSON Patch (application/json-patch+json)
+// или JSON Merge Patch (application/merge-patch+json). Проверяются только затронутые поля,
+// обработчик получает только их. С ?dry_run=true возвращает изменения без сохранения
+func (r *Router) handleFormPatch(w http.ResponseWriter, req *http.Request) {
+	f, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+	if f.Patch == nil || f.Patch.Handler == nil {
+		r.sendError(w, http.StatusMethodNotAllowed, "PATCH не поддерживается для этой формы")
+		return
+	}
+	// Частичное обновление не должно обходить согласование отправок
+	if f.Approval != nil {
+		r.sendError(w, http.StatusMethodNotAllowed, "Изменения формы требуют согласования, используйте POST")
+		return
+	}
+	id := chi.URLParam(req, "id")
+
+	changes, status, err := r.patchChanges(req, f, id)
+	if aborted(req) {
+		return
+	}
+	if errors.Is(err, errOverloaded) {
+		r.sendOverloaded(w)
+		return
+	}
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			r.reportRequestError(req, err, reporting.KindHandler, f.Key(), "patchLoad")
+		}
+		r.sendError(w, status, err.Error())
+		return
+	}
+
+	if r.accessLog != nil {
+		r.noteAccess(req, f, changes)
+	}
+
+	if err := r.normalizeFormData(req, f, changes); err != nil {
+		r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Ошибка валидации: %v", err))
+		return
+	}
+	if err := r.validateFormChanges(req.Context(), f, changes); err != nil {
+		r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Ошибка валидации: %v", err))
+		return
+	}
+
+	warnings := r.formWarnings(f, changes)
+	if isDryRunRequest(req) {
+		r.sendJSON(w, types.APIResponse{
+			Success: true,
+			Data:    changes,
+			Message: "Пробный запуск: изменения не сохранены",
+			Meta:    warningsMeta(warnings),
+		})
+		return
+	}
+	if len(changes) == 0 {
+		r.sendJSON(w, types.APIResponse{
+			Success: true,
+			Message: "Изменений нет",
+		})
+		return
+	}
+	if len(warnings) > 0 && f.ConfirmWarnings && !warningsConfirmed(req) {
+		r.sendWarningsConfirmation(w, warnings)
+		return
+	}
+
+	result, err := r.callFormHandler(req.Context(), f, func(ctx context.Context) (interface{}, error) {
+		return f.Patch.Handler(ctx, id, changes)
+	})
+	if aborted(req) {
+		return
+	}
+	if errors.Is(err, errOverloaded) {
+		r.sendOverloaded(w)
+		return
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, f.Key(), "onPatch")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка обработки: %v", err))
+		return
+	}
+
+	fields := make([]string, 0, len(changes))
+	for name := range changes {
+		fields = append(fields, name)
+	}
+	slices.Sort(fields)
+
+	r.responses.invalidate(f.Key())
+	r.recordProvenance(req, f, id, changes)
+	r.Audit().Record(req.Context(), audit.ActionFormPatch, f.Key(), map[string]interface{}{
+		"record": id,
+		"fields": fields,
+	})
+	r.sendResult(w, req, result, warnings)
+}
+
+// patchChanges разбирает патч и возвращает измененные поля записи. С Patch.Load патч
+// применяется к загруженной записи. При ошибке возвращает статус ответа
+func (r *Router) patchChanges(req *http.Request, f *types.Form, id string) (map[string]interface{}, int, error) {
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if contentType != form.ContentTypeJSONPatch && contentType != form.ContentTypeMergePatch {
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("ожидается %s или %s", form.ContentTypeJSONPatch, form.ContentTypeMergePatch)
+	}
+
+	var ops []form.PatchOperation
+	var merge map[string]interface{}
+	var err error
+	if contentType == form.ContentTypeJSONPatch {
+		err = r.decodeJSON(req.Body, &ops)
+	} else {
+		err = r.decodeJSON(req.Body, &merge)
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("Некорректный патч")
+	}
+
+	if f.Patch.Load == nil {
+		var changes map[string]interface{}
+		if ops != nil {
+			changes, err = form.JSONPatchFields(ops)
+		} else {
+			changes, err = form.MergePatchFields(merge)
+		}
+		if err != nil {
+			return nil, http.StatusUnprocessableEntity, err
+		}
+		return changes, http.StatusOK, nil
+	}
+
+	result, err := r.callFormHandler(req.Context(), f, func(ctx context.Context) (interface{}, error) {
+		return f.Patch.Load(ctx, id)
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Ошибка получения записи: %w", err)
+	}
+	record, _ := result.(map[string]interface{})
+	if record == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("Запись не найдена")
+	}
+
+	var patched map[string]interface{}
+	if ops != nil {
+		patched, err = form.ApplyJSONPatch(record, ops)
+	} else {
+		patched, err = form.ApplyMergePatch(record, merge)
+	}
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	changes, err := form.PatchChanges(record, patched)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return changes, http.StatusOK, nil
+}
@@ -0,0 +1,171 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/auth"
+)
+
+const (
+	// DefaultProfileDuration длительность CPU профиля по умолчанию
+	DefaultProfileDuration = 30 * time.Second
+
+	// MaxProfileDuration максимальная длительность CPU профиля и трассировки
+	MaxProfileDuration = 2 * time.Minute
+)
+
+// SetProfiling монтирует /admin/debug/pprof для клиентов из сетей networks.
+// Пустой список выключает профилирование
+func (r *Router) SetProfiling(networks []netip.Prefix) {
+	r.profiling = slices.Clone(networks)
+	r.rebuild()
+}
+
+// mountProfiling монтирует эндпоинты профилирования, если они включены
+func (r *Router) mountProfiling(adminRouter chi.Router) {
+	if len(r.profiling) == 0 {
+		return
+	}
+
+	adminRouter.Route("/debug/pprof", func(pprofRouter chi.Router) {
+		pprofRouter.Use(r.profilingGuard, r.requireReadPermission(auth.PermissionProfiling))
+		pprofRouter.Get("/", r.handlePprofIndex)
+		pprofRouter.Get("/profile", r.handlePprofCPU)
+		pprofRouter.Get("/trace", r.handlePprofTrace)
+		pprofRouter.Get("/{profile}", r.handlePprofProfile)
+	})
+}
+
+// profilingGuard пропускает только клиентов из разрешенных сетей. Для остальных
+// эндпоинты выглядят несуществующими. Адрес берется из RemoteAddr: за прокси
+// подключите middleware.RealIP
+func (r *Router) profilingGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		addr, err := netip.ParseAddr(remoteIP(req))
+		if err == nil && slices.ContainsFunc(r.profiling, func(network netip.Prefix) bool {
+			return network.Contains(addr.Unmap())
+		}) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		r.sendError(w, http.StatusNotFound, "Не найдено")
+	})
+}
+
+// profileDuration читает ?seconds= с ограничением MaxProfileDuration
+func profileDuration(req *http.Request, fallback time.Duration) time.Duration {
+	seconds, err := strconv.ParseFloat(req.URL.Query().Get("seconds"), 64)
+	if err != nil || seconds <= 0 {
+		return fallback
+	}
+	return min(time.Duration(seconds*float64(time.Second)), MaxProfileDuration)
+}
+
+// recordProfiling записывает снятие профиля в журнал аудита
+func (r *Router) recordProfiling(req *http.Request, profile string) {
+	r.Audit().Record(req.Context(), audit.ActionProfilingCapture, profile, map[string]interface{}{
+		"ip": remoteIP(req),
+	})
+}
+
+// handlePprofIndex возвращает список доступных профилей
+func (r *Router) handlePprofIndex(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprintln(w, "profile?seconds=30 - CPU профиль")
+	fmt.Fprintln(w, "trace?seconds=1 - трассировка выполнения")
+	for _, profile := range pprof.Profiles() {
+		fmt.Fprintf(w, "%s - %d\n", profile.Name(), profile.Count())
+	}
+}
+
+// handlePprofProfile отдает именованный профиль (heap, goroutine, allocs, block, mutex...).
+// ?debug=1 - текстовый формат, ?gc=1 - сборка мусора перед снятием heap
+func (r *Router) handlePprofProfile(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "profile")
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		r.sendError(w, http.StatusNotFound, "Профиль не найден")
+		return
+	}
+
+	query := req.URL.Query()
+	debug, _ := strconv.Atoi(query.Get("debug"))
+	if name == "heap" && query.Get("gc") != "" {
+		runtime.GC()
+	}
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	}
+	r.recordProfiling(req, name)
+	profile.WriteTo(w, debug)
+}
+
+// handlePprofCPU снимает CPU профиль за ?seconds= (по умолчанию DefaultProfileDuration)
+func (r *Router) handlePprofCPU(w http.ResponseWriter, req *http.Request) {
+	duration := profileDuration(req, DefaultProfileDuration)
+	extendWriteDeadline(w, duration)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	w.Header().Set("Cache-Control", "no-store")
+	if err := pprof.StartCPUProfile(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		r.sendError(w, http.StatusConflict, fmt.Sprintf("CPU профиль уже снимается: %v", err))
+		return
+	}
+	r.recordProfiling(req, "profile")
+	waitProfile(req, duration)
+	pprof.StopCPUProfile()
+}
+
+// handlePprofTrace записывает трассировку выполнения за ?seconds= (по умолчанию 1 секунда)
+func (r *Router) handlePprofTrace(w http.ResponseWriter, req *http.Request) {
+	duration := profileDuration(req, time.Second)
+	extendWriteDeadline(w, duration)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	w.Header().Set("Cache-Control", "no-store")
+	if err := trace.Start(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		r.sendError(w, http.StatusConflict, fmt.Sprintf("Трассировка уже выполняется: %v", err))
+		return
+	}
+	r.recordProfiling(req, "trace")
+	waitProfile(req, duration)
+	trace.Stop()
+}
+
+// extendWriteDeadline продлевает WriteTimeout сервера на время снятия профиля
+func extendWriteDeadline(w http.ResponseWriter, duration time.Duration) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(duration + 10*time.Second))
+}
+
+// waitProfile ждет окончания снятия профиля или ухода клиента
+func waitProfile(req *http.Request, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-req.Context().Done():
+	}
+}
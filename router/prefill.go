@@ -0,0 +1,21 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/koteyye/go-formist/form"
+	"github.com/koteyye/go-formist/types"
+)
+
+// formPrefill возвращает значения полей из параметров ?prefill[поле]=значение,
+// проверенные по определению формы и справочникам
+func (r *Router) formPrefill(req *http.Request, f *types.Form) (map[string]interface{}, error) {
+	values, err := form.ParsePrefill(f, req.URL.Query())
+	if err != nil || values == nil {
+		return nil, err
+	}
+	if err := r.validateDictionaryValues(req.Context(), f, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,199 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/types"
+)
+
+// PresenceKeepAlive интервал пингов канала присутствия, чтобы прокси не закрывали соединение
+const PresenceKeepAlive = 25 * time.Second
+
+// presenceConn соединение канала присутствия
+type presenceConn struct {
+	viewer  types.Viewer
+	updates chan struct{}
+}
+
+// presenceHub отслеживает, кто сейчас открыл форму или запись.
+// Зритель присутствует, пока открыт его канал GET /admin/presence
+// или продолжаются запросы GET /admin/presence/poll
+type presenceHub struct {
+	mu       sync.Mutex
+	seq      uint64
+	targets  map[string]map[uint64]*presenceConn
+	versions map[string]uint64 // номер последнего изменения цели, курсор long polling
+	polls    *pollSessions
+}
+
+// newPresenceHub создает реестр присутствия
+func newPresenceHub() *presenceHub {
+	return &presenceHub{
+		targets:  make(map[string]map[uint64]*presenceConn),
+		versions: make(map[string]uint64),
+		polls:    newPollSessions(),
+	}
+}
+
+// presenceTarget ключ формы или записи формы
+func presenceTarget(form *types.Form, id string) string {
+	return form.Key() + "#" + id
+}
+
+// join регистрирует зрителя и уведомляет остальных. Возвращает соединение и функцию выхода
+func (h *presenceHub) join(target string, viewer types.Viewer) (uint64, *presenceConn, func()) {
+	conn := &presenceConn{viewer: viewer, updates: make(chan struct{}, 1)}
+
+	h.mu.Lock()
+	h.seq++
+	id := h.seq
+	if h.targets[target] == nil {
+		h.targets[target] = make(map[uint64]*presenceConn)
+	}
+	h.targets[target][id] = conn
+	h.notify(target)
+	h.mu.Unlock()
+
+	return id, conn, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.targets[target], id)
+		if len(h.targets[target]) == 0 {
+			delete(h.targets, target)
+			delete(h.versions, target)
+			return
+		}
+		h.notify(target)
+	}
+}
+
+// notify сообщает соединениям цели об изменении. Вызывается под блокировкой
+func (h *presenceHub) notify(target string) {
+	h.versions[target]++
+	for _, conn := range h.targets[target] {
+		select {
+		case conn.updates <- struct{}{}:
+		default: // уведомление уже ожидает обработки
+		}
+	}
+}
+
+// version возвращает номер последнего изменения цели
+func (h *presenceHub) version(target string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.versions[target]
+}
+
+// viewers возвращает зрителей цели, кроме соединения exceptConn и пользователя exceptUser.
+// Пользователь с несколькими вкладками учитывается один раз
+func (h *presenceHub) viewers(target string, exceptConn uint64, exceptUser string) []types.Viewer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var viewers []types.Viewer
+	seen := make(map[string]int)
+	for id, conn := range h.targets[target] {
+		viewer := conn.viewer
+		if id == exceptConn || (exceptUser != "" && viewer.UserID == exceptUser) {
+			continue
+		}
+		if viewer.UserID != "" {
+			if i, ok := seen[viewer.UserID]; ok {
+				if viewer.Since.Before(viewers[i].Since) {
+					viewers[i].Since = viewer.Since
+				}
+				continue
+			}
+			seen[viewer.UserID] = len(viewers)
+		}
+		viewers = append(viewers, viewer)
+	}
+
+	sort.Slice(viewers, func(i, j int) bool { return viewers[i].Since.Before(viewers[j].Since) })
+	return viewers
+}
+
+// formViewers возвращает других пользователей, открывших форму или запись из ?id=
+func (r *Router) formViewers(req *http.Request, form *types.Form) []types.Viewer {
+	target := presenceTarget(form, req.URL.Query().Get(types.LinkParamDefault))
+	return r.presence.viewers(target, 0, requestUserID(req))
+}
+
+// presenceRequest проверяет форму и доступ к ней и возвращает цель присутствия (?form=ключ&id=запись).
+// При ошибке отправляет ответ и возвращает false
+func (r *Router) presenceRequest(w http.ResponseWriter, req *http.Request) (string, bool) {
+	query := req.URL.Query()
+	form, exists := r.lookupForm(query.Get("form"))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return "", false
+	}
+	if status, message := r.formAccess(req, form); status != http.StatusOK {
+		r.sendError(w, status, message)
+		return "", false
+	}
+	return presenceTarget(form, query.Get(types.LinkParamDefault)), true
+}
+
+// presenceViewer возвращает зрителя текущего запроса
+func presenceViewer(req *http.Request) types.Viewer {
+	viewer := types.Viewer{Since: time.Now().UTC()}
+	if user, ok := auth.UserFromContext(req.Context()); ok {
+		viewer.UserID, viewer.Name = user.ID, user.Name
+	}
+	return viewer
+}
+
+// handlePresence держит канал Server-Sent Events присутствия (?form=ключ&id=запись):
+// пока канал открыт, пользователь виден другим, и ему отправляются события
+// presence со списком остальных зрителей
+func (r *Router) handlePresence(w http.ResponseWriter, req *http.Request) {
+	target, ok := r.presenceRequest(w, req)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		r.sendError(w, http.StatusNotImplemented, "Потоковые ответы не поддерживаются")
+		return
+	}
+
+	viewer := presenceViewer(req)
+	connID, conn, leave := r.presence.join(target, viewer)
+	defer leave()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(PresenceKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-conn.updates:
+			viewers := r.presence.viewers(target, connID, viewer.UserID)
+			if viewers == nil {
+				viewers = []types.Viewer{}
+			}
+			data, _ := json.Marshal(viewers)
+			fmt.Fprintf(w, "event: presence\ndata: %s\n\n", data)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,72 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/privacy"
+	"github.com/koteyye/go-formist/types"
+)
+
+// AddPrivacySource подключает хранилище с данными субъектов к экспорту и удалению
+func (r *Router) AddPrivacySource(name string, source privacy.Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.privacySources[name] = source
+}
+
+// PrivacySources возвращает хранилища с данными субъектов:
+// журнал аудита, хранилища заявок, комментариев, токенов и изменений полей и подключенные вручную
+func (r *Router) PrivacySources() map[string]privacy.Source {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sources := make(map[string]privacy.Source, len(r.privacySources)+5)
+	if r.audit != nil {
+		sources["audit"] = r.audit
+	}
+	if store, ok := r.workflow.Store().(privacy.Source); ok {
+		sources["submissions"] = store
+	}
+	if store, ok := r.comments.Store().(privacy.Source); ok {
+		sources["comments"] = store
+	}
+	if store, ok := r.tokens.Store().(privacy.Source); ok {
+		sources["tokens"] = store
+	}
+	if store, ok := r.provenance.Store().(privacy.Source); ok {
+		sources["provenance"] = store
+	}
+	for name, source := range r.privacySources {
+		sources[name] = source
+	}
+	return sources
+}
+
+// handlePrivacyExport выгружает все данные субъекта
+func (r *Router) handlePrivacyExport(w http.ResponseWriter, req *http.Request) {
+	data, report := privacy.Export(req.Context(), chi.URLParam(req, "subject"), r.PrivacySources())
+	// Идентификатор субъекта в журнал не пишем
+	r.Audit().Record(req.Context(), audit.ActionPrivacyExport, "", map[string]interface{}{"records": report.Total})
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"report": report,
+			"data":   data,
+		},
+	})
+}
+
+// handlePrivacyErase обезличивает данные субъекта во всех хранилищах
+func (r *Router) handlePrivacyErase(w http.ResponseWriter, req *http.Request) {
+	report := privacy.Erase(req.Context(), chi.URLParam(req, "subject"), r.PrivacySources())
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
@@ -0,0 +1,53 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/koteyye/go-formist/provenance"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// SetProvenance устанавливает учет изменений полей записей
+func (r *Router) SetProvenance(tracker *provenance.Tracker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.provenance = tracker
+}
+
+// Provenance возвращает учет изменений полей записей
+func (r *Router) Provenance() *provenance.Tracker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.provenance
+}
+
+// fieldProvenance возвращает последние изменения полей записи ?id= для формы с учетом изменений.
+// Ошибка хранилища не мешает открыть запись: изменения просто не показываются
+func (r *Router) fieldProvenance(req *http.Request, form *types.Form) map[string]types.FieldChange {
+	record := req.URL.Query().Get(types.LinkParamDefault)
+	if !form.Provenance || record == "" {
+		return nil
+	}
+
+	changes, err := r.Provenance().Fields(req.Context(), form.Key(), record)
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindStorage, form.Key(), "provenance")
+		return nil
+	}
+	return changes
+}
+
+// recordProvenance отмечает поля записи record, измененные отправкой формы.
+// Вызывается после успешного сохранения; ошибка хранилища не отменяет отправку
+func (r *Router) recordProvenance(req *http.Request, form *types.Form, record string, data map[string]interface{}) {
+	if !form.Provenance || record == "" {
+		return
+	}
+
+	if _, err := r.Provenance().Record(req.Context(), form, record, data); err != nil {
+		r.reportRequestError(req, err, reporting.KindStorage, form.Key(), "provenance")
+	}
+}
@@ -0,0 +1,165 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/koteyye/go-formist/types"
+)
+
+// proxyPath путь страниц-прокси относительно /admin
+const proxyPath = "/proxy"
+
+// ForwardedPrefixHeader заголовок с путем, под которым инструмент доступен через админку
+const ForwardedPrefixHeader = "X-Forwarded-Prefix"
+
+// proxyBaseKey ключ контекста с путем страницы-прокси
+type proxyBaseKey struct{}
+
+// ValidatePageProxy проверяет настройки страницы-прокси
+func ValidatePageProxy(page *types.Page) error {
+	if page.Proxy == nil {
+		return nil
+	}
+	_, err := newPageProxy(page.Proxy)
+	return err
+}
+
+// pageProxyPath возвращает путь страницы-прокси с учетом префикса
+func (r *Router) pageProxyPath(name string) string {
+	return r.prefix + "/admin" + proxyPath + "/" + name
+}
+
+// handlePageProxy передает запрос внешнему инструменту страницы-прокси
+func (r *Router) handlePageProxy(w http.ResponseWriter, req *http.Request) {
+	name := chi.URLParam(req, "name")
+
+	r.mu.RLock()
+	proxy := r.pageProxies[name]
+	r.mu.RUnlock()
+
+	if proxy == nil {
+		r.sendError(w, http.StatusNotFound, "Страница не найдена")
+		return
+	}
+
+	base := r.pageProxyPath(name)
+	out := req.Clone(context.WithValue(req.Context(), proxyBaseKey{}, base))
+	out.URL.Path = "/" + chi.URLParam(req, "*")
+	out.URL.RawPath = ""
+	proxy.ServeHTTP(w, out)
+}
+
+// newPageProxy создает обратный прокси страницы
+func newPageProxy(config *types.PageProxy) (*httputil.ReverseProxy, error) {
+	upstream, err := url.Parse(config.Upstream)
+	if err != nil || (upstream.Scheme != "http" && upstream.Scheme != "https") || upstream.Host == "" {
+		return nil, fmt.Errorf("некорректный адрес прокси %q", config.Upstream)
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(upstream)
+			pr.SetXForwarded()
+			if base, ok := pr.In.Context().Value(proxyBaseKey{}).(string); ok {
+				pr.Out.Header.Set(ForwardedPrefixHeader, base)
+			}
+
+			pr.Out.Header.Del("Authorization")
+			pr.Out.Header.Del(APIKeyHeader)
+			if !config.ForwardCookies {
+				pr.Out.Header.Del("Cookie")
+			}
+			for name, value := range config.RequestHeaders {
+				pr.Out.Header.Set(name, value)
+			}
+		},
+		Transport: authorizingTransport{authorize: config.Authorize},
+		ModifyResponse: func(resp *http.Response) error {
+			for _, name := range config.RemoveResponseHeaders {
+				resp.Header.Del(name)
+			}
+			base, _ := resp.Request.Context().Value(proxyBaseKey{}).(string)
+			if base != "" {
+				rewriteLocation(resp.Header, upstream, base)
+			}
+			// Cookie инструмента без ForwardCookies не вернутся к нему, а с ForwardCookies
+			// ограничиваются путем страницы-прокси, чтобы не затрагивать остальную админку
+			if !config.ForwardCookies || base == "" {
+				resp.Header.Del("Set-Cookie")
+			} else {
+				rescopeCookies(resp.Header, base)
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprintf(w, "{\"success\":false,\"error\":%q}\n", "Инструмент недоступен: "+err.Error())
+		},
+	}, nil
+}
+
+// rewriteLocation переводит перенаправления инструмента на путь страницы-прокси
+func rewriteLocation(header http.Header, upstream *url.URL, base string) {
+	location := header.Get("Location")
+	if location == "" {
+		return
+	}
+
+	target, err := url.Parse(location)
+	if err != nil {
+		return
+	}
+	if target.IsAbs() && (target.Scheme != upstream.Scheme || target.Host != upstream.Host) {
+		return // перенаправление на другой сайт
+	}
+	if !target.IsAbs() && !strings.HasPrefix(target.Path, "/") {
+		return // относительный путь работает и через прокси
+	}
+
+	upstreamPath := strings.TrimRight(upstream.Path, "/")
+	rest, ok := strings.CutPrefix(target.Path, upstreamPath)
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return
+	}
+
+	rewritten := url.URL{Path: base + rest, RawQuery: target.RawQuery, Fragment: target.Fragment}
+	header.Set("Location", rewritten.String())
+}
+
+// rescopeCookies ограничивает Cookie инструмента путем страницы-прокси base на домене админки.
+// Некорректные Set-Cookie отбрасываются
+func rescopeCookies(header http.Header, base string) {
+	values := header.Values("Set-Cookie")
+	header.Del("Set-Cookie")
+	for _, value := range values {
+		cookie, err := http.ParseSetCookie(value)
+		if err != nil {
+			continue
+		}
+		cookie.Domain = ""
+		cookie.Path = base
+		header.Add("Set-Cookie", cookie.String())
+	}
+}
+
+// authorizingTransport подставляет учетные данные инструмента перед отправкой запроса
+type authorizingTransport struct {
+	authorize func(req *http.Request) error
+}
+
+// RoundTrip выполняет запрос
+func (t authorizingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.authorize != nil {
+		if err := t.authorize(req); err != nil {
+			return nil, fmt.Errorf("авторизация: %w", err)
+		}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -0,0 +1,287 @@
+package router
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/types"
+)
+
+// QuotaSubject определяет субъект квоты по запросу: пользователя, арендатора и т.п.
+// Пустая строка - запрос не ограничивается квотами
+type QuotaSubject func(req *http.Request) string
+
+// DefaultQuotaSubject субъект квоты по умолчанию: ID пользователя, без аутентификации - IP клиента
+func DefaultQuotaSubject(req *http.Request) string {
+	if user, ok := auth.UserFromContext(req.Context()); ok {
+		return user.ID
+	}
+	return remoteIP(req)
+}
+
+// quotaTracker считает использование квот за текущие сутки UTC
+type quotaTracker struct {
+	quotas  types.Quotas
+	subject QuotaSubject
+
+	mu       sync.Mutex
+	day      string
+	usage    map[string]*types.QuotaUsage
+	rejected map[string]*atomic.Uint64
+}
+
+// newQuotaTracker создает счетчики квот
+func newQuotaTracker(quotas types.Quotas, subject QuotaSubject) *quotaTracker {
+	if subject == nil {
+		subject = DefaultQuotaSubject
+	}
+	return &quotaTracker{
+		quotas:  quotas,
+		subject: subject,
+		usage:   make(map[string]*types.QuotaUsage),
+		rejected: map[string]*atomic.Uint64{
+			types.QuotaSubmissions: new(atomic.Uint64),
+			types.QuotaUploadBytes: new(atomic.Uint64),
+			types.QuotaExportRows:  new(atomic.Uint64),
+		},
+	}
+}
+
+// current возвращает использование субъекта, сбрасывая счетчики при смене суток
+func (q *quotaTracker) current(subject string, now time.Time) *types.QuotaUsage {
+	if day := now.UTC().Format(time.DateOnly); day != q.day {
+		q.day = day
+		clear(q.usage)
+	}
+	usage, ok := q.usage[subject]
+	if !ok {
+		usage = &types.QuotaUsage{Submissions: make(map[string]int)}
+		q.usage[subject] = usage
+	}
+	return usage
+}
+
+// reserveUpload учитывает байты тела запроса, если они помещаются в суточную квоту
+func (q *quotaTracker) reserveUpload(subject string, size int64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage := q.current(subject, time.Now())
+	if usage.UploadBytes+size > q.quotas.UploadBytesPerDay {
+		return false
+	}
+	usage.UploadBytes += size
+	return true
+}
+
+// reserveSubmissions занимает count отправок формы в суточной квоте. Возвращает функцию,
+// возвращающую неуспешные отправки, и false, если все count не помещаются в квоту
+func (q *quotaTracker) reserveSubmissions(subject, form string, count int) (func(failed int), bool) {
+	limit := q.quotas.SubmissionLimit(form)
+	if limit <= 0 {
+		return func(int) {}, true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage := q.current(subject, time.Now())
+	if usage.Submissions[form]+count > limit {
+		return nil, false
+	}
+	usage.Submissions[form] += count
+	day := q.day
+	return func(failed int) {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		if q.day == day {
+			usage.Submissions[form] -= min(failed, usage.Submissions[form])
+		}
+	}, true
+}
+
+// stats возвращает снимок счетчиков
+func (q *quotaTracker) stats() *types.QuotaStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.current("", time.Now())
+	delete(q.usage, "")
+	stats := &types.QuotaStats{
+		Quotas:   q.quotas,
+		Day:      q.day,
+		Usage:    make(map[string]types.QuotaUsage, len(q.usage)),
+		Rejected: make(map[string]uint64, len(q.rejected)),
+	}
+	stats.Quotas.Forms = maps.Clone(q.quotas.Forms)
+	for subject, usage := range q.usage {
+		stats.Usage[subject] = types.QuotaUsage{
+			Submissions: maps.Clone(usage.Submissions),
+			UploadBytes: usage.UploadBytes,
+		}
+	}
+	for kind, counter := range q.rejected {
+		stats.Rejected[kind] = counter.Load()
+	}
+	return stats
+}
+
+// SetQuotas включает квоты использования (nil отключает их). subject nil - DefaultQuotaSubject
+func (r *Router) SetQuotas(quotas *types.Quotas, subject QuotaSubject) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.quotas = nil
+	if quotas != nil {
+		clone := *quotas
+		clone.Forms = maps.Clone(quotas.Forms)
+		r.quotas = newQuotaTracker(clone, subject)
+	}
+}
+
+// quotaTracker возвращает счетчики квот или nil
+func (r *Router) quotaTracker() *quotaTracker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.quotas
+}
+
+// QuotaStats возвращает использование квот за текущие сутки (nil, если квоты не включены)
+func (r *Router) QuotaStats() *types.QuotaStats {
+	tracker := r.quotaTracker()
+	if tracker == nil {
+		return nil
+	}
+	return tracker.stats()
+}
+
+// enforceQuotas проверяет квоты запросов к форме: байты тела изменяющих запросов
+// и число успешных отправок формы (POST формы, кроме пробного запуска).
+// Отправка, завершившаяся статусом >= 400, квоту не расходует
+func (r *Router) enforceQuotas(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tracker := r.quotaTracker()
+		if tracker == nil || req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+			next.ServeHTTP(w, req)
+			return
+		}
+		subject := tracker.subject(req)
+		if subject == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		release := func(int) {}
+		if req.Method == http.MethodPost && isSubmission(req) && !isDryRunRequest(req) {
+			var ok bool
+			if release, ok = tracker.reserveSubmissions(subject, formKey(req), 1); !ok {
+				tracker.sendSubmissionsExceeded(r, w, formKey(req))
+				return
+			}
+		}
+
+		if tracker.quotas.UploadBytesPerDay > 0 {
+			if req.ContentLength < 0 {
+				release(1)
+				r.sendError(w, http.StatusLengthRequired, "Не указан размер тела запроса (Content-Length)")
+				return
+			}
+			if !tracker.reserveUpload(subject, req.ContentLength) {
+				release(1)
+				tracker.rejected[types.QuotaUploadBytes].Add(1)
+				r.sendError(w, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("Превышена суточная квота загрузок: %d байт", tracker.quotas.UploadBytesPerDay))
+				return
+			}
+		}
+
+		sw := &sloWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, req)
+		if sw.status >= http.StatusBadRequest || (sw.status == 0 && req.Context().Err() != nil) {
+			release(1)
+		}
+	})
+}
+
+// sendSubmissionsExceeded отвечает 429 с Retry-After до сброса суточных квот
+func (q *quotaTracker) sendSubmissionsExceeded(r *Router, w http.ResponseWriter, form string) {
+	q.rejected[types.QuotaSubmissions].Add(1)
+	w.Header().Set("Retry-After", strconv.Itoa(untilMidnight(time.Now())))
+	r.sendError(w, http.StatusTooManyRequests,
+		fmt.Sprintf("Превышена суточная квота отправок формы: %d", q.quotas.SubmissionLimit(form)))
+}
+
+// reserveBatch занимает в квоте отправок count элементов пакета. Возвращает функцию
+// возврата неуспешных элементов; если пакет не помещается в квоту, отвечает 429
+func (r *Router) reserveBatch(w http.ResponseWriter, req *http.Request, form string, count int) (func(failed int), bool) {
+	tracker := r.quotaTracker()
+	if tracker == nil {
+		return func(int) {}, true
+	}
+	subject := tracker.subject(req)
+	if subject == "" {
+		return func(int) {}, true
+	}
+	release, ok := tracker.reserveSubmissions(subject, form, count)
+	if !ok {
+		tracker.sendSubmissionsExceeded(r, w, form)
+	}
+	return release, ok
+}
+
+// isSubmission сообщает, что запрос - отправка формы (POST на адрес формы).
+// Элементы пакетной отправки учитываются обработчиком пакета, см. reserveBatch
+func isSubmission(req *http.Request) bool {
+	rctx := chi.RouteContext(req.Context())
+	if rctx == nil {
+		return false
+	}
+	return strings.HasSuffix(strings.TrimRight(rctx.RoutePattern(), "/"), "/{name}")
+}
+
+// untilMidnight возвращает секунды до сброса суточных квот
+func untilMidnight(now time.Time) int {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return int(midnight.Sub(now).Seconds()) + 1
+}
+
+// exportRowsQuota проверяет число строк, запрошенных из таблицы (?limit=).
+// Без явного limit размер страницы ограничивается квотой; сверх квоты - 413
+func (r *Router) exportRowsQuota(w http.ResponseWriter, limit int, explicit bool) (int, bool) {
+	tracker := r.quotaTracker()
+	if tracker == nil || tracker.quotas.ExportRows <= 0 || limit <= tracker.quotas.ExportRows {
+		return limit, true
+	}
+	if !explicit {
+		return tracker.quotas.ExportRows, true
+	}
+	tracker.rejected[types.QuotaExportRows].Add(1)
+	r.sendError(w, http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("Запрошено строк больше квоты: %d", tracker.quotas.ExportRows))
+	return 0, false
+}
+
+// handleQuotas возвращает квоты и их использование за текущие сутки
+func (r *Router) handleQuotas(w http.ResponseWriter, req *http.Request) {
+	stats := r.QuotaStats()
+	if stats == nil {
+		r.sendError(w, http.StatusNotFound, "Квоты не включены")
+		return
+	}
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    stats,
+	})
+}
@@ -0,0 +1,129 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// raceForm форма с вложенными настройками, которые вызывающий код может изменить после регистрации
+func raceForm() *types.Form {
+	return &types.Form{
+		Name:  "orders",
+		Title: "Заказы",
+		Fields: []types.Field{
+			{Name: "title", Type: types.FieldTypeText, Label: "Название", Required: true,
+				Validation: []types.ValidationRule{{Type: "maxLength", Value: 64, Message: "Слишком длинное"}}},
+			{Name: "status", Type: types.FieldTypeSelect, Label: "Статус", Options: []types.SelectOption{
+				types.SelectOption{Value: "new", Label: "Новый"}.WithLabel("en", "New"),
+				types.SelectOption{Value: "done", Label: "Готов"}.WithLabel("en", "Done"),
+			}},
+			{Name: "items", Type: types.FieldTypeTable, Label: "Позиции", TableConfig: &types.TableConfig{
+				Columns: []types.TableColumn{
+					{Key: "id", Title: "ID", Type: types.FieldTypeText},
+					{Key: "kind", Title: "Вид", Type: types.FieldTypeSelect, Options: []types.SelectOption{
+						types.SelectOption{Value: "a", Label: "Первый"}.WithLabel("en", "First"),
+					}},
+				},
+			}},
+		},
+		Groups:  []types.FieldGroup{{Name: "main", Title: "Основное", Fields: []string{"title", "status"}}},
+		Tags:    []string{"sales"},
+		Actions: &types.Actions{Custom: []types.Action{{Name: "close", Label: "Закрыть"}}},
+		OnPost: func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
+			return data, nil
+		},
+	}
+}
+
+// mutateForm изменяет все вложенные настройки формы
+func mutateForm(form *types.Form, i int) {
+	label := strings.Repeat("x", i%8)
+	form.Title = label
+	form.Fields[0].Label = label
+	form.Fields[0].Validation[0].Value = i
+	form.Fields[1].Options[0].Label = label
+	form.Fields[1].Options[0].Labels["en"] = label
+	form.Fields[2].TableConfig.Columns[1].Title = label
+	form.Fields[2].TableConfig.Columns[1].Options[0].Labels["en"] = label
+	form.Groups[0].Fields[0] = label
+	form.Tags[0] = label
+	form.Actions.Custom[0].Label = label
+}
+
+// TestRegisterFormIsolatesCaller проверяет, что изменения формы после регистрации
+// не видны обработчикам запросов. Запускайте с go test -race
+func TestRegisterFormIsolatesCaller(t *testing.T) {
+	r := NewRouter()
+	form := raceForm()
+	if err := r.RegisterForm(form); err != nil {
+		t.Fatal(err)
+	}
+	handler := r.Handler()
+
+	stop := make(chan struct{})
+	mutated := make(chan struct{})
+	go func() {
+		defer close(mutated)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				mutateForm(form, i)
+			}
+		}
+	}()
+
+	requests := []func() *http.Request{
+		func() *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/admin/forms/orders", nil)
+		},
+		func() *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/admin/forms/orders/schema", nil)
+		},
+		func() *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+		},
+		func() *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/admin/forms/orders", strings.NewReader(`{"title":"Стол","status":"new"}`))
+			req.Header.Set("Content-Type", "application/json")
+			return req
+		},
+	}
+	var wg sync.WaitGroup
+	for _, newRequest := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				w := httptest.NewRecorder()
+				req := newRequest()
+				handler.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					t.Errorf("%s %s: статус %d: %s", req.Method, req.URL, w.Code, w.Body)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/forms/orders", nil))
+	close(stop)
+	<-mutated
+
+	body := w.Body.String()
+	for _, want := range []string{"Заказы", "Название", "Новый", "First", "Закрыть"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("ответ не содержит %q зарегистрированной формы: %s", want, body)
+		}
+	}
+}
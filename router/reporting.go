@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/reporting"
+)
+
+// SetErrorHandler устанавливает получателя ошибок админки (например, Sentry)
+func (r *Router) SetErrorHandler(handler reporting.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errorHandler = handler
+}
+
+// ReportError передает ошибку получателю, если он установлен.
+// Отмена запроса клиентом ошибкой не считается
+func (r *Router) ReportError(ctx context.Context, err error, meta reporting.Meta) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	r.mu.RLock()
+	handler := r.errorHandler
+	r.mu.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	var panicErr *reporting.PanicError
+	if errors.As(err, &panicErr) {
+		meta.Kind = reporting.KindPanic
+		meta.Stack = panicErr.Stack
+	}
+	if user, ok := auth.UserFromContext(ctx); ok && meta.User == "" {
+		meta.User = user.ID
+	}
+
+	handler(ctx, err, meta)
+}
+
+// reportRequestError передает ошибку обработки запроса с его метаданными
+func (r *Router) reportRequestError(req *http.Request, err error, kind reporting.Kind, form, op string) {
+	r.ReportError(req.Context(), err, reporting.Meta{
+		Kind:      kind,
+		Form:      form,
+		Op:        op,
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		RequestID: middleware.GetReqID(req.Context()),
+	})
+}
+
+// panicReporter передает panic получателю ошибок и пробрасывает его дальше (в Recoverer)
+func (r *Router) panicReporter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p != http.ErrAbortHandler {
+					err := &reporting.PanicError{Value: p, Stack: debug.Stack()}
+					r.reportRequestError(req, err, reporting.KindPanic, "", "")
+				}
+				panic(p)
+			}
+		}()
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// panicError преобразует значение panic в ошибку со стеком вызовов
+func panicError(p interface{}) error {
+	return &reporting.PanicError{Value: p, Stack: debug.Stack()}
+}
@@ -0,0 +1,60 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/koteyye/go-formist/retention"
+	"github.com/koteyye/go-formist/types"
+)
+
+// SetRetention устанавливает очистку устаревших данных
+func (r *Router) SetRetention(purger *retention.Purger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.retention = purger
+}
+
+// Retention возвращает очистку устаревших данных (nil, если не настроена)
+func (r *Router) Retention() *retention.Purger {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.retention
+}
+
+// handleRetentionGet возвращает политики хранения и метрики очистки
+func (r *Router) handleRetentionGet(w http.ResponseWriter, req *http.Request) {
+	purger := r.Retention()
+	if purger == nil {
+		r.sendError(w, http.StatusNotImplemented, "Политики хранения не настроены")
+		return
+	}
+
+	policies := make(map[string]int)
+	for _, policy := range purger.Policies() {
+		policies[policy.Name] = policy.Days
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"policies": policies,
+			"stats":    purger.Stats(),
+		},
+	})
+}
+
+// handleRetentionRun запускает очистку; с ?dry_run=true только сообщает, что будет удалено
+func (r *Router) handleRetentionRun(w http.ResponseWriter, req *http.Request) {
+	purger := r.Retention()
+	if purger == nil {
+		r.sendError(w, http.StatusNotImplemented, "Политики хранения не настроены")
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    purger.Run(req.Context(), isDryRunRequest(req)),
+	})
+}
@@ -1,31 +1,117 @@
 package router
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
-	"strconv"
+	"net/http/httputil"
+	"net/netip"
+	"os"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
-
-	"github.com/koteyye/go-formist/schema"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/koteyye/go-formist/accesslog"
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/backup"
+	"github.com/koteyye/go-formist/chaos"
+	"github.com/koteyye/go-formist/comments"
+	"github.com/koteyye/go-formist/demo"
+	"github.com/koteyye/go-formist/dictionary"
+	"github.com/koteyye/go-formist/federation"
+	"github.com/koteyye/go-formist/icons"
+	"github.com/koteyye/go-formist/interpolate"
+	"github.com/koteyye/go-formist/privacy"
+	"github.com/koteyye/go-formist/provenance"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/retention"
+	"github.com/koteyye/go-formist/serialize"
+	"github.com/koteyye/go-formist/snapshot"
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/tokens"
 	"github.com/koteyye/go-formist/types"
+	"github.com/koteyye/go-formist/workflow"
 )
 
 // Router представляет HTTP роутер для админки
 type Router struct {
+	mu              sync.RWMutex
 	mux             *chi.Mux
 	forms           map[string]*types.Form
 	pages           map[string]*types.Page
+	pageProxies     map[string]*httputil.ReverseProxy
+	modules         map[string]*types.Module
 	title           string
 	authEnabled     bool
 	corsEnabled     bool
 	corsOrigins     []string
 	middlewares     []types.MiddlewareFunc
 	storageHandlers map[string]http.HandlerFunc
+	compressEnabled bool
+	compressMinSize int
+	prefix          string
+	updatedAt       time.Time
+	schemaCache     map[string]*types.FormResponse
+	validators      map[string]*formValidator
+	errorHandler    reporting.Handler
+	accessLog       *accesslog.Logger
+	chaos           *chaos.Injector
+	debugLog        *accesslog.DebugLogger
+	debugForms      map[string]*types.FormDebug
+	locale          string
+	timezone        *time.Location
+	templateVars    func(req *http.Request) map[string]string
+	icons           *icons.Registry
+	demo            *demo.Generator
+	limiters        map[string]*formLimiter
+	workflow        *workflow.Engine
+	comments        *comments.Service
+	provenance      *provenance.Tracker
+	tokens          *tokens.Service
+	dictionaries    *dictionary.Registry
+	serializers     *serialize.Registry
+	undo            *undoRegistry
+	responses       *responseCache
+	presence        *presenceHub
+	eventTransport  string
+	slo             *sloRegistry
+	shedder         *loadShedder
+	profiling       []netip.Prefix
+	environment     *types.Environment
+	branding        *types.Branding
+	readOnly        *types.ReadOnlyInfo
+	maintenance     *types.Maintenance
+	audit           *audit.Log
+	retention       *retention.Purger
+	backup          func() backup.Sources
+	configImport    *configImport
+	diagnose        func(ctx context.Context) *types.DiagnosticsReport
+	federation      *federation.Federation
+	federationProxy http.Handler
+	privacySources  map[string]privacy.Source
+	apiKeys         []apiKey
+	snapshots       *snapshot.Store
+	quotas          *quotaTracker
+	schemaVersions  map[string][]schemaVersion
+
+	maintenancePersist MaintenancePersister
+	schemaCacheControl string
+
+	// flights объединяет одновременные одинаковые чтения форм с Coalesce
+	flights singleflight.Group
+
+	// codec кодек JSON ответов и тел запросов (codecBox), см. SetCodec
+	codec atomic.Value
 }
 
 // NewRouter создает новый роутер
@@ -34,11 +120,30 @@ func NewRouter() *Router {
 		mux:         chi.NewRouter(),
 		forms:       make(map[string]*types.Form),
 		pages:       make(map[string]*types.Page),
+		pageProxies: make(map[string]*httputil.ReverseProxy),
+		modules:     make(map[string]*types.Module),
+		validators:  make(map[string]*formValidator),
+		limiters:    make(map[string]*formLimiter),
+		debugForms:  make(map[string]*types.FormDebug),
+		debugLog:    accesslog.NewDebug(os.Stderr, accesslog.Options{}),
 		title:       "Admin Panel",
 		authEnabled: false,
 		corsEnabled: false,
 		corsOrigins: []string{"*"},
 		middlewares: make([]types.MiddlewareFunc, 0),
+		updatedAt:   time.Now(),
+		workflow:    workflow.NewEngine(nil, nil),
+		comments:    comments.New(nil, nil),
+		provenance:  provenance.New(nil),
+		tokens:      tokens.New(nil),
+		undo:        newUndoRegistry(),
+		responses:   newResponseCache(),
+		presence:    newPresenceHub(),
+		slo:         newSLORegistry(),
+
+		privacySources: make(map[string]privacy.Source),
+		dictionaries:   dictionary.New(nil),
+		serializers:    serialize.New(),
 	}
 
 	r.setupMiddleware()
@@ -49,12 +154,20 @@ func NewRouter() *Router {
 
 // SetTitle устанавливает заголовок админки
 func (r *Router) SetTitle(title string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.title = title
+	r.updatedAt = time.Now()
 }
 
 // EnableAuth включает авторизацию
 func (r *Router) EnableAuth(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.authEnabled = enabled
+	r.updatedAt = time.Now()
 }
 
 // EnableCORS включает CORS
@@ -63,7 +176,11 @@ func (r *Router) EnableCORS(enabled bool, origins ...string) {
 	if len(origins) > 0 {
 		r.corsOrigins = origins
 	}
-	// Пересоздаем mux с новыми настройками
+	r.rebuild()
+}
+
+// rebuild пересоздает mux с текущими настройками
+func (r *Router) rebuild() {
 	r.mux = chi.NewRouter()
 	r.setupMiddleware()
 	r.setupRoutes()
@@ -72,16 +189,107 @@ func (r *Router) EnableCORS(enabled bool, origins ...string) {
 // AddMiddleware добавляет middleware
 func (r *Router) AddMiddleware(middleware types.MiddlewareFunc) {
 	r.middlewares = append(r.middlewares, middleware)
+	r.rebuild()
+}
+
+// RegisterForm регистрирует копию формы.
+// Возвращает ошибку, если правила валидации формы некорректны.
+// Форма с иконкой не из набора админки не регистрируется
+func (r *Router) RegisterForm(form *types.Form) error {
+	if err := r.ValidateIcon(form.Icon); err != nil {
+		return err
+	}
+	if err := form.SLO.Validate(); err != nil {
+		return err
+	}
+
+	form = form.Clone()
+	validator, err := compileValidator(form)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := form.Key()
+	r.forms[key] = form
+	r.validators[key] = validator
+	if limiter := newFormLimiter(form.Concurrency); limiter != nil {
+		r.limiters[key] = limiter
+	} else {
+		delete(r.limiters, key)
+	}
+	delete(r.schemaCache, key)
+	r.recordSchemaVersion(form)
+	r.responses.invalidate(key)
+	r.slo.remove(key)
+	r.updatedAt = time.Now()
+	return err
 }
 
-// RegisterForm регистрирует форму
-func (r *Router) RegisterForm(form *types.Form) {
-	r.forms[form.Name] = form
+// UnregisterForm удаляет форму из роутера
+func (r *Router) UnregisterForm(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.forms, name)
+	delete(r.validators, name)
+	delete(r.limiters, name)
+	delete(r.schemaCache, name)
+	delete(r.schemaVersions, name)
+	r.responses.invalidate(name)
+	r.slo.remove(name)
+	r.updatedAt = time.Now()
 }
 
-// RegisterPage регистрирует страницу
+// Form возвращает зарегистрированную форму по имени.
+// Возвращаемое значение не должно изменяться
+func (r *Router) Form(name string) (*types.Form, bool) {
+	return r.lookupForm(name)
+}
+
+// RegisterPage регистрирует копию страницы
 func (r *Router) RegisterPage(page *types.Page) {
-	r.pages[page.Name] = page
+	clone := *page
+	if page.Meta != nil {
+		meta := *page.Meta
+		clone.Meta = &meta
+	}
+	if page.Proxy != nil {
+		proxy := *page.Proxy
+		proxy.RequestHeaders = maps.Clone(proxy.RequestHeaders)
+		proxy.RemoveResponseHeaders = slices.Clone(proxy.RemoveResponseHeaders)
+		clone.Proxy = &proxy
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pages[page.Name] = &clone
+	delete(r.pageProxies, page.Name)
+	if clone.Proxy != nil {
+		// Некорректные настройки отклоняет ValidatePageProxy при регистрации через Admin
+		if proxy, err := newPageProxy(clone.Proxy); err == nil {
+			r.pageProxies[page.Name] = proxy
+		}
+	}
+	r.updatedAt = time.Now()
+}
+
+// lookupForm возвращает зарегистрированную форму по имени
+func (r *Router) lookupForm(name string) (*types.Form, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	form, exists := r.forms[name]
+	return form, exists
+}
+
+// lookupPage возвращает зарегистрированную страницу по имени
+func (r *Router) lookupPage(name string) (*types.Page, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page, exists := r.pages[name]
+	return page, exists
 }
 
 // Handler возвращает HTTP handler
@@ -100,43 +308,153 @@ func (r *Router) setupMiddleware() {
 	r.mux.Use(middleware.Logger)
 	r.mux.Use(middleware.Recoverer)
 	r.mux.Use(middleware.RequestID)
+	r.mux.Use(r.panicReporter)
+
+	// Журнал доступа
+	if r.accessLog != nil {
+		r.mux.Use(r.accessLogMiddleware)
+	}
+
+	// Ограничение одновременных запросов
+	if r.shedder != nil {
+		r.mux.Use(r.loadSheddingMiddleware)
+	}
+
+	// Сжатие ответов
+	if r.compressEnabled {
+		r.mux.Use(compressMiddleware(r.compressMinSize))
+	}
 
 	// CORS
 	if r.corsEnabled {
 		r.mux.Use(cors.Handler(cors.Options{
 			AllowedOrigins:   r.corsOrigins,
-			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-			ExposedHeaders:   []string{"Link"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", types.SchemaVersionHeader},
+			ExposedHeaders:   []string{"Link", storage.ConsistencyHeader, types.SchemaVersionHeader},
 			AllowCredentials: true,
 			MaxAge:           300,
 		}))
 	}
 
+	// Внедрение сбоев для проверки UI
+	if r.chaos != nil {
+		r.mux.Use(r.chaosMiddleware)
+	}
+
+	// Персональные токены доступа
+	r.mux.Use(r.tokenAuth)
+
 	// Кастомные middleware
 	for _, mw := range r.middlewares {
 		r.mux.Use(mw)
 	}
+	if r.accessLog != nil {
+		r.mux.Use(r.accessLogUser)
+	}
+
+	// Режим обслуживания и режим только для чтения
+	r.mux.Use(r.maintenanceGuard)
+	r.mux.Use(r.readOnlyGuard)
 }
 
-// setupRoutes настраивает маршруты
+// SetPrefix устанавливает префикс, под которым монтируются маршруты /admin и /api.
+// Позволяет обслуживать несколько админок в одном процессе
+func (r *Router) SetPrefix(prefix string) {
+	r.prefix = strings.TrimRight(prefix, "/")
+	if r.prefix != "" && !strings.HasPrefix(r.prefix, "/") {
+		r.prefix = "/" + r.prefix
+	}
+	r.rebuild()
+}
+
+// Prefix возвращает префикс маршрутов админки
+func (r *Router) Prefix() string {
+	return r.prefix
+}
+
+// setupRoutes настраивает маршруты с учетом префикса
 func (r *Router) setupRoutes() {
-	r.mux.Route("/admin", func(adminRouter chi.Router) {
+	if r.prefix == "" {
+		r.mountRoutes(r.mux)
+		return
+	}
+
+	r.mux.Route(r.prefix, func(prefixRouter chi.Router) {
+		r.mountRoutes(prefixRouter)
+	})
+}
+
+// mountRoutes монтирует маршруты админки в root
+func (r *Router) mountRoutes(root chi.Router) {
+	root.Route("/admin", func(adminRouter chi.Router) {
 		// Конфигурация админки
 		adminRouter.Get("/config", r.handleConfig)
 
+		// Проверка состояния
+		adminRouter.Get("/health", r.handleHealth)
+		adminRouter.With(r.requireReadPermission(auth.PermissionDiagnostics)).Get("/diagnostics", r.handleDiagnostics)
+
+		// Метаданные ссылок и ссылки на записи
+		adminRouter.Get("/meta/resolve", r.handleMetaResolve)
+		adminRouter.Get("/links/resolve", r.handleLinkResolve)
+
+		// Присутствие пользователей на формах и записях
+		adminRouter.Get("/presence", r.handlePresence)
+		adminRouter.Get("/presence/poll", r.handlePresencePoll)
+
 		// Формы
 		adminRouter.Route("/forms", func(formsRouter chi.Router) {
 			formsRouter.Get("/", r.handleFormsList)
-			formsRouter.Get("/{name}", r.handleFormGet)
-			formsRouter.Post("/{name}", r.handleFormPost)
+			r.mountFormRoutes(formsRouter, "/{name}")
 		})
 
+		// Формы модулей
+		r.mountModuleRoutes(adminRouter)
+
+		// Профилирование
+		r.mountProfiling(adminRouter)
+
+		// Согласование отправок
+		adminRouter.Route("/approvals", func(approvalsRouter chi.Router) {
+			approvalsRouter.Get("/", r.handleApprovalsList)
+			approvalsRouter.Get("/{id}", r.handleApprovalGet)
+			approvalsRouter.Post("/{id}/approve", r.handleApprovalApprove)
+			approvalsRouter.Post("/{id}/reject", r.handleApprovalReject)
+		})
+
+		// Комментарии к записям и заявкам
+		adminRouter.Route("/comments", func(commentsRouter chi.Router) {
+			commentsRouter.Get("/", r.handleCommentsList)
+			commentsRouter.Post("/", r.handleCommentCreate)
+			commentsRouter.Delete("/{id}", r.handleCommentDelete)
+		})
+
+		// Справочники для полей выбора
+		adminRouter.Get("/dictionaries", r.handleDictionariesList)
+		adminRouter.Get("/dictionaries/{name}", r.handleDictionaryEntries)
+
+		// Персональные токены доступа
+		adminRouter.Route("/tokens", func(tokensRouter chi.Router) {
+			tokensRouter.Get("/", r.handleTokensList)
+			tokensRouter.Post("/", r.handleTokenCreate)
+			tokensRouter.Delete("/{id}", r.handleTokenRevoke)
+		})
+
+		// Отмена действий в течение окна отмены
+		adminRouter.Post("/undo/{token}", r.handleUndo)
+
+		// Удаленные админки федерации в режиме proxy
+		adminRouter.With(r.requireReadPermission(auth.PermissionFederation)).Handle(remotePath+"/*", http.HandlerFunc(r.handleRemoteProxy))
+
 		// Страницы
 		adminRouter.Route("/pages", func(pagesRouter chi.Router) {
 			pagesRouter.Get("/{name}", r.handlePageGet)
 		})
 
+		// Страницы-прокси внешних инструментов
+		adminRouter.Handle(proxyPath+"/{name}/*", http.HandlerFunc(r.handlePageProxy))
+
 		// Авторизация (если включена)
 		if r.authEnabled {
 			adminRouter.Post("/login", r.handleLogin)
@@ -145,71 +463,151 @@ func (r *Router) setupRoutes() {
 	})
 
 	// API роуты (вне /admin для удобства)
-	r.mux.Route("/api", func(apiRouter chi.Router) {
+	root.Route("/api", func(apiRouter chi.Router) {
+		// Режим обслуживания
+		apiRouter.Get("/maintenance", r.handleGetMaintenance)
+		apiRouter.With(r.requirePermission(auth.PermissionMaintenance)).Put("/maintenance", r.handleSetMaintenance)
+
+		// Журнал аудита
+		apiRouter.Route("/audit", func(auditRouter chi.Router) {
+			auditRouter.Use(r.requireReadPermission(auth.PermissionAudit))
+			auditRouter.Get("/", r.handleAuditExport)
+			auditRouter.Get("/verify", r.handleAuditVerify)
+			auditRouter.Post("/verify", r.handleAuditVerify)
+		})
+
+		// Отладочный режим форм
+		apiRouter.Route("/debug", func(debugRouter chi.Router) {
+			debugRouter.Use(r.requirePermission(auth.PermissionDebugWrite))
+			debugRouter.Get("/", r.handleDebugList)
+			debugRouter.Put("/forms/*", r.handleDebugEnable)
+			debugRouter.Delete("/forms/*", r.handleDebugDisable)
+		})
+
+		// Снимки неудачных запросов форм
+		apiRouter.Route("/snapshots", func(snapshotsRouter chi.Router) {
+			snapshotsRouter.Use(r.requireReadPermission(auth.PermissionSnapshots))
+			snapshotsRouter.Get("/", r.handleSnapshotsList)
+			// ID запроса может содержать "/", поэтому передается последней частью пути
+			snapshotsRouter.With(r.requirePermission(auth.PermissionSnapshotsReplay)).Post("/replay/*", r.handleSnapshotReplay)
+			snapshotsRouter.Get("/*", r.handleSnapshotGet)
+			snapshotsRouter.With(r.requirePermission(auth.PermissionSnapshotsWrite)).Delete("/*", r.handleSnapshotDelete)
+		})
+
+		// Федерация админок
+		apiRouter.With(r.requireReadPermission(auth.PermissionFederation)).Get("/federation", r.handleFederation)
+
+		// Резервные копии
+		apiRouter.Route("/backup", func(backupRouter chi.Router) {
+			backupRouter.Use(r.requireReadPermission(auth.PermissionBackup))
+			backupRouter.Get("/", r.handleBackup)
+			backupRouter.Post("/restore", r.handleRestore)
+		})
+
+		// Импорт пакетов конфигурации: план, затем явное применение
+		apiRouter.Route("/config", func(configRouter chi.Router) {
+			configRouter.Use(r.requireReadPermission(auth.PermissionRoutesWrite))
+			configRouter.Get("/", r.handleConfigExport)
+			configRouter.Post("/plan", r.handleConfigPlan)
+			configRouter.Get("/plans/{id}", r.handleConfigPlanGet)
+			configRouter.Post("/plans/{id}/apply", r.handleConfigApply)
+
+			// Код FormBuilder зарегистрированной формы или определения из тела запроса
+			configRouter.Get("/codegen/*", r.handleCodegenForm)
+			configRouter.Post("/codegen", r.handleCodegen)
+		})
+
+		// Сводки и метрики SLO форм
+		apiRouter.Route("/slo", func(sloRouter chi.Router) {
+			sloRouter.Use(r.requireReadPermission(auth.PermissionMetrics))
+			sloRouter.Get("/", r.handleSLO)
+			sloRouter.Get("/metrics", r.handleSLOMetrics)
+			sloRouter.Get("/rules", r.handleSLORules)
+		})
+
+		// Квоты и их использование
+		apiRouter.With(r.requireReadPermission(auth.PermissionMetrics)).Get("/quotas", r.handleQuotas)
+
+		// Политики хранения
+		apiRouter.Route("/retention", func(retentionRouter chi.Router) {
+			retentionRouter.Use(r.requireReadPermission(auth.PermissionRetention))
+			retentionRouter.Get("/", r.handleRetentionGet)
+			retentionRouter.Post("/run", r.handleRetentionRun)
+		})
+
+		// Данные субъектов (GDPR)
+		apiRouter.Route("/privacy", func(privacyRouter chi.Router) {
+			privacyRouter.Use(r.requireReadPermission(auth.PermissionPrivacy))
+			privacyRouter.Get("/{subject}", r.handlePrivacyExport)
+			privacyRouter.Delete("/{subject}", r.handlePrivacyErase)
+		})
+
 		apiRouter.Route("/routes", func(routesRouter chi.Router) {
+			// Изменение роутов требует разрешения routes:write или API ключа
+			routesRouter.Use(r.requirePermission(auth.PermissionRoutesWrite))
+
 			// GET /api/routes - получить все роуты
-			routesRouter.Get("/", func(w http.ResponseWriter, req *http.Request) {
-				if r.storageHandlers != nil {
-					if handler, ok := r.storageHandlers["getRoutes"]; ok {
-						handler(w, req)
-						return
-					}
-				}
-				http.Error(w, "Storage not configured", http.StatusNotImplemented)
-			})
+			routesRouter.Get("/", r.storageHandler("getRoutes"))
 
 			// GET /api/routes/{id} - получить роут по ID
-			routesRouter.Get("/{id}", func(w http.ResponseWriter, req *http.Request) {
-				if r.storageHandlers != nil {
-					if handler, ok := r.storageHandlers["getRoute"]; ok {
-						handler(w, req)
-						return
-					}
-				}
-				http.Error(w, "Storage not configured", http.StatusNotImplemented)
-			})
+			routesRouter.Get("/{id}", r.storageHandler("getRoute"))
 
 			// POST /api/routes - создать новый роут
-			routesRouter.Post("/", func(w http.ResponseWriter, req *http.Request) {
-				if r.storageHandlers != nil {
-					if handler, ok := r.storageHandlers["createRoute"]; ok {
-						handler(w, req)
-						return
-					}
-				}
-				http.Error(w, "Storage not configured", http.StatusNotImplemented)
-			})
+			routesRouter.Post("/", r.storageHandler("createRoute"))
 
 			// PUT /api/routes/{id} - обновить роут
-			routesRouter.Put("/{id}", func(w http.ResponseWriter, req *http.Request) {
-				if r.storageHandlers != nil {
-					if handler, ok := r.storageHandlers["updateRoute"]; ok {
-						handler(w, req)
-						return
-					}
-				}
-				http.Error(w, "Storage not configured", http.StatusNotImplemented)
-			})
+			routesRouter.Put("/{id}", r.storageHandler("updateRoute"))
 
 			// DELETE /api/routes/{id} - удалить роут
-			routesRouter.Delete("/{id}", func(w http.ResponseWriter, req *http.Request) {
-				if r.storageHandlers != nil {
-					if handler, ok := r.storageHandlers["deleteRoute"]; ok {
-						handler(w, req)
-						return
-					}
-				}
-				http.Error(w, "Storage not configured", http.StatusNotImplemented)
-			})
+			routesRouter.Delete("/{id}", r.storageHandler("deleteRoute"))
+
+			// Очередь неудачных записей в storage
+			routesRouter.Get("/dead-letter", r.storageHandler("getDeadLetters"))
+			routesRouter.Post("/dead-letter/replay", r.storageHandler("replayDeadLetters"))
+			routesRouter.Delete("/dead-letter", r.storageHandler("drainDeadLetters"))
 		})
 	})
 }
 
+// storageHandler возвращает обработчик, делегирующий запрос в storage обработчик по имени
+func (r *Router) storageHandler(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if r.storageHandlers != nil {
+			if handler, ok := r.storageHandlers[name]; ok {
+				handler(w, req)
+				return
+			}
+		}
+		http.Error(w, "Storage not configured", http.StatusNotImplemented)
+	}
+}
+
+// handleHealth обрабатывает проверку состояния админки
+func (r *Router) handleHealth(w http.ResponseWriter, req *http.Request) {
+	if r.storageHandlers != nil {
+		if handler, ok := r.storageHandlers["health"]; ok {
+			handler(w, req)
+			return
+		}
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"status": "ok",
+		},
+	})
+}
+
 // handleConfig обрабатывает запрос конфигурации
 func (r *Router) handleConfig(w http.ResponseWriter, req *http.Request) {
+	timezone := r.displayLocation(req)
+	vars := r.requestVars(req)
+
+	r.mu.RLock()
 	formsMap := make(map[string]string)
 	for name, form := range r.forms {
-		formsMap[name] = form.Title
+		formsMap[name] = interpolate.Expand(form.Title, vars)
 	}
 
 	pagesMap := make(map[string]string)
@@ -217,25 +615,79 @@ func (r *Router) handleConfig(w http.ResponseWriter, req *http.Request) {
 		pagesMap[name] = page.Title
 	}
 
+	// Группировка форм по модулям для меню
+	var modulesMap map[string]types.ModuleInfo
+	if len(r.modules) > 0 {
+		modulesMap = make(map[string]types.ModuleInfo, len(r.modules))
+		for name, module := range r.modules {
+			modulesMap[name] = types.ModuleInfo{
+				Title: module.Title,
+				Icon:  module.Icon,
+				Forms: make(map[string]string),
+			}
+		}
+		for _, form := range r.forms {
+			if info, ok := modulesMap[form.Module]; ok {
+				info.Forms[form.Name] = interpolate.Expand(form.Title, vars)
+			}
+		}
+	}
+
 	config := types.ConfigResponse{
 		Title:       r.title,
+		BasePath:    r.prefix,
 		AuthEnabled: r.authEnabled,
 		Forms:       formsMap,
 		Pages:       pagesMap,
+		Modules:     modulesMap,
+		Menu:        r.menuItems(vars),
+		Environment: r.environment,
+		Branding:    r.branding,
+		ReadOnly:    r.readOnly,
+		Maintenance: r.maintenanceInfo(),
+		Timezone:    timezone.String(),
+		Demo:        r.demo != nil,
+	}
+	config.Capabilities = r.capabilities()
+	updatedAt := r.updatedAt
+	remotes := r.federation
+	r.mu.RUnlock()
+
+	// Пункты меню удаленных админок
+	if remotes != nil {
+		base := r.remoteBase()
+		config.Menu = append(config.Menu, remotes.Menu(base)...)
+		config.Remotes = remotes.Info(base)
+		if changedAt := remotes.ChangedAt(); changedAt.After(updatedAt) {
+			updatedAt = changedAt
+		}
 	}
 
-	r.sendJSON(w, types.APIResponse{
+	r.sendConditionalJSON(w, req, types.APIResponse{
 		Success: true,
 		Data:    config,
-	})
+	}, updatedAt)
 }
 
-// handleFormsList обрабатывает запрос списка форм
+// handleFormsList обрабатывает запрос списка форм.
+// С ?detail=summary возвращает краткие описания форм без генерации схем
 func (r *Router) handleFormsList(w http.ResponseWriter, req *http.Request) {
+	vars := r.requestVars(req)
+
+	if req.URL.Query().Get("detail") == "summary" {
+		r.sendJSON(w, types.APIResponse{
+			Success: true,
+			Data:    r.formSummaries(vars),
+		})
+		return
+	}
+
+	r.mu.RLock()
 	formsMap := make(map[string]string)
 	for name, form := range r.forms {
-		formsMap[name] = form.Title
+		formsMap[name] = interpolate.Expand(form.Title, vars)
 	}
+	r.mu.RUnlock()
 
 	r.sendJSON(w, types.APIResponse{
 		Success: true,
@@ -243,89 +695,157 @@ func (r *Router) handleFormsList(w http.ResponseWriter, req *http.Request) {
 	})
 }
 
-// handleFormGet обрабатывает GET запрос формы
+// handleFormGet обрабатывает GET запрос формы.
+// ?fields=schema,uiSchema или ?fields=data возвращает только указанные части ответа,
+// ?include=tables добавляет первые страницы табличных полей
 func (r *Router) handleFormGet(w http.ResponseWriter, req *http.Request) {
-	name := chi.URLParam(req, "name")
-	form, exists := r.forms[name]
+	form, exists := r.lookupForm(formKey(req))
 	if !exists {
 		r.sendError(w, http.StatusNotFound, "Форма не найдена")
 		return
 	}
 
-	// Генерируем схемы
-	jsonSchema, err := schema.GenerateJSONSchema(form)
+	fields, err := parseResponseFields(req)
 	if err != nil {
-		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка генерации схемы: %v", err))
+		r.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	uiSchema := schema.GenerateUISchema(form)
+	prefill, err := r.formPrefill(req, form)
+	if err != nil {
+		r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Ошибка предзаполнения: %v", err))
+		return
+	}
 
-	response := types.FormResponse{
-		Schema:   jsonSchema,
-		UISchema: uiSchema,
+	if r.accessLog != nil {
+		r.noteAccess(req, form, nil)
 	}
 
-	// Если есть обработчик GET, получаем данные
-	if form.OnGet != nil {
-		data, err := form.OnGet()
-		if err != nil {
-			r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения данных: %v", err))
-			return
+	// Схемы, данные и таблицы получаем одновременно
+	var response types.FormResponse
+	policy, ok := r.fanOutFormGet(w, req, form, fields, &response)
+	if !ok {
+		return
+	}
+	if policy != nil {
+		w.Header().Set("Cache-Control", policy.CacheControl())
+	}
+
+	response.Prefill = prefill
+
+	// Другие пользователи, открывшие форму, - предупреждение о возможных конфликтах правок
+	var meta *types.ResponseMeta
+	if viewers := r.formViewers(req, form); len(viewers) > 0 {
+		meta = &types.ResponseMeta{Viewers: viewers}
+	}
+
+	// Кто и когда последним изменил поля записи
+	if fields.has(FieldData) {
+		if changes := r.fieldProvenance(req, form); len(changes) > 0 {
+			if meta == nil {
+				meta = &types.ResponseMeta{}
+			}
+			meta.Provenance = changes
 		}
-		response.Data = data
 	}
 
 	r.sendJSON(w, types.APIResponse{
 		Success: true,
-		Data:    response,
+		Data:    fields.apply(response),
+		Meta:    meta,
 	})
 }
 
 // handleFormPost обрабатывает POST запрос формы
 func (r *Router) handleFormPost(w http.ResponseWriter, req *http.Request) {
-	name := chi.URLParam(req, "name")
-	form, exists := r.forms[name]
+	form, exists := r.lookupForm(formKey(req))
 	if !exists {
 		r.sendError(w, http.StatusNotFound, "Форма не найдена")
 		return
 	}
 
-	if form.OnPost == nil {
+	dryRun := isDryRunRequest(req)
+	if form.OnPost == nil && !(dryRun && form.OnDryRun != nil) {
 		r.sendError(w, http.StatusMethodNotAllowed, "POST не поддерживается для этой формы")
 		return
 	}
 
+	if err := r.validatorError(form.Key()); err != nil {
+		r.reportRequestError(req, err, reporting.KindValidation, form.Key(), "validator")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка конфигурации формы: %v", err))
+		return
+	}
+
 	// Парсим данные
 	var data map[string]interface{}
-	if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+	if err := r.decodeJSON(req.Body, &data); err != nil {
 		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
 		return
 	}
 
+	if r.accessLog != nil {
+		r.noteAccess(req, form, data)
+	}
+
+	// Приводим локализованные числа и даты к каноническим значениям
+	if err := r.normalizeFormData(req, form, data); err != nil {
+		r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Ошибка валидации: %v", err))
+		return
+	}
+
 	// Валидируем данные
-	if err := r.validateFormData(form, data); err != nil {
+	if err := r.validateFormData(req.Context(), form, data); err != nil {
 		r.sendError(w, http.StatusBadRequest, fmt.Sprintf("Ошибка валидации: %v", err))
 		return
 	}
 
+	// Предупреждения не блокируют отправку, но могут требовать подтверждения
+	warnings := r.formWarnings(form, data)
+
+	// Пробный запуск: валидация и обработчик без сохранения изменений
+	if dryRun {
+		r.handleDryRun(w, req, form, data, warnings)
+		return
+	}
+
+	if len(warnings) > 0 && form.ConfirmWarnings && !warningsConfirmed(req) {
+		r.sendWarningsConfirmation(w, warnings)
+		return
+	}
+
+	// Отправка, требующая согласования, ожидает решения
+	if form.Approval != nil {
+		r.submitForApproval(w, req, form, data, warnings)
+		return
+	}
+
 	// Обрабатываем данные
-	result, err := form.OnPost(data)
+	result, err := r.callFormHandler(req.Context(), form, func(ctx context.Context) (interface{}, error) {
+		return form.OnPost(ctx, data)
+	})
+	if aborted(req) {
+		return
+	}
+	if errors.Is(err, errOverloaded) {
+		r.sendOverloaded(w)
+		return
+	}
 	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "onPost")
 		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка обработки: %v", err))
 		return
 	}
 
-	r.sendJSON(w, types.APIResponse{
-		Success: true,
-		Data:    result,
-	})
+	r.responses.invalidate(form.Key())
+	r.recordProvenance(req, form, req.URL.Query().Get(types.LinkParamDefault), data)
+	r.Audit().Record(req.Context(), audit.ActionFormSubmit, form.Key(), nil)
+	r.sendResult(w, req, result, warnings)
 }
 
 // handlePageGet обрабатывает GET запрос страницы
 func (r *Router) handlePageGet(w http.ResponseWriter, req *http.Request) {
 	name := chi.URLParam(req, "name")
-	page, exists := r.pages[name]
+	page, exists := r.lookupPage(name)
 	if !exists {
 		r.sendError(w, http.StatusNotFound, "Страница не найдена")
 		return
@@ -338,12 +858,17 @@ func (r *Router) handlePageGet(w http.ResponseWriter, req *http.Request) {
 	}
 
 	// Иначе возвращаем содержимое страницы
+	data := map[string]interface{}{
+		"title":   page.Title,
+		"content": page.Content,
+	}
+	if page.Proxy != nil {
+		data["proxy"] = r.pageProxyPath(page.Name) + "/"
+	}
+
 	r.sendJSON(w, types.APIResponse{
 		Success: true,
-		Data: map[string]interface{}{
-			"title":   page.Title,
-			"content": page.Content,
-		},
+		Data:    data,
 	})
 }
 
@@ -365,223 +890,35 @@ func (r *Router) handleLogout(w http.ResponseWriter, req *http.Request) {
 	})
 }
 
-// validateFormData валидирует данные формы
-func (r *Router) validateFormData(form *types.Form, data map[string]interface{}) error {
-	for _, field := range form.Fields {
-		value, exists := data[field.Name]
-
-		// Проверяем обязательные поля
-		if field.Required && (!exists || isEmpty(value)) {
-			return fmt.Errorf("поле '%s' обязательно для заполнения", field.Label)
-		}
-
-		// Если поле не обязательное и пустое, пропускаем валидацию
-		if !exists || isEmpty(value) {
-			continue
-		}
-
-		// Применяем правила валидации
-		for _, rule := range field.Validation {
-			if err := r.validateRule(value, rule); err != nil {
-				return fmt.Errorf("поле '%s': %v", field.Label, err)
-			}
-		}
-	}
-
-	return nil
-}
-
-// validateRule применяет правило валидации
-func (r *Router) validateRule(value interface{}, rule types.ValidationRule) error {
-	switch rule.Type {
-	case "email":
-		return r.validateEmail(value, rule.Message)
-	case "min":
-		return r.validateMin(value, rule.Value, rule.Message)
-	case "max":
-		return r.validateMax(value, rule.Value, rule.Message)
-	case "minLength":
-		return r.validateMinLength(value, rule.Value, rule.Message)
-	case "maxLength":
-		return r.validateMaxLength(value, rule.Value, rule.Message)
-	default:
-		return nil
-	}
-}
-
-// validateEmail валидирует email
-func (r *Router) validateEmail(value interface{}, message string) error {
-	str, ok := value.(string)
-	if !ok {
-		return fmt.Errorf("значение должно быть строкой")
-	}
-
-	if !strings.Contains(str, "@") || !strings.Contains(str, ".") {
-		if message != "" {
-			return fmt.Errorf("%s", message)
-		}
-		return fmt.Errorf("некорректный email адрес")
-	}
-
-	return nil
-}
-
-// validateMin валидирует минимальное значение
-func (r *Router) validateMin(value interface{}, minValue interface{}, message string) error {
-	num, err := toFloat64(value)
-	if err != nil {
-		return err
-	}
-
-	min, err := toFloat64(minValue)
-	if err != nil {
-		return err
-	}
-
-	if num < min {
-		if message != "" {
-			return fmt.Errorf("%s", message)
-		}
-		return fmt.Errorf("значение должно быть не менее %v", min)
-	}
-
-	return nil
-}
-
-// validateMax валидирует максимальное значение
-func (r *Router) validateMax(value interface{}, maxValue interface{}, message string) error {
-	num, err := toFloat64(value)
-	if err != nil {
-		return err
-	}
-
-	max, err := toFloat64(maxValue)
-	if err != nil {
-		return err
-	}
-
-	if num > max {
-		if message != "" {
-			return fmt.Errorf("%s", message)
-		}
-		return fmt.Errorf("значение должно быть не более %v", max)
-	}
-
-	return nil
-}
-
-// validateMinLength валидирует минимальную длину
-func (r *Router) validateMinLength(value interface{}, minLength interface{}, message string) error {
-	str, ok := value.(string)
-	if !ok {
-		return fmt.Errorf("значение должно быть строкой")
-	}
-
-	min, err := toInt(minLength)
-	if err != nil {
-		return err
-	}
-
-	if len(str) < min {
-		if message != "" {
-			return fmt.Errorf("%s", message)
-		}
-		return fmt.Errorf("длина должна быть не менее %d символов", min)
-	}
-
-	return nil
+// sendJSON отправляет JSON ответ
+func (r *Router) sendJSON(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	r.encodeJSON(w, data)
 }
 
-// validateMaxLength валидирует максимальную длину
-func (r *Router) validateMaxLength(value interface{}, maxLength interface{}, message string) error {
-	str, ok := value.(string)
-	if !ok {
-		return fmt.Errorf("значение должно быть строкой")
-	}
-
-	max, err := toInt(maxLength)
+// sendConditionalJSON отправляет JSON ответ с ETag и Last-Modified
+// или 304 Not Modified, если данные у клиента актуальны
+func (r *Router) sendConditionalJSON(w http.ResponseWriter, req *http.Request, data interface{}, lastModified time.Time) {
+	body, err := r.Codec().Marshal(data)
 	if err != nil {
-		return err
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка сериализации: %v", err))
+		return
 	}
 
-	if len(str) > max {
-		if message != "" {
-			return fmt.Errorf("%s", message)
-		}
-		return fmt.Errorf("длина должна быть не более %d символов", max)
+	if CheckNotModified(w, req, ETag(body), lastModified) {
+		return
 	}
 
-	return nil
-}
-
-// sendJSON отправляет JSON ответ
-func (r *Router) sendJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	w.Write(append(body, '\n'))
 }
 
 // sendError отправляет ошибку
 func (r *Router) sendError(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(types.APIResponse{
+	r.encodeJSON(w, types.APIResponse{
 		Success: false,
 		Error:   message,
 	})
 }
-
-// isEmpty проверяет, является ли значение пустым
-func isEmpty(value interface{}) bool {
-	if value == nil {
-		return true
-	}
-
-	switch v := value.(type) {
-	case string:
-		return strings.TrimSpace(v) == ""
-	case []interface{}:
-		return len(v) == 0
-	default:
-		return false
-	}
-}
-
-// toFloat64 конвертирует значение в float64
-func toFloat64(value interface{}) (float64, error) {
-	switch v := value.(type) {
-	case float64:
-		return v, nil
-	case float32:
-		return float64(v), nil
-	case int:
-		return float64(v), nil
-	case int32:
-		return float64(v), nil
-	case int64:
-		return float64(v), nil
-	case string:
-		return strconv.ParseFloat(v, 64)
-	default:
-		return 0, fmt.Errorf("не удается конвертировать %T в число", value)
-	}
-}
-
-// toInt конвертирует значение в int
-func toInt(value interface{}) (int, error) {
-	switch v := value.(type) {
-	case int:
-		return v, nil
-	case int32:
-		return int(v), nil
-	case int64:
-		return int(v), nil
-	case float64:
-		return int(v), nil
-	case float32:
-		return int(v), nil
-	case string:
-		return strconv.Atoi(v)
-	default:
-		return 0, fmt.Errorf("не удается конвертировать %T в целое число", value)
-	}
-}
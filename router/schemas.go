@@ -0,0 +1,106 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/koteyye/go-formist/interpolate"
+	"github.com/koteyye/go-formist/schema"
+	"github.com/koteyye/go-formist/types"
+)
+
+// PregenerateSchemas проверяет все зарегистрированные формы и заранее генерирует их схемы.
+// Возвращает первую найденную ошибку определения формы
+func (r *Router) PregenerateSchemas() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cache := make(map[string]*types.FormResponse, len(r.forms))
+
+	for name, form := range r.forms {
+		if err := schema.ValidateForm(form); err != nil {
+			return err
+		}
+
+		response, err := generateFormSchemas(form)
+		if err != nil {
+			return fmt.Errorf("форма %s: %w", name, err)
+		}
+		cache[name] = response
+	}
+
+	r.schemaCache = cache
+	return nil
+}
+
+// WriteSchemas записывает сгенерированные схемы в директорию dir
+// в виде файлов <name>.schema.json для раздачи через CDN
+func (r *Router) WriteSchemas(dir string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("не удалось создать директорию %s: %w", dir, err)
+	}
+
+	for name, response := range r.schemaCache {
+		data, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			return fmt.Errorf("не удалось сериализовать схему формы %s: %w", name, err)
+		}
+
+		path := filepath.Join(dir, strings.ReplaceAll(name, "/", ".")+".schema.json")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("не удалось записать схему формы %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// formSchemas возвращает схемы формы из кеша или генерирует их
+func (r *Router) formSchemas(form *types.Form) (*types.FormResponse, error) {
+	r.mu.RLock()
+	cached, ok := r.schemaCache[form.Key()]
+	r.mu.RUnlock()
+
+	if ok {
+		return cached, nil
+	}
+	return generateFormSchemas(form)
+}
+
+// generateFormSchemas генерирует JSON Schema и UI Schema формы
+func generateFormSchemas(form *types.Form) (*types.FormResponse, error) {
+	jsonSchema, err := schema.GenerateJSONSchema(form)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.FormResponse{
+		Schema:   jsonSchema,
+		UISchema: schema.GenerateUISchema(form),
+	}, nil
+}
+
+// formSummaries возвращает краткие описания зарегистрированных форм, упорядоченные по ключу
+func (r *Router) formSummaries(vars interpolate.Vars) []types.FormSummary {
+	r.mu.RLock()
+	summaries := make([]types.FormSummary, 0, len(r.forms))
+	for _, form := range r.forms {
+		summary := form.Summary()
+		summary.Title = interpolate.Expand(summary.Title, vars)
+		summary.Description = interpolate.Expand(summary.Description, vars)
+		summaries = append(summaries, summary)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Key < summaries[j].Key
+	})
+	return summaries
+}
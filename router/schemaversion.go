@@ -0,0 +1,100 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// maxSchemaVersions число хранимых версий схемы формы, для которых вычисляются изменения полей
+const maxSchemaVersions = 20
+
+// schemaVersion версия схемы формы и хеши ее полей
+type schemaVersion struct {
+	version string
+	fields  map[string]string
+}
+
+// recordSchemaVersion запоминает версию схемы зарегистрированной формы.
+// Вызывается под r.mu; повторная регистрация без изменений полей версию не добавляет
+func (r *Router) recordSchemaVersion(form *types.Form) {
+	fields := form.FieldVersions()
+	current := schemaVersion{version: types.SchemaVersion(fields), fields: fields}
+
+	if r.schemaVersions == nil {
+		r.schemaVersions = make(map[string][]schemaVersion)
+	}
+	key := form.Key()
+	history := r.schemaVersions[key]
+	if len(history) > 0 && history[len(history)-1].version == current.version {
+		return
+	}
+	history = append(history, current)
+	if len(history) > maxSchemaVersions {
+		history = history[len(history)-maxSchemaVersions:]
+	}
+	r.schemaVersions[key] = history
+}
+
+// schemaConflict возвращает текущую версию схемы формы и конфликт, если версия клиента устарела
+func (r *Router) schemaConflict(key, client string) (string, *types.SchemaConflict) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := r.schemaVersions[key]
+	if len(history) == 0 {
+		return "", nil
+	}
+	current := history[len(history)-1]
+	if client == "" || client == current.version {
+		return current.version, nil
+	}
+
+	conflict := &types.SchemaConflict{Version: current.version, ClientVersion: client, Unknown: true}
+	for _, previous := range history[:len(history)-1] {
+		if previous.version == client {
+			conflict.Changes = types.SchemaChanges(previous.fields, current.fields)
+			conflict.Unknown = false
+			break
+		}
+	}
+	return current.version, conflict
+}
+
+// SchemaVersion возвращает текущую версию схемы зарегистрированной формы
+func (r *Router) SchemaVersion(key string) (string, bool) {
+	version, _ := r.schemaConflict(key, "")
+	return version, version != ""
+}
+
+// checkSchemaVersion возвращает текущую версию схемы формы в заголовке types.SchemaVersionHeader
+// и отвечает 409 с измененными полями на изменяющий запрос по устаревшей версии.
+// Запросы без версии не проверяются
+func (r *Router) checkSchemaVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		client := req.Header.Get(types.SchemaVersionHeader)
+		if client == "" {
+			client = req.URL.Query().Get(types.SchemaVersionParam)
+		}
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			client = ""
+		}
+
+		version, conflict := r.schemaConflict(formKey(req), client)
+		if version != "" {
+			w.Header().Set(types.SchemaVersionHeader, version)
+		}
+		if conflict == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		r.encodeJSON(w, types.APIResponse{
+			Success: false,
+			Error:   "Схема формы изменилась, обновите страницу",
+			Data:    conflict,
+		})
+	})
+}
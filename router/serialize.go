@@ -0,0 +1,39 @@
+package router
+
+import (
+	"github.com/koteyye/go-formist/serialize"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Serializers возвращает реестр представлений значений в ответах обработчиков
+func (r *Router) Serializers() *serialize.Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.serializers
+}
+
+// serializeData применяет представления значений к данным OnGet или обработчика таблицы.
+// У таблицы обрабатываются строки
+func (r *Router) serializeData(data interface{}) (interface{}, error) {
+	serializers := r.Serializers()
+	if serializers.Empty() {
+		return data, nil
+	}
+
+	table, ok := data.(types.TableData)
+	if !ok {
+		return serializers.Apply(data)
+	}
+
+	rows := make([]map[string]interface{}, len(table.Rows))
+	for i, row := range table.Rows {
+		value, err := serializers.Apply(row)
+		if err != nil {
+			return nil, err
+		}
+		rows[i], _ = value.(map[string]interface{})
+	}
+	table.Rows = rows
+	return table, nil
+}
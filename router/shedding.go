@@ -0,0 +1,152 @@
+package router
+
+import (
+	"errors"
+	"maps"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// routeShedder лимит запросов по префиксу пути
+type routeShedder struct {
+	prefix  string
+	limiter *formLimiter
+	shed    atomic.Uint64
+}
+
+// loadShedder ограничивает одновременные запросы к админке
+type loadShedder struct {
+	global     *routeShedder
+	routes     []*routeShedder // по убыванию длины префикса
+	retryAfter string
+}
+
+// newLoadShedder создает ограничитель по настройкам
+func newLoadShedder(config *types.LoadShedding) *loadShedder {
+	retryAfter := config.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	shedder := &loadShedder{
+		retryAfter: strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))),
+	}
+
+	if limiter := newFormLimiter(&types.Concurrency{
+		MaxConcurrent: config.MaxInFlight,
+		QueueSize:     config.QueueSize,
+		QueueTimeout:  config.QueueTimeout,
+	}); limiter != nil {
+		shedder.global = &routeShedder{limiter: limiter}
+	}
+
+	for prefix, limit := range config.Routes {
+		shedder.routes = append(shedder.routes, &routeShedder{
+			prefix:  strings.TrimRight(prefix, "/"),
+			limiter: newFormLimiter(&limit),
+		})
+	}
+	sort.Slice(shedder.routes, func(i, j int) bool {
+		return len(shedder.routes[i].prefix) > len(shedder.routes[j].prefix)
+	})
+	return shedder
+}
+
+// route возвращает лимит самого длинного подходящего префикса
+func (s *loadShedder) route(path string) *routeShedder {
+	for _, route := range s.routes {
+		if path == route.prefix || strings.HasPrefix(path, route.prefix+"/") {
+			return route
+		}
+	}
+	return nil
+}
+
+// stats возвращает состояние лимита
+func (s *routeShedder) stats() types.RouteLoadStats {
+	return types.RouteLoadStats{
+		InFlight: len(s.limiter.slots),
+		Queued:   int(s.limiter.waiting.Load()),
+		Shed:     s.shed.Load(),
+	}
+}
+
+// SetLoadShedding включает ограничение одновременных запросов к админке. nil отключает его
+func (r *Router) SetLoadShedding(config *types.LoadShedding) {
+	r.shedder = nil
+	if config != nil {
+		clone := *config
+		clone.Routes = maps.Clone(config.Routes)
+		r.shedder = newLoadShedder(&clone)
+	}
+	r.rebuild()
+}
+
+// LoadSheddingStats возвращает занятые слоты, очереди и количество отклоненных запросов
+func (r *Router) LoadSheddingStats() *types.LoadSheddingStats {
+	shedder := r.shedder
+	if shedder == nil {
+		return nil
+	}
+
+	stats := &types.LoadSheddingStats{}
+	if shedder.global != nil {
+		stats.RouteLoadStats = shedder.global.stats()
+	}
+	if len(shedder.routes) > 0 {
+		stats.Routes = make(map[string]types.RouteLoadStats, len(shedder.routes))
+		for _, route := range shedder.routes {
+			stats.Routes[route.prefix] = route.stats()
+		}
+	}
+	return stats
+}
+
+// sheddingExempt проверяет, что запрос не ограничивается: проверки состояния,
+// сбор метрик и долгоживущий канал присутствия
+func sheddingExempt(path string) bool {
+	path = strings.TrimRight(path, "/")
+	for _, suffix := range []string{"/admin/health", "/admin/presence", "/api/slo/metrics"} {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSheddingMiddleware занимает слот пути и общий слот на время запроса.
+// При перегрузке отвечает 503 с Retry-After; если клиент ушел из очереди, ответ не отправляется
+func (r *Router) loadSheddingMiddleware(next http.Handler) http.Handler {
+	shedder := r.shedder
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path := strings.TrimPrefix(req.URL.Path, r.prefix)
+		if req.Method == http.MethodOptions || sheddingExempt(path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		// Слот пути занимается первым, чтобы ожидание в его очереди не удерживало общий слот
+		for _, limit := range []*routeShedder{shedder.route(path), shedder.global} {
+			if limit == nil {
+				continue
+			}
+			if err := limit.limiter.acquire(req.Context()); err != nil {
+				if errors.Is(err, errOverloaded) {
+					limit.shed.Add(1)
+					w.Header().Set("Retry-After", shedder.retryAfter)
+					r.sendError(w, http.StatusServiceUnavailable, "Админка перегружена, повторите запрос позже")
+				}
+				return
+			}
+			defer limit.limiter.release()
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
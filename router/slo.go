@@ -0,0 +1,577 @@
+package router
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// SLOBuckets границы гистограммы длительности запросов форм в секундах.
+// Порог SLO.Latency формы добавляется к границам ее гистограммы
+var SLOBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// sloMinutes глубина поминутной истории для окон сводок
+const sloMinutes = 60
+
+// sloWindows окна сводок в минутах
+var sloWindows = map[string]int64{
+	types.SLOWindowShort: 5,
+	types.SLOWindowLong:  60,
+}
+
+// sloCounters счетчики запросов формы
+type sloCounters struct {
+	requests uint64
+	errors   uint64
+	buckets  []uint64 // по границам гистограммы, последний - выше всех границ
+	sum      float64  // суммарная длительность в секундах
+}
+
+// add прибавляет счетчики other
+func (c *sloCounters) add(other *sloCounters) {
+	c.requests += other.requests
+	c.errors += other.errors
+	c.sum += other.sum
+	for i, count := range other.buckets {
+		c.buckets[i] += count
+	}
+}
+
+// sloMinute счетчики одной минуты
+type sloMinute struct {
+	minute   int64
+	counters sloCounters
+}
+
+// formSLO статистика запросов формы
+type formSLO struct {
+	bounds  []float64
+	codes   map[string]uint64 // по классу ответа: 2xx, 4xx, 5xx
+	total   sloCounters
+	minutes [sloMinutes]sloMinute
+}
+
+// sloRegistry собирает статистику запросов форм для сводок и метрик SLO
+type sloRegistry struct {
+	mu    sync.Mutex
+	forms map[string]*formSLO
+}
+
+// newSLORegistry создает реестр статистики SLO
+func newSLORegistry() *sloRegistry {
+	return &sloRegistry{
+		forms: make(map[string]*formSLO),
+	}
+}
+
+// sloBounds возвращает границы гистограммы формы с порогом задержки SLO
+func sloBounds(slo *types.SLO) []float64 {
+	if slo == nil || slo.Latency <= 0 {
+		return SLOBuckets
+	}
+	threshold := slo.Latency.Seconds()
+	if slices.Contains(SLOBuckets, threshold) {
+		return SLOBuckets
+	}
+	bounds := append(slices.Clone(SLOBuckets), threshold)
+	slices.Sort(bounds)
+	return bounds
+}
+
+// newCounters создает счетчики под границы гистограммы
+func (s *formSLO) newCounters() sloCounters {
+	return sloCounters{buckets: make([]uint64, len(s.bounds)+1)}
+}
+
+// observe учитывает запрос формы со статусом status
+func (r *sloRegistry) observe(form *types.Form, status int, duration time.Duration, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := form.Key()
+	stats, ok := r.forms[key]
+	if !ok {
+		stats = &formSLO{bounds: sloBounds(form.SLO), codes: make(map[string]uint64)}
+		stats.total = stats.newCounters()
+		r.forms[key] = stats
+	}
+
+	seconds := duration.Seconds()
+	observed := stats.newCounters()
+	observed.requests = 1
+	observed.sum = seconds
+	if status >= http.StatusInternalServerError {
+		observed.errors = 1
+	}
+	bucket, _ := slices.BinarySearch(stats.bounds, seconds)
+	observed.buckets[bucket] = 1
+
+	minute := now.Unix() / 60
+	slot := &stats.minutes[minute%sloMinutes]
+	if slot.minute != minute || slot.counters.buckets == nil {
+		slot.minute = minute
+		slot.counters = stats.newCounters()
+	}
+	slot.counters.add(&observed)
+	stats.total.add(&observed)
+	stats.codes[fmt.Sprintf("%dxx", status/100)]++
+}
+
+// remove сбрасывает статистику формы (перерегистрация или удаление)
+func (r *sloRegistry) remove(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.forms, key)
+}
+
+// report возвращает сводки формы по окнам
+func (r *sloRegistry) report(form *types.Form, now time.Time) types.FormSLOReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := types.FormSLOReport{
+		Form:    form.Key(),
+		SLO:     form.SLO,
+		Windows: make(map[string]types.SLOSummary),
+	}
+
+	stats, ok := r.forms[form.Key()]
+	if !ok {
+		stats = &formSLO{bounds: sloBounds(form.SLO)}
+		stats.total = stats.newCounters()
+	}
+
+	minute := now.Unix() / 60
+	for name, length := range sloWindows {
+		window := stats.newCounters()
+		for _, slot := range stats.minutes {
+			if slot.counters.buckets != nil && slot.minute > minute-length && slot.minute <= minute {
+				window.add(&slot.counters)
+			}
+		}
+		report.Windows[name] = summarize(&window, stats.bounds, form.SLO)
+	}
+	report.Windows[types.SLOWindowTotal] = summarize(&stats.total, stats.bounds, form.SLO)
+	return report
+}
+
+// summarize вычисляет сводку по счетчикам
+func summarize(c *sloCounters, bounds []float64, slo *types.SLO) types.SLOSummary {
+	summary := types.SLOSummary{
+		Requests: c.requests,
+		Errors:   c.errors,
+	}
+	if c.requests == 0 {
+		return summary
+	}
+
+	errorRate := float64(c.errors) / float64(c.requests)
+	summary.ErrorRate = roundRate(errorRate)
+	summary.P50Ms = roundRate(quantile(0.5, c, bounds) * 1000)
+	summary.P90Ms = roundRate(quantile(0.9, c, bounds) * 1000)
+	summary.P99Ms = roundRate(quantile(0.99, c, bounds) * 1000)
+
+	if slo != nil {
+		summary.BurnRate = roundRate(errorRate / (1 - slo.Availability))
+		if slo.Latency > 0 {
+			var fast uint64
+			for i, bound := range bounds {
+				if bound > slo.Latency.Seconds() {
+					break
+				}
+				fast += c.buckets[i]
+			}
+			summary.SlowRate = roundRate(float64(c.requests-fast) / float64(c.requests))
+		}
+	}
+	return summary
+}
+
+// roundRate округляет значение сводки до шести знаков после запятой
+func roundRate(value float64) float64 {
+	return math.Round(value*1e6) / 1e6
+}
+
+// quantile оценивает квантиль длительности в секундах линейной интерполяцией
+// внутри корзины гистограммы (как histogram_quantile в Prometheus)
+func quantile(q float64, c *sloCounters, bounds []float64) float64 {
+	rank := q * float64(c.requests)
+	var cumulative uint64
+	for i, count := range c.buckets {
+		if float64(cumulative+count) < rank || count == 0 {
+			cumulative += count
+			continue
+		}
+		if i == len(bounds) {
+			return bounds[len(bounds)-1]
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = bounds[i-1]
+		}
+		return lower + (bounds[i]-lower)*(rank-float64(cumulative))/float64(count)
+	}
+	return bounds[len(bounds)-1]
+}
+
+// sloWriter запоминает статус ответа формы
+type sloWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader запоминает статус ответа
+func (w *sloWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write отмечает неявный статус 200
+func (w *sloWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController
+func (w *sloWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// trackSLO учитывает статус и длительность запросов к зарегистрированным формам.
+// Запросы, прерванные клиентом до ответа, не учитываются
+func (r *Router) trackSLO(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		form, exists := r.lookupForm(formKey(req))
+		if !exists {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		start := time.Now()
+		sw := &sloWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, req)
+
+		status := sw.status
+		if status == 0 {
+			if req.Context().Err() != nil {
+				return
+			}
+			status = http.StatusOK
+		}
+		r.slo.observe(form, status, time.Since(start), time.Now())
+	})
+}
+
+// sortedForms возвращает зарегистрированные формы по ключу
+func (r *Router) sortedForms() []*types.Form {
+	r.mu.RLock()
+	forms := make([]*types.Form, 0, len(r.forms))
+	for _, form := range r.forms {
+		forms = append(forms, form)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(forms, func(i, j int) bool {
+		return forms[i].Key() < forms[j].Key()
+	})
+	return forms
+}
+
+// SLOReports возвращает сводки ошибок и задержек всех зарегистрированных форм
+func (r *Router) SLOReports() []types.FormSLOReport {
+	now := time.Now()
+	forms := r.sortedForms()
+	reports := make([]types.FormSLOReport, 0, len(forms))
+	for _, form := range forms {
+		reports = append(reports, r.slo.report(form, now))
+	}
+	return reports
+}
+
+// handleSLO возвращает сводки всех форм или формы из ?form=ключ
+func (r *Router) handleSLO(w http.ResponseWriter, req *http.Request) {
+	if key := req.URL.Query().Get("form"); key != "" {
+		form, exists := r.lookupForm(key)
+		if !exists {
+			r.sendError(w, http.StatusNotFound, "Форма не найдена")
+			return
+		}
+		r.sendJSON(w, types.APIResponse{
+			Success: true,
+			Data:    r.slo.report(form, time.Now()),
+		})
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    r.SLOReports(),
+	})
+}
+
+// Метрики форм в формате Prometheus
+const (
+	metricRequests         = "formist_form_requests_total"
+	metricDuration         = "formist_form_request_duration_seconds"
+	metricAvailability     = "formist_form_slo_availability"
+	metricLatencyObjective = "formist_form_slo_latency_seconds"
+	metricShed             = "formist_load_shed_total"
+	metricInFlight         = "formist_load_in_flight"
+	metricQueued           = "formist_load_queued"
+	metricQuotaRejected    = "formist_quota_rejected_total"
+	metricQuotaSubmissions = "formist_quota_submissions"
+	metricQuotaUploadBytes = "formist_quota_upload_bytes"
+)
+
+// promLabel экранирует значение метки Prometheus
+var promLabel = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace
+
+// promFloat форматирует число для метрик и правил одинаково
+func promFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// promThreshold форматирует порог правила без погрешности вычислений
+func promThreshold(value float64) string {
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
+
+// handleSLOMetrics отдает счетчики и гистограммы запросов форм в текстовом формате Prometheus
+func (r *Router) handleSLOMetrics(w http.ResponseWriter, req *http.Request) {
+	forms := r.sortedForms()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s Запросы к форме по классу ответа.\n# TYPE %s counter\n", metricRequests, metricRequests)
+	r.slo.mu.Lock()
+	for _, form := range forms {
+		stats, ok := r.slo.forms[form.Key()]
+		if !ok {
+			continue
+		}
+		codes := make([]string, 0, len(stats.codes))
+		for code := range stats.codes {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Fprintf(&b, "%s{form=\"%s\",code=\"%s\"} %d\n", metricRequests, promLabel(form.Key()), code, stats.codes[code])
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP %s Длительность запросов к форме.\n# TYPE %s histogram\n", metricDuration, metricDuration)
+	for _, form := range forms {
+		stats, ok := r.slo.forms[form.Key()]
+		if !ok {
+			continue
+		}
+		label := promLabel(form.Key())
+		var cumulative uint64
+		for i, bound := range stats.bounds {
+			cumulative += stats.total.buckets[i]
+			fmt.Fprintf(&b, "%s_bucket{form=\"%s\",le=\"%s\"} %d\n", metricDuration, label, promFloat(bound), cumulative)
+		}
+		fmt.Fprintf(&b, "%s_bucket{form=\"%s\",le=\"+Inf\"} %d\n", metricDuration, label, stats.total.requests)
+		fmt.Fprintf(&b, "%s_sum{form=\"%s\"} %s\n", metricDuration, label, promFloat(stats.total.sum))
+		fmt.Fprintf(&b, "%s_count{form=\"%s\"} %d\n", metricDuration, label, stats.total.requests)
+	}
+	r.slo.mu.Unlock()
+
+	fmt.Fprintf(&b, "# HELP %s Цель доступности формы.\n# TYPE %s gauge\n", metricAvailability, metricAvailability)
+	for _, form := range forms {
+		if form.SLO != nil {
+			fmt.Fprintf(&b, "%s{form=\"%s\"} %s\n", metricAvailability, promLabel(form.Key()), promFloat(form.SLO.Availability))
+		}
+	}
+	fmt.Fprintf(&b, "# HELP %s Порог задержки SLO формы.\n# TYPE %s gauge\n", metricLatencyObjective, metricLatencyObjective)
+	for _, form := range forms {
+		if form.SLO != nil && form.SLO.Latency > 0 {
+			fmt.Fprintf(&b, "%s{form=\"%s\"} %s\n", metricLatencyObjective, promLabel(form.Key()), promFloat(form.SLO.Latency.Seconds()))
+		}
+	}
+
+	if stats := r.LoadSheddingStats(); stats != nil {
+		limits := map[string]types.RouteLoadStats{"global": stats.RouteLoadStats}
+		for prefix, route := range stats.Routes {
+			limits[prefix] = route
+		}
+		names := slices.Sorted(maps.Keys(limits))
+
+		fmt.Fprintf(&b, "# HELP %s Запросы, отклоненные ограничением нагрузки.\n# TYPE %s counter\n", metricShed, metricShed)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{limit=\"%s\"} %d\n", metricShed, promLabel(name), limits[name].Shed)
+		}
+		fmt.Fprintf(&b, "# HELP %s Выполняемые запросы по лимитам нагрузки.\n# TYPE %s gauge\n", metricInFlight, metricInFlight)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{limit=\"%s\"} %d\n", metricInFlight, promLabel(name), limits[name].InFlight)
+		}
+		fmt.Fprintf(&b, "# HELP %s Запросы в очереди по лимитам нагрузки.\n# TYPE %s gauge\n", metricQueued, metricQueued)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{limit=\"%s\"} %d\n", metricQueued, promLabel(name), limits[name].Queued)
+		}
+	}
+
+	if stats := r.QuotaStats(); stats != nil {
+		// Использование суммируется по субъектам, чтобы не размножать ряды метрик
+		submissions := make(map[string]int)
+		var uploaded int64
+		for _, usage := range stats.Usage {
+			for form, count := range usage.Submissions {
+				submissions[form] += count
+			}
+			uploaded += usage.UploadBytes
+		}
+
+		fmt.Fprintf(&b, "# HELP %s Запросы, отклоненные квотами.\n# TYPE %s counter\n", metricQuotaRejected, metricQuotaRejected)
+		for _, kind := range slices.Sorted(maps.Keys(stats.Rejected)) {
+			fmt.Fprintf(&b, "%s{quota=\"%s\"} %d\n", metricQuotaRejected, kind, stats.Rejected[kind])
+		}
+		fmt.Fprintf(&b, "# HELP %s Отправки формы за текущие сутки.\n# TYPE %s gauge\n", metricQuotaSubmissions, metricQuotaSubmissions)
+		for _, form := range slices.Sorted(maps.Keys(submissions)) {
+			fmt.Fprintf(&b, "%s{form=\"%s\"} %d\n", metricQuotaSubmissions, promLabel(form), submissions[form])
+		}
+		fmt.Fprintf(&b, "# HELP %s Байты тел запросов к формам за текущие сутки.\n# TYPE %s gauge\n", metricQuotaUploadBytes, metricQuotaUploadBytes)
+		fmt.Fprintf(&b, "%s %d\n", metricQuotaUploadBytes, uploaded)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprint(w, b.String())
+}
+
+// burnWindow окно оповещения о расходе бюджета ошибок (несколько окон, SRE Workbook):
+// срабатывает, если за Long израсходована доля Budget бюджета, и расход продолжается в Short
+type burnWindow struct {
+	Long     time.Duration
+	Short    time.Duration
+	Budget   float64
+	Severity string
+}
+
+// burnWindows окна оповещений: быстрый расход будит дежурного, медленный создает задачу
+var burnWindows = []burnWindow{
+	{Long: time.Hour, Short: 5 * time.Minute, Budget: 0.02, Severity: "critical"},
+	{Long: 6 * time.Hour, Short: 30 * time.Minute, Budget: 0.05, Severity: "critical"},
+	{Long: 72 * time.Hour, Short: 6 * time.Hour, Budget: 0.1, Severity: "warning"},
+}
+
+// alertRule правило оповещения Prometheus
+type alertRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// ruleGroup группа правил Prometheus
+type ruleGroup struct {
+	Name  string      `yaml:"name"`
+	Rules []alertRule `yaml:"rules"`
+}
+
+// promDuration форматирует длительность окна для PromQL (1h, 30m, 3d)
+func promDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// errorRatio выражение доли ошибок формы за окно
+func errorRatio(selector string, window time.Duration) string {
+	w := promDuration(window)
+	return fmt.Sprintf(`sum(rate(%s{%s,code="5xx"}[%s])) / sum(rate(%s{%s}[%s]))`,
+		metricRequests, selector, w, metricRequests, selector, w)
+}
+
+// slowRatio выражение доли запросов формы медленнее порога за окно
+func slowRatio(selector string, threshold time.Duration, window time.Duration) string {
+	w := promDuration(window)
+	return fmt.Sprintf(`1 - sum(rate(%s_bucket{%s,le="%s"}[%s])) / sum(rate(%s_count{%s}[%s]))`,
+		metricDuration, selector, promFloat(threshold.Seconds()), w, metricDuration, selector, w)
+}
+
+// SLOAlertRules формирует пример файла правил оповещений Prometheus для форм с SLO:
+// оповещения о быстром и медленном расходе бюджета ошибок и бюджета задержки
+// по метрикам /api/slo/metrics
+func (r *Router) SLOAlertRules() ([]byte, error) {
+	group := ruleGroup{Name: "formist-slo", Rules: []alertRule{}}
+	for _, form := range r.sortedForms() {
+		slo := form.SLO
+		if slo == nil {
+			continue
+		}
+		period := slo.Period
+		if period == 0 {
+			period = types.DefaultSLOPeriod
+		}
+		selector := fmt.Sprintf(`form="%s"`, promLabel(form.Key()))
+
+		for _, window := range burnWindows {
+			factor := window.Budget * period.Hours() / window.Long.Hours()
+			labels := map[string]string{"severity": window.Severity, "form": form.Key()}
+
+			threshold := promThreshold(factor * (1 - slo.Availability))
+			group.Rules = append(group.Rules, alertRule{
+				Alert: "FormistFormErrorBudgetBurn",
+				Expr: fmt.Sprintf("(%s) > %s\nand\n(%s) > %s",
+					errorRatio(selector, window.Long), threshold, errorRatio(selector, window.Short), threshold),
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("Форма %s расходует бюджет ошибок в %s раз быстрее допустимого (окно %s)",
+						form.Key(), promThreshold(factor), promDuration(window.Long)),
+				},
+			})
+
+			// Доля медленных запросов не превышает 1: при мягкой цели быстрое окно не сработает
+			if slo.LatencyTarget > 0 && factor*(1-slo.LatencyTarget) < 1 {
+				threshold := promThreshold(factor * (1 - slo.LatencyTarget))
+				group.Rules = append(group.Rules, alertRule{
+					Alert: "FormistFormLatencyBudgetBurn",
+					Expr: fmt.Sprintf("(%s) > %s\nand\n(%s) > %s",
+						slowRatio(selector, slo.Latency, window.Long), threshold,
+						slowRatio(selector, slo.Latency, window.Short), threshold),
+					Labels: labels,
+					Annotations: map[string]string{
+						"summary": fmt.Sprintf("Форма %s отвечает дольше %s чаще допустимого в %s раз (окно %s)",
+							form.Key(), slo.Latency, promThreshold(factor), promDuration(window.Long)),
+					},
+				})
+			}
+		}
+	}
+
+	return yaml.Marshal(map[string][]ruleGroup{"groups": {group}})
+}
+
+// handleSLORules отдает пример правил оповещений Prometheus в YAML
+func (r *Router) handleSLORules(w http.ResponseWriter, req *http.Request) {
+	rules, err := r.SLOAlertRules()
+	if err != nil {
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Write(rules)
+}
@@ -0,0 +1,281 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/snapshot"
+	"github.com/koteyye/go-formist/types"
+)
+
+// replayKey ключ контекста повтора снимка: повтор не создает новый снимок
+type replayKey struct{}
+
+// replayHeaders заголовки запроса повтора, которые не копируются из запроса сопровождающего
+var replayHeaders = []string{"Content-Type", "Content-Length", middleware.RequestIDHeader}
+
+// SnapshotReplay результат повтора снимка в пробном режиме
+type SnapshotReplay struct {
+	Status   int         `json:"status"`
+	Original int         `json:"original"` // статус исходного запроса
+	Response interface{} `json:"response,omitempty"`
+}
+
+// SetSnapshots включает снимки неудачных запросов форм (store nil - выключает)
+func (r *Router) SetSnapshots(store *snapshot.Store) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.snapshots = store
+}
+
+// snapshotStore возвращает хранилище снимков или nil
+func (r *Router) snapshotStore() *snapshot.Store {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.snapshots
+}
+
+// captureSnapshots сохраняет снимок отправки формы, завершившейся статусом >= 400.
+// ID запроса возвращается в заголовке X-Request-Id, чтобы пользователь мог указать его в обращении
+func (r *Router) captureSnapshots(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		store := r.snapshotStore()
+		requestID := middleware.GetReqID(req.Context())
+		if store == nil || requestID == "" || req.Context().Value(replayKey{}) != nil ||
+			req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		// Сохраняется только начало тела, остальное читается обработчиком напрямую
+		head, err := io.ReadAll(io.LimitReader(req.Body, snapshot.MaxPayload+1))
+		if err != nil {
+			r.sendError(w, http.StatusBadRequest, "Ошибка чтения тела запроса")
+			return
+		}
+		req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), req.Body), Closer: req.Body}
+
+		w.Header().Set(middleware.RequestIDHeader, requestID)
+		dw := &debugWriter{ResponseWriter: w}
+		next.ServeHTTP(dw, req)
+		if dw.status < http.StatusBadRequest {
+			return
+		}
+
+		key := formKey(req)
+		form, _ := r.lookupForm(key)
+		if form == nil {
+			form = &types.Form{}
+		}
+		entry := snapshot.Snapshot{
+			ID:          requestID,
+			Form:        key,
+			Method:      req.Method,
+			Path:        req.URL.Path,
+			Query:       req.URL.RawQuery,
+			ContentType: req.Header.Get("Content-Type"),
+			Status:      dw.status,
+			Truncated:   dw.truncated,
+			Replayable:  replayable(req) && replaySafe(form, req.Method),
+		}
+		if user, ok := auth.UserFromContext(req.Context()); ok {
+			entry.User = user.ID
+		}
+		store.Capture(form, entry, head, dw.body.Bytes())
+	})
+}
+
+// replayable сообщает, что маршрут поддерживает пробный запуск (?dry_run=true).
+// Действия формы его не поддерживают и не повторяются
+func replayable(req *http.Request) bool {
+	rctx := chi.RouteContext(req.Context())
+	return rctx != nil && !strings.Contains(rctx.RoutePattern(), "/actions/")
+}
+
+// replaySafe сообщает, что пробный запуск запроса не вызывает обработчики сохранения:
+// PATCH записи и ячейки только проверяет данные, POST формы и пакета выполняется
+// только при явном OnDryRun
+func replaySafe(form *types.Form, method string) bool {
+	return method == http.MethodPatch || form.OnDryRun != nil
+}
+
+// readCloser объединяет чтение восстановленного тела с закрытием исходного
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// enabledSnapshots возвращает хранилище снимков или отвечает 404, если снимки не включены
+func (r *Router) enabledSnapshots(w http.ResponseWriter) *snapshot.Store {
+	store := r.snapshotStore()
+	if store == nil {
+		r.sendError(w, http.StatusNotFound, "Снимки запросов не включены")
+	}
+	return store
+}
+
+// handleSnapshotsList возвращает действующие снимки без тел (?form= - снимки формы)
+func (r *Router) handleSnapshotsList(w http.ResponseWriter, req *http.Request) {
+	store := r.enabledSnapshots(w)
+	if store == nil {
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    store.List(req.URL.Query().Get("form")),
+	})
+}
+
+// handleSnapshotGet возвращает снимок по ID запроса
+func (r *Router) handleSnapshotGet(w http.ResponseWriter, req *http.Request) {
+	store := r.enabledSnapshots(w)
+	if store == nil {
+		return
+	}
+
+	id := chi.URLParam(req, "*")
+	entry, ok := store.Get(id)
+	if !ok {
+		r.sendError(w, http.StatusNotFound, "Снимок не найден или истек")
+		return
+	}
+	r.Audit().Record(req.Context(), audit.ActionSnapshotView, entry.Form, map[string]interface{}{
+		"requestId": id,
+	})
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    entry,
+	})
+}
+
+// handleSnapshotDelete удаляет снимок до истечения срока хранения
+func (r *Router) handleSnapshotDelete(w http.ResponseWriter, req *http.Request) {
+	store := r.enabledSnapshots(w)
+	if store == nil {
+		return
+	}
+
+	if !store.Delete(chi.URLParam(req, "*")) {
+		r.sendError(w, http.StatusNotFound, "Снимок не найден или истек")
+		return
+	}
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Message: "Снимок удален",
+	})
+}
+
+// handleSnapshotReplay повторяет запрос снимка в пробном режиме (?dry_run=true) от имени
+// сопровождающего: данные проверяются теми же обработчиками, но не сохраняются
+func (r *Router) handleSnapshotReplay(w http.ResponseWriter, req *http.Request) {
+	store := r.enabledSnapshots(w)
+	if store == nil {
+		return
+	}
+
+	entry, ok := store.Get(chi.URLParam(req, "*"))
+	if !ok {
+		r.sendError(w, http.StatusNotFound, "Снимок не найден или истек")
+		return
+	}
+	// Форма могла быть перерегистрирована после сохранения снимка
+	form, exists := r.lookupForm(entry.Form)
+	if !entry.Replayable || entry.Truncated || !exists || !replaySafe(form, entry.Method) {
+		r.sendError(w, http.StatusConflict, "Запрос снимка нельзя повторить в пробном режиме")
+		return
+	}
+
+	var body []byte
+	switch request := entry.Request.(type) {
+	case nil:
+	case string:
+		body = []byte(request)
+	default:
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			r.sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	query, _ := url.ParseQuery(entry.Query)
+	query.Set("dry_run", "true")
+
+	// Новый контекст маршрутизации: повтор проходит все middleware и маршруты заново
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, (*chi.Context)(nil))
+	ctx = context.WithValue(ctx, replayKey{}, true)
+	replay, err := http.NewRequestWithContext(ctx, entry.Method, entry.Path+"?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	replay.Header = req.Header.Clone()
+	for _, name := range replayHeaders {
+		replay.Header.Del(name)
+	}
+	if entry.ContentType != "" {
+		replay.Header.Set("Content-Type", entry.ContentType)
+	}
+	replay.RemoteAddr = req.RemoteAddr
+
+	recorder := &replayRecorder{header: make(http.Header)}
+	r.mux.ServeHTTP(recorder, replay)
+	r.Audit().Record(req.Context(), audit.ActionSnapshotReplay, entry.Form, map[string]interface{}{
+		"requestId": entry.ID,
+		"status":    recorder.status,
+	})
+
+	result := SnapshotReplay{Status: recorder.status, Original: entry.Status}
+	if recorder.body.Len() > 0 {
+		var response interface{}
+		if err := json.Unmarshal(recorder.body.Bytes(), &response); err != nil {
+			response = recorder.body.String()
+		}
+		result.Response = response
+	}
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// replayRecorder сохраняет ответ повтора снимка
+type replayRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header возвращает заголовки ответа
+func (w *replayRecorder) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader запоминает статус ответа
+func (w *replayRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write сохраняет тело ответа
+func (w *replayRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(p)
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/koteyye/go-formist/auth"
+)
+
+// TestSnapshotDeleteRequiresWrite проверяет, что для удаления снимка недостаточно snapshots:read
+func TestSnapshotDeleteRequiresWrite(t *testing.T) {
+	r := NewRouter()
+	r.AddAPIKey("reader", "read", auth.PermissionSnapshots)
+	r.AddAPIKey("writer", "write", auth.PermissionSnapshots, auth.PermissionSnapshotsWrite)
+	handler := r.Handler()
+
+	remove := func(key string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/api/snapshots/req-1", nil)
+		req.Header.Set(APIKeyHeader, key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if status := remove("read"); status != http.StatusForbidden {
+		t.Fatalf("удаление с snapshots:read: статус %d, ожидался 403", status)
+	}
+	if status := remove("write"); status == http.StatusForbidden || status == http.StatusUnauthorized {
+		t.Fatalf("удаление с snapshots:write: статус %d", status)
+	}
+}
@@ -0,0 +1,161 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/koteyye/go-formist/datasource"
+	"github.com/koteyye/go-formist/interpolate"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// DefaultSchemaCacheControl Cache-Control ответа GET /admin/forms/{name}/schema по умолчанию:
+// схемы меняются редко, поэтому браузеры и CDN могут хранить их и проверять по ETag
+const DefaultSchemaCacheControl = "public, max-age=300, stale-while-revalidate=86400"
+
+// Cache-Control схем при включенной авторизации, схем, зависящих от запроса
+// (переменные пользователя, параметры), схем со справочниками и данных
+const (
+	authSchemaCacheControl       = "private, max-age=300"
+	privateSchemaCacheControl    = "private, no-cache"
+	dictionarySchemaCacheControl = "no-cache"
+	dataCacheControl             = "no-store"
+)
+
+// SetSchemaCacheControl устанавливает Cache-Control для GET /admin/forms/{name}/schema.
+// По умолчанию DefaultSchemaCacheControl, а при включенной авторизации схемы кешируются
+// только браузером. Схемы форм с переменными запроса всегда отдаются как private
+func (r *Router) SetSchemaCacheControl(value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.schemaCacheControl = value
+}
+
+// handleFormSchema возвращает только схемы формы с ETag для кеширования
+func (r *Router) handleFormSchema(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+	if r.accessLog != nil {
+		r.noteAccess(req, form, nil)
+	}
+
+	schemas, err := r.requestSchemas(req, form)
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindValidation, form.Key(), "schema")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка генерации схемы: %v", err))
+		return
+	}
+
+	r.mu.RLock()
+	cacheControl := r.schemaCacheControl
+	authEnabled := r.authEnabled
+	r.mu.RUnlock()
+
+	switch {
+	case interpolate.FormHasVariables(form):
+		cacheControl = privateSchemaCacheControl
+	case len(form.Dictionaries()) > 0:
+		cacheControl = dictionarySchemaCacheControl
+	case cacheControl != "":
+	case authEnabled:
+		cacheControl = authSchemaCacheControl
+	default:
+		cacheControl = DefaultSchemaCacheControl
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+
+	// ETag по содержимому: Last-Modified роутера меняется при любом изменении конфигурации
+	r.sendConditionalJSON(w, req, types.APIResponse{
+		Success: true,
+		Data:    responseFields{FieldSchema: true, FieldUISchema: true}.apply(*schemas),
+	}, time.Time{})
+}
+
+// handleFormData возвращает только данные формы без схем. Ответ не кешируется,
+// если обработчик не вернул types.CachedResult
+func (r *Router) handleFormData(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+
+	onGet := r.formGetHandler(form)
+	if onGet == nil {
+		r.sendError(w, http.StatusMethodNotAllowed, "Получение данных не поддерживается для этой формы")
+		return
+	}
+	if r.accessLog != nil {
+		r.noteAccess(req, form, nil)
+	}
+
+	var response types.FormResponse
+	policy, ok := r.fetchFormData(w, req, form, onGet, &response)
+	if !ok {
+		return
+	}
+
+	cacheControl := dataCacheControl
+	if policy != nil {
+		cacheControl = policy.CacheControl()
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    responseFields{FieldData: true}.apply(response),
+	})
+}
+
+// fetchFormData вызывает обработчик GET формы и записывает данные в response.
+// Возвращает политику кеширования, если обработчик ее задал.
+// При ошибке отправляет ответ и возвращает false
+func (r *Router) fetchFormData(w http.ResponseWriter, req *http.Request, form *types.Form, onGet types.GetHandler, response *types.FormResponse) (*types.CachePolicy, bool) {
+	labelsMode, err := parseLabelsMode(req)
+	if err != nil {
+		r.sendError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	data, policy, err := r.cachedRead(req, form, getFlightKey(form, types.RecordID(req.Context())), onGet)
+	if aborted(req) {
+		return nil, false
+	}
+	if errors.Is(err, datasource.ErrNotFound) {
+		r.sendError(w, http.StatusNotFound, "Запись не найдена")
+		return nil, false
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "onGet")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения данных: %v", err))
+		return nil, false
+	}
+
+	if !r.completeFormData(w, req, form, data, labelsMode, response) {
+		return nil, false
+	}
+	return policy, true
+}
+
+// completeFormData представляет данные OnGet для ответа: часовой пояс, названия значений
+// и сериализаторы. При ошибке отправляет ответ и возвращает false
+func (r *Router) completeFormData(w http.ResponseWriter, req *http.Request, form *types.Form, data interface{}, labelsMode string, response *types.FormResponse) bool {
+	response.Data, response.Timezone = r.presentFormData(req, form, data)
+	if err := r.formLabels(req, form, labelsMode, response); err != nil {
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения названий: %v", err))
+		return false
+	}
+	var err error
+	if response.Data, err = r.serializeData(response.Data); err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "onGet")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка сериализации: %v", err))
+		return false
+	}
+	return true
+}
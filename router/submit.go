@@ -0,0 +1,38 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/koteyye/go-formist/audit"
+)
+
+// ErrFormNotFound возвращается при отправке незарегистрированной формы
+var ErrFormNotFound = errors.New("форма не найдена")
+
+// SubmitForm отправляет данные формы в обход HTTP: с валидацией, лимитами и OnPost формы.
+// Согласование не применяется. Используется для начальных данных и скриптов в процессе
+func (r *Router) SubmitForm(ctx context.Context, key string, data map[string]interface{}) (interface{}, error) {
+	form, exists := r.lookupForm(key)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrFormNotFound, key)
+	}
+	if form.OnPost == nil {
+		return nil, fmt.Errorf("форма %s не поддерживает отправку", key)
+	}
+	if err := r.validateFormData(ctx, form, data); err != nil {
+		return nil, fmt.Errorf("ошибка валидации: %w", err)
+	}
+
+	result, err := r.callFormHandler(ctx, form, func(ctx context.Context) (interface{}, error) {
+		return form.OnPost(ctx, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.responses.invalidate(form.Key())
+	r.Audit().Record(ctx, audit.ActionFormSubmit, form.Key(), nil)
+	return unwrapUndoable(result), nil
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Количество подсказок в ответе
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// handleFieldSuggest возвращает подсказки значений поля по префиксу ?q=
+func (r *Router) handleFieldSuggest(w http.ResponseWriter, req *http.Request) {
+	form, exists := r.lookupForm(formKey(req))
+	if !exists {
+		r.sendError(w, http.StatusNotFound, "Форма не найдена")
+		return
+	}
+	if r.accessLog != nil {
+		r.noteAccess(req, form, nil)
+	}
+
+	fieldName := chi.URLParam(req, "field")
+	var suggest types.SuggestHandler
+	for i := range form.Fields {
+		if form.Fields[i].Name == fieldName {
+			suggest = form.Fields[i].Suggest
+			break
+		}
+	}
+
+	if suggest == nil {
+		r.sendError(w, http.StatusNotFound, "Подсказки для поля не настроены")
+		return
+	}
+
+	limit, err := strconv.Atoi(req.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	prefix := req.URL.Query().Get("q")
+	data, err := callHandler(req.Context(), func(ctx context.Context) (interface{}, error) {
+		return suggest(ctx, prefix), nil
+	})
+	if aborted(req) {
+		return
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, form.Key(), "suggest:"+fieldName)
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка получения подсказок: %v", err))
+		return
+	}
+
+	suggestions, _ := data.([]string)
+	if suggestions == nil {
+		suggestions = []string{}
+	}
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    suggestions,
+	})
+}
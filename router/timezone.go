@@ -0,0 +1,52 @@
+package router
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/auth"
+)
+
+// locationCache загруженные часовые пояса пользователей
+var locationCache sync.Map
+
+// SetTimezone устанавливает часовой пояс отображения админки (по умолчанию UTC)
+func (r *Router) SetTimezone(tz *time.Location) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.timezone = tz
+	r.updatedAt = time.Now()
+}
+
+// displayLocation определяет часовой пояс отображения: пользователя, админки или UTC
+func (r *Router) displayLocation(req *http.Request) *time.Location {
+	if user, ok := auth.UserFromContext(req.Context()); ok && user.Timezone != "" {
+		if tz, ok := loadLocation(user.Timezone); ok {
+			return tz
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.timezone != nil {
+		return r.timezone
+	}
+	return time.UTC
+}
+
+// loadLocation загружает часовой пояс по имени IANA с кешированием
+func loadLocation(name string) (*time.Location, bool) {
+	if cached, ok := locationCache.Load(name); ok {
+		return cached.(*time.Location), true
+	}
+
+	tz, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	locationCache.Store(name, tz)
+	return tz, true
+}
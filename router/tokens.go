@@ -0,0 +1,260 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/tokens"
+	"github.com/koteyye/go-formist/types"
+)
+
+// tokenContextKey ключ персонального токена запроса в контексте
+type tokenContextKey struct{}
+
+// tokenRequest тело запроса выпуска токена
+type tokenRequest struct {
+	Name      string         `json:"name"`
+	Scopes    []tokens.Scope `json:"scopes"`
+	ExpiresIn string         `json:"expiresIn"` // например "720h", по умолчанию tokens.DefaultTTL
+}
+
+// issuedToken ответ на выпуск токена: значение показывается только один раз
+type issuedToken struct {
+	*tokens.Token
+	Secret string `json:"secret"`
+}
+
+// SetTokens устанавливает сервис персональных токенов
+func (r *Router) SetTokens(service *tokens.Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = service
+}
+
+// Tokens возвращает сервис персональных токенов
+func (r *Router) Tokens() *tokens.Service {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tokens
+}
+
+// tokenSecret возвращает значение персонального токена из заголовков запроса
+func tokenSecret(req *http.Request) (string, bool) {
+	secret := req.Header.Get(APIKeyHeader)
+	if secret == "" {
+		secret, _ = strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	}
+	return secret, strings.HasPrefix(secret, tokens.Prefix)
+}
+
+// tokenRoute проверяет, что путь относится к формам: персональные токены действуют только там
+func tokenRoute(path string) bool {
+	path = strings.TrimRight(path, "/")
+	if path == "/admin/forms" || strings.HasPrefix(path, "/admin/forms/") {
+		return true
+	}
+	module, ok := strings.CutPrefix(path, "/admin/modules/")
+	if !ok {
+		return false
+	}
+	_, rest, ok := strings.Cut(module, "/")
+	return ok && strings.HasPrefix(rest, "forms/")
+}
+
+// tokenAuth аутентифицирует запросы с персональным токеном: пользователем запроса
+// становится владелец токена, а ID токена попадает в записи аудита
+func (r *Router) tokenAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		secret, ok := tokenSecret(req)
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		token, err := r.Tokens().Authenticate(req.Context(), secret)
+		switch {
+		case errors.Is(err, tokens.ErrInvalid), errors.Is(err, tokens.ErrExpired):
+			r.sendError(w, http.StatusUnauthorized, err.Error())
+			return
+		case err != nil:
+			r.sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if !tokenRoute(strings.TrimPrefix(req.URL.Path, r.prefix)) {
+			r.sendError(w, http.StatusForbidden, "Персональный токен действует только для форм")
+			return
+		}
+
+		ctx := auth.WithUser(req.Context(), token.User())
+		ctx = context.WithValue(ctx, tokenContextKey{}, token)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// tokenScope проверяет, что токен запроса разрешает операцию с формой:
+// чтение для GET, действие для /actions/{action}, отправку для остальных запросов
+func (r *Router) tokenScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, ok := req.Context().Value(tokenContextKey{}).(*tokens.Token)
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		key := formKey(req)
+		var allowed bool
+		switch action := chi.URLParam(req, "action"); {
+		case req.Method == http.MethodGet || req.Method == http.MethodHead:
+			allowed = token.CanRead(key)
+		case action != "":
+			allowed = token.CanAction(key, action)
+		default:
+			allowed = token.CanSubmit(key)
+		}
+
+		if !allowed {
+			r.sendError(w, http.StatusForbidden, "Токен не разрешает эту операцию с формой")
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// tokensManager проверяет, что пользователь может просматривать и отзывать чужие токены
+func (r *Router) tokensManager(req *http.Request) bool {
+	r.mu.RLock()
+	manager := !r.authEnabled && len(r.apiKeys) == 0
+	r.mu.RUnlock()
+	if user, ok := auth.UserFromContext(req.Context()); ok && user.HasPermission(auth.PermissionTokensManage) {
+		manager = true
+	}
+	return manager
+}
+
+// handleTokensList возвращает токены текущего пользователя. ?all=1 - токены всех
+// пользователей, требуется разрешение auth.PermissionTokensManage
+func (r *Router) handleTokensList(w http.ResponseWriter, req *http.Request) {
+	var userID string
+	if req.URL.Query().Get("all") != "" {
+		if !r.tokensManager(req) {
+			r.sendError(w, http.StatusForbidden, "Недостаточно прав: требуется "+auth.PermissionTokensManage)
+			return
+		}
+	} else {
+		user, ok := auth.UserFromContext(req.Context())
+		if !ok {
+			r.sendError(w, http.StatusUnauthorized, "Требуется авторизация")
+			return
+		}
+		userID = user.ID
+	}
+
+	items, err := r.Tokens().List(req.Context(), userID)
+	if err != nil {
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Data:    items,
+	})
+}
+
+// handleTokenCreate выпускает токен текущего пользователя для доступных ему форм
+func (r *Router) handleTokenCreate(w http.ResponseWriter, req *http.Request) {
+	user, ok := auth.UserFromContext(req.Context())
+	if !ok {
+		r.sendError(w, http.StatusUnauthorized, "Требуется авторизация")
+		return
+	}
+
+	var request tokenRequest
+	if err := r.decodeJSON(req.Body, &request); err != nil {
+		r.sendError(w, http.StatusBadRequest, "Некорректные данные JSON")
+		return
+	}
+
+	var ttl time.Duration
+	if request.ExpiresIn != "" {
+		parsed, err := time.ParseDuration(request.ExpiresIn)
+		if err != nil || parsed <= 0 {
+			r.sendError(w, http.StatusBadRequest, "Некорректный срок действия токена")
+			return
+		}
+		ttl = parsed
+	}
+
+	for _, scope := range request.Scopes {
+		form, exists := r.lookupForm(scope.Form)
+		if !exists {
+			r.sendError(w, http.StatusBadRequest, "Форма не найдена: "+scope.Form)
+			return
+		}
+		if status, message := r.formAccess(req, form); status != http.StatusOK {
+			r.sendError(w, status, message)
+			return
+		}
+	}
+
+	token, secret, err := r.Tokens().Issue(req.Context(), user, request.Name, request.Scopes, ttl)
+	if err != nil {
+		r.sendTokenError(w, err)
+		return
+	}
+	r.Audit().Record(req.Context(), audit.ActionTokenCreate, token.ID, map[string]interface{}{
+		"name":      token.Name,
+		"scopes":    token.Scopes,
+		"expiresAt": token.ExpiresAt,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	r.encodeJSON(w, types.APIResponse{
+		Success: true,
+		Data:    issuedToken{Token: token, Secret: secret},
+	})
+}
+
+// handleTokenRevoke отзывает токен. Чужие токены отзывает
+// только пользователь с разрешением auth.PermissionTokensManage
+func (r *Router) handleTokenRevoke(w http.ResponseWriter, req *http.Request) {
+	token, err := r.Tokens().Revoke(req.Context(), chi.URLParam(req, "id"), r.tokensManager(req))
+	if err != nil {
+		r.sendTokenError(w, err)
+		return
+	}
+	r.Audit().Record(req.Context(), audit.ActionTokenRevoke, token.ID, map[string]interface{}{
+		"owner": token.Owner.ID,
+	})
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Message: "Токен отозван",
+	})
+}
+
+// sendTokenError отправляет ошибку токенов с соответствующим статусом
+func (r *Router) sendTokenError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, tokens.ErrNotFound):
+		r.sendError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, tokens.ErrForbidden):
+		r.sendError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, tokens.ErrNoScopes), errors.Is(err, tokens.ErrTTL):
+		r.sendError(w, http.StatusBadRequest, err.Error())
+	default:
+		r.sendError(w, http.StatusInternalServerError, err.Error())
+	}
+}
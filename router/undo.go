@@ -0,0 +1,169 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/koteyye/go-formist/audit"
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/reporting"
+	"github.com/koteyye/go-formist/types"
+)
+
+// undoEntry представляет зарегистрированную компенсирующую функцию
+type undoEntry struct {
+	undo      types.UndoFunc
+	userID    string
+	expiresAt time.Time
+}
+
+// undoRegistry хранит компенсирующие функции до истечения окна отмены
+type undoRegistry struct {
+	mu      sync.Mutex
+	entries map[string]*undoEntry
+}
+
+// newUndoRegistry создает реестр отмен
+func newUndoRegistry() *undoRegistry {
+	return &undoRegistry{
+		entries: make(map[string]*undoEntry),
+	}
+}
+
+// register сохраняет компенсирующую функцию и возвращает данные для UI
+func (u *undoRegistry) register(ctx context.Context, result *types.UndoableResult) *types.UndoInfo {
+	ttl := result.TTL
+	if ttl <= 0 {
+		ttl = types.DefaultUndoTTL
+	}
+
+	entry := &undoEntry{
+		undo:      result.Undo,
+		expiresAt: time.Now().Add(ttl),
+	}
+	if user, ok := auth.UserFromContext(ctx); ok {
+		entry.userID = user.ID
+	}
+
+	token := newToken()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.purge()
+	u.entries[token] = entry
+
+	return &types.UndoInfo{
+		Token:     token,
+		Label:     result.Label,
+		ExpiresAt: entry.expiresAt,
+	}
+}
+
+// take извлекает компенсирующую функцию: отменить действие можно только один раз
+// и только пользователю, который его выполнил
+func (u *undoRegistry) take(ctx context.Context, token string) (*undoEntry, int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.purge()
+	entry, ok := u.entries[token]
+	if !ok {
+		return nil, http.StatusGone
+	}
+
+	if entry.userID != "" {
+		user, _ := auth.UserFromContext(ctx)
+		if user == nil || user.ID != entry.userID {
+			return nil, http.StatusForbidden
+		}
+	}
+
+	delete(u.entries, token)
+	return entry, http.StatusOK
+}
+
+// purge удаляет записи с истекшим окном отмены
+func (u *undoRegistry) purge() {
+	now := time.Now()
+	for token, entry := range u.entries {
+		if now.After(entry.expiresAt) {
+			delete(u.entries, token)
+		}
+	}
+}
+
+// newToken генерирует случайный токен отмены
+func newToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// sendResult отправляет результат обработчика с предупреждениями валидации в meta.warnings.
+// Для отменяемого результата регистрирует компенсацию и добавляет токен в meta.undo
+func (r *Router) sendResult(w http.ResponseWriter, req *http.Request, result interface{}, warnings []types.ValidationWarning) {
+	response := types.APIResponse{
+		Success: true,
+		Data:    result,
+		Meta:    warningsMeta(warnings),
+	}
+
+	if undoable, ok := result.(*types.UndoableResult); ok && undoable != nil {
+		response.Data = undoable.Data
+		if undoable.Undo != nil {
+			if response.Meta == nil {
+				response.Meta = &types.ResponseMeta{}
+			}
+			response.Meta.Undo = r.undo.register(req.Context(), undoable)
+		}
+	}
+
+	r.sendJSON(w, response)
+}
+
+// unwrapUndoable возвращает данные результата без регистрации отмены
+func unwrapUndoable(result interface{}) interface{} {
+	if undoable, ok := result.(*types.UndoableResult); ok && undoable != nil {
+		return undoable.Data
+	}
+	return result
+}
+
+// handleUndo вызывает компенсирующую функцию по токену отмены
+func (r *Router) handleUndo(w http.ResponseWriter, req *http.Request) {
+	entry, status := r.undo.take(req.Context(), chi.URLParam(req, "token"))
+	switch status {
+	case http.StatusGone:
+		r.sendError(w, status, "Время отмены истекло или действие уже отменено")
+		return
+	case http.StatusForbidden:
+		r.sendError(w, status, "Нет прав на отмену действия")
+		return
+	}
+
+	_, err := callHandler(req.Context(), func(ctx context.Context) (interface{}, error) {
+		return nil, entry.undo(ctx)
+	})
+	if aborted(req) {
+		return
+	}
+	if err != nil {
+		r.reportRequestError(req, err, reporting.KindHandler, "", "undo")
+		r.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка отмены: %v", err))
+		return
+	}
+	r.Audit().Record(req.Context(), audit.ActionUndo, chi.URLParam(req, "token"), nil)
+
+	r.sendJSON(w, types.APIResponse{
+		Success: true,
+		Message: "Действие отменено",
+	})
+}
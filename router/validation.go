@@ -0,0 +1,115 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/koteyye/go-formist/form"
+	"github.com/koteyye/go-formist/types"
+)
+
+// formValidator хранит подготовленный валидатор формы или ошибку его создания
+type formValidator struct {
+	validator *form.Validator
+	err       error
+}
+
+// compileValidator подготавливает валидатор формы при регистрации
+func compileValidator(f *types.Form) (*formValidator, error) {
+	validator, err := form.NewValidator(f)
+	return &formValidator{
+		validator: validator,
+		err:       err,
+	}, err
+}
+
+// lookupValidator возвращает подготовленный валидатор формы
+func (r *Router) lookupValidator(name string) (*formValidator, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fv, ok := r.validators[name]
+	return fv, ok
+}
+
+// validatorError возвращает ошибку подготовки валидатора формы
+func (r *Router) validatorError(name string) error {
+	if fv, ok := r.lookupValidator(name); ok {
+		return fv.err
+	}
+	return nil
+}
+
+// normalizeFormData приводит локализованные значения к каноническим типам
+func (r *Router) normalizeFormData(req *http.Request, f *types.Form, data map[string]interface{}) error {
+	loc, ok := r.inputLocale(req, f)
+	if ok {
+		if err := form.Normalize(f, data, loc); err != nil {
+			return err
+		}
+	}
+	return form.NormalizeDateTimes(f, data, loc, r.displayLocation(req))
+}
+
+// presentFormData переводит значения datetime в часовой пояс отображения.
+// Возвращает данные и имя часового пояса
+func (r *Router) presentFormData(req *http.Request, f *types.Form, data interface{}) (interface{}, string) {
+	tz := r.displayLocation(req)
+	return form.PresentDateTimes(f, data, tz), tz.String()
+}
+
+// formWarnings возвращает сработавшие предупреждающие правила формы
+func (r *Router) formWarnings(f *types.Form, data map[string]interface{}) []types.ValidationWarning {
+	if fv, ok := r.lookupValidator(f.Key()); ok && fv.validator != nil {
+		return fv.validator.Warnings(data)
+	}
+
+	validator, err := form.NewValidator(f)
+	if err != nil {
+		return nil
+	}
+	return validator.Warnings(data)
+}
+
+// validateFormData валидирует данные формы подготовленным валидатором
+// и проверяет значения полей со ссылкой на справочник
+func (r *Router) validateFormData(ctx context.Context, f *types.Form, data map[string]interface{}) error {
+	validator, ok := r.lookupValidator(f.Key())
+	if !ok || validator.validator == nil {
+		compiled, err := form.NewValidator(f)
+		if err != nil {
+			return err
+		}
+		validator = &formValidator{validator: compiled}
+	}
+
+	if err := validator.validator.Validate(data); err != nil {
+		return err
+	}
+	return r.validateDictionaryValues(ctx, f, data)
+}
+
+// validateFormChanges проверяет только поля, измененные частичным обновлением
+func (r *Router) validateFormChanges(ctx context.Context, f *types.Form, changes map[string]interface{}) error {
+	validator, ok := r.lookupValidator(f.Key())
+	if !ok || validator.validator == nil {
+		compiled, err := form.NewValidator(f)
+		if err != nil {
+			return err
+		}
+		validator = &formValidator{validator: compiled}
+	}
+
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := validator.validator.ValidateValue(name, changes[name]); err != nil {
+			return err
+		}
+	}
+	return r.validateDictionaryValues(ctx, f, changes)
+}
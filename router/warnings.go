@@ -0,0 +1,34 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// warningsConfirmed проверяет параметр ?confirm_warnings=true
+func warningsConfirmed(req *http.Request) bool {
+	confirmed, _ := strconv.ParseBool(req.URL.Query().Get("confirm_warnings"))
+	return confirmed
+}
+
+// warningsMeta возвращает meta с предупреждениями или nil, если их нет
+func warningsMeta(warnings []types.ValidationWarning) *types.ResponseMeta {
+	if len(warnings) == 0 {
+		return nil
+	}
+	return &types.ResponseMeta{Warnings: warnings}
+}
+
+// sendWarningsConfirmation отвечает 409, если форма требует подтверждения предупреждений.
+// Клиент повторяет запрос с ?confirm_warnings=true
+func (r *Router) sendWarningsConfirmation(w http.ResponseWriter, warnings []types.ValidationWarning) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	r.encodeJSON(w, types.APIResponse{
+		Success: false,
+		Error:   "Требуется подтверждение предупреждений",
+		Meta:    warningsMeta(warnings),
+	})
+}
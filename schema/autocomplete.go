@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// autocompleteFields содержит имена полей HTML autocomplete (WHATWG HTML, 4.10.18.7)
+var autocompleteFields = map[string]bool{
+	"name": true, "honorific-prefix": true, "given-name": true, "additional-name": true,
+	"family-name": true, "honorific-suffix": true, "nickname": true, "username": true,
+	"new-password": true, "current-password": true, "one-time-code": true,
+	"organization-title": true, "organization": true, "street-address": true,
+	"address-line1": true, "address-line2": true, "address-line3": true,
+	"address-level4": true, "address-level3": true, "address-level2": true, "address-level1": true,
+	"country": true, "country-name": true, "postal-code": true,
+	"cc-name": true, "cc-given-name": true, "cc-additional-name": true, "cc-family-name": true,
+	"cc-number": true, "cc-exp": true, "cc-exp-month": true, "cc-exp-year": true, "cc-csc": true, "cc-type": true,
+	"transaction-currency": true, "transaction-amount": true, "language": true,
+	"bday": true, "bday-day": true, "bday-month": true, "bday-year": true,
+	"sex": true, "url": true, "photo": true,
+	"tel": true, "tel-country-code": true, "tel-national": true, "tel-area-code": true,
+	"tel-local": true, "tel-extension": true, "email": true, "impp": true,
+}
+
+// autocompleteContactFields поля, допускающие уточнение home/work/mobile/fax/pager
+var autocompleteContactFields = map[string]bool{
+	"tel": true, "tel-country-code": true, "tel-national": true, "tel-area-code": true,
+	"tel-local": true, "tel-extension": true, "email": true, "impp": true,
+}
+
+// ValidateAutocomplete проверяет значение атрибута autocomplete:
+// "on", "off" или [section-*] [shipping|billing] [home|work|mobile|fax|pager] поле [webauthn]
+func ValidateAutocomplete(value string) error {
+	tokens := strings.Fields(strings.ToLower(value))
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	if len(tokens) == 1 && (tokens[0] == "on" || tokens[0] == "off") {
+		return nil
+	}
+
+	if tokens[len(tokens)-1] == "webauthn" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("autocomplete %q: не указано поле", value)
+	}
+
+	name := tokens[len(tokens)-1]
+	if !autocompleteFields[name] {
+		return fmt.Errorf("autocomplete %q: неизвестное поле %s", value, name)
+	}
+
+	prefixes := tokens[:len(tokens)-1]
+	if len(prefixes) > 0 && strings.HasPrefix(prefixes[0], "section-") {
+		prefixes = prefixes[1:]
+	}
+	if len(prefixes) > 0 && (prefixes[0] == "shipping" || prefixes[0] == "billing") {
+		prefixes = prefixes[1:]
+	}
+	if len(prefixes) > 0 && autocompleteContactFields[name] {
+		switch prefixes[0] {
+		case "home", "work", "mobile", "fax", "pager":
+			prefixes = prefixes[1:]
+		}
+	}
+	if len(prefixes) > 0 {
+		return fmt.Errorf("autocomplete %q: недопустимый токен %s", value, prefixes[0])
+	}
+
+	return nil
+}
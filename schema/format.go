@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// currencyCode код валюты ISO 4217
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// linkPlaceholder подстановка в шаблоне ссылки колонки
+var linkPlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// validateColumnFormat проверяет форматирование значений колонки таблицы
+func validateColumnFormat(config *types.TableConfig, column *types.TableColumn) error {
+	format := column.Format
+	if format == nil {
+		return nil
+	}
+	if format.Decimals != nil && (*format.Decimals < 0 || *format.Decimals > 20) {
+		return fmt.Errorf("число знаков после запятой должно быть от 0 до 20")
+	}
+
+	switch format.Kind {
+	case types.ColumnFormatCurrency:
+		if !currencyCode.MatchString(format.Currency) {
+			return fmt.Errorf("некорректный код валюты %q: ожидается код ISO 4217, например RUB", format.Currency)
+		}
+
+	case types.ColumnFormatPercent, types.ColumnFormatBytes:
+
+	case types.ColumnFormatDateTime:
+		if strings.TrimSpace(format.Pattern) == "" {
+			return fmt.Errorf("не задан шаблон даты")
+		}
+
+	case types.ColumnFormatBadge:
+		if len(format.Badges) == 0 {
+			return fmt.Errorf("не заданы цвета бейджей")
+		}
+		if len(column.Options) > 0 {
+			for value := range format.Badges {
+				if !hasOption(column.Options, value) {
+					return fmt.Errorf("цвет бейджа задан для значения %q не из списка опций", value)
+				}
+			}
+		}
+
+	case types.ColumnFormatLink:
+		if format.Link == "" {
+			return fmt.Errorf("не задан шаблон ссылки")
+		}
+		for _, match := range linkPlaceholder.FindAllStringSubmatch(format.Link, -1) {
+			if match[1] != "value" && !hasColumn(config, match[1]) {
+				return fmt.Errorf("шаблон ссылки ссылается на неизвестную колонку %q", match[1])
+			}
+		}
+
+	default:
+		return fmt.Errorf("неизвестный вид форматирования %q", format.Kind)
+	}
+
+	return nil
+}
+
+// validateRowKey проверяет, что ключ строки ссылается на колонки таблицы
+func validateRowKey(config *types.TableConfig) error {
+	rowKey := config.RowKey
+	if rowKey == nil {
+		return nil
+	}
+	if len(rowKey.Columns) == 0 && rowKey.Func == nil {
+		return fmt.Errorf("у ключа строки не заданы колонки или функция")
+	}
+	for _, key := range rowKey.Columns {
+		if !hasColumn(config, key) {
+			return fmt.Errorf("ключ строки ссылается на неизвестную колонку %q", key)
+		}
+	}
+	return nil
+}
+
+// hasOption сообщает, что значение входит в список опций
+func hasOption(options []types.SelectOption, value string) bool {
+	for _, option := range options {
+		if fmt.Sprint(option.Value) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// hasColumn сообщает, что у таблицы есть колонка с ключом key
+func hasColumn(config *types.TableConfig, key string) bool {
+	for _, column := range config.Columns {
+		if column.Key == key {
+			return true
+		}
+	}
+	return false
+}
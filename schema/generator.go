@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/koteyye/go-formist/types"
 )
@@ -31,9 +32,7 @@ func GenerateJSONSchema(form *types.Form) (*JSONSchema, error) {
 		Type:        "object",
 		Title:       form.Title,
 		Description: form.Description,
-		Properties:  make(map[string]interface{}),
-		Required:    make([]string, 0),
-		Definitions: make(map[string]interface{}),
+		Properties:  make(map[string]interface{}, len(form.Fields)),
 	}
 
 	// Обрабатываем поля формы
@@ -94,112 +93,227 @@ func GenerateUISchema(form *types.Form) map[string]interface{} {
 		uiSchema["ui:groups"] = groups
 	}
 
+	// Кнопки формы
+	if form.Actions != nil {
+		generateActionsUISchema(form.Actions, uiSchema)
+	}
+
+	// Сочетания клавиш
+	if len(form.Shortcuts) > 0 {
+		shortcuts := make([]map[string]interface{}, 0, len(form.Shortcuts))
+		for _, shortcut := range form.Shortcuts {
+			keys, err := types.NormalizeShortcut(shortcut.Keys)
+			if err != nil {
+				continue
+			}
+			shortcutUI := map[string]interface{}{
+				"keys":   keys,
+				"action": shortcut.Action,
+			}
+			if shortcut.Label != "" {
+				shortcutUI["label"] = shortcut.Label
+			}
+			shortcuts = append(shortcuts, shortcutUI)
+		}
+		uiSchema["ui:shortcuts"] = shortcuts
+	}
+
 	return uiSchema
 }
 
-// generateFieldSchema генерирует схему для отдельного поля
-func generateFieldSchema(field *types.Field) (map[string]interface{}, error) {
-	fieldSchema := map[string]interface{}{
-		"title":       field.Label,
-		"description": field.Description,
+// generateActionsUISchema добавляет в UI схему настройки кнопок формы
+func generateActionsUISchema(actions *types.Actions, uiSchema map[string]interface{}) {
+	if actions.SubmitLabel != "" {
+		uiSchema["ui:submitButtonOptions"] = map[string]interface{}{
+			"submitText": actions.SubmitLabel,
+		}
+	}
+	if actions.CancelLabel != "" {
+		uiSchema["ui:cancelText"] = actions.CancelLabel
+	}
+	if actions.ConfirmSubmit != "" {
+		uiSchema["ui:confirmSubmit"] = actions.ConfirmSubmit
+	}
+
+	if len(actions.Custom) > 0 {
+		custom := make([]map[string]interface{}, 0, len(actions.Custom))
+		for _, action := range actions.Custom {
+			actionUI := map[string]interface{}{
+				"name":  action.Name,
+				"label": action.Label,
+			}
+			if action.Confirm != "" {
+				actionUI["confirm"] = action.Confirm
+			}
+			custom = append(custom, actionUI)
+		}
+		uiSchema["ui:actions"] = custom
+	}
+
+	if actions.Duplicate != nil {
+		label := actions.Duplicate.Label
+		if label == "" {
+			label = "Копировать"
+		}
+		uiSchema["ui:duplicate"] = map[string]interface{}{
+			"label": label,
+		}
+	}
+}
+
+// FieldSchema представляет JSON Schema отдельного поля.
+// Используется вместо map[string]interface{}, чтобы не создавать
+// множество мелких map на каждый запрос схемы формы
+type FieldSchema struct {
+	Type        string                 `json:"type,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	Examples    []string               `json:"examples,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	Items       *FieldSchema           `json:"items,omitempty"`
+	UniqueItems bool                   `json:"uniqueItems,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	Default     interface{}            `json:"default,omitempty"`
+	Minimum     *float64               `json:"minimum,omitempty"`
+	Maximum     *float64               `json:"maximum,omitempty"`
+	MinLength   *int                   `json:"minLength,omitempty"`
+	MaxLength   *int                   `json:"maxLength,omitempty"`
+	Pattern     string                 `json:"pattern,omitempty"`
+}
+
+// generateFieldSchema генерирует схему для отдельного поля.
+//
+// BenchmarkGenerateSchema (форма из 61 поля, GenerateJSONSchema + json.Marshal):
+// со схемами полей в map[string]interface{} - 456 allocs/op, 49417 B/op;
+// с FieldSchema - 322 allocs/op, 30190 B/op
+func generateFieldSchema(field *types.Field) (*FieldSchema, error) {
+	fieldSchema := &FieldSchema{
+		Title:       field.Label,
+		Description: field.Description,
 	}
 
 	// Устанавливаем тип и формат в зависимости от типа поля
 	switch field.Type {
 	case types.FieldTypeText:
-		fieldSchema["type"] = "string"
+		fieldSchema.Type = "string"
 		if field.Placeholder != "" {
-			fieldSchema["examples"] = []string{field.Placeholder}
+			fieldSchema.Examples = []string{field.Placeholder}
 		}
 
 	case types.FieldTypeEmail:
-		fieldSchema["type"] = "string"
-		fieldSchema["format"] = "email"
+		fieldSchema.Type = "string"
+		fieldSchema.Format = "email"
 
 	case types.FieldTypePassword:
-		fieldSchema["type"] = "string"
-		fieldSchema["format"] = "password"
+		fieldSchema.Type = "string"
+		fieldSchema.Format = "password"
 
 	case types.FieldTypeNumber:
-		fieldSchema["type"] = "number"
+		fieldSchema.Type = "number"
 
 	case types.FieldTypeTextarea:
-		fieldSchema["type"] = "string"
+		fieldSchema.Type = "string"
 
 	case types.FieldTypeDate:
-		fieldSchema["type"] = "string"
-		fieldSchema["format"] = "date"
+		fieldSchema.Type = "string"
+		fieldSchema.Format = "date"
 
 	case types.FieldTypeTime:
-		fieldSchema["type"] = "string"
-		fieldSchema["format"] = "time"
+		fieldSchema.Type = "string"
+		fieldSchema.Format = "time"
+
+	case types.FieldTypeDateTime:
+		fieldSchema.Type = "string"
+		fieldSchema.Format = "date-time"
 
 	case types.FieldTypeFile:
-		fieldSchema["type"] = "string"
-		fieldSchema["format"] = "data-url"
+		fieldSchema.Type = "string"
+		fieldSchema.Format = "data-url"
 
 	case types.FieldTypeCheckbox:
-		fieldSchema["type"] = "boolean"
+		fieldSchema.Type = "boolean"
 
 	case types.FieldTypeRadio, types.FieldTypeSelect:
 		if field.Multiple {
-			fieldSchema["type"] = "array"
-			fieldSchema["items"] = map[string]interface{}{
-				"type": "string",
-				"enum": getOptionValues(field.Options),
+			fieldSchema.Type = "array"
+			fieldSchema.Items = &FieldSchema{
+				Type: "string",
+				Enum: getOptionValues(field.Options),
 			}
-			fieldSchema["uniqueItems"] = true
+			fieldSchema.UniqueItems = true
 		} else {
-			fieldSchema["type"] = "string"
-			fieldSchema["enum"] = getOptionValues(field.Options)
+			fieldSchema.Type = "string"
+			fieldSchema.Enum = getOptionValues(field.Options)
 		}
 
 	case types.FieldTypeTable:
 		if field.TableConfig != nil {
-			tableSchema := generateTableSchema(field.TableConfig)
-			fieldSchema = tableSchema
+			fieldSchema = generateTableSchema(field.TableConfig)
 		} else {
-			fieldSchema["type"] = "object"
+			fieldSchema.Type = "object"
 		}
 
 	default:
-		fieldSchema["type"] = "string"
+		fieldSchema.Type = "string"
 	}
 
 	// Добавляем значение по умолчанию
 	if field.DefaultValue != nil {
-		fieldSchema["default"] = field.DefaultValue
+		fieldSchema.Default = field.DefaultValue
+	}
+
+	// Явные примеры значений заменяют пример из placeholder
+	if len(field.Examples) > 0 {
+		fieldSchema.Examples = field.Examples
 	}
 
-	// Добавляем правила валидации
+	// Добавляем правила валидации. Предупреждения не ограничивают ввод и передаются в UI Schema
 	for _, rule := range field.Validation {
+		if rule.IsWarning() {
+			continue
+		}
 		switch rule.Type {
 		case "min":
 			if num, ok := rule.Value.(float64); ok {
-				fieldSchema["minimum"] = num
+				fieldSchema.Minimum = &num
 			}
 		case "max":
 			if num, ok := rule.Value.(float64); ok {
-				fieldSchema["maximum"] = num
+				fieldSchema.Maximum = &num
 			}
 		case "minLength":
 			if num, ok := rule.Value.(float64); ok {
-				fieldSchema["minLength"] = int(num)
+				length := int(num)
+				fieldSchema.MinLength = &length
 			}
 		case "maxLength":
 			if num, ok := rule.Value.(float64); ok {
-				fieldSchema["maxLength"] = int(num)
+				length := int(num)
+				fieldSchema.MaxLength = &length
 			}
 		case "pattern":
 			if pattern, ok := rule.Value.(string); ok {
-				fieldSchema["pattern"] = pattern
+				fieldSchema.Pattern = pattern
 			}
 		}
 	}
 
+	// Маска ввода проверяется тем же выражением на клиенте и сервере
+	if field.Mask != "" && fieldSchema.Pattern == "" {
+		pattern, err := MaskPattern(field.Mask)
+		if err != nil {
+			return nil, err
+		}
+		fieldSchema.Pattern = pattern
+	}
+
 	return fieldSchema, nil
 }
 
+// SuggestDebounce задержка, которую UI выдерживает после ввода перед запросом подсказок
+const SuggestDebounce = 300 * time.Millisecond
+
 // generateFieldUISchema генерирует UI схему для отдельного поля
 func generateFieldUISchema(field *types.Field) map[string]interface{} {
 	uiSchema := make(map[string]interface{})
@@ -218,6 +332,9 @@ func generateFieldUISchema(field *types.Field) map[string]interface{} {
 	case types.FieldTypeFile:
 		uiSchema["ui:widget"] = "file"
 
+	case types.FieldTypeDateTime:
+		uiSchema["ui:widget"] = "datetime"
+
 	case types.FieldTypeCheckbox:
 		uiSchema["ui:widget"] = "checkbox"
 
@@ -251,6 +368,62 @@ func generateFieldUISchema(field *types.Field) map[string]interface{} {
 		uiSchema["ui:widget"] = "hidden"
 	}
 
+	// Маска ввода
+	if field.Mask != "" {
+		if uiOptions, ok := uiSchema["ui:options"].(map[string]interface{}); ok {
+			uiOptions["mask"] = field.Mask
+		} else {
+			uiSchema["ui:options"] = map[string]interface{}{"mask": field.Mask}
+		}
+	}
+
+	// Предупреждающие правила
+	var warnings []types.ValidationRule
+	for _, rule := range field.Validation {
+		if rule.IsWarning() {
+			warnings = append(warnings, rule)
+		}
+	}
+	if len(warnings) > 0 {
+		uiSchema["ui:warnings"] = warnings
+	}
+
+	// Подсказки значений с сервера; UI запрашивает их не чаще, чем раз в debounceMs
+	if field.Suggest != nil {
+		uiSchema["ui:suggest"] = map[string]interface{}{
+			"debounceMs": SuggestDebounce.Milliseconds(),
+		}
+	}
+
+	// Ссылка на запись другой формы
+	if field.Link != nil {
+		uiSchema["ui:link"] = field.Link
+	}
+
+	// Справка и подсказка
+	if field.Help != "" {
+		uiSchema["ui:help"] = field.Help
+	}
+	if field.Tooltip != "" {
+		uiSchema["ui:tooltip"] = field.Tooltip
+	}
+
+	// Автозаполнение и доступность
+	if field.Autocomplete != "" {
+		uiSchema["ui:autocomplete"] = field.Autocomplete
+	}
+	if a11y := field.Accessibility; a11y != nil {
+		if a11y.AriaLabel != "" {
+			uiSchema["ui:ariaLabel"] = a11y.AriaLabel
+		}
+		if a11y.AriaDescribedBy != "" {
+			uiSchema["ui:ariaDescribedBy"] = a11y.AriaDescribedBy
+		}
+		if a11y.TabIndex != 0 {
+			uiSchema["ui:tabIndex"] = a11y.TabIndex
+		}
+	}
+
 	// Placeholder
 	if field.Placeholder != "" {
 		uiSchema["ui:placeholder"] = field.Placeholder
@@ -282,63 +455,44 @@ func generateFieldUISchema(field *types.Field) map[string]interface{} {
 	return uiSchema
 }
 
+// tableSchemaProperties содержит неизменяемые свойства схемы таблицы.
+// Вычисляется один раз, так как не зависит от конфигурации таблицы
+var tableSchemaProperties = map[string]interface{}{
+	"columns": &FieldSchema{
+		Type:  "array",
+		Items: generateTableColumnSchema(),
+	},
+	"rows": &FieldSchema{
+		Type:  "array",
+		Items: &FieldSchema{Type: "object"},
+	},
+	"total": &FieldSchema{Type: "integer"},
+	"page":  &FieldSchema{Type: "integer"},
+	"limit": &FieldSchema{Type: "integer"},
+}
+
 // generateTableSchema генерирует схему для таблицы
-func generateTableSchema(config *types.TableConfig) map[string]interface{} {
-	schema := map[string]interface{}{
-		"type":  "object",
-		"title": "Таблица",
-		"properties": map[string]interface{}{
-			"columns": map[string]interface{}{
-				"type":  "array",
-				"items": generateTableColumnSchema(),
-			},
-			"rows": map[string]interface{}{
-				"type":  "array",
-				"items": map[string]interface{}{
-					"type": "object",
-				},
-			},
-			"total": map[string]interface{}{
-				"type": "integer",
-			},
-			"page": map[string]interface{}{
-				"type": "integer",
-			},
-			"limit": map[string]interface{}{
-				"type": "integer",
-			},
-		},
+func generateTableSchema(config *types.TableConfig) *FieldSchema {
+	return &FieldSchema{
+		Type:       "object",
+		Title:      "Таблица",
+		Properties: tableSchemaProperties,
 	}
-
-	return schema
 }
 
 // generateTableColumnSchema генерирует схему для колонки таблицы
-func generateTableColumnSchema() map[string]interface{} {
-	return map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"key": map[string]interface{}{
-				"type": "string",
-			},
-			"title": map[string]interface{}{
-				"type": "string",
-			},
-			"type": map[string]interface{}{
-				"type": "string",
-			},
-			"sortable": map[string]interface{}{
-				"type": "boolean",
-			},
-			"filterable": map[string]interface{}{
-				"type": "boolean",
-			},
-			"width": map[string]interface{}{
-				"type": "string",
-			},
-			"align": map[string]interface{}{
-				"type": "string",
-			},
+func generateTableColumnSchema() *FieldSchema {
+	return &FieldSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"key":        &FieldSchema{Type: "string"},
+			"title":      &FieldSchema{Type: "string"},
+			"type":       &FieldSchema{Type: "string"},
+			"sortable":   &FieldSchema{Type: "boolean"},
+			"filterable": &FieldSchema{Type: "boolean"},
+			"width":      &FieldSchema{Type: "string"},
+			"align":      &FieldSchema{Type: "string"},
+			"format":     &FieldSchema{Type: "object"},
 		},
 	}
 }
@@ -352,6 +506,7 @@ func generateTableUIOptions(config *types.TableConfig) map[string]interface{} {
 		"filterable":  config.Filterable,
 		"selectable":  config.Selectable,
 		"editable":    config.Editable,
+		"rowKey":      config.RowKey,
 		"columns":     config.Columns,
 	}
 
@@ -375,6 +530,9 @@ func convertOptionsToEnumOptions(options []types.SelectOption) []map[string]inte
 			"value": option.Value,
 			"label": option.Label,
 		}
+		if option.Disabled {
+			enumOptions[i]["disabled"] = true
+		}
 	}
 	return enumOptions
 }
@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// benchmarkForm форма из 61 поля всех основных типов с вариантами, правилами и таблицей
+func benchmarkForm() *types.Form {
+	form := &types.Form{Name: "bench", Title: "Анкета", Description: "Форма для замера генерации схемы"}
+
+	options := []types.SelectOption{
+		{Value: "a", Label: "Первый"},
+		{Value: "b", Label: "Второй"},
+		{Value: "c", Label: "Третий", Disabled: true},
+	}
+	kinds := []types.FieldType{
+		types.FieldTypeText, types.FieldTypeEmail, types.FieldTypeNumber, types.FieldTypeTextarea,
+		types.FieldTypeSelect, types.FieldTypeRadio, types.FieldTypeCheckbox, types.FieldTypeDate,
+		types.FieldTypeTime, types.FieldTypeDateTime, types.FieldTypeFile, types.FieldTypePassword,
+	}
+	for i := 0; i < 60; i++ {
+		field := types.Field{
+			Name:        fmt.Sprintf("field%d", i),
+			Type:        kinds[i%len(kinds)],
+			Label:       fmt.Sprintf("Поле %d", i),
+			Description: "Подсказка к полю",
+			Required:    i%3 == 0,
+		}
+		switch field.Type {
+		case types.FieldTypeSelect, types.FieldTypeRadio:
+			field.Options = options
+		case types.FieldTypeNumber:
+			field.Validation = []types.ValidationRule{
+				{Type: "min", Value: 0, Message: "Не меньше 0"},
+				{Type: "max", Value: 100, Message: "Не больше 100"},
+			}
+		case types.FieldTypeText:
+			field.Validation = []types.ValidationRule{
+				{Type: "minLength", Value: 2, Message: "Не короче 2 символов"},
+				{Type: "maxLength", Value: 64, Message: "Не длиннее 64 символов"},
+				{Type: "pattern", Value: "^[a-z]+$", Message: "Только латиница"},
+			}
+		}
+		form.Fields = append(form.Fields, field)
+	}
+
+	form.Fields = append(form.Fields, types.Field{
+		Name:  "items",
+		Type:  types.FieldTypeTable,
+		Label: "Позиции",
+		TableConfig: &types.TableConfig{
+			Columns: []types.TableColumn{
+				{Key: "id", Title: "ID", Type: types.FieldTypeText},
+				{Key: "qty", Title: "Количество", Type: types.FieldTypeNumber},
+				{Key: "kind", Title: "Вид", Type: types.FieldTypeSelect, Options: options},
+			},
+			Pagination: true,
+			PageSize:   20,
+		},
+	})
+	return form
+}
+
+// BenchmarkGenerateSchema замеряет генерацию JSON Schema формы вместе с кодированием ответа
+func BenchmarkGenerateSchema(b *testing.B) {
+	form := benchmarkForm()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		schema, err := GenerateJSONSchema(form)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := json.Marshal(schema); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Символы маски ввода
+const (
+	maskDigit    = '9' // цифра
+	maskLetter   = 'a' // буква (латиница или кириллица)
+	maskAlnum    = '*' // цифра или буква
+	maskEscape   = '\\'
+	letterClass  = `[A-Za-zА-Яа-яЁё]`
+	alnumClass   = `[0-9A-Za-zА-Яа-яЁё]`
+	digitPattern = `[0-9]`
+)
+
+// MaskPattern преобразует маску ввода в регулярное выражение для серверной проверки.
+// В маске 9 - цифра, a - буква, * - цифра или буква, \ экранирует следующий символ,
+// остальные символы должны совпадать буквально. Например, "+7 (999) 999-99-99"
+func MaskPattern(mask string) (string, error) {
+	if mask == "" {
+		return "", fmt.Errorf("пустая маска")
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+
+	escaped := false
+	for _, r := range mask {
+		if escaped {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+			continue
+		}
+
+		switch r {
+		case maskEscape:
+			escaped = true
+		case maskDigit:
+			b.WriteString(digitPattern)
+		case maskLetter:
+			b.WriteString(letterClass)
+		case maskAlnum:
+			b.WriteString(alnumClass)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	if escaped {
+		return "", fmt.Errorf("маска %q заканчивается символом экранирования", mask)
+	}
+
+	b.WriteString("$")
+	return b.String(), nil
+}
@@ -0,0 +1,185 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// ValidateForm проверяет корректность определения формы
+func ValidateForm(form *types.Form) error {
+	if form == nil {
+		return fmt.Errorf("форма не задана")
+	}
+
+	if form.Name == "" {
+		return fmt.Errorf("у формы не задано имя")
+	}
+
+	names := make(map[string]bool, len(form.Fields))
+	for _, field := range form.Fields {
+		if field.Name == "" {
+			return fmt.Errorf("форма %s: у поля не задано имя", form.Name)
+		}
+
+		if names[field.Name] {
+			return fmt.Errorf("форма %s: поле %s объявлено повторно", form.Name, field.Name)
+		}
+		names[field.Name] = true
+
+		if err := validateFieldDefinition(&field); err != nil {
+			return fmt.Errorf("форма %s, поле %s: %w", form.Name, field.Name, err)
+		}
+	}
+
+	if err := form.SLO.Validate(); err != nil {
+		return fmt.Errorf("форма %s: %w", form.Name, err)
+	}
+
+	if form.Actions != nil {
+		actions := make(map[string]bool, len(form.Actions.Custom))
+		for _, action := range form.Actions.Custom {
+			if action.Name == "" {
+				return fmt.Errorf("форма %s: у действия не задано имя", form.Name)
+			}
+			if actions[action.Name] {
+				return fmt.Errorf("форма %s: действие %s объявлено повторно", form.Name, action.Name)
+			}
+			actions[action.Name] = true
+		}
+
+		if duplicate := form.Actions.Duplicate; duplicate != nil {
+			if actions[types.ActionDuplicate] {
+				return fmt.Errorf("форма %s: действие %s совпадает со встроенным копированием", form.Name, types.ActionDuplicate)
+			}
+			for _, name := range duplicate.Exclude {
+				if duplicate.Form == "" && !names[name] {
+					return fmt.Errorf("форма %s: копирование исключает неизвестное поле %s", form.Name, name)
+				}
+			}
+		}
+	}
+
+	if err := validateShortcuts(form); err != nil {
+		return fmt.Errorf("форма %s: %w", form.Name, err)
+	}
+
+	for _, name := range form.Prefill {
+		if !names[name] {
+			return fmt.Errorf("форма %s: предзаполнение ссылается на неизвестное поле %s", form.Name, name)
+		}
+	}
+
+	for _, group := range form.Groups {
+		for _, name := range group.Fields {
+			if !names[name] {
+				return fmt.Errorf("форма %s: группа %s ссылается на неизвестное поле %s", form.Name, group.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateFieldDefinition проверяет корректность определения поля
+func validateFieldDefinition(field *types.Field) error {
+	switch field.Type {
+	case types.FieldTypeSelect, types.FieldTypeRadio:
+		if len(field.Options) == 0 && field.Dictionary == nil {
+			return fmt.Errorf("не заданы опции выбора")
+		}
+
+	case types.FieldTypeTable:
+		if field.TableConfig == nil || len(field.TableConfig.Columns) == 0 {
+			return fmt.Errorf("у таблицы не заданы колонки")
+		}
+	}
+
+	if field.Mask != "" {
+		if _, err := MaskPattern(field.Mask); err != nil {
+			return err
+		}
+	}
+
+	if field.Link != nil && field.Link.Form == "" {
+		return fmt.Errorf("у ссылки не задана форма назначения")
+	}
+	if field.TableConfig != nil {
+		if err := validateRowKey(field.TableConfig); err != nil {
+			return err
+		}
+		for _, column := range field.TableConfig.Columns {
+			if column.Link != nil && column.Link.Form == "" {
+				return fmt.Errorf("колонка %s: у ссылки не задана форма назначения", column.Key)
+			}
+			if err := validateColumnFormat(field.TableConfig, &column); err != nil {
+				return fmt.Errorf("колонка %s: %w", column.Key, err)
+			}
+			if err := validateRules(column.Validation); err != nil {
+				return fmt.Errorf("колонка %s: %w", column.Key, err)
+			}
+		}
+	}
+
+	if err := ValidateAutocomplete(field.Autocomplete); err != nil {
+		return err
+	}
+
+	if field.Accessibility != nil && field.Accessibility.TabIndex < -1 {
+		return fmt.Errorf("tabIndex должен быть не меньше -1")
+	}
+
+	return validateRules(field.Validation)
+}
+
+// validateRules проверяет уровни правил и регулярные выражения паттернов
+func validateRules(rules []types.ValidationRule) error {
+	for _, rule := range rules {
+		switch rule.Level {
+		case "", types.ValidationLevelError, types.ValidationLevelWarning:
+		default:
+			return fmt.Errorf("неизвестный уровень правила %s: %s", rule.Type, rule.Level)
+		}
+
+		if rule.Type != "pattern" {
+			continue
+		}
+
+		pattern, ok := rule.Value.(string)
+		if !ok {
+			return fmt.Errorf("паттерн должен быть строкой")
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("некорректное регулярное выражение: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateShortcuts проверяет сочетания клавиш формы: корректность, уникальность и действия
+func validateShortcuts(form *types.Form) error {
+	if len(form.Shortcuts) == 0 {
+		return nil
+	}
+
+	actions := form.ShortcutActions()
+	keys := make(map[string]bool, len(form.Shortcuts))
+	for _, shortcut := range form.Shortcuts {
+		normalized, err := types.NormalizeShortcut(shortcut.Keys)
+		if err != nil {
+			return err
+		}
+		if keys[normalized] {
+			return fmt.Errorf("сочетание %s назначено повторно", normalized)
+		}
+		keys[normalized] = true
+
+		if !actions[shortcut.Action] {
+			return fmt.Errorf("сочетание %s вызывает неизвестное действие %s", normalized, shortcut.Action)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package formist
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/koteyye/go-formist/seed"
+	"github.com/koteyye/go-formist/types"
+)
+
+// WithSeed заполняет окружение начальными данными из файлов YAML или JSON при запуске
+// (хук OnStartup). Файлы читаются сразу: некорректный файл вызывает панику.
+// Каждый файл применяется один раз на версию, см. пакет seed
+func (a *Admin) WithSeed(paths ...string) *Admin {
+	files := make([]*seed.File, 0, len(paths))
+	for _, path := range paths {
+		file, err := seed.Load(path)
+		if err != nil {
+			panic(fmt.Sprintf("formist: %v", err))
+		}
+		files = append(files, file)
+	}
+
+	return a.OnStartup(func(ctx context.Context) error {
+		for _, file := range files {
+			if _, err := a.Seed(ctx, file); err != nil {
+				return fmt.Errorf("начальные данные %s: %w", file.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Seed применяет файл начальных данных: настройки и каталоги сохраняются в storage,
+// демонстрационные отправки проходят валидацию и OnPost форм
+func (a *Admin) Seed(ctx context.Context, file *seed.File) (*seed.Result, error) {
+	return seed.Apply(ctx, seed.Target{
+		Storage: a.storage,
+		Submit: func(ctx context.Context, form string, data map[string]interface{}) error {
+			_, err := a.router.SubmitForm(ctx, form, data)
+			return err
+		},
+	}, file)
+}
+
+// Catalog возвращает варианты выбора каталога, сохраненного начальными данными
+func (a *Admin) Catalog(ctx context.Context, name string) ([]types.SelectOption, error) {
+	return seed.LoadCatalog(ctx, a.storage, name)
+}
@@ -0,0 +1,264 @@
+// Package seed заполняет новое окружение начальными данными из файла YAML или JSON:
+// настройками, каталогами вариантов выбора и демонстрационными отправками форм.
+// Примененная версия файла сохраняется в storage, поэтому повторный запуск ничего не меняет,
+// а при увеличении версии применяются только записи, добавленные в новых версиях
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/types"
+)
+
+const (
+	// DefaultName имя файла начальных данных по умолчанию
+	DefaultName = "default"
+
+	// CatalogPrefix префикс ключей настроек, в которых хранятся каталоги вариантов выбора
+	CatalogPrefix = "catalog:"
+
+	// statePrefix префикс ключей настроек с примененными версиями
+	statePrefix = "seed:"
+)
+
+// ErrNoSettings возвращается, если storage не поддерживает настройки
+var ErrNoSettings = errors.New("начальные данные требуют storage с поддержкой настроек")
+
+// Setting начальное значение настройки storage
+type Setting struct {
+	Key   string      `yaml:"key" json:"key"`
+	Value interface{} `yaml:"value" json:"value"`
+	Since int         `yaml:"since,omitempty" json:"since,omitempty"` // версия, в которой запись добавлена, по умолчанию 1
+}
+
+// Catalog каталог вариантов выбора
+type Catalog struct {
+	Name    string               `yaml:"name" json:"name"`
+	Options []types.SelectOption `yaml:"options" json:"options"`
+	Since   int                  `yaml:"since,omitempty" json:"since,omitempty"`
+}
+
+// Submission демонстрационная отправка формы. Проходит валидацию и OnPost формы
+type Submission struct {
+	Form  string                 `yaml:"form" json:"form"`
+	Data  map[string]interface{} `yaml:"data" json:"data"`
+	Since int                    `yaml:"since,omitempty" json:"since,omitempty"`
+}
+
+// File файл начальных данных
+type File struct {
+	Name        string       `yaml:"name,omitempty" json:"name,omitempty"`
+	Version     int          `yaml:"version" json:"version"`
+	Settings    []Setting    `yaml:"settings,omitempty" json:"settings,omitempty"`
+	Catalogs    []Catalog    `yaml:"catalogs,omitempty" json:"catalogs,omitempty"`
+	Submissions []Submission `yaml:"submissions,omitempty" json:"submissions,omitempty"`
+}
+
+// Load читает файл начальных данных. JSON читается как YAML
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Parse разбирает и проверяет начальные данные в формате YAML или JSON
+func Parse(data []byte) (*File, error) {
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Name == "" {
+		file.Name = DefaultName
+	}
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Validate проверяет версии и обязательные поля записей
+func (f *File) Validate() error {
+	if f.Version <= 0 {
+		return fmt.Errorf("начальные данные %s: версия должна быть положительной", f.Name)
+	}
+
+	check := func(kind, name string, since int) error {
+		if name == "" {
+			return fmt.Errorf("начальные данные %s: %s без имени", f.Name, kind)
+		}
+		if since < 0 || since > f.Version {
+			return fmt.Errorf("начальные данные %s: %s %s: версия %d вне диапазона 1..%d", f.Name, kind, name, since, f.Version)
+		}
+		return nil
+	}
+	for _, setting := range f.Settings {
+		if err := check("настройка", setting.Key, setting.Since); err != nil {
+			return err
+		}
+	}
+	for _, catalog := range f.Catalogs {
+		if err := check("каталог", catalog.Name, catalog.Since); err != nil {
+			return err
+		}
+	}
+	for _, submission := range f.Submissions {
+		if err := check("отправка", submission.Form, submission.Since); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Target куда записываются начальные данные
+type Target struct {
+	Storage storage.Storage
+	Submit  func(ctx context.Context, form string, data map[string]interface{}) error
+}
+
+// Result итог применения файла
+type Result struct {
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Previous    int    `json:"previous"` // версия, примененная ранее
+	Applied     bool   `json:"applied"`  // false - версия уже применена или данные заполняет другой экземпляр
+	Settings    int    `json:"settings"`
+	Catalogs    int    `json:"catalogs"`
+	Submissions int    `json:"submissions"`
+}
+
+// state примененная версия файла
+type state struct {
+	Version   int       `json:"version"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// Apply применяет записи файла, добавленные после сохраненной версии, и сохраняет новую версию.
+// Если storage поддерживает блокировки, данные заполняет один экземпляр
+func Apply(ctx context.Context, target Target, file *File) (*Result, error) {
+	settings, ok := target.Storage.(storage.SettingsStorage)
+	if !ok {
+		return nil, ErrNoSettings
+	}
+	result := &Result{Name: file.Name, Version: file.Version}
+
+	if locker, ok := storage.LockerOf(target.Storage); ok {
+		lock, acquired, err := locker.TryLock(ctx, statePrefix+file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			return result, nil
+		}
+		defer lock.Release(context.WithoutCancel(ctx))
+	}
+
+	previous, err := loadState(ctx, settings, file.Name)
+	if err != nil {
+		return nil, err
+	}
+	result.Previous = previous.Version
+	if previous.Version >= file.Version {
+		return result, nil
+	}
+
+	pending := func(since int) bool {
+		return max(since, 1) > previous.Version
+	}
+
+	for _, setting := range file.Settings {
+		if !pending(setting.Since) {
+			continue
+		}
+		if err := saveJSON(ctx, settings, setting.Key, setting.Value); err != nil {
+			return nil, fmt.Errorf("настройка %s: %w", setting.Key, err)
+		}
+		result.Settings++
+	}
+
+	for _, catalog := range file.Catalogs {
+		if !pending(catalog.Since) {
+			continue
+		}
+		if err := saveJSON(ctx, settings, CatalogPrefix+catalog.Name, catalog.Options); err != nil {
+			return nil, fmt.Errorf("каталог %s: %w", catalog.Name, err)
+		}
+		result.Catalogs++
+	}
+
+	for i, submission := range file.Submissions {
+		if !pending(submission.Since) {
+			continue
+		}
+		if target.Submit == nil {
+			return nil, errors.New("отправка форм не подключена")
+		}
+		if err := target.Submit(ctx, submission.Form, submission.Data); err != nil {
+			return nil, fmt.Errorf("отправка %d формы %s: %w", i+1, submission.Form, err)
+		}
+		result.Submissions++
+	}
+
+	if err := saveJSON(ctx, settings, statePrefix+file.Name, state{Version: file.Version, AppliedAt: time.Now().UTC()}); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// LoadCatalog возвращает варианты выбора каталога из storage
+func LoadCatalog(ctx context.Context, s storage.Storage, name string) ([]types.SelectOption, error) {
+	settings, ok := s.(storage.SettingsStorage)
+	if !ok {
+		return nil, ErrNoSettings
+	}
+
+	value, err := settings.GetSetting(ctx, CatalogPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []types.SelectOption
+	if err := json.Unmarshal(value, &options); err != nil {
+		return nil, fmt.Errorf("каталог %s: %w", name, err)
+	}
+	return options, nil
+}
+
+// loadState читает примененную версию файла
+func loadState(ctx context.Context, settings storage.SettingsStorage, name string) (state, error) {
+	var current state
+	value, err := settings.GetSetting(ctx, statePrefix+name)
+	if errors.Is(err, storage.ErrNotFound) {
+		return current, nil
+	}
+	if err != nil {
+		return current, err
+	}
+	if err := json.Unmarshal(value, &current); err != nil {
+		return current, fmt.Errorf("версия начальных данных %s: %w", name, err)
+	}
+	return current, nil
+}
+
+// saveJSON сохраняет значение настройки в формате JSON
+func saveJSON(ctx context.Context, settings storage.SettingsStorage, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return settings.SaveSetting(ctx, key, data)
+}
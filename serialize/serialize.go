@@ -0,0 +1,162 @@
+// Package serialize задает представление в JSON значений, которые возвращают обработчики форм
+// и таблиц: время (RFC 3339 или Unix), десятичные числа, собственные типы предметной области.
+// Представление настраивается один раз для админки, а не в каждом обработчике
+package serialize
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Func возвращает представление значения для JSON ответа
+type Func func(value interface{}) (interface{}, error)
+
+// Registry функции представления по типам значений
+type Registry struct {
+	mu    sync.RWMutex
+	funcs map[reflect.Type]Func
+}
+
+// New создает пустой реестр: значения кодируются стандартным encoding/json
+func New() *Registry {
+	return &Registry{
+		funcs: make(map[reflect.Type]Func),
+	}
+}
+
+// Register задает представление значений типа sample (например, time.Time{}).
+// fn == nil возвращает стандартное кодирование
+func (r *Registry) Register(sample interface{}, fn Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(sample)
+	if fn == nil {
+		delete(r.funcs, t)
+		return
+	}
+	r.funcs[t] = fn
+}
+
+// RegisterType задает представление значений типа T типизированной функцией
+func RegisterType[T any](r *Registry, fn func(value T) (interface{}, error)) {
+	var sample T
+	r.Register(sample, func(value interface{}) (interface{}, error) {
+		return fn(value.(T))
+	})
+}
+
+// Empty сообщает, что представления не заданы
+func (r *Registry) Empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.funcs) == 0
+}
+
+// lookup возвращает функцию представления типа
+func (r *Registry) lookup(t reflect.Type) (Func, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fn, ok := r.funcs[t]
+	return fn, ok
+}
+
+// Apply возвращает data, в котором значения зарегистрированных типов заменены их представлением.
+// Обходятся map со строковыми ключами, срезы и массивы; контейнеры копируются, исходные данные
+// не изменяются. Поля структур не обходятся: структура кодируется своими json тегами
+// или MarshalJSON, если ее тип не зарегистрирован
+func (r *Registry) Apply(data interface{}) (interface{}, error) {
+	if data == nil || r.Empty() {
+		return data, nil
+	}
+	return r.apply(reflect.ValueOf(data))
+}
+
+// apply заменяет значение и обходит вложенные контейнеры
+func (r *Registry) apply(v reflect.Value) (interface{}, error) {
+	if fn, ok := r.lookup(v.Type()); ok {
+		return fn(v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return v.Interface(), nil
+		}
+		if fn, ok := r.lookup(v.Elem().Type()); ok {
+			return fn(v.Elem().Interface())
+		}
+		if v.Kind() == reflect.Interface {
+			return r.apply(v.Elem())
+		}
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.IsNil() {
+			break
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := r.apply(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", iter.Key().String(), err)
+			}
+			out[iter.Key().String()] = value
+		}
+		return out, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			break
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			value, err := r.apply(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = value
+		}
+		return out, nil
+	}
+
+	return v.Interface(), nil
+}
+
+// TimeUnix представляет time.Time секундами Unix
+func TimeUnix(value interface{}) (interface{}, error) {
+	return value.(time.Time).Unix(), nil
+}
+
+// TimeUnixMilli представляет time.Time миллисекундами Unix
+func TimeUnixMilli(value interface{}) (interface{}, error) {
+	return value.(time.Time).UnixMilli(), nil
+}
+
+// TimeLayout представляет time.Time строкой в формате layout (например, time.DateOnly)
+func TimeLayout(layout string) Func {
+	return func(value interface{}) (interface{}, error) {
+		return value.(time.Time).Format(layout), nil
+	}
+}
+
+// Number представляет значение JSON числом по его строковой записи (fmt.Stringer),
+// например десятичные типы, которые по умолчанию кодируются строкой
+func Number(value interface{}) (interface{}, error) {
+	number := json.Number(fmt.Sprint(value))
+	if _, err := json.Marshal(number); err != nil {
+		return nil, fmt.Errorf("значение %s не является числом", number)
+	}
+	return number, nil
+}
+
+// String представляет значение строкой fmt.Sprint, например для сохранения точности
+// десятичных чисел или идентификаторов больше 2^53
+func String(value interface{}) (interface{}, error) {
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,286 @@
+// Package snapshot хранит обезличенные снимки неудачных отправок форм (статус >= 400)
+// по ID запроса, чтобы сопровождающие могли воспроизвести ошибку из обращения пользователя.
+// Снимки хранятся в памяти ограниченное время и доступны только с разрешением snapshots:read
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/koteyye/go-formist/accesslog"
+	"github.com/koteyye/go-formist/types"
+)
+
+// Значения Options по умолчанию
+const (
+	DefaultTTL      = 24 * time.Hour
+	DefaultCapacity = 500
+)
+
+// MaxPayload максимальный размер сохраняемого тела запроса или ответа
+const MaxPayload = accesslog.MaxDebugPayload
+
+// keepParams параметры запроса, значения которых нужны для воспроизведения и не маскируются
+var keepParams = map[string]bool{
+	types.LinkParamDefault: true,
+	"dry_run":              true,
+	"locale":               true,
+	"labels":               true,
+	"fields":               true,
+	"include":              true,
+	"page":                 true,
+	"limit":                true,
+}
+
+// keepKeys ключи ответа, значения которых описывают ошибку и не маскируются
+var keepKeys = map[string]bool{
+	"error":   true,
+	"message": true,
+	"field":   true,
+	"rule":    true,
+	"type":    true,
+	"code":    true,
+	"path":    true,
+	"op":      true,
+}
+
+// Options настройки хранения снимков
+type Options struct {
+	TTL      time.Duration // время хранения снимка, по умолчанию DefaultTTL
+	Capacity int           // максимальное число снимков, при переполнении вытесняются самые старые
+
+	// SensitiveNames части имен полей, значения которых заменяются на accesslog.Redacted.
+	// Дополняют accesslog.DefaultSensitiveNames, поля типа password и поля с Sensitive
+	SensitiveNames []string
+
+	// KeepValues сохраняет значения полей как есть, скрывая только чувствительные.
+	// По умолчанию строки маскируются с сохранением длины и формата: буквы - x, цифры - 0
+	KeepValues bool
+}
+
+// Snapshot снимок неудачного запроса формы
+type Snapshot struct {
+	ID          string      `json:"id"` // ID запроса (X-Request-Id)
+	Time        time.Time   `json:"time"`
+	ExpiresAt   time.Time   `json:"expiresAt"`
+	Form        string      `json:"form"`
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Query       string      `json:"query,omitempty"`
+	ContentType string      `json:"contentType,omitempty"`
+	Status      int         `json:"status"`
+	User        string      `json:"user,omitempty"` // псевдоним пользователя, см. Pseudonym
+	Request     interface{} `json:"request,omitempty"`
+	Response    interface{} `json:"response,omitempty"`
+	Truncated   bool        `json:"truncated,omitempty"` // тело запроса или ответа обрезано до MaxPayload
+	Replayable  bool        `json:"replayable"`          // запрос можно повторить в пробном режиме
+}
+
+// Brief возвращает снимок без тел запроса и ответа для списка
+func (s Snapshot) Brief() Snapshot {
+	s.Request, s.Response = nil, nil
+	return s
+}
+
+// Store хранит снимки в памяти
+type Store struct {
+	mu       sync.Mutex
+	items    map[string]*Snapshot
+	order    []string // ID в порядке сохранения
+	options  Options
+	redactor *accesslog.DebugLogger
+}
+
+// New создает хранилище снимков
+func New(options Options) *Store {
+	if options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+	if options.Capacity <= 0 {
+		options.Capacity = DefaultCapacity
+	}
+	return &Store{
+		items:    make(map[string]*Snapshot),
+		options:  options,
+		redactor: accesslog.NewDebug(io.Discard, accesslog.Options{SensitiveNames: options.SensitiveNames}),
+	}
+}
+
+// TTL возвращает время хранения снимков
+func (s *Store) TTL() time.Duration {
+	return s.options.TTL
+}
+
+// Capture обезличивает тела запроса и ответа и сохраняет снимок.
+// Снимок с тем же ID заменяет предыдущий
+func (s *Store) Capture(form *types.Form, snapshot Snapshot, request, response []byte) {
+	if len(request) > MaxPayload || len(response) > MaxPayload {
+		snapshot.Truncated = true
+		request = request[:min(len(request), MaxPayload)]
+		response = response[:min(len(response), MaxPayload)]
+	}
+	snapshot.Query = s.query(snapshot.Query)
+	snapshot.Request = s.anonymize(form, request, nil)
+	snapshot.Response = s.anonymize(form, response, keepKeys)
+	if snapshot.User != "" {
+		snapshot.User = Pseudonym(snapshot.User)
+	}
+	if snapshot.Time.IsZero() {
+		snapshot.Time = time.Now().UTC()
+	}
+	snapshot.ExpiresAt = snapshot.Time.Add(s.options.TTL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	if _, exists := s.items[snapshot.ID]; exists {
+		s.remove(snapshot.ID)
+	}
+	for len(s.order) >= s.options.Capacity {
+		s.remove(s.order[0])
+	}
+	s.items[snapshot.ID] = &snapshot
+	s.order = append(s.order, snapshot.ID)
+}
+
+// Get возвращает действующий снимок по ID запроса
+func (s *Store) Get(id string) (Snapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	snapshot, ok := s.items[id]
+	if !ok {
+		return Snapshot{}, false
+	}
+	return *snapshot, true
+}
+
+// List возвращает действующие снимки формы (пустая строка - всех форм) без тел, новые первыми
+func (s *Store) List(form string) []Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	items := make([]Snapshot, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		snapshot := s.items[s.order[i]]
+		if form == "" || snapshot.Form == form {
+			items = append(items, snapshot.Brief())
+		}
+	}
+	return items
+}
+
+// Delete удаляет снимок
+func (s *Store) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[id]; !ok {
+		return false
+	}
+	s.remove(id)
+	return true
+}
+
+// prune удаляет истекшие снимки. Снимки упорядочены по времени сохранения
+func (s *Store) prune(now time.Time) {
+	for len(s.order) > 0 && !now.Before(s.items[s.order[0]].ExpiresAt) {
+		s.remove(s.order[0])
+	}
+}
+
+// remove удаляет снимок из индекса и очереди
+func (s *Store) remove(id string) {
+	delete(s.items, id)
+	for i, item := range s.order {
+		if item == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// anonymize разбирает тело и скрывает персональные данные: чувствительные поля
+// заменяются на accesslog.Redacted, остальные строки маскируются (кроме ключей keep)
+func (s *Store) anonymize(form *types.Form, body []byte, keep map[string]bool) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	value := s.redactor.Payload(form, body)
+	if s.options.KeepValues {
+		return value
+	}
+	return maskValue(value, "", keep)
+}
+
+// query маскирует значения параметров запроса, кроме нужных для воспроизведения
+func (s *Store) query(raw string) string {
+	if raw == "" || s.options.KeepValues {
+		return raw
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return ""
+	}
+	for name, items := range values {
+		if keepParams[name] {
+			continue
+		}
+		for i, item := range items {
+			items[i] = Mask(item)
+		}
+	}
+	return values.Encode()
+}
+
+// maskValue рекурсивно маскирует строки значения
+func maskValue(value interface{}, key string, keep map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, item := range v {
+			v[name] = maskValue(item, name, keep)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskValue(item, key, keep)
+		}
+	case string:
+		if v != accesslog.Redacted && !keep[key] {
+			return Mask(v)
+		}
+	}
+	return value
+}
+
+// Mask заменяет буквы на x (X для заглавных) и цифры на 0, сохраняя длину,
+// пробелы и знаки: "Иван 8-900-12" - "Xxxx 0-000-00", "a.b@mail.ru" - "x.x@xxxx.xx"
+func Mask(value string) string {
+	masked := []rune(value)
+	for i, r := range masked {
+		switch {
+		case unicode.IsUpper(r):
+			masked[i] = 'X'
+		case unicode.IsLetter(r):
+			masked[i] = 'x'
+		case unicode.IsDigit(r):
+			masked[i] = '0'
+		}
+	}
+	return string(masked)
+}
+
+// Pseudonym возвращает стабильный псевдоним пользователя: по обращению с известным ID
+// можно найти снимки пользователя, не храня сам ID
+func Pseudonym(user string) string {
+	sum := sha256.Sum256([]byte(user))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,117 @@
+package formist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// StartupTimeout ограничение времени каждого хука запуска
+const StartupTimeout = 30 * time.Second
+
+// startupHook хук запуска и его порядковый номер для отчета
+type startupHook struct {
+	target string
+	run    func(ctx context.Context) error
+}
+
+// startupState хуки запуска и результат их выполнения
+type startupState struct {
+	mu    sync.Mutex
+	hooks []startupHook
+	done  bool
+	check types.DiagnosticCheck
+}
+
+// OnStartup добавляет хук, выполняемый до начала обслуживания запросов: загрузка
+// вариантов выбора, прогрев кешей, проверка внешних зависимостей. Хуки выполняются
+// параллельно при первом вызове Handler (или явно через Startup); ошибка хука не
+// останавливает запуск, а попадает в отчет самодиагностики (проверка startup)
+func (a *Admin) OnStartup(hook func(ctx context.Context) error) *Admin {
+	a.startup.mu.Lock()
+	defer a.startup.mu.Unlock()
+
+	if a.startup.done {
+		panic("formist: OnStartup вызван после запуска")
+	}
+	a.startup.hooks = append(a.startup.hooks, startupHook{
+		target: fmt.Sprintf("hook %d", len(a.startup.hooks)+1),
+		run:    hook,
+	})
+	return a
+}
+
+// Startup выполняет хуки запуска один раз и возвращает их объединенную ошибку.
+// Каждый хук ограничен StartupTimeout; повторные вызовы возвращают сохраненный результат
+func (a *Admin) Startup(ctx context.Context) error {
+	a.startup.mu.Lock()
+	defer a.startup.mu.Unlock()
+
+	if !a.startup.done {
+		a.startup.check = runStartupHooks(ctx, a.startup.hooks)
+		a.startup.done = true
+	}
+
+	var errs []error
+	for _, issue := range a.startup.check.Issues {
+		errs = append(errs, fmt.Errorf("%s: %s", issue.Target, issue.Message))
+	}
+	return errors.Join(errs...)
+}
+
+// runStartupHooks выполняет хуки параллельно и собирает их ошибки в проверку
+func runStartupHooks(ctx context.Context, hooks []startupHook) types.DiagnosticCheck {
+	if len(hooks) == 0 {
+		return types.DiagnosticCheck{Name: types.CheckStartup, Status: types.DiagnosticSkipped}
+	}
+
+	start := time.Now()
+	errs := make([]error, len(hooks))
+	var wg sync.WaitGroup
+	for i, hook := range hooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					errs[i] = fmt.Errorf("паника: %v", recovered)
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(ctx, StartupTimeout)
+			defer cancel()
+			errs[i] = hook.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	var issues []types.DiagnosticIssue
+	for i, err := range errs {
+		if err != nil {
+			issues = append(issues, types.DiagnosticIssue{
+				Level:   types.DiagnosticError,
+				Target:  hooks[i].target,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	check := types.NewDiagnosticCheck(types.CheckStartup, issues)
+	check.Duration = time.Since(start)
+	return check
+}
+
+// diagnoseStartup возвращает результат хуков запуска
+func (a *Admin) diagnoseStartup() types.DiagnosticCheck {
+	a.startup.mu.Lock()
+	defer a.startup.mu.Unlock()
+
+	if !a.startup.done {
+		return types.DiagnosticCheck{Name: types.CheckStartup, Status: types.DiagnosticSkipped}
+	}
+	return a.startup.check
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrChangesNotSupported возвращается, если storage не поддерживает уведомления об изменениях
+var ErrChangesNotSupported = errors.New("storage не поддерживает уведомления об изменениях")
+
+// Виды изменяемых сущностей
+const (
+	ChangeKindRoute   = "route"
+	ChangeKindSetting = "setting"
+	ChangeKindForms   = "forms" // определения форм (например, после синхронизации с реестром)
+)
+
+// Операции над сущностями
+const (
+	ChangeOpSave   = "save"
+	ChangeOpDelete = "delete"
+)
+
+// ChangeEvent уведомление об изменении данных, разделяемых несколькими экземплярами приложения
+type ChangeEvent struct {
+	Kind   string `json:"kind"`
+	Op     string `json:"op"`
+	Key    string `json:"key,omitempty"`    // ID роута, ключ настройки и т.п.
+	Origin string `json:"origin,omitempty"` // экземпляр-источник изменения
+}
+
+// ChangeNotifier необязательное расширение Storage для рассылки изменений между экземплярами
+// (например, LISTEN/NOTIFY в PostgreSQL). Собственные изменения экземпляру не доставляются
+type ChangeNotifier interface {
+	// PublishChange рассылает уведомление остальным экземплярам
+	PublishChange(ctx context.Context, event ChangeEvent) error
+
+	// ListenChanges передает уведомления в handler до отмены ctx или ошибки соединения
+	ListenChanges(ctx context.Context, handler func(ChangeEvent)) error
+}
+
+// ChangeNotifierOf возвращает ChangeNotifier storage, в том числе обернутого опциями
+func ChangeNotifierOf(s Storage) (ChangeNotifier, bool) {
+	for s != nil {
+		if notifier, ok := s.(ChangeNotifier); ok {
+			return notifier, true
+		}
+
+		wrapper, ok := s.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		s = wrapper.Unwrap()
+	}
+	return nil, false
+}
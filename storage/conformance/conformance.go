@@ -0,0 +1,392 @@
+// Package conformance содержит общий набор тестов для реализаций хранилищ formist.
+// Сторонний backend проверяет семантику (upsert, порядок, ошибки ErrNotFound и ErrConflict)
+// одним вызовом из своего теста:
+//
+//	func TestStorage(t *testing.T) {
+//		conformance.TestStorage(t, func(t *testing.T) storage.Storage {
+//			s := newTestStorage(t)
+//			t.Cleanup(func() { s.Close() })
+//			return s
+//		})
+//	}
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/workflow"
+)
+
+// createdAt фиксированное время создания, сохраняемое с точностью до секунды любым backend
+var createdAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// TestStorage проверяет реализацию storage.Storage. newStorage вызывается для каждого
+// подтеста и должен возвращать пустое хранилище; закрывать его следует через t.Cleanup
+func TestStorage(t *testing.T, newStorage func(t *testing.T) storage.Storage) {
+	t.Run("Empty", func(t *testing.T) {
+		routes, err := newStorage(t).GetRoutes(context.Background())
+		if err != nil {
+			t.Fatalf("GetRoutes: %v", err)
+		}
+		if len(routes) != 0 {
+			t.Fatalf("GetRoutes пустого хранилища вернул %d роутов", len(routes))
+		}
+	})
+
+	t.Run("SaveAssignsIDAndTimestamps", func(t *testing.T) {
+		s := newStorage(t)
+		route := testRoute("", "users", storage.RouteTypeForm, "Пользователи")
+		route.CreatedAt = time.Time{}
+
+		if err := s.SaveRoute(context.Background(), route); err != nil {
+			t.Fatalf("SaveRoute: %v", err)
+		}
+		if route.ID == "" {
+			t.Fatal("SaveRoute не сгенерировал ID")
+		}
+		if route.CreatedAt.IsZero() || route.UpdatedAt.IsZero() {
+			t.Fatal("SaveRoute не заполнил CreatedAt и UpdatedAt")
+		}
+
+		got := findRoute(t, s, route.ID)
+		if got.CreatedAt.IsZero() || got.UpdatedAt.IsZero() {
+			t.Fatal("сохраненный роут без временных меток")
+		}
+	})
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		s := newStorage(t)
+		route := testRoute("route-1", "users", storage.RouteTypeForm, "Пользователи")
+		route.Description = "Управление пользователями"
+		route.Icon = "users"
+
+		if err := s.SaveRoute(context.Background(), route); err != nil {
+			t.Fatalf("SaveRoute: %v", err)
+		}
+
+		got := findRoute(t, s, "route-1")
+		assertRoute(t, got, route)
+		if !got.CreatedAt.Equal(createdAt) {
+			t.Fatalf("CreatedAt = %v, ожидалось %v", got.CreatedAt, createdAt)
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		s := newStorage(t)
+		if err := s.SaveRoute(context.Background(), testRoute("route-1", "users", storage.RouteTypeForm, "Пользователи")); err != nil {
+			t.Fatalf("SaveRoute: %v", err)
+		}
+
+		updated := testRoute("route-1", "users", storage.RouteTypeForm, "Клиенты")
+		updated.Path = "/clients"
+		updated.CreatedAt = time.Time{}
+		if err := s.SaveRoute(context.Background(), updated); err != nil {
+			t.Fatalf("повторный SaveRoute: %v", err)
+		}
+
+		routes := getRoutes(t, s)
+		if len(routes) != 1 {
+			t.Fatalf("после обновления %d роутов, ожидался 1", len(routes))
+		}
+		assertRoute(t, routes[0], updated)
+		if !routes[0].CreatedAt.Equal(createdAt) {
+			t.Fatalf("обновление изменило CreatedAt: %v, ожидалось %v", routes[0].CreatedAt, createdAt)
+		}
+		if routes[0].UpdatedAt.Before(routes[0].CreatedAt) {
+			t.Fatal("UpdatedAt раньше CreatedAt")
+		}
+	})
+
+	t.Run("NameConflict", func(t *testing.T) {
+		s := newStorage(t)
+		if err := s.SaveRoute(context.Background(), testRoute("r1", "users", storage.RouteTypeForm, "Пользователи")); err != nil {
+			t.Fatalf("SaveRoute: %v", err)
+		}
+
+		err := s.SaveRoute(context.Background(), testRoute("r2", "users", storage.RouteTypeForm, "Клиенты"))
+		if !errors.Is(err, storage.ErrConflict) {
+			t.Fatalf("SaveRoute с занятым именем вернул %v, ожидалась ошибка класса storage.ErrConflict", err)
+		}
+
+		// Повторная регистрация формы после перезапуска сохраняет роут под ID, найденным по имени
+		if err := s.SaveRoute(context.Background(), testRoute("r1", "users", storage.RouteTypeForm, "Клиенты")); err != nil {
+			t.Fatalf("SaveRoute под ID роута с тем же именем: %v", err)
+		}
+		routes := getRoutes(t, s)
+		if len(routes) != 1 || routes[0].ID != "r1" || routes[0].Title != "Клиенты" {
+			t.Fatalf("после повторной регистрации ожидался один обновленный роут r1, получено %+v", routes)
+		}
+	})
+
+	t.Run("Ordering", func(t *testing.T) {
+		s := newStorage(t)
+		for _, route := range []*storage.Route{
+			testRoute("r1", "reports", storage.RouteTypePage, "Отчеты"),
+			testRoute("r2", "users", storage.RouteTypeForm, "Пользователи"),
+			testRoute("r3", "about", storage.RouteTypePage, "Инструкция"),
+			testRoute("r4", "orders", storage.RouteTypeForm, "Заказы"),
+		} {
+			if err := s.SaveRoute(context.Background(), route); err != nil {
+				t.Fatalf("SaveRoute %s: %v", route.ID, err)
+			}
+		}
+
+		var ids []string
+		for _, route := range getRoutes(t, s) {
+			ids = append(ids, route.ID)
+		}
+		if want := []string{"r4", "r2", "r3", "r1"}; !reflect.DeepEqual(ids, want) {
+			t.Fatalf("порядок роутов %v, ожидался %v (по типу, затем по заголовку)", ids, want)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		s := newStorage(t)
+		for _, id := range []string{"r1", "r2"} {
+			if err := s.SaveRoute(context.Background(), testRoute(id, "form-"+id, storage.RouteTypeForm, id)); err != nil {
+				t.Fatalf("SaveRoute %s: %v", id, err)
+			}
+		}
+
+		if err := s.DeleteRoute(context.Background(), "r1"); err != nil {
+			t.Fatalf("DeleteRoute: %v", err)
+		}
+		routes := getRoutes(t, s)
+		if len(routes) != 1 || routes[0].ID != "r2" {
+			t.Fatalf("после удаления r1 остались %v", routeIDs(routes))
+		}
+
+		if err := s.DeleteRoute(context.Background(), "r1"); !errors.Is(err, storage.ErrRouteNotFound) {
+			t.Fatalf("повторный DeleteRoute вернул %v, ожидалась storage.ErrRouteNotFound", err)
+		}
+		if err := s.DeleteRoute(context.Background(), "r1"); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("DeleteRoute вернул %v, ожидалась ошибка класса storage.ErrNotFound", err)
+		}
+	})
+
+	t.Run("DeleteNotFound", func(t *testing.T) {
+		err := newStorage(t).DeleteRoute(context.Background(), "missing")
+		if !errors.Is(err, storage.ErrRouteNotFound) {
+			t.Fatalf("DeleteRoute несуществующего роута вернул %v, ожидалась storage.ErrRouteNotFound", err)
+		}
+	})
+}
+
+// TestSettingsStorage проверяет реализацию storage.SettingsStorage
+func TestSettingsStorage(t *testing.T, newStorage func(t *testing.T) storage.SettingsStorage) {
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := newStorage(t).GetSetting(context.Background(), "missing")
+		if !errors.Is(err, storage.ErrSettingNotFound) {
+			t.Fatalf("GetSetting отсутствующей настройки вернул %v, ожидалась storage.ErrSettingNotFound", err)
+		}
+	})
+
+	t.Run("SaveAndOverwrite", func(t *testing.T) {
+		s := newStorage(t)
+		for _, value := range []string{`{"enabled":true,"message":"Обслуживание"}`, `{"enabled":false}`} {
+			if err := s.SaveSetting(context.Background(), "maintenance", []byte(value)); err != nil {
+				t.Fatalf("SaveSetting: %v", err)
+			}
+
+			got, err := s.GetSetting(context.Background(), "maintenance")
+			if err != nil {
+				t.Fatalf("GetSetting: %v", err)
+			}
+			if string(got) != value {
+				t.Fatalf("GetSetting = %s, ожидалось %s", got, value)
+			}
+		}
+	})
+
+	t.Run("KeysIndependent", func(t *testing.T) {
+		s := newStorage(t)
+		if err := s.SaveSetting(context.Background(), "a", []byte(`1`)); err != nil {
+			t.Fatalf("SaveSetting: %v", err)
+		}
+		if _, err := s.GetSetting(context.Background(), "b"); !errors.Is(err, storage.ErrSettingNotFound) {
+			t.Fatalf("GetSetting другого ключа вернул %v, ожидалась storage.ErrSettingNotFound", err)
+		}
+	})
+}
+
+// TestSubmissionStore проверяет хранилище заявок на согласование workflow.Store
+func TestSubmissionStore(t *testing.T, newStore func(t *testing.T) workflow.Store) {
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := newStore(t).Get(context.Background(), "missing")
+		if !errors.Is(err, workflow.ErrNotFound) {
+			t.Fatalf("Get отсутствующей заявки вернул %v, ожидалась workflow.ErrNotFound", err)
+		}
+	})
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		s := newStore(t)
+		submission := testSubmission("s1", workflow.StatusPending, 0)
+		if err := s.Save(context.Background(), submission); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		got, err := s.Get(context.Background(), "s1")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if got.Form != submission.Form || got.Status != submission.Status || !got.SubmittedAt.Equal(submission.SubmittedAt) {
+			t.Fatalf("Get = %+v, ожидалось %+v", got, submission)
+		}
+		if fmt.Sprint(got.Data) != fmt.Sprint(submission.Data) {
+			t.Fatalf("данные заявки %v, ожидалось %v", got.Data, submission.Data)
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		s := newStore(t)
+		submission := testSubmission("s1", workflow.StatusPending, 0)
+		if err := s.Save(context.Background(), submission); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		submission.Status = workflow.StatusApproved
+		submission.Comment = "ok"
+		if err := s.Save(context.Background(), submission); err != nil {
+			t.Fatalf("повторный Save: %v", err)
+		}
+
+		items, err := s.List(context.Background(), "")
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(items) != 1 || items[0].Status != workflow.StatusApproved || items[0].Comment != "ok" {
+			t.Fatalf("после обновления List вернул %d заявок: %+v", len(items), items)
+		}
+	})
+
+	t.Run("Isolation", func(t *testing.T) {
+		s := newStore(t)
+		submission := testSubmission("s1", workflow.StatusPending, 0)
+		if err := s.Save(context.Background(), submission); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		// Изменение переданной и полученной заявки не должно менять сохраненную
+		submission.Status = workflow.StatusRejected
+		got, err := s.Get(context.Background(), "s1")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		got.Status = workflow.StatusFailed
+
+		again, err := s.Get(context.Background(), "s1")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if again.Status != workflow.StatusPending {
+			t.Fatalf("сохраненная заявка изменилась без Save: %s", again.Status)
+		}
+	})
+
+	t.Run("ListFilterAndOrder", func(t *testing.T) {
+		s := newStore(t)
+		for _, submission := range []*workflow.Submission{
+			testSubmission("s3", workflow.StatusPending, 3),
+			testSubmission("s1", workflow.StatusPending, 1),
+			testSubmission("s2", workflow.StatusRejected, 2),
+		} {
+			if err := s.Save(context.Background(), submission); err != nil {
+				t.Fatalf("Save %s: %v", submission.ID, err)
+			}
+		}
+
+		for _, tc := range []struct {
+			status workflow.Status
+			want   []string
+		}{
+			{"", []string{"s1", "s2", "s3"}},
+			{workflow.StatusPending, []string{"s1", "s3"}},
+			{workflow.StatusApproved, nil},
+		} {
+			items, err := s.List(context.Background(), tc.status)
+			if err != nil {
+				t.Fatalf("List(%q): %v", tc.status, err)
+			}
+			var ids []string
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.want) {
+				t.Fatalf("List(%q) = %v, ожидалось %v (по времени отправки)", tc.status, ids, tc.want)
+			}
+		}
+	})
+}
+
+// testRoute создает роут с фиксированным временем создания
+func testRoute(id, name, routeType, title string) *storage.Route {
+	return &storage.Route{
+		ID:        id,
+		Name:      name,
+		Path:      "/" + name,
+		Title:     title,
+		Type:      routeType,
+		CreatedAt: createdAt,
+	}
+}
+
+// testSubmission создает заявку, отправленную через minutes минут после createdAt
+func testSubmission(id string, status workflow.Status, minutes int) *workflow.Submission {
+	return &workflow.Submission{
+		ID:          id,
+		Form:        "refund",
+		Data:        map[string]interface{}{"amount": "100"},
+		Roles:       []string{"finance"},
+		Status:      status,
+		SubmittedAt: createdAt.Add(time.Duration(minutes) * time.Minute),
+	}
+}
+
+// getRoutes возвращает роуты, завершая тест при ошибке
+func getRoutes(t *testing.T, s storage.Storage) []*storage.Route {
+	t.Helper()
+
+	routes, err := s.GetRoutes(context.Background())
+	if err != nil {
+		t.Fatalf("GetRoutes: %v", err)
+	}
+	return routes
+}
+
+// findRoute возвращает сохраненный роут по ID
+func findRoute(t *testing.T, s storage.Storage, id string) *storage.Route {
+	t.Helper()
+
+	for _, route := range getRoutes(t, s) {
+		if route.ID == id {
+			return route
+		}
+	}
+	t.Fatalf("роут %s не найден в GetRoutes", id)
+	return nil
+}
+
+// assertRoute сравнивает сохраняемые поля роута
+func assertRoute(t *testing.T, got, want *storage.Route) {
+	t.Helper()
+
+	if got.ID != want.ID || got.Name != want.Name || got.Path != want.Path || got.Title != want.Title ||
+		got.Description != want.Description || got.Icon != want.Icon || got.Type != want.Type {
+		t.Fatalf("роут %+v, ожидался %+v", got, want)
+	}
+}
+
+// routeIDs возвращает ID роутов
+func routeIDs(routes []*storage.Route) []string {
+	ids := make([]string, len(routes))
+	for i, route := range routes {
+		ids[i] = route.ID
+	}
+	return ids
+}
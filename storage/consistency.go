@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConsistencyHeader заголовок ответа на изменение роута с токеном согласованности
+const ConsistencyHeader = "X-Formist-Consistency"
+
+// ConsistencyParam параметр списка роутов: ?consistency=токен гарантирует, что изменение видно в ответе
+const ConsistencyParam = "consistency"
+
+// DefaultConsistencyWait время ожидания видимости изменения по умолчанию
+const DefaultConsistencyWait = 2 * time.Second
+
+// ErrNotVisible возвращается, если изменение не стало видимым за время ожидания
+// (реплика или кэш storage еще не получили запись)
+var ErrNotVisible = errors.New("изменение еще не видно в storage")
+
+// ConsistencyToken описывает изменение роута, которое должно быть видно при чтении
+type ConsistencyToken struct {
+	Op        string    `json:"op"` // ChangeOpSave или ChangeOpDelete
+	Route     string    `json:"route"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// NewConsistencyToken возвращает токен изменения роута операцией op (ChangeOpSave, ChangeOpDelete)
+func NewConsistencyToken(op string, route *Route) string {
+	token := ConsistencyToken{Op: op, Route: route.ID}
+	if op == ChangeOpSave {
+		// Хранилища могут округлять время, поэтому сравниваем с точностью до миллисекунды
+		token.UpdatedAt = route.UpdatedAt.Truncate(time.Millisecond)
+	}
+
+	raw, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseConsistencyToken разбирает токен согласованности
+func ParseConsistencyToken(value string) (ConsistencyToken, error) {
+	var token ConsistencyToken
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(raw, &token)
+	}
+	if err != nil || token.Route == "" || (token.Op != ChangeOpSave && token.Op != ChangeOpDelete) {
+		return ConsistencyToken{}, fmt.Errorf("некорректный токен согласованности")
+	}
+	return token, nil
+}
+
+// Visible сообщает, что изменение из токена отражено в списке роутов:
+// сохраненный роут присутствует не старее токена, удаленный - отсутствует
+func (t ConsistencyToken) Visible(routes []*Route) bool {
+	for _, route := range routes {
+		if route.ID == t.Route {
+			return t.Op == ChangeOpSave && !route.UpdatedAt.Before(t.UpdatedAt)
+		}
+	}
+	return t.Op == ChangeOpDelete
+}
+
+// WaitVisible читает роуты через load, пока изменения из tokens не станут видимы.
+// Через wait возвращает последний прочитанный список и ErrNotVisible
+func WaitVisible(ctx context.Context, tokens []ConsistencyToken, wait time.Duration, load func(ctx context.Context) ([]*Route, error)) ([]*Route, error) {
+	deadline := time.Now().Add(wait)
+	delay := 20 * time.Millisecond
+
+	for {
+		routes, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if visible(tokens, routes) {
+			return routes, nil
+		}
+
+		if time.Until(deadline) < delay {
+			return routes, ErrNotVisible
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(2*delay, 200*time.Millisecond)
+	}
+}
+
+// visible сообщает, что видны все изменения
+func visible(tokens []ConsistencyToken, routes []*Route) bool {
+	for _, token := range tokens {
+		if !token.Visible(routes) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Классы ошибок storage для errors.Is. Конкретные ошибки (ErrRouteNotFound и т.п.)
+// оборачивают их, поэтому вызывающий код может проверять как конкретную ошибку, так и класс
+var (
+	// ErrNotFound запись не найдена
+	ErrNotFound = errors.New("не найдено")
+
+	// ErrConflict запись конфликтует с существующей (например, нарушено ограничение уникальности)
+	ErrConflict = errors.New("конфликт")
+)
+
+// Error ошибка storage определенного класса
+type Error struct {
+	Kind    error // ErrNotFound или ErrConflict
+	Message string
+}
+
+// Error возвращает текст ошибки
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap возвращает класс ошибки
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Errorf создает ошибку класса kind с форматированным сообщением
+func Errorf(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// IsPermanent сообщает, что повтор операции не изменит результат:
+// запись не найдена, конфликтует с существующей или не прошла валидацию
+func IsPermanent(err error) bool {
+	var validationErr *ValidationError
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) || errors.As(err, &validationErr)
+}
@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// Option модифицирует storage при подключении (admin.WithStorage(s, opts...))
+type Option func(Storage) Storage
+
+// Apply применяет опции к storage по порядку
+func Apply(s Storage, opts ...Option) Storage {
+	for _, opt := range opts {
+		if opt != nil {
+			s = opt(s)
+		}
+	}
+	return s
+}
+
+// Классы ошибок storage в метриках
+const (
+	ErrorClassTimeout    = "timeout"
+	ErrorClassCanceled   = "canceled"
+	ErrorClassNotFound   = "not_found"
+	ErrorClassConflict   = "conflict"
+	ErrorClassValidation = "validation"
+	ErrorClassConnection = "connection"
+	ErrorClassOther      = "other"
+)
+
+// DefaultSlowThreshold порог медленного запроса по умолчанию
+const DefaultSlowThreshold = 200 * time.Millisecond
+
+// Instrumentation настройки инструментирования storage
+type Instrumentation struct {
+	// SlowThreshold порог, после которого операция считается медленной
+	// (по умолчанию DefaultSlowThreshold)
+	SlowThreshold time.Duration
+
+	// Logger получает предупреждения о медленных операциях (по умолчанию slog.Default())
+	Logger *slog.Logger
+
+	// Classify определяет класс ошибки (по умолчанию ClassifyError)
+	Classify func(err error) string
+
+	// OnOperation, если задан, вызывается после каждой операции
+	OnOperation func(OperationEvent)
+}
+
+// OperationEvent описывает выполненную операцию storage
+type OperationEvent struct {
+	Op         string
+	Duration   time.Duration
+	Slow       bool
+	Err        error
+	ErrorClass string
+}
+
+// OperationStats метрики одной операции storage
+type OperationStats struct {
+	Count         int            `json:"count"`
+	Slow          int            `json:"slow"`
+	Errors        map[string]int `json:"errors,omitempty"` // по классам ошибок
+	TotalDuration time.Duration  `json:"totalDuration"`
+	MaxDuration   time.Duration  `json:"maxDuration"`
+	LastDuration  time.Duration  `json:"lastDuration"`
+}
+
+// AvgDuration возвращает среднюю длительность операции
+func (s OperationStats) AvgDuration() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Count)
+}
+
+// MetricsProvider storage, предоставляющий метрики операций
+type MetricsProvider interface {
+	Metrics() map[string]OperationStats
+}
+
+// ClassifyError определяет класс ошибки storage
+func ClassifyError(err error) string {
+	var validationErr *ValidationError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, ErrNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, ErrConflict):
+		return ErrorClassConflict
+	case errors.As(err, &validationErr):
+		return ErrorClassValidation
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassConnection
+	default:
+		return ErrorClassOther
+	}
+}
+
+// WithInstrumentation оборачивает storage метриками задержек, предупреждениями
+// о медленных операциях и классификацией ошибок
+func WithInstrumentation(config Instrumentation) Option {
+	return func(s Storage) Storage {
+		return Instrument(s, config)
+	}
+}
+
+// Instrument оборачивает storage инструментированием.
+// Если storage реализует SettingsStorage, обертка тоже его реализует
+func Instrument(s Storage, config Instrumentation) Storage {
+	if config.SlowThreshold <= 0 {
+		config.SlowThreshold = DefaultSlowThreshold
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.Classify == nil {
+		config.Classify = ClassifyError
+	}
+
+	instrumented := &instrumentedStorage{
+		next:   s,
+		config: config,
+		stats:  make(map[string]*OperationStats),
+	}
+	if settings, ok := s.(SettingsStorage); ok {
+		return &instrumentedSettingsStorage{instrumentedStorage: instrumented, settings: settings}
+	}
+	return instrumented
+}
+
+// instrumentedStorage обертка storage с метриками
+type instrumentedStorage struct {
+	next   Storage
+	config Instrumentation
+
+	mu    sync.Mutex
+	stats map[string]*OperationStats
+}
+
+// SaveRoute сохраняет роут
+func (s *instrumentedStorage) SaveRoute(ctx context.Context, route *Route) error {
+	return s.observe(ctx, "saveRoute", func() error {
+		return s.next.SaveRoute(ctx, route)
+	})
+}
+
+// GetRoutes возвращает роуты
+func (s *instrumentedStorage) GetRoutes(ctx context.Context) ([]*Route, error) {
+	var routes []*Route
+	err := s.observe(ctx, "getRoutes", func() error {
+		var err error
+		routes, err = s.next.GetRoutes(ctx)
+		return err
+	})
+	return routes, err
+}
+
+// DeleteRoute удаляет роут
+func (s *instrumentedStorage) DeleteRoute(ctx context.Context, id string) error {
+	return s.observe(ctx, "deleteRoute", func() error {
+		return s.next.DeleteRoute(ctx, id)
+	})
+}
+
+// Close закрывает storage
+func (s *instrumentedStorage) Close() error {
+	return s.next.Close()
+}
+
+// Unwrap возвращает исходный storage
+func (s *instrumentedStorage) Unwrap() Storage {
+	return s.next
+}
+
+// Metrics возвращает метрики по операциям
+func (s *instrumentedStorage) Metrics() map[string]OperationStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics := make(map[string]OperationStats, len(s.stats))
+	for op, stats := range s.stats {
+		copied := *stats
+		if stats.Errors != nil {
+			copied.Errors = make(map[string]int, len(stats.Errors))
+			for class, count := range stats.Errors {
+				copied.Errors[class] = count
+			}
+		}
+		metrics[op] = copied
+	}
+	return metrics
+}
+
+// observe выполняет операцию, замеряя длительность и классифицируя ошибку
+func (s *instrumentedStorage) observe(ctx context.Context, op string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	duration := time.Since(start)
+
+	event := OperationEvent{
+		Op:       op,
+		Duration: duration,
+		Slow:     duration >= s.config.SlowThreshold,
+		Err:      err,
+	}
+	if err != nil {
+		event.ErrorClass = s.config.Classify(err)
+	}
+
+	s.record(event)
+
+	if event.Slow {
+		attrs := []any{
+			slog.String("op", op),
+			slog.Duration("duration", duration),
+			slog.Duration("threshold", s.config.SlowThreshold),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()), slog.String("errorClass", event.ErrorClass))
+		}
+		s.config.Logger.WarnContext(ctx, "медленная операция storage", attrs...)
+	}
+
+	if s.config.OnOperation != nil {
+		s.config.OnOperation(event)
+	}
+	return err
+}
+
+// record обновляет метрики по событию
+func (s *instrumentedStorage) record(event OperationEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, ok := s.stats[event.Op]
+	if !ok {
+		stats = &OperationStats{}
+		s.stats[event.Op] = stats
+	}
+
+	stats.Count++
+	stats.TotalDuration += event.Duration
+	stats.LastDuration = event.Duration
+	if event.Duration > stats.MaxDuration {
+		stats.MaxDuration = event.Duration
+	}
+	if event.Slow {
+		stats.Slow++
+	}
+	if event.ErrorClass != "" {
+		if stats.Errors == nil {
+			stats.Errors = make(map[string]int)
+		}
+		stats.Errors[event.ErrorClass]++
+	}
+}
+
+// instrumentedSettingsStorage обертка storage с поддержкой настроек
+type instrumentedSettingsStorage struct {
+	*instrumentedStorage
+	settings SettingsStorage
+}
+
+// GetSetting возвращает значение настройки
+func (s *instrumentedSettingsStorage) GetSetting(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := s.observe(ctx, "getSetting", func() error {
+		var err error
+		value, err = s.settings.GetSetting(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+// SaveSetting сохраняет значение настройки
+func (s *instrumentedSettingsStorage) SaveSetting(ctx context.Context, key string, value []byte) error {
+	return s.observe(ctx, "saveSetting", func() error {
+		return s.settings.SaveSetting(ctx, key, value)
+	})
+}
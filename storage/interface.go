@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// ErrRouteNotFound возвращается при удалении несуществующего роута
+var ErrRouteNotFound = &Error{Kind: ErrNotFound, Message: "роут не найден"}
+
 // Route представляет роут для навигации
 type Route struct {
 	ID          string    `json:"id" db:"id"`
@@ -20,15 +23,54 @@ type Route struct {
 
 // Storage интерфейс для хранения роутов
 type Storage interface {
-	// SaveRoute сохраняет или обновляет роут
+	// SaveRoute сохраняет или обновляет роут по ID. Пустой ID генерируется, нулевой CreatedAt
+	// заполняется, UpdatedAt обновляется; при обновлении сохраненный CreatedAt не меняется.
+	// Роут с именем другого роута отклоняется ошибкой класса ErrConflict
 	SaveRoute(ctx context.Context, route *Route) error
 	
-	// GetRoutes возвращает все роуты для UI
+	// GetRoutes возвращает все роуты для UI, упорядоченные по типу и заголовку
 	GetRoutes(ctx context.Context) ([]*Route, error)
 	
-	// DeleteRoute удаляет роут по ID
+	// DeleteRoute удаляет роут по ID или возвращает ErrRouteNotFound
 	DeleteRoute(ctx context.Context, id string) error
 	
 	// Close закрывает соединение
 	Close() error
-}
\ No newline at end of file
+}
+
+// RoutesSaver необязательное расширение Storage для атомарного сохранения нескольких роутов
+type RoutesSaver interface {
+	// SaveRoutes сохраняет или обновляет роуты как SaveRoute; при ошибке не сохраняется ни один
+	SaveRoutes(ctx context.Context, routes []*Route) error
+}
+
+// RoutesSaverOf возвращает RoutesSaver storage, в том числе обернутого опциями
+// (Instrument, шифрование), которые сами пакетного сохранения не реализуют
+func RoutesSaverOf(s Storage) (RoutesSaver, bool) {
+	for s != nil {
+		if saver, ok := s.(RoutesSaver); ok {
+			return saver, true
+		}
+
+		wrapper, ok := s.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		s = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// SaveRoutes сохраняет роуты одним пакетом, если storage (в том числе обернутый опциями)
+// поддерживает RoutesSaver, иначе по одному через SaveRoute
+func SaveRoutes(ctx context.Context, s Storage, routes []*Route) error {
+	if saver, ok := RoutesSaverOf(s); ok {
+		return saver.SaveRoutes(ctx, routes)
+	}
+	for _, route := range routes {
+		if err := s.SaveRoute(ctx, route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+// batchStorage storage с пакетным сохранением роутов
+type batchStorage struct {
+	Storage
+	saved int
+}
+
+func (s *batchStorage) SaveRoutes(ctx context.Context, routes []*Route) error {
+	s.saved += len(routes)
+	return nil
+}
+
+func TestRoutesSaverOfUnwrapsInstrument(t *testing.T) {
+	inner := &batchStorage{}
+	saver, ok := RoutesSaverOf(Instrument(inner, Instrumentation{}))
+	if !ok {
+		t.Fatal("RoutesSaver не найден за оберткой Instrument")
+	}
+	if err := saver.SaveRoutes(context.Background(), []*Route{{ID: "a"}, {ID: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if inner.saved != 2 {
+		t.Fatalf("сохранено роутов: %d, ожидалось 2", inner.saved)
+	}
+}
+
+// routeStorage storage без пакетного сохранения роутов
+type routeStorage struct {
+	Storage
+	saved []string
+}
+
+func (s *routeStorage) SaveRoute(ctx context.Context, route *Route) error {
+	s.saved = append(s.saved, route.ID)
+	return nil
+}
+
+func TestSaveRoutesFallsBackToSaveRoute(t *testing.T) {
+	inner := &routeStorage{}
+	if err := SaveRoutes(context.Background(), Instrument(inner, Instrumentation{}), []*Route{{ID: "a"}, {ID: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.saved) != 2 {
+		t.Fatalf("сохранено роутов: %v, ожидалось 2", inner.saved)
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrLockingNotSupported возвращается, если storage не поддерживает распределенные блокировки
+var ErrLockingNotSupported = errors.New("storage не поддерживает распределенные блокировки")
+
+// Lock удерживаемая распределенная блокировка
+type Lock interface {
+	// Check проверяет, что блокировка все еще удерживается (например, соединение с БД не разорвано)
+	Check(ctx context.Context) error
+
+	// Release освобождает блокировку
+	Release(ctx context.Context) error
+}
+
+// Locker необязательное расширение Storage для распределенных блокировок между экземплярами
+// (например, advisory locks в PostgreSQL)
+type Locker interface {
+	// TryLock пытается захватить блокировку name без ожидания.
+	// Если блокировка занята другим экземпляром, возвращается acquired == false
+	TryLock(ctx context.Context, name string) (lock Lock, acquired bool, err error)
+}
+
+// LockerOf возвращает Locker storage, в том числе обернутого опциями
+func LockerOf(s Storage) (Locker, bool) {
+	for s != nil {
+		if locker, ok := s.(Locker); ok {
+			return locker, true
+		}
+
+		wrapper, ok := s.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		s = wrapper.Unwrap()
+	}
+	return nil, false
+}
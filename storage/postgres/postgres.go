@@ -2,23 +2,111 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/koteyye/go-formist/id"
 	"github.com/koteyye/go-formist/storage"
 )
 
+// ChangesChannel канал LISTEN/NOTIFY для уведомлений об изменениях между экземплярами
+const ChangesChannel = "formist_changes"
+
+// querier выполняет запросы через пул соединений или транзакцию
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // PostgresStorage реализация Storage для PostgreSQL
 type PostgresStorage struct {
-	pool *pgxpool.Pool
-	sb   sq.StatementBuilderType
+	pool   *pgxpool.Pool
+	db     querier // пул или транзакция, переданная через WithTx
+	tx     pgx.Tx
+	sb     sq.StatementBuilderType
+	origin string // идентификатор экземпляра в уведомлениях
+
+	replicas      []*pgxpool.Pool // пулы чтения роутов
+	ownedReplicas []*pgxpool.Pool // пулы реплик, созданные по DSN и закрываемые в Close
+	next          *atomic.Uint64  // счетчик выбора реплики, общий для копий WithTx
+}
+
+// Option настройка PostgresStorage
+type Option func(*options)
+
+// options параметры подключения
+type options struct {
+	replicaDSNs  []string
+	replicaPools []*pgxpool.Pool
+}
+
+// WithReplicas направляет чтение роутов на реплики с указанными DSN (по кругу).
+// Запись и чтение настроек выполняются на основном сервере
+func WithReplicas(dsns ...string) Option {
+	return func(o *options) {
+		o.replicaDSNs = append(o.replicaDSNs, dsns...)
+	}
+}
+
+// WithReplicaPools направляет чтение роутов на готовые пулы реплик.
+// Пулы принадлежат вызывающему коду и не закрываются в Close
+func WithReplicaPools(pools ...*pgxpool.Pool) Option {
+	return func(o *options) {
+		o.replicaPools = append(o.replicaPools, pools...)
+	}
 }
 
 // NewPostgresStorage создает новое подключение к PostgreSQL
-func NewPostgresStorage(ctx context.Context, dsn string) (*PostgresStorage, error) {
+func NewPostgresStorage(ctx context.Context, dsn string, opts ...Option) (*PostgresStorage, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	pool, err := newPool(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	ps := &PostgresStorage{
+		pool:     pool,
+		db:       pool,
+		replicas: append([]*pgxpool.Pool(nil), o.replicaPools...),
+		next:     new(atomic.Uint64),
+		sb:       sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		origin:   id.New(),
+	}
+
+	for _, replicaDSN := range o.replicaDSNs {
+		replica, err := newPool(ctx, replicaDSN)
+		if err != nil {
+			ps.Close()
+			return nil, fmt.Errorf("реплика: %w", err)
+		}
+		ps.replicas = append(ps.replicas, replica)
+		ps.ownedReplicas = append(ps.ownedReplicas, replica)
+	}
+
+	// Создаем таблицу если её нет
+	if err := ps.createTable(ctx); err != nil {
+		ps.Close()
+		return nil, fmt.Errorf("не удалось создать таблицу: %w", err)
+	}
+
+	return ps, nil
+}
+
+// newPool создает пул соединений и проверяет подключение
+func newPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось распарсить DSN: %w", err)
@@ -37,20 +125,21 @@ func NewPostgresStorage(ctx context.Context, dsn string) (*PostgresStorage, erro
 
 	// Проверяем соединение
 	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("не удалось проверить соединение: %w", err)
 	}
 
-	ps := &PostgresStorage{
-		pool: pool,
-		sb:   sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
-	}
-	
-	// Создаем таблицу если её нет
-	if err := ps.createTable(ctx); err != nil {
-		return nil, fmt.Errorf("не удалось создать таблицу: %w", err)
-	}
+	return pool, nil
+}
 
-	return ps, nil
+// reader возвращает соединение для чтения роутов: транзакцию WithTx,
+// следующую реплику или основной пул
+func (ps *PostgresStorage) reader() (querier, bool) {
+	if ps.tx != nil || len(ps.replicas) == 0 {
+		return ps.db, false
+	}
+	n := ps.next.Add(1)
+	return ps.replicas[int(n%uint64(len(ps.replicas)))], true
 }
 
 // createTable создает таблицу для хранения роутов
@@ -70,17 +159,93 @@ func (ps *PostgresStorage) createTable(ctx context.Context) error {
 
 	CREATE INDEX IF NOT EXISTS idx_routes_type ON formist_routes(type);
 	CREATE INDEX IF NOT EXISTS idx_routes_name ON formist_routes(name);
+
+	CREATE TABLE IF NOT EXISTS formist_settings (
+		key VARCHAR(255) PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
 	`
 
 	_, err := ps.pool.Exec(ctx, query)
 	return err
 }
 
+// schemaTables таблицы, которые создает createTable
+var schemaTables = []string{"formist_routes", "formist_settings"}
+
+// CheckSchema проверяет, что таблицы formist созданы
+func (ps *PostgresStorage) CheckSchema(ctx context.Context) error {
+	for _, table := range schemaTables {
+		var exists bool
+		if err := ps.db.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+			return fmt.Errorf("не удалось проверить таблицу %s: %w", table, err)
+		}
+		if !exists {
+			return fmt.Errorf("таблица %s не создана", table)
+		}
+	}
+	return nil
+}
+
 // SaveRoute сохраняет или обновляет роут
 func (ps *PostgresStorage) SaveRoute(ctx context.Context, route *storage.Route) error {
+	query, args, err := ps.saveRouteQuery(route)
+	if err != nil {
+		return err
+	}
+
+	_, err = ps.db.Exec(ctx, query, args...)
+	if err != nil {
+		return saveRouteError(route, err)
+	}
+
+	ps.notify(ctx, storage.ChangeEvent{Kind: storage.ChangeKindRoute, Op: storage.ChangeOpSave, Key: route.ID})
+	return nil
+}
+
+// SaveRoutes сохраняет или обновляет роуты одним пакетом (pgx.Batch) в транзакции:
+// при ошибке любого роута не сохраняется ни один. В хранилище, привязанном
+// к транзакции через WithTx, пакет выполняется в точке сохранения этой транзакции
+func (ps *PostgresStorage) SaveRoutes(ctx context.Context, routes []*storage.Route) error {
+	if len(routes) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, route := range routes {
+		query, args, err := ps.saveRouteQuery(route)
+		if err != nil {
+			return err
+		}
+		batch.Queue(query, args...)
+	}
+
+	err := pgx.BeginFunc(ctx, ps.db, func(tx pgx.Tx) error {
+		results := tx.SendBatch(ctx, batch)
+		for _, route := range routes {
+			if _, err := results.Exec(); err != nil {
+				results.Close()
+				return saveRouteError(route, err)
+			}
+		}
+		return results.Close()
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		ps.notify(ctx, storage.ChangeEvent{Kind: storage.ChangeKindRoute, Op: storage.ChangeOpSave, Key: route.ID})
+	}
+	return nil
+}
+
+// saveRouteQuery заполняет ID и временные метки роута и строит запрос upsert
+func (ps *PostgresStorage) saveRouteQuery(route *storage.Route) (string, []interface{}, error) {
 	// Генерируем ID если его нет
 	if route.ID == "" {
-		route.ID = fmt.Sprintf("%s_%s_%d", route.Type, route.Name, time.Now().Unix())
+		route.ID = id.New()
 	}
 
 	// Устанавливаем временные метки
@@ -118,19 +283,42 @@ func (ps *PostgresStorage) SaveRoute(ctx context.Context, route *storage.Route)
 		ToSql()
 
 	if err != nil {
-		return fmt.Errorf("не удалось построить запрос: %w", err)
+		return "", nil, fmt.Errorf("не удалось построить запрос: %w", err)
 	}
+	return query, args, nil
+}
 
-	_, err = ps.pool.Exec(ctx, query, args...)
-	if err != nil {
-		return fmt.Errorf("не удалось сохранить роут: %w", err)
+// saveRouteError приводит ошибку сохранения роута к ошибке storage
+func saveRouteError(route *storage.Route, err error) error {
+	if isUniqueViolation(err) {
+		return storage.Errorf(storage.ErrConflict, "роут с именем %s уже существует", route.Name)
 	}
+	return fmt.Errorf("не удалось сохранить роут %s: %w", route.Name, err)
+}
 
-	return nil
+// WithTx возвращает хранилище, выполняющее запросы в транзакции tx вызывающего кода.
+// Фиксацией и откатом управляет вызывающий код, а уведомления об изменениях
+// доставляются другим экземплярам только после фиксации
+func (ps *PostgresStorage) WithTx(tx pgx.Tx) *PostgresStorage {
+	clone := *ps
+	clone.db = tx
+	clone.tx = tx
+	return &clone
 }
 
-// GetRoutes возвращает все роуты
+// GetRoutes возвращает все роуты. При настроенных репликах чтение выполняется на них,
+// а при ошибке реплики повторяется на основном сервере
 func (ps *PostgresStorage) GetRoutes(ctx context.Context) ([]*storage.Route, error) {
+	db, replica := ps.reader()
+	routes, err := ps.getRoutes(ctx, db)
+	if err != nil && replica && ctx.Err() == nil {
+		routes, err = ps.getRoutes(ctx, ps.db)
+	}
+	return routes, err
+}
+
+// getRoutes читает роуты через db
+func (ps *PostgresStorage) getRoutes(ctx context.Context, db querier) ([]*storage.Route, error) {
 	query, args, err := ps.sb.
 		Select("id", "name", "path", "title", "description", "icon", "type", "created_at", "updated_at").
 		From("formist_routes").
@@ -141,7 +329,7 @@ func (ps *PostgresStorage) GetRoutes(ctx context.Context) ([]*storage.Route, err
 		return nil, fmt.Errorf("не удалось построить запрос: %w", err)
 	}
 
-	rows, err := ps.pool.Query(ctx, query, args...)
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось выполнить запрос: %w", err)
 	}
@@ -194,20 +382,188 @@ func (ps *PostgresStorage) DeleteRoute(ctx context.Context, id string) error {
 		return fmt.Errorf("не удалось построить запрос: %w", err)
 	}
 
-	result, err := ps.pool.Exec(ctx, query, args...)
+	result, err := ps.db.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("не удалось удалить роут: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("роут с ID %s не найден", id)
+		return fmt.Errorf("роут с ID %s: %w", id, storage.ErrRouteNotFound)
+	}
+
+	ps.notify(ctx, storage.ChangeEvent{Kind: storage.ChangeKindRoute, Op: storage.ChangeOpDelete, Key: id})
+	return nil
+}
+
+// GetSetting возвращает значение настройки. Читается с основного сервера: настройки
+// перечитываются сразу после уведомления об изменении, реплика может отставать
+func (ps *PostgresStorage) GetSetting(ctx context.Context, key string) ([]byte, error) {
+	query, args, err := ps.sb.
+		Select("value").
+		From("formist_settings").
+		Where(sq.Eq{"key": key}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("не удалось построить запрос: %w", err)
+	}
+
+	var value string
+	if err := ps.db.QueryRow(ctx, query, args...).Scan(&value); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrSettingNotFound
+		}
+		return nil, fmt.Errorf("не удалось получить настройку: %w", err)
+	}
+
+	return []byte(value), nil
+}
+
+// SaveSetting сохраняет или обновляет значение настройки
+func (ps *PostgresStorage) SaveSetting(ctx context.Context, key string, value []byte) error {
+	query, args, err := ps.sb.
+		Insert("formist_settings").
+		Columns("key", "value", "updated_at").
+		Values(key, string(value), time.Now()).
+		Suffix(`
+			ON CONFLICT (key) DO UPDATE SET
+				value = EXCLUDED.value,
+				updated_at = EXCLUDED.updated_at
+		`).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("не удалось построить запрос: %w", err)
+	}
+
+	if _, err := ps.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("не удалось сохранить настройку: %w", err)
+	}
+
+	ps.notify(ctx, storage.ChangeEvent{Kind: storage.ChangeKindSetting, Op: storage.ChangeOpSave, Key: key})
+	return nil
+}
+
+// PublishChange рассылает уведомление об изменении через NOTIFY
+func (ps *PostgresStorage) PublishChange(ctx context.Context, event storage.ChangeEvent) error {
+	event.Origin = ps.origin
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ps.db.Exec(ctx, "SELECT pg_notify($1, $2)", ChangesChannel, string(payload)); err != nil {
+		return fmt.Errorf("не удалось отправить уведомление: %w", err)
+	}
+	return nil
+}
+
+// ListenChanges подписывается на канал ChangesChannel и передает уведомления
+// других экземпляров в handler. Блокируется до отмены ctx или ошибки соединения
+func (ps *PostgresStorage) ListenChanges(ctx context.Context, handler func(storage.ChangeEvent)) error {
+	conn, err := ps.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось получить соединение: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+ChangesChannel); err != nil {
+		return fmt.Errorf("не удалось подписаться на уведомления: %w", err)
+	}
+	defer func() {
+		// Соединение возвращается в пул, подписку нужно снять
+		if !conn.Conn().IsClosed() {
+			unlistenCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			conn.Exec(unlistenCtx, "UNLISTEN "+ChangesChannel)
+		}
+	}()
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event storage.ChangeEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			continue
+		}
+		if event.Origin == ps.origin {
+			continue
+		}
+		handler(event)
+	}
+}
+
+// TryLock пытается захватить advisory lock с именем name. Блокировка привязана
+// к выделенному соединению и освобождается при его разрыве
+func (ps *PostgresStorage) TryLock(ctx context.Context, name string) (storage.Lock, bool, error) {
+	conn, err := ps.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("не удалось получить соединение: %w", err)
 	}
 
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("не удалось захватить блокировку: %w", err)
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	return &advisoryLock{conn: conn, name: name}, true, nil
+}
+
+// advisoryLock удерживаемый advisory lock
+type advisoryLock struct {
+	conn *pgxpool.Conn
+	name string
+}
+
+// Check проверяет соединение, на котором удерживается блокировка
+func (l *advisoryLock) Check(ctx context.Context) error {
+	if _, err := l.conn.Exec(ctx, "SELECT 1"); err != nil {
+		return fmt.Errorf("блокировка %s потеряна: %w", l.name, err)
+	}
 	return nil
 }
 
-// Close закрывает пул соединений
+// Release освобождает блокировку и возвращает соединение в пул
+func (l *advisoryLock) Release(ctx context.Context) error {
+	defer l.conn.Release()
+
+	if _, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock(hashtext($1))", l.name); err != nil {
+		// Закрытие соединения освобождает блокировку на стороне сервера
+		l.conn.Conn().Close(ctx)
+		return fmt.Errorf("не удалось освободить блокировку: %w", err)
+	}
+	return nil
+}
+
+// notify рассылает уведомление об изменении. Ошибка не возвращается:
+// данные уже сохранены, остальные экземпляры получат их при следующем чтении
+func (ps *PostgresStorage) notify(ctx context.Context, event storage.ChangeEvent) {
+	_ = ps.PublishChange(ctx, event)
+}
+
+// isUniqueViolation проверяет нарушение ограничения уникальности
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// Close закрывает пул соединений. Хранилище, привязанное к транзакции через WithTx,
+// пул не закрывает
 func (ps *PostgresStorage) Close() error {
+	if ps.tx != nil {
+		return nil
+	}
+	for _, replica := range ps.ownedReplicas {
+		replica.Close()
+	}
 	ps.pool.Close()
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/koteyye/go-formist/storage"
+	"github.com/koteyye/go-formist/storage/conformance"
+)
+
+// testDSNEnv переменная окружения с DSN тестовой базы PostgreSQL
+const testDSNEnv = "FORMIST_TEST_POSTGRES_DSN"
+
+// schemaSeq номер схемы подтеста
+var schemaSeq atomic.Uint64
+
+// TestStorage проверяет PostgresStorage общими наборами тестов хранилищ роутов и настроек.
+// Каждый подтест работает в отдельной схеме, которая удаляется после него
+func TestStorage(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s не задан", testDSNEnv)
+	}
+
+	admin, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("подключение к %s: %v", testDSNEnv, err)
+	}
+	t.Cleanup(admin.Close)
+
+	conformance.TestStorage(t, func(t *testing.T) storage.Storage {
+		return newTestStorage(t, admin, dsn)
+	})
+	conformance.TestSettingsStorage(t, func(t *testing.T) storage.SettingsStorage {
+		return newTestStorage(t, admin, dsn)
+	})
+}
+
+// newTestStorage создает хранилище в новой пустой схеме
+func newTestStorage(t *testing.T, admin *pgxpool.Pool, dsn string) *PostgresStorage {
+	ctx := context.Background()
+	schema := fmt.Sprintf("formist_test_%d_%d", time.Now().UnixNano(), schemaSeq.Add(1))
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("создание схемы: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("удаление схемы: %v", err)
+		}
+	})
+
+	s, err := NewPostgresStorage(ctx, withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("NewPostgresStorage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// withSearchPath добавляет в DSN (URL или ключ=значение) параметр search_path
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			query := u.Query()
+			query.Set("search_path", schema)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path=" + schema
+}
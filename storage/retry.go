@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RetryPolicy описывает политику повторных попыток записи в storage
+type RetryPolicy struct {
+	MaxAttempts    int           `json:"maxAttempts"`
+	InitialBackoff time.Duration `json:"initialBackoff"`
+	MaxBackoff     time.Duration `json:"maxBackoff"`
+	Multiplier     float64       `json:"multiplier"`
+}
+
+// DefaultRetryPolicy возвращает политику повторов по умолчанию
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// Backoff возвращает задержку перед попыткой с номером attempt (начиная с 1)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * p.Multiplier)
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// Retry выполняет fn с повторами согласно политике. Постоянные ошибки (см. IsPermanent)
+// не повторяются. Возвращает количество выполненных попыток и последнюю ошибку
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) (int, error) {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(ctx); err == nil {
+			return attempt, nil
+		}
+
+		if attempt == attempts || IsPermanent(err) {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return attempts, err
+}
+
+// FailedWrite представляет запись, которую не удалось сохранить в storage
+type FailedWrite struct {
+	Op       string    `json:"op"` // saveRoute и т.п.
+	Route    *Route    `json:"route,omitempty"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// DeadLetterQueue хранит в памяти записи, не сохраненные после всех повторов
+type DeadLetterQueue struct {
+	mu       sync.Mutex
+	items    []FailedWrite
+	capacity int
+	total    int
+}
+
+// NewDeadLetterQueue создает очередь заданной вместимости.
+// При переполнении вытесняются самые старые записи
+func NewDeadLetterQueue(capacity int) *DeadLetterQueue {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &DeadLetterQueue{
+		items:    make([]FailedWrite, 0),
+		capacity: capacity,
+	}
+}
+
+// Push добавляет запись в очередь
+func (q *DeadLetterQueue) Push(item FailedWrite) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) >= q.capacity {
+		q.items = q.items[1:]
+	}
+	q.items = append(q.items, item)
+	q.total++
+}
+
+// Items возвращает копию текущих записей
+func (q *DeadLetterQueue) Items() []FailedWrite {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := make([]FailedWrite, len(q.items))
+	copy(items, q.items)
+	return items
+}
+
+// Drain возвращает все записи и очищает очередь
+func (q *DeadLetterQueue) Drain() []FailedWrite {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := q.items
+	q.items = make([]FailedWrite, 0)
+	return items
+}
+
+// Len возвращает количество записей в очереди
+func (q *DeadLetterQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Total возвращает общее количество неудачных записей за время работы
+func (q *DeadLetterQueue) Total() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.total
+}
@@ -0,0 +1,25 @@
+package storage
+
+import "context"
+
+// SchemaChecker необязательное расширение Storage для проверки, что таблицы storage созданы
+type SchemaChecker interface {
+	// CheckSchema возвращает ошибку, если схема хранилища отсутствует или неполна
+	CheckSchema(ctx context.Context) error
+}
+
+// SchemaCheckerOf возвращает SchemaChecker storage, в том числе обернутого опциями
+func SchemaCheckerOf(s Storage) (SchemaChecker, bool) {
+	for s != nil {
+		if checker, ok := s.(SchemaChecker); ok {
+			return checker, true
+		}
+
+		wrapper, ok := s.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		s = wrapper.Unwrap()
+	}
+	return nil, false
+}
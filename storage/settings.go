@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+)
+
+// ErrSettingNotFound возвращается, если настройка не сохранена
+var ErrSettingNotFound = &Error{Kind: ErrNotFound, Message: "настройка не найдена"}
+
+// SettingsStorage необязательное расширение Storage для хранения настроек админки
+// (например, режима обслуживания). Значения сохраняются в формате JSON
+type SettingsStorage interface {
+	// GetSetting возвращает значение настройки или ErrSettingNotFound
+	GetSetting(ctx context.Context, key string) ([]byte, error)
+
+	// SaveSetting сохраняет или обновляет значение настройки
+	SaveSetting(ctx context.Context, key string, value []byte) error
+}
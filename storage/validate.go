@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Типы роутов
+const (
+	RouteTypeForm = "form"
+	RouteTypePage = "page"
+)
+
+// ValidationError содержит ошибки полей роута
+type ValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+// Error возвращает описание ошибок в стабильном порядке
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %s", name, e.Fields[name]))
+	}
+	return "некорректный роут: " + strings.Join(parts, "; ")
+}
+
+// Normalize приводит роут к каноническому виду: обрезает пробелы,
+// добавляет ведущий слеш пути, убирает завершающий и приводит тип к нижнему регистру
+func (r *Route) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Title = strings.TrimSpace(r.Title)
+	r.Icon = strings.TrimSpace(r.Icon)
+	r.Type = strings.ToLower(strings.TrimSpace(r.Type))
+
+	path := strings.TrimSpace(r.Path)
+	if path != "" {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		if len(path) > 1 {
+			path = strings.TrimRight(path, "/")
+		}
+	}
+	r.Path = path
+}
+
+// Validate проверяет обязательные поля, формат пути и тип роута
+func (r *Route) Validate() error {
+	fields := make(map[string]string)
+
+	if r.Name == "" {
+		fields["name"] = "обязательное поле"
+	}
+	if r.Title == "" {
+		fields["title"] = "обязательное поле"
+	}
+
+	switch {
+	case r.Path == "":
+		fields["path"] = "обязательное поле"
+	case !strings.HasPrefix(r.Path, "/"):
+		fields["path"] = "путь должен начинаться с /"
+	case strings.ContainsAny(r.Path, " \t\n?#"):
+		fields["path"] = "путь содержит недопустимые символы"
+	case strings.Contains(r.Path, "//") || strings.Contains(r.Path, "/../") || strings.HasSuffix(r.Path, "/.."):
+		fields["path"] = "некорректный путь"
+	}
+
+	switch r.Type {
+	case RouteTypeForm, RouteTypePage:
+	case "":
+		fields["type"] = "обязательное поле"
+	default:
+		fields["type"] = fmt.Sprintf("допустимые значения: %s, %s", RouteTypeForm, RouteTypePage)
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
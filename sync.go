@@ -0,0 +1,97 @@
+package formist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/koteyye/go-formist/registry"
+	"github.com/koteyye/go-formist/storage"
+)
+
+// SyncFormsOnce загружает определения форм и пунктов меню из реестра и применяет их.
+// Снимок проверяется целиком до применения: ошибка в одной форме не меняет ни одну из них.
+// Формы, пропавшие из реестра, снимаются с регистрации
+func (a *Admin) SyncFormsOnce(ctx context.Context, source registry.Source) error {
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+
+	a.syncSource = source
+
+	snapshot, etag, err := source.Fetch(ctx, a.syncETag)
+	if err != nil {
+		return err
+	}
+
+	// Данные не изменились
+	if snapshot == nil {
+		return nil
+	}
+
+	if err := snapshot.Validate(); err != nil {
+		return err
+	}
+	for _, f := range snapshot.Forms {
+		if err := a.router.ValidateIcon(f.Icon); err != nil {
+			return fmt.Errorf("форма %s: %w", f.Key(), err)
+		}
+	}
+	for _, route := range snapshot.Routes {
+		if err := a.router.ValidateIcon(route.Icon); err != nil {
+			return fmt.Errorf("роут %s: %w", route.Name, err)
+		}
+	}
+
+	current := make(map[string]bool, len(snapshot.Forms))
+	for _, f := range snapshot.Forms {
+		a.replaceForm(f)
+		current[f.Key()] = true
+	}
+
+	for name := range a.syncForms {
+		if !current[name] {
+			a.router.UnregisterForm(name)
+		}
+	}
+	a.syncForms = current
+
+	if a.storage != nil {
+		for _, route := range snapshot.Routes {
+			_ = a.saveRoute(ctx, route)
+		}
+	}
+
+	a.syncETag = etag
+
+	// Остальные экземпляры синхронизируются сразу, не дожидаясь своего интервала
+	if notifier, ok := storage.ChangeNotifierOf(a.storage); ok {
+		_ = notifier.PublishChange(ctx, storage.ChangeEvent{Kind: storage.ChangeKindForms, Op: storage.ChangeOpSave})
+	}
+	return nil
+}
+
+// SyncForms периодически синхронизирует формы с реестром до отмены ctx.
+// Первая синхронизация выполняется сразу, ошибки передаются в onError
+func (a *Admin) SyncForms(ctx context.Context, source registry.Source, interval time.Duration, onError func(error)) {
+	run := func() {
+		if err := a.SyncFormsOnce(ctx, source); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+
+	run()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
@@ -0,0 +1,376 @@
+// Package tokens реализует персональные токены доступа: пользователь выпускает токен,
+// ограниченный формами, операциями и сроком действия, чтобы скрипты отправляли формы
+// от его имени. Хранится только хеш токена
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/id"
+	"github.com/koteyye/go-formist/privacy"
+)
+
+// Prefix начало значения персонального токена, по которому его отличают от других учетных данных
+const Prefix = "fpat_"
+
+const (
+	// DefaultTTL срок действия токена по умолчанию
+	DefaultTTL = 30 * 24 * time.Hour
+
+	// MaxTTL максимальный срок действия токена
+	MaxTTL = 365 * 24 * time.Hour
+)
+
+// Ошибки токенов
+var (
+	ErrNotFound  = errors.New("токен не найден")
+	ErrInvalid   = errors.New("недействительный токен")
+	ErrExpired   = errors.New("срок действия токена истек")
+	ErrForbidden = errors.New("отозвать токен может только владелец или администратор токенов")
+	ErrNoScopes  = errors.New("у токена не заданы формы")
+	ErrTTL       = errors.New("срок действия токена превышает максимальный")
+)
+
+// Scope разрешает операции с одной формой
+type Scope struct {
+	Form    string   `json:"form"`              // ключ формы, например billing/refund
+	Read    bool     `json:"read,omitempty"`    // чтение формы, данных и таблиц
+	Submit  bool     `json:"submit,omitempty"`  // отправка, пакетная отправка и изменение ячеек
+	Actions []string `json:"actions,omitempty"` // дополнительные действия формы
+}
+
+// Token персональный токен доступа
+type Token struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"`  // начало значения для узнавания токена в списке
+	Owner      *auth.User `json:"owner"` // владелец на момент выпуска, без разрешений
+	Scopes     []Scope    `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	Hash       string     `json:"-"`
+}
+
+// scope возвращает разрешения токена для формы
+func (t *Token) scope(form string) (Scope, bool) {
+	for _, scope := range t.Scopes {
+		if scope.Form == form {
+			return scope, true
+		}
+	}
+	return Scope{}, false
+}
+
+// CanRead сообщает, что токен разрешает чтение формы
+func (t *Token) CanRead(form string) bool {
+	scope, ok := t.scope(form)
+	return ok && scope.Read
+}
+
+// CanSubmit сообщает, что токен разрешает отправку формы
+func (t *Token) CanSubmit(form string) bool {
+	scope, ok := t.scope(form)
+	return ok && scope.Submit
+}
+
+// CanAction сообщает, что токен разрешает дополнительное действие формы
+func (t *Token) CanAction(form, action string) bool {
+	scope, ok := t.scope(form)
+	return ok && slices.Contains(scope.Actions, action)
+}
+
+// User возвращает пользователя, от имени которого выполняются запросы с токеном
+func (t *Token) User() *auth.User {
+	user := *t.Owner
+	user.Roles = slices.Clone(t.Owner.Roles)
+	user.Permissions = nil
+	user.TokenID = t.ID
+	return &user
+}
+
+// clone копирует токен
+func (t *Token) clone() *Token {
+	clone := *t
+	if t.Owner != nil {
+		owner := *t.Owner
+		owner.Roles = slices.Clone(t.Owner.Roles)
+		clone.Owner = &owner
+	}
+	clone.Scopes = make([]Scope, len(t.Scopes))
+	for i, scope := range t.Scopes {
+		scope.Actions = slices.Clone(scope.Actions)
+		clone.Scopes[i] = scope
+	}
+	if t.LastUsedAt != nil {
+		lastUsed := *t.LastUsedAt
+		clone.LastUsedAt = &lastUsed
+	}
+	return &clone
+}
+
+// Hash возвращает хеш значения токена, по которому он хранится
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Store хранит токены
+type Store interface {
+	Save(ctx context.Context, token *Token) error
+	Get(ctx context.Context, id string) (*Token, error)
+	FindByHash(ctx context.Context, hash string) (*Token, error)
+	List(ctx context.Context, userID string) ([]*Token, error) // пустой userID - все токены
+	Delete(ctx context.Context, id string) error
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// MemoryStore хранит токены в памяти
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]*Token
+}
+
+// NewMemoryStore создает хранилище токенов в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]*Token),
+	}
+}
+
+// Save сохраняет копию токена
+func (s *MemoryStore) Save(ctx context.Context, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[token.ID] = token.clone()
+	return nil
+}
+
+// Get возвращает копию токена по ID
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return token.clone(), nil
+}
+
+// FindByHash возвращает копию токена по хешу значения
+func (s *MemoryStore) FindByHash(ctx context.Context, hash string) (*Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, token := range s.items {
+		if token.Hash == hash {
+			return token.clone(), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// List возвращает токены пользователя в порядке выпуска
+func (s *MemoryStore) List(ctx context.Context, userID string) ([]*Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]*Token, 0)
+	for _, token := range s.items {
+		if userID == "" || token.Owner.ID == userID {
+			items = append(items, token.clone())
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+// Delete удаляет токен
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.items, id)
+	return nil
+}
+
+// Touch отмечает время последнего использования токена
+func (s *MemoryStore) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	token.LastUsedAt = &usedAt
+	return nil
+}
+
+// ExportSubject возвращает токены субъекта
+func (s *MemoryStore) ExportSubject(ctx context.Context, subject string) ([]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]interface{}, 0)
+	for _, token := range s.items {
+		if privacy.Matches(token.Owner.ID, subject) {
+			records = append(records, token.clone())
+		}
+	}
+	return records, nil
+}
+
+// EraseSubject отзывает токены субъекта
+func (s *MemoryStore) EraseSubject(ctx context.Context, subject string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for id, token := range s.items {
+		if privacy.Matches(token.Owner.ID, subject) {
+			delete(s.items, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Service выпускает, проверяет и отзывает токены
+type Service struct {
+	store Store
+	ids   id.Generator
+	mu    sync.Mutex
+}
+
+// New создает сервис токенов. По умолчанию токены хранятся в памяти
+func New(store Store) *Service {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Service{
+		store: store,
+		ids:   id.Default(),
+	}
+}
+
+// SetIDGenerator устанавливает генератор идентификаторов токенов
+func (s *Service) SetIDGenerator(g id.Generator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ids = g
+}
+
+// Store возвращает хранилище токенов
+func (s *Service) Store() Store {
+	return s.store
+}
+
+// Issue выпускает токен владельца owner на ttl (0 - DefaultTTL). Значение токена
+// возвращается только здесь: в хранилище остается его хеш
+func (s *Service) Issue(ctx context.Context, owner *auth.User, name string, scopes []Scope, ttl time.Duration) (*Token, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", ErrNoScopes
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if ttl > MaxTTL {
+		return nil, "", ErrTTL
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	secret := Prefix + base64.RawURLEncoding.EncodeToString(random)
+
+	s.mu.Lock()
+	ids := s.ids
+	s.mu.Unlock()
+
+	now := time.Now().UTC()
+	token := (&Token{
+		ID:        ids.NewID(),
+		Name:      strings.TrimSpace(name),
+		Hint:      secret[:len(Prefix)+4],
+		Owner:     &auth.User{ID: owner.ID, Name: owner.Name, Roles: owner.Roles, Timezone: owner.Timezone},
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		Hash:      Hash(secret),
+	}).clone()
+
+	if err := s.store.Save(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+// Authenticate находит токен по значению и отмечает его использование
+func (s *Service) Authenticate(ctx context.Context, secret string) (*Token, error) {
+	if !strings.HasPrefix(secret, Prefix) {
+		return nil, ErrInvalid
+	}
+
+	token, err := s.store.FindByHash(ctx, Hash(secret))
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !now.Before(token.ExpiresAt) {
+		return nil, ErrExpired
+	}
+	s.store.Touch(ctx, token.ID, now)
+	token.LastUsedAt = &now
+	return token, nil
+}
+
+// List возвращает токены пользователя (пустой userID - все токены)
+func (s *Service) List(ctx context.Context, userID string) ([]*Token, error) {
+	return s.store.List(ctx, userID)
+}
+
+// Revoke отзывает токен. Без manager отозвать можно только собственный токен
+func (s *Service) Revoke(ctx context.Context, id string, manager bool) (*Token, error) {
+	token, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manager {
+		user, ok := auth.UserFromContext(ctx)
+		if !ok || token.Owner.ID != user.ID {
+			return nil, ErrForbidden
+		}
+	}
+
+	if err := s.store.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
@@ -0,0 +1,137 @@
+// Package transaction открывает транзакцию БД на каждую отправку формы и передает
+// ее обработчику через контекст: при успехе обработчика транзакция фиксируется,
+// при ошибке, panic или пробном запуске - откатывается
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// Tx транзакция БД. Интерфейсу напрямую соответствует pgx.Tx,
+// для database/sql (и sqlx) используется SQL
+type Tx interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// Beginner открывает новую транзакцию
+type Beginner func(ctx context.Context) (Tx, error)
+
+// txKey ключ транзакции в контексте
+type txKey struct{}
+
+// WithTx возвращает контекст с транзакцией
+func WithTx(ctx context.Context, tx Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// FromContext возвращает транзакцию текущей отправки
+func FromContext(ctx context.Context) (Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(Tx)
+	return tx, ok
+}
+
+// Run выполняет fn в транзакции. Если в контексте уже есть транзакция, fn выполняется
+// в ней, а фиксацией управляет внешний вызов
+func Run(ctx context.Context, begin Beginner, fn func(ctx context.Context) error) (err error) {
+	if _, ok := FromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := begin(ctx)
+	if err != nil {
+		return fmt.Errorf("открытие транзакции: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(context.WithoutCancel(ctx))
+			panic(p)
+		}
+	}()
+
+	if err := fn(WithTx(ctx, tx)); err != nil {
+		if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("откат транзакции: %w", rbErr))
+		}
+		return err
+	}
+
+	// Пробный запуск не сохраняет изменения
+	if types.IsDryRun(ctx) {
+		if err := tx.Rollback(context.WithoutCancel(ctx)); err != nil {
+			return fmt.Errorf("откат транзакции: %w", err)
+		}
+		return nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("фиксация транзакции: %w", err)
+	}
+	return nil
+}
+
+// Handler оборачивает обработчик формы: каждая отправка выполняется в своей транзакции
+func Handler(begin Beginner, handler types.FormHandler) types.FormHandler {
+	return func(ctx context.Context, data map[string]interface{}) (interface{}, error) {
+		var result interface{}
+		err := Run(ctx, begin, func(ctx context.Context) error {
+			var err error
+			result, err = handler(ctx, data)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+// Batch возвращает types.TxFunc для пакетной отправки: весь пакет выполняется
+// в одной транзакции, а обработчики, обернутые Handler, используют ее
+func Batch(begin Beginner) types.TxFunc {
+	return func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return Run(ctx, begin, fn)
+	}
+}
+
+// sqlTx адаптирует *sql.Tx к интерфейсу Tx
+type sqlTx struct {
+	tx *sql.Tx
+}
+
+// Commit фиксирует транзакцию
+func (t sqlTx) Commit(context.Context) error {
+	return t.tx.Commit()
+}
+
+// Rollback откатывает транзакцию
+func (t sqlTx) Rollback(context.Context) error {
+	return t.tx.Rollback()
+}
+
+// SQL открывает транзакции database/sql с параметрами opts (может быть nil)
+func SQL(db *sql.DB, opts *sql.TxOptions) Beginner {
+	return func(ctx context.Context) (Tx, error) {
+		tx, err := db.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return sqlTx{tx: tx}, nil
+	}
+}
+
+// SQLFromContext возвращает *sql.Tx текущей отправки, открытую через SQL
+func SQLFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	wrapped, ok := tx.(sqlTx)
+	return wrapped.tx, ok
+}
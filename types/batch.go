@@ -0,0 +1,24 @@
+package types
+
+import "context"
+
+// TxFunc выполняет fn в транзакции: если fn возвращает ошибку, изменения откатываются
+type TxFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+
+// BatchItemResult результат обработки элемента пакетной отправки
+type BatchItemResult struct {
+	Index    int                 `json:"index"`
+	Success  bool                `json:"success"`
+	Data     interface{}         `json:"data,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Warnings []ValidationWarning `json:"warnings,omitempty"`
+}
+
+// BatchResult результат пакетной отправки формы
+type BatchResult struct {
+	Total      int               `json:"total"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	RolledBack bool              `json:"rolledBack,omitempty"` // транзакция пакета откатена
+	Items      []BatchItemResult `json:"items"`
+}
@@ -0,0 +1,33 @@
+package types
+
+// Цвета оформления по умолчанию
+const (
+	DefaultBackgroundColor  = "#ffffff"
+	DefaultTextColor        = "#212121"
+	DefaultPrimaryTextColor = "#ffffff"
+)
+
+// Branding оформление админки: логотип и цвета интерфейса.
+// Цвета задаются в CSS: #rrggbb, #rgb или rgb(r, g, b)
+type Branding struct {
+	Logo             string `json:"logo,omitempty"`             // URL логотипа
+	LogoAlt          string `json:"logoAlt,omitempty"`          // альтернативный текст логотипа для экранных дикторов
+	PrimaryColor     string `json:"primaryColor,omitempty"`     // цвет кнопок и ссылок
+	PrimaryTextColor string `json:"primaryTextColor,omitempty"` // цвет текста на кнопках, по умолчанию DefaultPrimaryTextColor
+	BackgroundColor  string `json:"backgroundColor,omitempty"`  // по умолчанию DefaultBackgroundColor
+	TextColor        string `json:"textColor,omitempty"`        // по умолчанию DefaultTextColor
+}
+
+// Colors возвращает цвета оформления с подставленными значениями по умолчанию
+func (b Branding) Colors() Branding {
+	if b.PrimaryTextColor == "" {
+		b.PrimaryTextColor = DefaultPrimaryTextColor
+	}
+	if b.BackgroundColor == "" {
+		b.BackgroundColor = DefaultBackgroundColor
+	}
+	if b.TextColor == "" {
+		b.TextColor = DefaultTextColor
+	}
+	return b
+}
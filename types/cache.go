@@ -0,0 +1,49 @@
+package types
+
+import (
+	"strconv"
+	"time"
+)
+
+// CachePolicy описывает кешируемость данных, возвращенных OnGet или обработчиком таблицы
+type CachePolicy struct {
+	// MaxAge время, в течение которого данные считаются актуальными
+	MaxAge time.Duration
+	// Public разрешает хранить ответ общим кешам (CDN, прокси).
+	// Игнорируется при VaryByUser
+	Public bool
+	// VaryByUser данные зависят от пользователя: ответ кешируется только браузером,
+	// а кеш роутера хранит отдельную копию для каждого пользователя
+	VaryByUser bool
+	// Shared сохраняет данные в кеше роутера: повторные запросы в течение MaxAge
+	// обслуживаются без вызова обработчика
+	Shared bool
+}
+
+// CacheControl возвращает значение заголовка Cache-Control для политики
+func (p CachePolicy) CacheControl() string {
+	if p.MaxAge <= 0 {
+		return "private, no-cache"
+	}
+
+	scope := "private"
+	if p.Public && !p.VaryByUser {
+		scope = "public"
+	}
+	return scope + ", max-age=" + strconv.Itoa(int(p.MaxAge/time.Second))
+}
+
+// CachedResult результат обработчика чтения с политикой кеширования.
+// Клиент получает данные Data, а роутер выставляет Cache-Control по Policy
+type CachedResult struct {
+	Data   interface{}
+	Policy CachePolicy
+}
+
+// Cacheable оборачивает результат OnGet или обработчика таблицы политикой кеширования
+func Cacheable(data interface{}, policy CachePolicy) *CachedResult {
+	return &CachedResult{
+		Data:   data,
+		Policy: policy,
+	}
+}
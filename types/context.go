@@ -0,0 +1,33 @@
+package types
+
+import "context"
+
+// dryRunKey ключ признака пробного запуска в контексте
+type dryRunKey struct{}
+
+// WithDryRun помечает контекст как пробный запуск
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun сообщает, что обработчик вызван в режиме пробного запуска
+// и не должен сохранять изменения
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// recordIDKey ключ ID записи в контексте
+type recordIDKey struct{}
+
+// WithRecordID добавляет в контекст ID записи, с которой работает запрос формы
+func WithRecordID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, recordIDKey{}, id)
+}
+
+// RecordID возвращает ID записи из параметра ?id= запроса формы (GET, POST, действия)
+// или пустую строку для новой записи
+func RecordID(ctx context.Context) string {
+	id, _ := ctx.Value(recordIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,76 @@
+package types
+
+import "time"
+
+// Статусы проверок самодиагностики
+const (
+	DiagnosticOK      = "ok"
+	DiagnosticWarning = "warning"
+	DiagnosticError   = "error"
+	DiagnosticSkipped = "skipped" // проверка неприменима (например, storage не подключен)
+)
+
+// Проверки самодиагностики
+const (
+	CheckStorage        = "storage"        // storage доступен
+	CheckMigrations     = "migrations"     // таблицы storage созданы
+	CheckDuplicateNames = "duplicateNames" // повторяющиеся имена форм и роутов
+	CheckHandlers       = "handlers"       // формы без обработчиков
+	CheckPatterns       = "patterns"       // некорректные правила валидации (регулярные выражения)
+	CheckSchemaSize     = "schemaSize"     // слишком большие схемы форм
+	CheckLint           = "lint"           // ошибки конфигурации форм (lint.Form)
+	CheckAccessibility  = "accessibility"  // доступность страниц и оформления (a11y)
+	CheckStartup        = "startup"        // хуки запуска выполнены без ошибок
+)
+
+// FormChecks проверки зарегистрированных форм в порядке отчета
+var FormChecks = []string{CheckDuplicateNames, CheckHandlers, CheckPatterns, CheckSchemaSize, CheckLint, CheckAccessibility}
+
+// DiagnosticIssue проблема, найденная проверкой
+type DiagnosticIssue struct {
+	Level   string `json:"level"`            // DiagnosticWarning или DiagnosticError
+	Target  string `json:"target,omitempty"` // ключ формы, имя роута и т.п.
+	Message string `json:"message"`
+}
+
+// DiagnosticCheck результат одной проверки
+type DiagnosticCheck struct {
+	Name     string            `json:"name"`
+	Status   string            `json:"status"`
+	Duration time.Duration     `json:"duration,omitempty"`
+	Issues   []DiagnosticIssue `json:"issues,omitempty"`
+}
+
+// DiagnosticsReport отчет самодиагностики админки
+type DiagnosticsReport struct {
+	Status    string            `json:"status"` // худший статус проверок
+	CheckedAt time.Time         `json:"checkedAt"`
+	Checks    []DiagnosticCheck `json:"checks"`
+}
+
+// NewDiagnosticCheck создает результат проверки; статус определяется найденными проблемами
+func NewDiagnosticCheck(name string, issues []DiagnosticIssue) DiagnosticCheck {
+	check := DiagnosticCheck{Name: name, Status: DiagnosticOK, Issues: issues}
+	for _, issue := range issues {
+		if issue.Level == DiagnosticError {
+			check.Status = DiagnosticError
+			break
+		}
+		check.Status = DiagnosticWarning
+	}
+	return check
+}
+
+// Add добавляет проверку в отчет и обновляет общий статус
+func (r *DiagnosticsReport) Add(check DiagnosticCheck) {
+	r.Checks = append(r.Checks, check)
+
+	switch {
+	case check.Status == DiagnosticError:
+		r.Status = DiagnosticError
+	case check.Status == DiagnosticWarning && r.Status != DiagnosticError:
+		r.Status = DiagnosticWarning
+	case r.Status == "":
+		r.Status = DiagnosticOK
+	}
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// dictionaryNamePattern допустимое имя справочника
+var dictionaryNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Dictionary справочник: редактируемый список значений (статусы, категории),
+// на который ссылаются поля выбора разных форм
+type Dictionary struct {
+	Name        string `json:"name"` // латиница в нижнем регистре, цифры, - и _
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// Validate проверяет имя и заголовок справочника
+func (d *Dictionary) Validate() error {
+	if !dictionaryNamePattern.MatchString(d.Name) {
+		return fmt.Errorf("справочник %q: некорректное имя", d.Name)
+	}
+	if d.Title == "" {
+		return fmt.Errorf("справочник %s: не задан заголовок", d.Name)
+	}
+	return nil
+}
+
+// DictionaryRef ссылка поля выбора на справочник: варианты выбора берутся
+// из справочника при каждом запросе схемы, а отправленное значение проверяется по нему
+type DictionaryRef struct {
+	Name string `json:"name"`
+}
+
+// Dictionaries возвращает имена справочников, на которые ссылаются поля формы
+func (f *Form) Dictionaries() []string {
+	var names []string
+	for _, field := range f.Fields {
+		if field.Dictionary != nil {
+			names = append(names, field.Dictionary.Name)
+		}
+	}
+	return names
+}
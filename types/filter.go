@@ -0,0 +1,38 @@
+package types
+
+// FilterParam параметр запроса таблицы с выражением фильтра,
+// например status in (paid,shipped) and total > 100
+const FilterParam = "filter"
+
+// FilterOperator оператор условия фильтра таблицы
+type FilterOperator string
+
+// Операторы условий фильтра
+const (
+	FilterEq       FilterOperator = "eq"
+	FilterNe       FilterOperator = "ne"
+	FilterGt       FilterOperator = "gt"
+	FilterGte      FilterOperator = "gte"
+	FilterLt       FilterOperator = "lt"
+	FilterLte      FilterOperator = "lte"
+	FilterIn       FilterOperator = "in"
+	FilterNotIn    FilterOperator = "not_in"
+	FilterContains FilterOperator = "contains"
+)
+
+// FilterCondition условие фильтра таблицы. Условия выражения объединяются через И.
+// Value приведено к типу колонки: float64 для number, bool для checkbox,
+// time.Time для date и datetime, значение опции для колонок с Options.
+// Для FilterIn и FilterNotIn Value - []interface{}
+type FilterCondition struct {
+	Field    string         `json:"field"`
+	Operator FilterOperator `json:"op"`
+	Value    interface{}    `json:"value"`
+}
+
+// FilterConditions возвращает условия разобранного выражения фильтра
+// из filters обработчика таблицы или nil, если фильтр не задан
+func FilterConditions(filters map[string]interface{}) []FilterCondition {
+	conditions, _ := filters[FilterParam].([]FilterCondition)
+	return conditions
+}
@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+// Viewer пользователь, у которого сейчас открыта форма или запись
+type Viewer struct {
+	UserID string    `json:"userId,omitempty"`
+	Name   string    `json:"name,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// FieldChange последнее изменение поля записи: кто и когда изменил значение
+type FieldChange struct {
+	UserID    string    `json:"userId,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+}
@@ -0,0 +1,57 @@
+package types
+
+import "fmt"
+
+// Quotas лимиты использования на субъект квоты - пользователя или арендатора.
+// Суточные счетчики сбрасываются в полночь UTC. Отправки сверх лимита получают
+// 429 Too Many Requests с Retry-After, загрузки и выгрузки - 413 Request Entity Too Large
+type Quotas struct {
+	SubmissionsPerDay int   `json:"submissionsPerDay,omitempty"` // успешных отправок каждой формы в сутки, 0 - без лимита
+	UploadBytesPerDay int64 `json:"uploadBytesPerDay,omitempty"` // байт тел запросов к формам в сутки, 0 - без лимита
+	ExportRows        int   `json:"exportRows,omitempty"`        // строк таблицы в одном запросе, 0 - без лимита
+
+	// Forms лимиты отправок в сутки по ключу формы, заменяют SubmissionsPerDay
+	Forms map[string]int `json:"forms,omitempty"`
+}
+
+// Validate проверяет лимиты
+func (q *Quotas) Validate() error {
+	if q.SubmissionsPerDay < 0 || q.UploadBytesPerDay < 0 || q.ExportRows < 0 {
+		return fmt.Errorf("квоты: отрицательный лимит")
+	}
+	for form, limit := range q.Forms {
+		if limit <= 0 {
+			return fmt.Errorf("квоты: некорректный лимит отправок формы %s", form)
+		}
+	}
+	return nil
+}
+
+// SubmissionLimit возвращает лимит отправок формы в сутки (0 - без лимита)
+func (q *Quotas) SubmissionLimit(form string) int {
+	if limit, ok := q.Forms[form]; ok {
+		return limit
+	}
+	return q.SubmissionsPerDay
+}
+
+// Виды квот в счетчиках отклоненных запросов
+const (
+	QuotaSubmissions = "submissions"
+	QuotaUploadBytes = "uploadBytes"
+	QuotaExportRows  = "exportRows"
+)
+
+// QuotaUsage использование квот субъектом за текущие сутки
+type QuotaUsage struct {
+	Submissions map[string]int `json:"submissions,omitempty"` // по ключу формы
+	UploadBytes int64          `json:"uploadBytes"`
+}
+
+// QuotaStats настройки квот, использование по субъектам и отклоненные запросы
+type QuotaStats struct {
+	Quotas   Quotas                `json:"quotas"`
+	Day      string                `json:"day"` // текущие сутки UTC, 2006-01-02
+	Usage    map[string]QuotaUsage `json:"usage"`
+	Rejected map[string]uint64     `json:"rejected"` // по виду квоты с начала работы
+}
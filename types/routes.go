@@ -0,0 +1,27 @@
+package types
+
+import "github.com/koteyye/go-formist/storage"
+
+// Данные ответов /api/routes. Все ответы передаются в конверте APIResponse:
+// GET /api/routes - Data: []*storage.Route, POST /api/routes - Data: *storage.Route
+
+// DeadLettersData данные ответа dead-letter очереди
+type DeadLettersData struct {
+	Items []storage.FailedWrite `json:"items"`
+}
+
+// ReplayData данные ответа повторной записи dead-letter очереди
+type ReplayData struct {
+	Replayed  int `json:"replayed"`
+	Remaining int `json:"remaining"`
+}
+
+// HealthData данные ответа проверки состояния
+type HealthData struct {
+	Status            string `json:"status"`
+	DeadLetters       int    `json:"deadLetters"`
+	FailedWritesTotal int    `json:"failedWritesTotal"`
+
+	// Storage метрики операций, если storage инструментирован
+	Storage map[string]storage.OperationStats `json:"storage,omitempty"`
+}
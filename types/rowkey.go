@@ -0,0 +1,84 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RowKeyField служебное поле строки таблицы с ее ключом.
+// Добавляется к строкам таблиц с RowKey и используется UI для выбора строк,
+// действий и изменения ячеек вместо колонки id
+const RowKeyField = "$key"
+
+// DefaultRowKeyColumn колонка с ключом строки, если RowKey не задан
+const DefaultRowKeyColumn = "id"
+
+// RowKey описывает идентичность строк таблицы: одну или несколько колонок
+// (составной ключ) либо функцию Func
+type RowKey struct {
+	Columns []string                                `json:"columns,omitempty"`
+	Func    func(row map[string]interface{}) string `json:"-"`
+}
+
+// Of возвращает ключ строки. Значения колонок составного ключа экранируются
+// и объединяются через запятую. Возвращает false, если колонки ключа нет в строке
+func (k *RowKey) Of(row map[string]interface{}) (string, bool) {
+	if k == nil {
+		value, ok := row[DefaultRowKeyColumn]
+		if !ok || value == nil {
+			return "", false
+		}
+		return fmt.Sprint(value), true
+	}
+
+	if k.Func != nil {
+		key := k.Func(row)
+		return key, key != ""
+	}
+
+	parts := make([]string, len(k.Columns))
+	for i, column := range k.Columns {
+		value, ok := row[column]
+		if !ok || value == nil {
+			return "", false
+		}
+		parts[i] = fmt.Sprint(value)
+	}
+	if len(parts) == 1 {
+		return parts[0], true
+	}
+	for i := range parts {
+		parts[i] = url.PathEscape(parts[i])
+	}
+	return strings.Join(parts, ","), true
+}
+
+// Parse разбирает ключ строки на значения колонок ключа.
+// Для ключа, заданного только функцией, возвращает ключ целиком под RowKeyField
+func (k *RowKey) Parse(key string) (map[string]string, error) {
+	if k == nil {
+		return map[string]string{DefaultRowKeyColumn: key}, nil
+	}
+	if len(k.Columns) == 0 {
+		return map[string]string{RowKeyField: key}, nil
+	}
+	if len(k.Columns) == 1 {
+		return map[string]string{k.Columns[0]: key}, nil
+	}
+
+	parts := strings.Split(key, ",")
+	if len(parts) != len(k.Columns) {
+		return nil, fmt.Errorf("ключ строки должен содержать %d значений через запятую", len(k.Columns))
+	}
+
+	values := make(map[string]string, len(parts))
+	for i, part := range parts {
+		value, err := url.PathUnescape(part)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное значение ключа строки %q: %w", part, err)
+		}
+		values[k.Columns[i]] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// SchemaVersionHeader заголовок версии схемы формы: админка возвращает его в ответах форм,
+// клиент передает полученное значение при отправке. Вместо заголовка можно передать
+// параметр SchemaVersionParam
+const SchemaVersionHeader = "X-Schema-Version"
+
+// SchemaVersionParam параметр запроса с версией схемы формы
+const SchemaVersionParam = "schema_version"
+
+// Виды изменений поля между версиями схемы
+const (
+	SchemaFieldAdded   = "added"
+	SchemaFieldRemoved = "removed"
+	SchemaFieldChanged = "changed"
+)
+
+// SchemaChange изменение поля формы после версии схемы клиента
+type SchemaChange struct {
+	Field  string `json:"field"`
+	Change string `json:"change"` // SchemaFieldAdded, SchemaFieldRemoved или SchemaFieldChanged
+}
+
+// SchemaConflict данные ответа 409 на отправку по устаревшей схеме формы
+type SchemaConflict struct {
+	Version       string         `json:"version"`       // текущая версия схемы
+	ClientVersion string         `json:"clientVersion"` // версия, переданная клиентом
+	Changes       []SchemaChange `json:"changes,omitempty"`
+
+	// Unknown - версия клиента неизвестна (например, получена до перезапуска), изменения не вычислены
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// fieldContract часть поля, от которой зависит прием отправки: надписи,
+// подсказки и оформление на версию схемы не влияют
+type fieldContract struct {
+	Type       FieldType        `json:"type"`
+	Required   bool             `json:"required,omitempty"`
+	Multiple   bool             `json:"multiple,omitempty"`
+	Options    []string         `json:"options,omitempty"`
+	Validation []ruleContract   `json:"validation,omitempty"`
+	Mask       string           `json:"mask,omitempty"`
+	Dictionary *DictionaryRef   `json:"dictionary,omitempty"`
+	Columns    []columnContract `json:"columns,omitempty"`
+}
+
+// ruleContract правило валидации без сообщения
+type ruleContract struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value,omitempty"`
+	Level string      `json:"level,omitempty"`
+}
+
+// columnContract колонка табличного поля
+type columnContract struct {
+	Key  string    `json:"key"`
+	Type FieldType `json:"type"`
+}
+
+// FieldVersions возвращает хеши полей формы по имени: тип, обязательность,
+// значения вариантов, правила валидации, маска, справочник и колонки таблицы
+func (f *Form) FieldVersions() map[string]string {
+	versions := make(map[string]string, len(f.Fields))
+	for _, field := range f.Fields {
+		contract := fieldContract{
+			Type:       field.Type,
+			Required:   field.Required,
+			Multiple:   field.Multiple,
+			Mask:       field.Mask,
+			Dictionary: field.Dictionary,
+		}
+		for _, option := range field.Options {
+			contract.Options = append(contract.Options, option.Value)
+		}
+		for _, rule := range field.Validation {
+			contract.Validation = append(contract.Validation, ruleContract{Type: rule.Type, Value: rule.Value, Level: rule.Level})
+		}
+		if field.TableConfig != nil {
+			for _, column := range field.TableConfig.Columns {
+				contract.Columns = append(contract.Columns, columnContract{Key: column.Key, Type: column.Type})
+			}
+		}
+		versions[field.Name] = hashJSON(contract)
+	}
+	return versions
+}
+
+// SchemaVersion возвращает версию схемы по хешам полей (см. Form.FieldVersions)
+func SchemaVersion(fields map[string]string) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([][2]string, len(names))
+	for i, name := range names {
+		pairs[i] = [2]string{name, fields[name]}
+	}
+	return hashJSON(pairs)
+}
+
+// SchemaChanges возвращает изменения полей между версиями, упорядоченные по имени поля
+func SchemaChanges(from, to map[string]string) []SchemaChange {
+	var changes []SchemaChange
+	for name, version := range to {
+		previous, ok := from[name]
+		switch {
+		case !ok:
+			changes = append(changes, SchemaChange{Field: name, Change: SchemaFieldAdded})
+		case previous != version:
+			changes = append(changes, SchemaChange{Field: name, Change: SchemaFieldChanged})
+		}
+	}
+	for name := range from {
+		if _, ok := to[name]; !ok {
+			changes = append(changes, SchemaChange{Field: name, Change: SchemaFieldRemoved})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// hashJSON возвращает короткий хеш JSON значения
+func hashJSON(value interface{}) string {
+	data, _ := json.Marshal(value)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LoadShedding ограничивает одновременные запросы ко всей админке, чтобы всплеск
+// трафика админки во время инцидента не отнял ресурсы основного приложения.
+// Запросы сверх лимита ждут в очереди; при заполненной очереди или по таймауту
+// они получают 503 Service Unavailable с заголовком Retry-After
+type LoadShedding struct {
+	MaxInFlight  int           `json:"maxInFlight"`            // одновременных запросов, 0 - без общего лимита
+	QueueSize    int           `json:"queueSize,omitempty"`    // запросов, ожидающих освобождения
+	QueueTimeout time.Duration `json:"queueTimeout,omitempty"` // максимальное ожидание в очереди, 0 - до отмены запроса
+	RetryAfter   time.Duration `json:"retryAfter,omitempty"`   // значение Retry-After, по умолчанию 1 секунда
+
+	// Routes лимиты по префиксу пути без префикса админки, например /admin/forms/report.
+	// Действует самый длинный подходящий префикс, общий лимит применяется дополнительно
+	Routes map[string]Concurrency `json:"routes,omitempty"`
+}
+
+// Validate проверяет лимиты и префиксы путей
+func (l *LoadShedding) Validate() error {
+	if l.MaxInFlight < 0 || l.QueueSize < 0 || l.QueueTimeout < 0 || l.RetryAfter < 0 {
+		return fmt.Errorf("ограничение нагрузки: отрицательный лимит")
+	}
+	for prefix, limit := range l.Routes {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("ограничение нагрузки: путь %q должен начинаться с /", prefix)
+		}
+		if limit.MaxConcurrent <= 0 || limit.QueueSize < 0 || limit.QueueTimeout < 0 {
+			return fmt.Errorf("ограничение нагрузки: некорректный лимит пути %s", prefix)
+		}
+	}
+	return nil
+}
+
+// RouteLoadStats состояние лимита
+type RouteLoadStats struct {
+	InFlight int    `json:"inFlight"`
+	Queued   int    `json:"queued"`
+	Shed     uint64 `json:"shed"` // отклонено с 503
+}
+
+// LoadSheddingStats состояние общего лимита и лимитов путей
+type LoadSheddingStats struct {
+	RouteLoadStats
+	Routes map[string]RouteLoadStats `json:"routes,omitempty"`
+}
@@ -0,0 +1,144 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Встроенные действия сочетаний клавиш. Кроме них сочетание может вызывать
+// дополнительное действие формы по имени (Actions.Custom) или ActionDuplicate
+const (
+	ShortcutSubmit = "submit" // отправка формы
+	ShortcutCancel = "cancel" // отмена редактирования
+	ShortcutSearch = "search" // фокус на поиске или фильтре таблицы
+)
+
+// Shortcut сочетание клавиш формы. Keys - модификаторы и клавиша через +:
+// Mod+Enter, Shift+Alt+N, /, Escape. Mod означает Ctrl, а на macOS - Cmd
+type Shortcut struct {
+	Keys   string `json:"keys"`
+	Action string `json:"action"`
+	Label  string `json:"label,omitempty"` // подсказка в справке по сочетаниям
+}
+
+// DefaultShortcuts сочетания, которые UI поддерживает одинаково во всех формах:
+// Mod+Enter отправляет форму, Escape отменяет редактирование, / переходит к поиску
+func DefaultShortcuts() []Shortcut {
+	return []Shortcut{
+		{Keys: "Mod+Enter", Action: ShortcutSubmit, Label: "Отправить"},
+		{Keys: "Escape", Action: ShortcutCancel, Label: "Отмена"},
+		{Keys: "/", Action: ShortcutSearch, Label: "Поиск"},
+	}
+}
+
+// shortcutModifiers модификаторы в каноническом порядке
+var shortcutModifiers = []string{"Mod", "Ctrl", "Alt", "Shift", "Meta"}
+
+// shortcutKeys именованные клавиши в каноническом написании
+var shortcutKeys = map[string]string{
+	"enter": "Enter", "escape": "Escape", "esc": "Escape", "tab": "Tab", "space": "Space",
+	"backspace": "Backspace", "delete": "Delete", "insert": "Insert",
+	"home": "Home", "end": "End", "pageup": "PageUp", "pagedown": "PageDown",
+	"arrowup": "ArrowUp", "arrowdown": "ArrowDown", "arrowleft": "ArrowLeft", "arrowright": "ArrowRight",
+	"up": "ArrowUp", "down": "ArrowDown", "left": "ArrowLeft", "right": "ArrowRight",
+}
+
+// NormalizeShortcut приводит сочетание к каноническому виду: модификаторы в порядке
+// Mod, Ctrl, Alt, Shift, Meta и клавиша последней ("enter+ctrl" -> "Ctrl+Enter")
+func NormalizeShortcut(keys string) (string, error) {
+	parts := strings.Split(keys, "+")
+	if strings.HasSuffix(keys, "++") || keys == "+" {
+		// Клавиша + сама по себе: Ctrl++
+		parts = append(parts[:len(parts)-2], "+")
+	}
+
+	used := make(map[string]bool)
+	var key string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", fmt.Errorf("сочетание %q: пустая клавиша", keys)
+		}
+
+		if modifier, ok := shortcutModifier(part); ok {
+			if used[modifier] {
+				return "", fmt.Errorf("сочетание %q: модификатор %s повторяется", keys, modifier)
+			}
+			used[modifier] = true
+			continue
+		}
+
+		if key != "" {
+			return "", fmt.Errorf("сочетание %q: больше одной клавиши", keys)
+		}
+		name, ok := shortcutKey(part)
+		if !ok {
+			return "", fmt.Errorf("сочетание %q: неизвестная клавиша %s", keys, part)
+		}
+		key = name
+	}
+	if key == "" {
+		return "", fmt.Errorf("сочетание %q: не задана клавиша", keys)
+	}
+
+	normalized := make([]string, 0, len(used)+1)
+	for _, modifier := range shortcutModifiers {
+		if used[modifier] {
+			normalized = append(normalized, modifier)
+		}
+	}
+	return strings.Join(append(normalized, key), "+"), nil
+}
+
+// shortcutModifier возвращает модификатор в каноническом написании
+func shortcutModifier(part string) (string, bool) {
+	for _, modifier := range shortcutModifiers {
+		if strings.EqualFold(part, modifier) {
+			return modifier, true
+		}
+	}
+	switch strings.ToLower(part) {
+	case "control":
+		return "Ctrl", true
+	case "cmd", "command":
+		return "Meta", true
+	case "option":
+		return "Alt", true
+	}
+	return "", false
+}
+
+// shortcutKey возвращает клавишу в каноническом написании: символ, F1-F12 или именованную клавишу
+func shortcutKey(part string) (string, bool) {
+	if len([]rune(part)) == 1 {
+		return strings.ToUpper(part), true
+	}
+	if name, ok := shortcutKeys[strings.ToLower(part)]; ok {
+		return name, true
+	}
+	if part[0] == 'F' || part[0] == 'f' {
+		if n, err := strconv.Atoi(part[1:]); err == nil && n >= 1 && n <= 12 {
+			return fmt.Sprintf("F%d", n), true
+		}
+	}
+	return "", false
+}
+
+// ShortcutActions возвращает действия, которые могут вызывать сочетания клавиш формы
+func (f *Form) ShortcutActions() map[string]bool {
+	actions := map[string]bool{
+		ShortcutSubmit: true,
+		ShortcutCancel: true,
+		ShortcutSearch: true,
+	}
+	if f.Actions != nil {
+		for _, action := range f.Actions.Custom {
+			actions[action.Name] = true
+		}
+		if f.Actions.Duplicate != nil {
+			actions[ActionDuplicate] = true
+		}
+	}
+	return actions
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Окна сводок SLO формы
+const (
+	SLOWindowShort = "5m"
+	SLOWindowLong  = "1h"
+	SLOWindowTotal = "total" // с запуска или перерегистрации формы
+)
+
+// DefaultSLOPeriod период SLO по умолчанию
+const DefaultSLOPeriod = 30 * 24 * time.Hour
+
+// SLO цели уровня обслуживания формы. Ошибкой считается ответ 5xx,
+// медленным - ответ дольше Latency
+type SLO struct {
+	Availability  float64       `json:"availability"`            // доля успешных запросов, например 0.999
+	Latency       time.Duration `json:"latency,omitempty"`       // порог быстрого ответа
+	LatencyTarget float64       `json:"latencyTarget,omitempty"` // доля запросов быстрее Latency, например 0.95
+	Period        time.Duration `json:"period,omitempty"`        // период бюджета ошибок, по умолчанию DefaultSLOPeriod
+}
+
+// Validate проверяет, что цели заданы долями от 0 до 1, а порог задержки положителен
+func (s *SLO) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.Availability <= 0 || s.Availability >= 1 {
+		return fmt.Errorf("SLO: доступность должна быть больше 0 и меньше 1")
+	}
+	if s.LatencyTarget < 0 || s.LatencyTarget >= 1 {
+		return fmt.Errorf("SLO: доля быстрых запросов должна быть от 0 до 1")
+	}
+	if s.LatencyTarget > 0 && s.Latency <= 0 {
+		return fmt.Errorf("SLO: не задан порог задержки")
+	}
+	if s.Latency < 0 || s.Period < 0 {
+		return fmt.Errorf("SLO: отрицательная длительность")
+	}
+	return nil
+}
+
+// SLOSummary сводка запросов формы за окно
+type SLOSummary struct {
+	Requests  uint64  `json:"requests"`
+	Errors    uint64  `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+	P50Ms     float64 `json:"p50Ms"` // оценки по гистограмме
+	P90Ms     float64 `json:"p90Ms"`
+	P99Ms     float64 `json:"p99Ms"`
+	SlowRate  float64 `json:"slowRate,omitempty"` // доля запросов дольше SLO.Latency
+
+	// BurnRate скорость расхода бюджета ошибок: 1 - бюджет расходуется ровно за период SLO
+	BurnRate float64 `json:"burnRate,omitempty"`
+}
+
+// FormSLOReport сводки запросов формы по окнам SLOWindowShort, SLOWindowLong и SLOWindowTotal
+type FormSLOReport struct {
+	Form    string                `json:"form"`
+	SLO     *SLO                  `json:"slo,omitempty"`
+	Windows map[string]SLOSummary `json:"windows"`
+}
@@ -0,0 +1,65 @@
+package types
+
+// Возможности формы в кратком описании
+const (
+	CapabilityRead     = "read"     // есть OnGet
+	CapabilitySubmit   = "submit"   // есть OnPost
+	CapabilityDryRun   = "dryRun"   // поддерживается ?dry_run=true
+	CapabilityApproval = "approval" // отправка требует согласования
+	CapabilityTables   = "tables"   // есть табличные поля с данными
+	CapabilityActions  = "actions"  // есть дополнительные действия
+	CapabilityPatch    = "patch"    // поддерживается частичное обновление записи
+)
+
+// FormSummary краткое описание формы для навигации и дашбордов, без схем
+type FormSummary struct {
+	Key          string   `json:"key"`
+	Name         string   `json:"name"`
+	Module       string   `json:"module,omitempty"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description,omitempty"`
+	Icon         string   `json:"icon,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// Summary возвращает краткое описание формы
+func (f *Form) Summary() FormSummary {
+	summary := FormSummary{
+		Key:          f.Key(),
+		Name:         f.Name,
+		Module:       f.Module,
+		Title:        f.Title,
+		Description:  f.Description,
+		Icon:         f.Icon,
+		Tags:         append([]string(nil), f.Tags...),
+		Capabilities: make([]string, 0, 7),
+	}
+
+	if f.OnGet != nil {
+		summary.Capabilities = append(summary.Capabilities, CapabilityRead)
+	}
+	if f.OnPost != nil {
+		summary.Capabilities = append(summary.Capabilities, CapabilitySubmit)
+	}
+	if f.OnDryRun != nil || f.OnPost != nil {
+		summary.Capabilities = append(summary.Capabilities, CapabilityDryRun)
+	}
+	if f.Approval != nil {
+		summary.Capabilities = append(summary.Capabilities, CapabilityApproval)
+	}
+	for _, field := range f.Fields {
+		if field.TableConfig != nil && field.TableConfig.OnGet != nil {
+			summary.Capabilities = append(summary.Capabilities, CapabilityTables)
+			break
+		}
+	}
+	if f.Actions != nil && len(f.Actions.Custom) > 0 {
+		summary.Capabilities = append(summary.Capabilities, CapabilityActions)
+	}
+	if f.Patch != nil && f.Approval == nil {
+		summary.Capabilities = append(summary.Capabilities, CapabilityPatch)
+	}
+
+	return summary
+}
@@ -1,6 +1,12 @@
 package types
 
-import "net/http"
+import (
+	"context"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+)
 
 // FieldType представляет тип поля формы
 type FieldType string
@@ -17,6 +23,7 @@ const (
 	FieldTypeCheckbox FieldType = "checkbox"
 	FieldTypeDate     FieldType = "date"
 	FieldTypeTime     FieldType = "time"
+	FieldTypeDateTime FieldType = "datetime"
 	FieldTypeFile     FieldType = "file"
 	FieldTypeHidden   FieldType = "hidden"
 	FieldTypeTable    FieldType = "table"
@@ -24,9 +31,33 @@ const (
 
 // SelectOption представляет опцию для select/radio полей
 type SelectOption struct {
-	Value    string `json:"value"`
-	Label    string `json:"label"`
-	Disabled bool   `json:"disabled,omitempty"`
+	Value    string            `json:"value"`
+	Label    string            `json:"label"`
+	Labels   map[string]string `json:"labels,omitempty"` // переводы Label по коду языка (en, de)
+	Disabled bool              `json:"disabled,omitempty"`
+}
+
+// WithLabel возвращает опцию с переводом названия на язык tag
+func (o SelectOption) WithLabel(tag, label string) SelectOption {
+	labels := make(map[string]string, len(o.Labels)+1)
+	maps.Copy(labels, o.Labels)
+	labels[strings.ToLower(tag)] = label
+	o.Labels = labels
+	return o
+}
+
+// LocalizedLabel возвращает название на языке tag (ru, en-US) или Label, если перевода нет
+func (o SelectOption) LocalizedLabel(tag string) string {
+	tag = strings.ToLower(tag)
+	if label, ok := o.Labels[tag]; ok {
+		return label
+	}
+	if i := strings.IndexAny(tag, "-_"); i > 0 {
+		if label, ok := o.Labels[tag[:i]]; ok {
+			return label
+		}
+	}
+	return o.Label
 }
 
 // ValidationRule представляет правило валидации
@@ -34,58 +65,158 @@ type ValidationRule struct {
 	Type    string      `json:"type"`
 	Value   interface{} `json:"value,omitempty"`
 	Message string      `json:"message"`
+	Level   string      `json:"level,omitempty"` // ValidationLevelWarning - не блокирует отправку
+}
+
+// Уровни правил валидации
+const (
+	ValidationLevelError   = "error" // по умолчанию
+	ValidationLevelWarning = "warning"
+)
+
+// IsWarning сообщает, что правило выдает предупреждение, а не отклоняет отправку
+func (r ValidationRule) IsWarning() bool {
+	return r.Level == ValidationLevelWarning
+}
+
+// ValidationWarning описывает сработавшее предупреждающее правило
+type ValidationWarning struct {
+	Field   string `json:"field"`
+	Label   string `json:"label"`
+	Message string `json:"message"`
 }
 
 // TableColumn представляет колонку таблицы
 type TableColumn struct {
-	Key        string         `json:"key"`
-	Title      string         `json:"title"`
-	Type       FieldType      `json:"type"`
-	Sortable   bool           `json:"sortable,omitempty"`
-	Filterable bool           `json:"filterable,omitempty"`
-	Width      string         `json:"width,omitempty"`
-	Align      string         `json:"align,omitempty"`
-	Options    []SelectOption `json:"options,omitempty"`
-	Multiple   bool           `json:"multiple,omitempty"`
+	Key        string           `json:"key"`
+	Title      string           `json:"title"`
+	Type       FieldType        `json:"type"`
+	Sortable   bool             `json:"sortable,omitempty"`
+	Filterable bool             `json:"filterable,omitempty"`
+	Width      string           `json:"width,omitempty"`
+	Align      string           `json:"align,omitempty"`
+	Options    []SelectOption   `json:"options,omitempty"`
+	Multiple   bool             `json:"multiple,omitempty"`
+	Link       *Link            `json:"link,omitempty"` // ячейка ссылается на запись другой формы
+	Format     *ColumnFormat    `json:"format,omitempty"`
+	Required   bool             `json:"required,omitempty"`   // ячейку нельзя очистить при редактировании
+	Validation []ValidationRule `json:"validation,omitempty"` // правила проверки ячейки при редактировании
+}
+
+// ColumnFormatKind вид форматирования значений колонки
+type ColumnFormatKind string
+
+// Виды форматирования значений колонки
+const (
+	ColumnFormatCurrency ColumnFormatKind = "currency" // денежная сумма в валюте Currency
+	ColumnFormatPercent  ColumnFormatKind = "percent"  // доля: 0.15 отображается как 15%
+	ColumnFormatBytes    ColumnFormatKind = "bytes"    // размер в байтах: 1536 отображается как 1.5 KiB
+	ColumnFormatDateTime ColumnFormatKind = "datetime" // дата и время по шаблону Pattern
+	ColumnFormatBadge    ColumnFormatKind = "badge"    // бейдж с цветом из Badges по значению
+	ColumnFormatLink     ColumnFormatKind = "link"     // ссылка по шаблону Link
+)
+
+// ColumnFormat подсказка отображения значений колонки, одинаковая для всех фронтендов
+type ColumnFormat struct {
+	Kind     ColumnFormatKind  `json:"kind"`
+	Currency string            `json:"currency,omitempty"` // код валюты ISO 4217, например RUB
+	Decimals *int              `json:"decimals,omitempty"` // число знаков после запятой
+	Pattern  string            `json:"pattern,omitempty"`  // шаблон даты в нотации Unicode, например dd.MM.yyyy HH:mm
+	Badges   map[string]string `json:"badges,omitempty"`   // цвет бейджа по значению: success, warning, danger, info, neutral или CSS цвет
+	Link     string            `json:"link,omitempty"`     // шаблон ссылки с {value} и {ключ колонки}, например /orders/{id}
+}
+
+// Link описывает ссылку на запись другой формы.
+// UI открывает форму назначения с ID записи в параметре Param
+type Link struct {
+	Form    string `json:"form"`              // ключ формы назначения, например billing/invoices
+	IDField string `json:"idField,omitempty"` // колонка строки с ID записи, по умолчанию значение самой колонки
+	Param   string `json:"param,omitempty"`   // параметр ссылки с ID, по умолчанию LinkParamDefault
+}
+
+// LinkParamDefault параметр ссылки с ID записи по умолчанию
+const LinkParamDefault = "id"
+
+// ResolvedLink ссылка на запись, проверенная для текущего пользователя
+type ResolvedLink struct {
+	Form  string `json:"form"`
+	ID    string `json:"id"`
+	Path  string `json:"path"`
+	Title string `json:"title"`
+	Icon  string `json:"icon,omitempty"`
 }
 
 // TableData представляет данные таблицы
 type TableData struct {
-	Columns []TableColumn            `json:"columns"`
-	Rows    []map[string]interface{} `json:"rows"`
-	Total   int                      `json:"total"`
-	Page    int                      `json:"page"`
-	Limit   int                      `json:"limit"`
+	Columns []TableColumn                `json:"columns"`
+	Rows    []map[string]interface{}     `json:"rows"`
+	Total   int                          `json:"total"`
+	Page    int                          `json:"page"`
+	Limit   int                          `json:"limit"`
+	Labels  map[string]map[string]string `json:"labels,omitempty"` // названия значений колонок выбора при ?labels=map
 }
 
 // TableConfig представляет конфигурацию таблицы
 type TableConfig struct {
-	Columns    []TableColumn `json:"columns"`
-	Pagination bool          `json:"pagination"`
-	PageSize   int           `json:"pageSize"`
-	Sortable   bool          `json:"sortable"`
-	Filterable bool          `json:"filterable"`
-	Selectable bool          `json:"selectable"`
-	Editable   bool          `json:"editable"`
-	OnGet      TableHandler  `json:"-"`
+	Columns     []TableColumn    `json:"columns"`
+	Pagination  bool             `json:"pagination"`
+	PageSize    int              `json:"pageSize"`
+	Sortable    bool             `json:"sortable"`
+	Filterable  bool             `json:"filterable"`
+	Selectable  bool             `json:"selectable"`
+	Editable    bool             `json:"editable"`
+	RowKey      *RowKey          `json:"rowKey,omitempty"` // идентичность строк, по умолчанию колонка id
+	OnGet       TableHandler     `json:"-"`
+	OnRowUpdate RowUpdateHandler `json:"-"` // сохранение ячейки, проверенной по колонке
 }
 
 // Field представляет поле формы
 type Field struct {
-	Name         string                 `json:"name"`
-	Type         FieldType              `json:"type"`
-	Label        string                 `json:"label"`
-	Required     bool                   `json:"required"`
-	Placeholder  string                 `json:"placeholder,omitempty"`
-	DefaultValue interface{}            `json:"defaultValue,omitempty"`
-	Options      []SelectOption         `json:"options,omitempty"`
-	Multiple     bool                   `json:"multiple,omitempty"`
-	Validation   []ValidationRule       `json:"validation,omitempty"`
-	Group        string                 `json:"group,omitempty"`
-	Description  string                 `json:"description,omitempty"`
-	Disabled     bool                   `json:"disabled,omitempty"`
-	Config       map[string]interface{} `json:"config,omitempty"`
-	TableConfig  *TableConfig           `json:"tableConfig,omitempty"`
+	Name          string                 `json:"name"`
+	Type          FieldType              `json:"type"`
+	Label         string                 `json:"label"`
+	Required      bool                   `json:"required"`
+	Placeholder   string                 `json:"placeholder,omitempty"`
+	DefaultValue  interface{}            `json:"defaultValue,omitempty"`
+	Options       []SelectOption         `json:"options,omitempty"`
+	Multiple      bool                   `json:"multiple,omitempty"`
+	Validation    []ValidationRule       `json:"validation,omitempty"`
+	Group         string                 `json:"group,omitempty"`
+	Description   string                 `json:"description,omitempty"`
+	Help          string                 `json:"help,omitempty"`    // подробная справка под полем, поддерживает markdown
+	Tooltip       string                 `json:"tooltip,omitempty"` // краткая подсказка при наведении
+	Examples      []string               `json:"examples,omitempty"`
+	Disabled      bool                   `json:"disabled,omitempty"`
+	Config        map[string]interface{} `json:"config,omitempty"`
+	TableConfig   *TableConfig           `json:"tableConfig,omitempty"`
+	Sensitive     bool                   `json:"sensitive,omitempty"`    // значение не записывается в журналы
+	Mask          string                 `json:"mask,omitempty"`         // маска ввода, например MaskPhoneRU
+	Autocomplete  string                 `json:"autocomplete,omitempty"` // токены HTML autocomplete, например "email" или "shipping postal-code"
+	Accessibility *Accessibility         `json:"accessibility,omitempty"`
+	Suggest       SuggestHandler         `json:"-"`                    // подсказки значений при вводе (typeahead)
+	Link          *Link                  `json:"link,omitempty"`       // значение поля - ID записи другой формы
+	Dictionary    *DictionaryRef         `json:"dictionary,omitempty"` // варианты выбора из справочника
+}
+
+// Accessibility переопределяет метаданные доступности поля
+type Accessibility struct {
+	AriaLabel       string `json:"ariaLabel,omitempty"`       // по умолчанию используется Label
+	AriaDescribedBy string `json:"ariaDescribedBy,omitempty"` // id элемента с описанием
+	TabIndex        int    `json:"tabIndex,omitempty"`        // порядок обхода клавиатурой, -1 - исключить
+}
+
+// Маски ввода: 9 - цифра, a - буква, * - цифра или буква, \ экранирует символ
+const (
+	MaskPhoneRU      = "+7 (999) 999-99-99"
+	MaskPostalCodeRU = "999999"
+	MaskINNLegal     = "9999999999"   // ИНН юридического лица
+	MaskINNPerson    = "999999999999" // ИНН физического лица
+	MaskSNILS        = "999-999-999 99"
+)
+
+// IsSensitive проверяет, является ли поле чувствительным (явно или паролем)
+func (f Field) IsSensitive() bool {
+	return f.Sensitive || f.Type == FieldTypePassword
 }
 
 // FieldGroup представляет группу полей
@@ -96,48 +227,486 @@ type FieldGroup struct {
 	Fields      []string `json:"fields"`
 }
 
-// Form представляет форму
+// Form представляет форму.
+// После регистрации в админке форма копируется: изменения исходного значения
+// не влияют на обслуживаемую форму, а зарегистрированная копия не изменяется
 type Form struct {
-	Name        string       `json:"name"`
-	Title       string       `json:"title"`
-	Description string       `json:"description,omitempty"`
-	Fields      []Field      `json:"fields"`
-	Groups      []FieldGroup `json:"groups,omitempty"`
-	OnPost      FormHandler  `json:"-"`
-	OnGet       GetHandler   `json:"-"`
+	Name            string       `json:"name"`
+	Module          string       `json:"module,omitempty"`
+	Title           string       `json:"title"`
+	Description     string       `json:"description,omitempty"`
+	Icon            string       `json:"icon,omitempty"`
+	Tags            []string     `json:"tags,omitempty"` // метки для фильтрации в навигации
+	Fields          []Field      `json:"fields"`
+	Groups          []FieldGroup `json:"groups,omitempty"`
+	Approval        *Approval    `json:"approval,omitempty"`
+	Concurrency     *Concurrency `json:"concurrency,omitempty"`
+	SLO             *SLO         `json:"slo,omitempty"`
+	Locale          string       `json:"locale,omitempty"`          // формат ввода чисел и дат, например ru
+	ConfirmWarnings bool         `json:"confirmWarnings,omitempty"` // отправка с предупреждениями требует ?confirm_warnings=true
+	Prefill         []string     `json:"prefill,omitempty"`         // поля, заполняемые из ссылки параметрами ?prefill[поле]=значение
+	Shortcuts       []Shortcut   `json:"shortcuts,omitempty"`       // сочетания клавиш формы
+	Provenance      bool         `json:"provenance,omitempty"`      // учет автора и времени последнего изменения каждого поля записи
+	Coalesce        bool         `json:"-"`                         // одновременные одинаковые OnGet и TableHandler выполняются один раз
+	Actions         *Actions     `json:"actions,omitempty"`
+	Meta            *Meta        `json:"meta,omitempty"`
+	OnPost          FormHandler  `json:"-"`
+	OnGet           GetHandler   `json:"-"`
+	OnDryRun        FormHandler  `json:"-"` // пробный запуск без сохранения изменений
+	Patch           *Patch       `json:"-"` // частичное обновление записи PATCH /admin/forms/{name}/{id}
+	BatchTx         TxFunc       `json:"-"` // транзакция пакетной отправки: все элементы или ни одного
+
+	// PartTimeout ограничивает каждую часть GET формы (схема, OnGet, таблицы),
+	// которые выполняются одновременно. 0 - без отдельного ограничения
+	PartTimeout time.Duration `json:"-"`
+}
+
+// Meta описывает метаданные ссылки на форму или страницу (заголовок вкладки, превью).
+// Title и Description могут содержать переменные, в том числе {{query.<параметр>}} из ссылки
+type Meta struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
+
+// ResolvedMeta метаданные, вычисленные для конкретной ссылки
+type ResolvedMeta struct {
+	Type        string `json:"type"` // MenuItemForm или MenuItemPage
+	Key         string `json:"key"`
+	Path        string `json:"path"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+}
+
+// Key возвращает уникальный ключ формы с учетом модуля (например, billing/users)
+func (f *Form) Key() string {
+	if f.Module == "" {
+		return f.Name
+	}
+	return f.Module + "/" + f.Name
+}
+
+// Action возвращает дополнительное действие формы по имени
+func (f *Form) Action(name string) (Action, bool) {
+	if f.Actions == nil {
+		return Action{}, false
+	}
+	for _, action := range f.Actions.Custom {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// Actions настраивает кнопки формы
+type Actions struct {
+	SubmitLabel   string     `json:"submitLabel,omitempty"`
+	CancelLabel   string     `json:"cancelLabel,omitempty"`
+	ConfirmSubmit string     `json:"confirmSubmit,omitempty"` // текст подтверждения перед отправкой
+	Custom        []Action   `json:"custom,omitempty"`
+	Duplicate     *Duplicate `json:"duplicate,omitempty"` // встроенное копирование записи
+}
+
+// ActionDuplicate имя встроенного действия копирования записи
+const ActionDuplicate = "duplicate"
+
+// RecordHandler возвращает запись формы по ID
+type RecordHandler func(ctx context.Context, id string) (map[string]interface{}, error)
+
+// Duplicate настраивает копирование записи: запись загружается через Load,
+// поля Exclude (ID, уникальные номера) отбрасываются, а остальные значения
+// открываются в форме создания Form как новая запись
+type Duplicate struct {
+	Label   string        `json:"label,omitempty"`
+	Form    string        `json:"form,omitempty"`    // ключ формы создания, по умолчанию та же форма
+	Exclude []string      `json:"exclude,omitempty"` // поля, не копируемые в новую запись
+	Load    RecordHandler `json:"-"`
+}
+
+// PatchHandler сохраняет изменения записи id: только затронутые поля, удаленное поле - nil
+type PatchHandler func(ctx context.Context, id string, changes map[string]interface{}) (interface{}, error)
+
+// Patch настраивает частичное обновление записи JSON Patch или JSON Merge Patch.
+// С Load патч применяется к загруженной записи и поддерживает все операции RFC 6902,
+// без Load - только изменение полей верхнего уровня
+type Patch struct {
+	Load    RecordHandler
+	Handler PatchHandler
+}
+
+// DuplicateResult копия записи для открытия в форме создания
+type DuplicateResult struct {
+	Form     string                 `json:"form"`
+	Path     string                 `json:"path"`
+	Data     map[string]interface{} `json:"data"`
+	Timezone string                 `json:"timezone,omitempty"` // часовой пояс значений datetime в Data
+}
+
+// Action описывает дополнительную кнопку формы, вызывающую именованный обработчик.
+// Согласование формы (Approval) к действиям не применяется
+type Action struct {
+	Name     string      `json:"name"`
+	Label    string      `json:"label"`
+	Confirm  string      `json:"confirm,omitempty"`  // текст подтверждения перед вызовом
+	Validate bool        `json:"validate,omitempty"` // проверять данные формы перед вызовом
+	Handler  FormHandler `json:"-"`
+}
+
+// Approval описывает согласование отправки формы.
+// OnPost выполняется только после одобрения пользователем с одной из ролей
+type Approval struct {
+	Roles []string `json:"roles"`
+}
+
+// Concurrency ограничивает одновременные вызовы OnPost формы, чтобы тяжелая форма
+// не исчерпала ресурсы остальной админки. Запросы сверх очереди получают 503
+type Concurrency struct {
+	MaxConcurrent int           `json:"maxConcurrent"`          // одновременных вызовов OnPost
+	QueueSize     int           `json:"queueSize,omitempty"`    // запросов, ожидающих освобождения
+	QueueTimeout  time.Duration `json:"queueTimeout,omitempty"` // максимальное ожидание в очереди, 0 - до отмены запроса
+}
+
+// Module представляет модуль (пространство имен) форм
+type Module struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Icon        string           `json:"icon,omitempty"`
+	Roles       []string         `json:"roles,omitempty"` // роли с доступом к модулю, пусто - без ограничений
+	Middlewares []MiddlewareFunc `json:"-"`
+}
+
+// ModuleInfo представляет модуль в конфигурации для группировки меню
+type ModuleInfo struct {
+	Title string            `json:"title"`
+	Icon  string            `json:"icon,omitempty"`
+	Forms map[string]string `json:"forms"`
+}
+
+// Типы пунктов меню
+const (
+	MenuItemForm   = "form"
+	MenuItemPage   = "page"
+	MenuItemModule = "module"
+)
+
+// MenuItem представляет пункт меню навигации
+type MenuItem struct {
+	Type   string `json:"type"`
+	Key    string `json:"key"`
+	Title  string `json:"title"`
+	Icon   string `json:"icon,omitempty"` // имя из набора иконок или URL изображения
+	Module string `json:"module,omitempty"`
+	Remote string `json:"remote,omitempty"` // имя удаленной админки (федерация)
+	URL    string `json:"url,omitempty"`    // адрес пункта удаленной админки
 }
 
 // Page представляет кастомную страницу
 type Page struct {
-	Name    string             `json:"name"`
-	Title   string             `json:"title"`
-	Content string             `json:"content,omitempty"`
-	Handler http.HandlerFunc   `json:"-"`
+	Name    string           `json:"name"`
+	Title   string           `json:"title"`
+	Icon    string           `json:"icon,omitempty"`
+	Meta    *Meta            `json:"meta,omitempty"`
+	Content string           `json:"content,omitempty"`
+	Handler http.HandlerFunc `json:"-"`
+	Proxy   *PageProxy       `json:"-"`
+}
+
+// PageProxy описывает страницу, проксирующую внешний инструмент через /admin/proxy/{name}/.
+// Учетные данные админки (Authorization, X-API-Key и, без ForwardCookies, Cookie) не передаются
+type PageProxy struct {
+	Upstream              string            // адрес инструмента, например http://legacy.internal/tools
+	RequestHeaders        map[string]string // заголовки, добавляемые к запросам
+	RemoveResponseHeaders []string          // заголовки ответа, которые нужно удалить (например, X-Frame-Options)
+	ForwardCookies        bool              // передавать Cookie и принимать Set-Cookie инструмента (его сессия)
+
+	// Authorize, если задан, подставляет учетные данные инструмента в каждый запрос
+	Authorize func(req *http.Request) error
 }
 
-// Обработчики
-type FormHandler func(data map[string]interface{}) (interface{}, error)
-type GetHandler func() (interface{}, error)
-type TableHandler func(page, limit int, filters map[string]interface{}) (TableData, error)
+// Обработчики. Контекст отменяется, когда клиент прерывает запрос
+type FormHandler func(ctx context.Context, data map[string]interface{}) (interface{}, error)
+type GetHandler func(ctx context.Context) (interface{}, error)
+type TableHandler func(ctx context.Context, page, limit int, filters map[string]interface{}) (TableData, error)
+type RowUpdateHandler func(ctx context.Context, id, key string, value interface{}) (interface{}, error)
+type SuggestHandler func(ctx context.Context, prefix string) []string
 type MiddlewareFunc func(http.Handler) http.Handler
 
 // API Response структуры
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Message string      `json:"message,omitempty"`
+	Success bool          `json:"success"`
+	Data    interface{}   `json:"data,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Message string        `json:"message,omitempty"`
+	Meta    *ResponseMeta `json:"meta,omitempty"`
+}
+
+// ResponseMeta содержит служебные данные ответа для UI
+type ResponseMeta struct {
+	Undo     *UndoInfo           `json:"undo,omitempty"`
+	Warnings []ValidationWarning `json:"warnings,omitempty"`
+	Viewers  []Viewer            `json:"viewers,omitempty"` // другие пользователи, открывшие форму или запись
+
+	Provenance  map[string]FieldChange `json:"provenance,omitempty"`  // последние изменения полей записи
+	Consistency string                 `json:"consistency,omitempty"` // токен согласованности изменения для чтения списков
 }
 
 type ConfigResponse struct {
-	Title       string            `json:"title"`
-	AuthEnabled bool              `json:"authEnabled"`
-	Forms       map[string]string `json:"forms"`
-	Pages       map[string]string `json:"pages"`
+	Title       string                `json:"title"`
+	BasePath    string                `json:"basePath,omitempty"`
+	AuthEnabled bool                  `json:"authEnabled"`
+	Forms       map[string]string     `json:"forms"`
+	Pages       map[string]string     `json:"pages"`
+	Modules     map[string]ModuleInfo `json:"modules,omitempty"`
+	Menu        []MenuItem            `json:"menu"`
+	Environment *Environment          `json:"environment,omitempty"`
+	Branding    *Branding             `json:"branding,omitempty"`
+	ReadOnly    *ReadOnlyInfo         `json:"readOnly,omitempty"`
+	Maintenance *Maintenance          `json:"maintenance,omitempty"`
+	Timezone    string                `json:"timezone,omitempty"` // часовой пояс отображения для пользователя
+	Demo        bool                  `json:"demo,omitempty"`     // данные форм и таблиц сгенерированы
+	Remotes     []RemoteInfo          `json:"remotes,omitempty"`  // удаленные админки федерации
+
+	// Capabilities возможности сервера, например транспорт событий
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+}
+
+// Capabilities возможности сервера, по которым UI выбирает способ работы
+type Capabilities struct {
+	Events          string   `json:"events"`          // транспорт событий: sse или longpoll
+	EventTransports []string `json:"eventTransports"` // все поддерживаемые транспорты
+}
+
+// Event событие канала админки: то же, что event и data в Server-Sent Events
+type Event struct {
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+}
+
+// EventPoll ответ long polling: события после курсора запроса.
+// Session и Cursor передаются в следующий запрос
+type EventPoll struct {
+	Session string  `json:"session"`
+	Cursor  uint64  `json:"cursor"`
+	Events  []Event `json:"events"`
+}
+
+// RemoteInfo описывает удаленную админку федерации для UI
+type RemoteInfo struct {
+	Name      string    `json:"name"`
+	Title     string    `json:"title,omitempty"`
+	URL       string    `json:"url"`
+	Mode      string    `json:"mode"`      // link или proxy
+	Available bool      `json:"available"` // последняя загрузка каталога успешна
+	FetchedAt time.Time `json:"fetchedAt,omitempty"`
+}
+
+// Environment описывает окружение админки для баннеров UI
+type Environment struct {
+	Name   string `json:"name"`
+	Label  string `json:"label,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Banner string `json:"banner,omitempty"`
+}
+
+// EnvironmentOptions настройки окружения
+type EnvironmentOptions struct {
+	Label    string // название окружения для UI, например "Продакшен"
+	Color    string // цвет баннера, например "#d32f2f"
+	Banner   string // текст баннера
+	ReadOnly bool   // включить режим только для чтения
+}
+
+// Maintenance описывает режим обслуживания.
+// Пока он включен, админка отвечает 503 всем, кроме пользователей с ролями AllowedRoles
+type Maintenance struct {
+	Enabled      bool      `json:"enabled"`
+	Message      string    `json:"message,omitempty"`
+	AllowedRoles []string  `json:"allowedRoles,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt,omitempty"`
+}
+
+// FormDebug отладочный режим формы: до ExpiresAt тела запросов и ответов записываются в журнал
+type FormDebug struct {
+	Form      string    `json:"form"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	EnabledBy string    `json:"enabledBy,omitempty"`
+}
+
+// ReadOnlyInfo сообщает UI о включенном режиме только для чтения
+type ReadOnlyInfo struct {
+	Message string `json:"message,omitempty"`
 }
 
 type FormResponse struct {
-	Schema   interface{} `json:"schema"`
-	UISchema interface{} `json:"uiSchema"`
-	Data     interface{} `json:"data,omitempty"`
+	Schema   interface{}                  `json:"schema"`
+	UISchema interface{}                  `json:"uiSchema"`
+	Data     interface{}                  `json:"data,omitempty"`
+	Timezone string                       `json:"timezone,omitempty"` // часовой пояс значений datetime в Data
+	Prefill  map[string]interface{}       `json:"prefill,omitempty"`  // значения полей из параметров ?prefill[поле]=значение
+	Labels   map[string]map[string]string `json:"labels,omitempty"`   // названия значений полей выбора при ?labels=map
+
+	Tables map[string]interface{} `json:"tables,omitempty"` // первые страницы табличных полей при ?include=tables
+}
+
+// Clone возвращает глубокую копию формы.
+// Обработчики и значения внутри interface{} (DefaultValue, ValidationRule.Value) копируются поверхностно
+func (f *Form) Clone() *Form {
+	if f == nil {
+		return nil
+	}
+
+	clone := *f
+
+	if f.Fields != nil {
+		clone.Fields = make([]Field, len(f.Fields))
+		for i := range f.Fields {
+			clone.Fields[i] = f.Fields[i].Clone()
+		}
+	}
+
+	if f.Tags != nil {
+		clone.Tags = append([]string(nil), f.Tags...)
+	}
+
+	if f.Prefill != nil {
+		clone.Prefill = append([]string(nil), f.Prefill...)
+	}
+
+	if f.Shortcuts != nil {
+		clone.Shortcuts = append([]Shortcut(nil), f.Shortcuts...)
+	}
+
+	if f.Groups != nil {
+		clone.Groups = make([]FieldGroup, len(f.Groups))
+		for i, group := range f.Groups {
+			group.Fields = append([]string(nil), group.Fields...)
+			clone.Groups[i] = group
+		}
+	}
+
+	if f.Approval != nil {
+		clone.Approval = &Approval{Roles: append([]string(nil), f.Approval.Roles...)}
+	}
+
+	if f.Concurrency != nil {
+		concurrency := *f.Concurrency
+		clone.Concurrency = &concurrency
+	}
+
+	if f.SLO != nil {
+		slo := *f.SLO
+		clone.SLO = &slo
+	}
+
+	if f.Meta != nil {
+		meta := *f.Meta
+		clone.Meta = &meta
+	}
+
+	if f.Patch != nil {
+		patch := *f.Patch
+		clone.Patch = &patch
+	}
+
+	if f.Actions != nil {
+		actions := *f.Actions
+		actions.Custom = append([]Action(nil), f.Actions.Custom...)
+		if f.Actions.Duplicate != nil {
+			duplicate := *f.Actions.Duplicate
+			duplicate.Exclude = append([]string(nil), duplicate.Exclude...)
+			actions.Duplicate = &duplicate
+		}
+		clone.Actions = &actions
+	}
+
+	return &clone
+}
+
+// Clone возвращает глубокую копию поля
+func (f Field) Clone() Field {
+	clone := f
+
+	if f.Options != nil {
+		clone.Options = cloneOptions(f.Options)
+	}
+
+	if f.Validation != nil {
+		clone.Validation = append([]ValidationRule(nil), f.Validation...)
+	}
+
+	if f.Examples != nil {
+		clone.Examples = append([]string(nil), f.Examples...)
+	}
+
+	if f.Config != nil {
+		clone.Config = make(map[string]interface{}, len(f.Config))
+		for key, value := range f.Config {
+			clone.Config[key] = value
+		}
+	}
+
+	if f.TableConfig != nil {
+		tableConfig := *f.TableConfig
+		if f.TableConfig.Columns != nil {
+			tableConfig.Columns = make([]TableColumn, len(f.TableConfig.Columns))
+			for i, column := range f.TableConfig.Columns {
+				column.Options = cloneOptions(column.Options)
+				column.Validation = append([]ValidationRule(nil), column.Validation...)
+				if column.Link != nil {
+					link := *column.Link
+					column.Link = &link
+				}
+				if column.Format != nil {
+					format := *column.Format
+					format.Badges = maps.Clone(format.Badges)
+					if format.Decimals != nil {
+						decimals := *format.Decimals
+						format.Decimals = &decimals
+					}
+					column.Format = &format
+				}
+				tableConfig.Columns[i] = column
+			}
+		}
+		if f.TableConfig.RowKey != nil {
+			rowKey := *f.TableConfig.RowKey
+			rowKey.Columns = append([]string(nil), rowKey.Columns...)
+			tableConfig.RowKey = &rowKey
+		}
+		clone.TableConfig = &tableConfig
+	}
+
+	if f.Accessibility != nil {
+		accessibility := *f.Accessibility
+		clone.Accessibility = &accessibility
+	}
+
+	if f.Link != nil {
+		link := *f.Link
+		clone.Link = &link
+	}
+
+	if f.Dictionary != nil {
+		dictionary := *f.Dictionary
+		clone.Dictionary = &dictionary
+	}
+
+	return clone
+}
+
+// cloneOptions возвращает копию вариантов выбора вместе с переводами названий
+func cloneOptions(options []SelectOption) []SelectOption {
+	if options == nil {
+		return nil
+	}
+	clone := make([]SelectOption, len(options))
+	for i, option := range options {
+		option.Labels = maps.Clone(option.Labels)
+		clone[i] = option
+	}
+	return clone
 }
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFormCloneDeepCopy проверяет, что изменения исходной формы не затрагивают копию
+func TestFormCloneDeepCopy(t *testing.T) {
+	newForm := func() *Form {
+		decimals := 2
+		return &Form{
+			Name: "orders",
+			Fields: []Field{
+				{
+					Name: "status", Type: FieldTypeSelect,
+					Options:    []SelectOption{SelectOption{Value: "new", Label: "Новый"}.WithLabel("en", "New")},
+					Validation: []ValidationRule{{Type: "required", Message: "Обязательно"}},
+					Examples:   []string{"new"},
+					Config:     map[string]interface{}{"placeholder": "Статус"},
+					Link:       &Link{Form: "statuses"},
+				},
+				{
+					Name: "items", Type: FieldTypeTable,
+					TableConfig: &TableConfig{
+						Columns: []TableColumn{{
+							Key: "kind", Type: FieldTypeSelect,
+							Options: []SelectOption{SelectOption{Value: "a", Label: "Первый"}.WithLabel("en", "First")},
+							Format:  &ColumnFormat{Decimals: &decimals, Badges: map[string]string{"a": "green"}},
+						}},
+						RowKey: &RowKey{Columns: []string{"kind"}},
+					},
+				},
+			},
+			Tags:     []string{"sales"},
+			Groups:   []FieldGroup{{Name: "main", Fields: []string{"status"}}},
+			Approval: &Approval{Roles: []string{"manager"}},
+			Actions: &Actions{
+				Custom:    []Action{{Name: "close", Label: "Закрыть"}},
+				Duplicate: &Duplicate{Exclude: []string{"id"}},
+			},
+		}
+	}
+
+	form := newForm()
+	clone := form.Clone()
+
+	form.Fields[0].Options[0].Labels["en"] = "Changed"
+	form.Fields[0].Validation[0].Message = "Changed"
+	form.Fields[0].Examples[0] = "changed"
+	form.Fields[0].Config["placeholder"] = "Changed"
+	form.Fields[0].Link.Form = "changed"
+	column := &form.Fields[1].TableConfig.Columns[0]
+	column.Options[0].Labels["en"] = "Changed"
+	*column.Format.Decimals = 5
+	column.Format.Badges["a"] = "red"
+	form.Fields[1].TableConfig.RowKey.Columns[0] = "changed"
+	form.Tags[0] = "changed"
+	form.Groups[0].Fields[0] = "changed"
+	form.Approval.Roles[0] = "changed"
+	form.Actions.Custom[0].Label = "Changed"
+	form.Actions.Duplicate.Exclude[0] = "changed"
+
+	want := newForm()
+	if !reflect.DeepEqual(clone, want) {
+		t.Errorf("копия изменилась вместе с исходной формой:\n%+v\nожидалось:\n%+v", clone, want)
+	}
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultUndoTTL окно отмены по умолчанию
+const DefaultUndoTTL = 30 * time.Second
+
+// UndoFunc компенсирующая функция, отменяющая результат действия
+type UndoFunc func(ctx context.Context) error
+
+// UndoableResult результат обработчика, который можно отменить в течение TTL.
+// Клиент получает данные Data и токен отмены в meta.undo ответа
+type UndoableResult struct {
+	Data  interface{}
+	Label string
+	TTL   time.Duration
+	Undo  UndoFunc
+}
+
+// Undoable оборачивает результат обработчика компенсирующей функцией.
+// Если ttl не задан, используется DefaultUndoTTL
+func Undoable(data interface{}, ttl time.Duration, undo UndoFunc) *UndoableResult {
+	return &UndoableResult{
+		Data: data,
+		TTL:  ttl,
+		Undo: undo,
+	}
+}
+
+// UndoInfo сообщает UI о возможности отмены действия (например, для показа toast)
+type UndoInfo struct {
+	Token     string    `json:"token"`
+	Label     string    `json:"label,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,259 @@
+package formist
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/koteyye/go-formist/form"
+	"github.com/koteyye/go-formist/types"
+)
+
+// reloadDebounce задержка перед перезагрузкой файла, чтобы пропустить серию событий от редактора
+const reloadDebounce = 100 * time.Millisecond
+
+// ReloadErrorHandler вызывается при ошибке загрузки определения формы из файла
+type ReloadErrorHandler func(path string, err error)
+
+// LoadForms загружает определения форм из JSON/YAML файлов директории и регистрирует их.
+// Обработчики уже зарегистрированных форм с тем же именем сохраняются
+func (a *Admin) LoadForms(dir string) error {
+	forms, err := form.LoadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for path, f := range forms {
+		if err := a.router.ValidateIcon(f.Icon); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	for path, f := range forms {
+		a.registerFileForm(path, f)
+	}
+
+	return nil
+}
+
+// WatchForms загружает формы из директории и следит за изменениями файлов.
+// Измененные определения проверяются и атомарно заменяют зарегистрированные формы,
+// удаленные файлы снимают форму с регистрации. Наблюдение прекращается при отмене ctx
+func (a *Admin) WatchForms(ctx context.Context, dir string, onError ReloadErrorHandler) error {
+	if err := a.LoadForms(dir); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("не удалось создать наблюдатель: %w", err)
+	}
+
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("не удалось наблюдать за директорией %s: %w", dir, err)
+	}
+
+	go a.watchLoop(ctx, watcher, onError)
+	return nil
+}
+
+// watchLoop обрабатывает события файловой системы
+func (a *Admin) watchLoop(ctx context.Context, watcher *fsnotify.Watcher, onError ReloadErrorHandler) {
+	defer watcher.Close()
+
+	var mu sync.Mutex
+	timers := make(map[string]*time.Timer)
+
+	reportError := func(path string, err error) {
+		if onError != nil {
+			onError(path, err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			for _, timer := range timers {
+				timer.Stop()
+			}
+			mu.Unlock()
+			return
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			reportError("", err)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			path := filepath.Clean(event.Name)
+			if !form.IsDefinitionFile(path) {
+				continue
+			}
+
+			mu.Lock()
+			if timer, exists := timers[path]; exists {
+				timer.Stop()
+			}
+			timers[path] = time.AfterFunc(reloadDebounce, func() {
+				mu.Lock()
+				delete(timers, path)
+				mu.Unlock()
+
+				if err := a.reloadFile(path); err != nil {
+					reportError(path, err)
+				}
+			})
+			mu.Unlock()
+		}
+	}
+}
+
+// reloadFile перечитывает файл определения формы.
+// Если файл удален, форма снимается с регистрации
+func (a *Admin) reloadFile(path string) error {
+	f, err := form.LoadFile(path)
+	if err != nil {
+		if !fileExists(path) {
+			a.unregisterFileForm(path)
+			return nil
+		}
+		// Ошибочное определение не заменяет работающую форму
+		return err
+	}
+	if err := a.router.ValidateIcon(f.Icon); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	a.registerFileForm(path, f)
+	return nil
+}
+
+// registerFileForm регистрирует форму из файла, сохраняя обработчики текущей регистрации
+func (a *Admin) registerFileForm(path string, f *types.Form) {
+	a.fileFormsMu.Lock()
+	if previous, ok := a.fileForms[path]; ok && previous != f.Key() {
+		a.router.UnregisterForm(previous)
+	}
+	a.fileForms[path] = f.Key()
+	a.fileFormsMu.Unlock()
+
+	a.replaceForm(f)
+}
+
+// replaceForm регистрирует определение формы, сохраняя обработчики текущей регистрации
+func (a *Admin) replaceForm(f *types.Form) {
+	if current, ok := a.router.Form(f.Key()); ok {
+		inheritHandlers(f, current)
+	}
+
+	a.RegisterForm(f)
+}
+
+// unregisterFileForm снимает с регистрации форму, загруженную из файла
+func (a *Admin) unregisterFileForm(path string) {
+	a.fileFormsMu.Lock()
+	name, ok := a.fileForms[path]
+	delete(a.fileForms, path)
+	a.fileFormsMu.Unlock()
+
+	if ok {
+		a.router.UnregisterForm(name)
+	}
+}
+
+// inheritHandlers переносит из текущей регистрации настройки, которые задаются только
+// в коде (поля с тегом json:"-") и поэтому отсутствуют в загруженном определении:
+// обработчики, объединение чтений, таймаут частей и функцию ключа строк таблиц
+func inheritHandlers(f, current *types.Form) {
+	if f.OnGet == nil {
+		f.OnGet = current.OnGet
+	}
+	if f.OnPost == nil {
+		f.OnPost = current.OnPost
+	}
+	if f.OnDryRun == nil {
+		f.OnDryRun = current.OnDryRun
+	}
+	if f.BatchTx == nil {
+		f.BatchTx = current.BatchTx
+	}
+	if f.Patch == nil {
+		f.Patch = current.Patch
+	}
+	if !f.Coalesce {
+		f.Coalesce = current.Coalesce
+	}
+	if f.PartTimeout == 0 {
+		f.PartTimeout = current.PartTimeout
+	}
+
+	if f.Actions != nil {
+		for i := range f.Actions.Custom {
+			if f.Actions.Custom[i].Handler != nil {
+				continue
+			}
+			if action, ok := current.Action(f.Actions.Custom[i].Name); ok {
+				f.Actions.Custom[i].Handler = action.Handler
+			}
+		}
+
+		duplicate := f.Actions.Duplicate
+		if duplicate != nil && duplicate.Load == nil && current.Actions != nil && current.Actions.Duplicate != nil {
+			duplicate.Load = current.Actions.Duplicate.Load
+		}
+	}
+
+	for i := range f.Fields {
+		var previous *types.Field
+		for j := range current.Fields {
+			if current.Fields[j].Name == f.Fields[i].Name {
+				previous = &current.Fields[j]
+				break
+			}
+		}
+		if previous == nil {
+			continue
+		}
+
+		if f.Fields[i].Suggest == nil {
+			f.Fields[i].Suggest = previous.Suggest
+		}
+
+		table, previousTable := f.Fields[i].TableConfig, previous.TableConfig
+		if table == nil || previousTable == nil {
+			continue
+		}
+		if table.OnGet == nil {
+			table.OnGet = previousTable.OnGet
+		}
+		if table.OnRowUpdate == nil {
+			table.OnRowUpdate = previousTable.OnRowUpdate
+		}
+		if previousTable.RowKey != nil && previousTable.RowKey.Func != nil {
+			if table.RowKey == nil {
+				table.RowKey = &types.RowKey{}
+			}
+			if table.RowKey.Func == nil {
+				table.RowKey.Func = previousTable.RowKey.Func
+			}
+		}
+	}
+}
+
+// fileExists проверяет существование файла
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
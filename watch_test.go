@@ -0,0 +1,109 @@
+package formist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/koteyye/go-formist/types"
+)
+
+// TestLoadFormsKeepsCodeSettings проверяет, что перезагрузка формы из файла сохраняет
+// настройки, которые задаются только в коде (поля с тегом json:"-")
+func TestLoadFormsKeepsCodeSettings(t *testing.T) {
+	handler := func(ctx context.Context, data map[string]interface{}) (interface{}, error) { return data, nil }
+	record := func(ctx context.Context, id string) (map[string]interface{}, error) { return nil, nil }
+	rowKey := func(row map[string]interface{}) string { return "key" }
+
+	admin := New()
+	admin.RegisterForm(&types.Form{
+		Name:        "orders",
+		Title:       "Заказы",
+		Coalesce:    true,
+		PartTimeout: 3 * time.Second,
+		OnPost:      handler,
+		OnGet:       func(ctx context.Context) (interface{}, error) { return nil, nil },
+		OnDryRun:    handler,
+		BatchTx:     func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) },
+		Patch: &types.Patch{Handler: func(ctx context.Context, id string, changes map[string]interface{}) (interface{}, error) {
+			return changes, nil
+		}},
+		Actions: &types.Actions{
+			Custom:    []types.Action{{Name: "close", Label: "Закрыть", Handler: handler}},
+			Duplicate: &types.Duplicate{Load: record},
+		},
+		Fields: []types.Field{
+			{Name: "city", Type: types.FieldTypeText, Label: "Город",
+				Suggest: func(ctx context.Context, prefix string) []string { return nil }},
+			{Name: "items", Type: types.FieldTypeTable, Label: "Позиции", TableConfig: &types.TableConfig{
+				Columns: []types.TableColumn{{Key: "id", Title: "ID", Type: types.FieldTypeText}},
+				OnGet: func(ctx context.Context, page, limit int, filters map[string]interface{}) (types.TableData, error) {
+					return types.TableData{}, nil
+				},
+				OnRowUpdate: func(ctx context.Context, id, key string, value interface{}) (interface{}, error) { return value, nil },
+				RowKey:      &types.RowKey{Func: rowKey},
+			}},
+		},
+	})
+
+	dir := t.TempDir()
+	definition := `name: orders
+title: Заказы (файл)
+actions:
+  custom:
+    - name: close
+      label: Закрыть
+  duplicate:
+    label: Копировать
+fields:
+  - name: city
+    type: text
+    label: Город
+  - name: items
+    type: table
+    label: Позиции
+    tableConfig:
+      columns:
+        - key: id
+          title: ID
+          type: text
+`
+	if err := os.WriteFile(filepath.Join(dir, "orders.yaml"), []byte(definition), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := admin.LoadForms(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	f, ok := admin.router.Form("orders")
+	if !ok {
+		t.Fatal("форма не зарегистрирована")
+	}
+	if f.Title != "Заказы (файл)" {
+		t.Fatalf("определение из файла не применено: %q", f.Title)
+	}
+
+	table := f.Fields[1].TableConfig
+	checks := map[string]bool{
+		"Coalesce":                f.Coalesce,
+		"PartTimeout":             f.PartTimeout == 3*time.Second,
+		"OnPost":                  f.OnPost != nil,
+		"OnGet":                   f.OnGet != nil,
+		"OnDryRun":                f.OnDryRun != nil,
+		"BatchTx":                 f.BatchTx != nil,
+		"Patch":                   f.Patch != nil && f.Patch.Handler != nil,
+		"Actions.Custom.Handler":  f.Actions.Custom[0].Handler != nil,
+		"Actions.Duplicate.Load":  f.Actions.Duplicate.Load != nil,
+		"Field.Suggest":           f.Fields[0].Suggest != nil,
+		"TableConfig.OnGet":       table.OnGet != nil,
+		"TableConfig.OnRowUpdate": table.OnRowUpdate != nil,
+		"TableConfig.RowKey.Func": table.RowKey != nil && table.RowKey.Func != nil,
+	}
+	for name, ok := range checks {
+		if !ok {
+			t.Errorf("%s потерян при перезагрузке", name)
+		}
+	}
+}
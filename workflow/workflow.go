@@ -0,0 +1,353 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/koteyye/go-formist/auth"
+	"github.com/koteyye/go-formist/id"
+	"github.com/koteyye/go-formist/privacy"
+)
+
+// Status представляет статус заявки на согласование
+type Status string
+
+// Статусы заявки
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+	StatusFailed   Status = "failed" // согласована, но обработчик вернул ошибку
+)
+
+// Ошибки workflow
+var (
+	ErrNotFound       = errors.New("заявка не найдена")
+	ErrAlreadyDecided = errors.New("решение по заявке уже принято")
+	ErrForbidden      = errors.New("нет прав на согласование заявки")
+	ErrSelfApproval   = errors.New("нельзя согласовать собственную заявку")
+)
+
+// Submission представляет отправку формы, ожидающую согласования
+type Submission struct {
+	ID          string                 `json:"id"`
+	Form        string                 `json:"form"`
+	Data        map[string]interface{} `json:"data"`
+	Roles       []string               `json:"roles"`
+	Status      Status                 `json:"status"`
+	SubmittedBy *auth.User             `json:"submittedBy,omitempty"`
+	SubmittedAt time.Time              `json:"submittedAt"`
+	DecidedBy   *auth.User             `json:"decidedBy,omitempty"`
+	DecidedAt   *time.Time             `json:"decidedAt,omitempty"`
+	Comment     string                 `json:"comment,omitempty"`
+	Result      interface{}            `json:"result,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// EventType представляет тип события workflow
+type EventType string
+
+// Типы событий
+const (
+	EventSubmitted EventType = "submitted"
+	EventApproved  EventType = "approved"
+	EventRejected  EventType = "rejected"
+	EventFailed    EventType = "failed"
+)
+
+// Event описывает изменение состояния заявки для уведомлений и аудита
+type Event struct {
+	Type       EventType   `json:"type"`
+	Submission *Submission `json:"submission"`
+	Actor      *auth.User  `json:"actor,omitempty"`
+	At         time.Time   `json:"at"`
+}
+
+// Notifier получает события workflow (уведомления, аудит)
+type Notifier func(ctx context.Context, event Event)
+
+// Store хранит заявки на согласование
+type Store interface {
+	Save(ctx context.Context, submission *Submission) error
+	Get(ctx context.Context, id string) (*Submission, error)
+	List(ctx context.Context, status Status) ([]*Submission, error)
+}
+
+// MemoryStore хранит заявки в памяти
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]*Submission
+}
+
+// NewMemoryStore создает хранилище заявок в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]*Submission),
+	}
+}
+
+// Save сохраняет копию заявки
+func (s *MemoryStore) Save(ctx context.Context, submission *Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone := *submission
+	s.items[submission.ID] = &clone
+	return nil
+}
+
+// Get возвращает копию заявки по ID
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Submission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	submission, ok := s.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	clone := *submission
+	return &clone, nil
+}
+
+// List возвращает заявки с указанным статусом (все, если статус пустой)
+func (s *MemoryStore) List(ctx context.Context, status Status) ([]*Submission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]*Submission, 0)
+	for _, submission := range s.items {
+		if status == "" || submission.Status == status {
+			clone := *submission
+			items = append(items, &clone)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].SubmittedAt.Before(items[j].SubmittedAt)
+	})
+	return items, nil
+}
+
+// Purge удаляет заявки с принятым решением, созданные раньше before.
+// Ожидающие решения заявки не удаляются
+func (s *MemoryStore) Purge(ctx context.Context, before time.Time, dryRun bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for id, submission := range s.items {
+		if submission.Status == StatusPending || !submission.SubmittedAt.Before(before) {
+			continue
+		}
+		count++
+		if !dryRun {
+			delete(s.items, id)
+		}
+	}
+	return count, nil
+}
+
+// ExportSubject возвращает заявки, автором или согласующим которых является субъект,
+// или содержащие его идентификатор в данных
+func (s *MemoryStore) ExportSubject(ctx context.Context, subject string) ([]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]interface{}, 0)
+	for _, submission := range s.items {
+		if submissionMatches(submission, subject) {
+			clone := *submission
+			records = append(records, &clone)
+		}
+	}
+	return records, nil
+}
+
+// EraseSubject обезличивает заявки субъекта: пользователи и совпадающие значения данных
+// заменяются на privacy.Erased
+func (s *MemoryStore) EraseSubject(ctx context.Context, subject string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, submission := range s.items {
+		if !submissionMatches(submission, subject) {
+			continue
+		}
+		count++
+
+		if submission.SubmittedBy != nil && privacy.Matches(submission.SubmittedBy.ID, subject) {
+			submission.SubmittedBy = &auth.User{ID: privacy.Erased}
+		}
+		if submission.DecidedBy != nil && privacy.Matches(submission.DecidedBy.ID, subject) {
+			submission.DecidedBy = &auth.User{ID: privacy.Erased}
+		}
+		data, _ := privacy.Redact(submission.Data, subject)
+		submission.Data, _ = data.(map[string]interface{})
+	}
+	return count, nil
+}
+
+// submissionMatches проверяет, связана ли заявка с субъектом
+func submissionMatches(submission *Submission, subject string) bool {
+	if submission.SubmittedBy != nil && privacy.Matches(submission.SubmittedBy.ID, subject) {
+		return true
+	}
+	if submission.DecidedBy != nil && privacy.Matches(submission.DecidedBy.ID, subject) {
+		return true
+	}
+	return privacy.Contains(submission.Data, subject)
+}
+
+// ExecuteFunc выполняет отложенный обработчик формы после согласования
+type ExecuteFunc func(ctx context.Context, submission *Submission) (interface{}, error)
+
+// Engine управляет заявками на согласование
+type Engine struct {
+	store    Store
+	notifier Notifier
+	ids      id.Generator
+	mu       sync.Mutex
+}
+
+// NewEngine создает движок согласования
+func NewEngine(store Store, notifier Notifier) *Engine {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Engine{
+		store:    store,
+		notifier: notifier,
+		ids:      id.Default(),
+	}
+}
+
+// SetIDGenerator устанавливает генератор идентификаторов заявок
+func (e *Engine) SetIDGenerator(g id.Generator) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.ids = g
+}
+
+// Submit создает заявку, ожидающую согласования пользователем с одной из ролей
+func (e *Engine) Submit(ctx context.Context, form string, data map[string]interface{}, roles []string) (*Submission, error) {
+	user, _ := auth.UserFromContext(ctx)
+
+	e.mu.Lock()
+	ids := e.ids
+	e.mu.Unlock()
+
+	submission := &Submission{
+		ID:          ids.NewID(),
+		Form:        form,
+		Data:        data,
+		Roles:       roles,
+		Status:      StatusPending,
+		SubmittedBy: user,
+		SubmittedAt: time.Now(),
+	}
+
+	if err := e.store.Save(ctx, submission); err != nil {
+		return nil, err
+	}
+
+	e.notify(ctx, EventSubmitted, submission, user)
+	return submission, nil
+}
+
+// Store возвращает хранилище заявок
+func (e *Engine) Store() Store {
+	return e.store
+}
+
+// Get возвращает заявку по ID
+func (e *Engine) Get(ctx context.Context, id string) (*Submission, error) {
+	return e.store.Get(ctx, id)
+}
+
+// List возвращает заявки с указанным статусом
+func (e *Engine) List(ctx context.Context, status Status) ([]*Submission, error) {
+	return e.store.List(ctx, status)
+}
+
+// Approve согласует заявку и выполняет отложенный обработчик
+func (e *Engine) Approve(ctx context.Context, id, comment string, execute ExecuteFunc) (*Submission, error) {
+	return e.decide(ctx, id, comment, true, execute)
+}
+
+// Reject отклоняет заявку
+func (e *Engine) Reject(ctx context.Context, id, comment string) (*Submission, error) {
+	return e.decide(ctx, id, comment, false, nil)
+}
+
+// decide принимает решение по заявке
+func (e *Engine) decide(ctx context.Context, id, comment string, approve bool, execute ExecuteFunc) (*Submission, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	submission, err := e.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if submission.Status != StatusPending {
+		return nil, ErrAlreadyDecided
+	}
+
+	user, _ := auth.UserFromContext(ctx)
+	if user == nil || !user.HasAnyRole(submission.Roles) {
+		return nil, ErrForbidden
+	}
+	if submission.SubmittedBy != nil && submission.SubmittedBy.ID == user.ID {
+		return nil, ErrSelfApproval
+	}
+
+	now := time.Now()
+	submission.DecidedBy = user
+	submission.DecidedAt = &now
+	submission.Comment = comment
+
+	event := EventRejected
+	submission.Status = StatusRejected
+
+	if approve {
+		event = EventApproved
+		submission.Status = StatusApproved
+
+		if execute != nil {
+			result, err := execute(ctx, submission)
+			if err != nil {
+				event = EventFailed
+				submission.Status = StatusFailed
+				submission.Error = err.Error()
+			} else {
+				submission.Result = result
+			}
+		}
+	}
+
+	if err := e.store.Save(ctx, submission); err != nil {
+		return nil, err
+	}
+
+	e.notify(ctx, event, submission, user)
+	return submission, nil
+}
+
+// notify отправляет событие в notifier
+func (e *Engine) notify(ctx context.Context, eventType EventType, submission *Submission, actor *auth.User) {
+	if e.notifier == nil {
+		return
+	}
+
+	e.notifier(ctx, Event{
+		Type:       eventType,
+		Submission: submission,
+		Actor:      actor,
+		At:         time.Now(),
+	})
+}